@@ -1,10 +1,35 @@
 package config
 
-import "be-awarenix/models"
+import (
+	"be-awarenix/models"
+	"log"
+)
 
 func Migrations() {
+	// Tabel refresh_tokens lama menyimpan token plaintext; sesuaikan sebelum AutoMigrate
+	if err := models.PrepareRefreshTokens(DB); err != nil {
+		log.Printf("Failed to prepare refresh tokens table: %v", err)
+	}
+	// Domain target dan member attribute kini unik per organisasi
+	if err := models.PrepareTenantUniqueIndexes(DB); err != nil {
+		log.Printf("Failed to prepare tenant unique indexes: %v", err)
+	}
+
 	// Auto-migrate models
 	DB.AutoMigrate(
-		&models.User{}, &models.Event{}, &models.Group{}, &models.EmailTemplate{}, &models.LandingPage{}, &models.SendingProfiles{}, &models.Menu{}, &models.Submenu{}, &models.Role{}, &models.Member{}, &models.EmailHeader{}, models.PhishSettings{}, models.ActivityLog{}, models.RoleMenuAccess{}, models.RoleSubmenuAccess{}, models.Campaign{}, models.Event{}, models.Recipient{},
+		&models.User{}, &models.Event{}, &models.Group{}, &models.EmailTemplate{}, &models.LandingPage{}, &models.SendingProfiles{}, &models.Menu{}, &models.Submenu{}, &models.Role{}, &models.Member{}, &models.EmailHeader{}, &models.EmailAttachment{}, &models.DirectoryProfile{}, &models.GroupDirectorySync{}, &models.DirectorySyncLog{}, &models.ScimToken{}, &models.ScimUser{}, &models.CampaignGroupSnapshot{}, &models.Person{}, &models.TrainingCompletion{}, &models.MemberAttribute{}, &models.Exclusion{}, &models.TargetDomain{}, &models.Holiday{}, &models.BlackoutWindow{}, &models.UserSession{}, &models.UserMfa{}, &models.MfaRecoveryCode{}, &models.MfaChallenge{}, &models.MfaRolePolicy{}, &models.LoginThrottle{}, &models.UserToken{}, &models.PasswordPolicy{}, &models.OidcProvider{}, &models.OidcRoleMapping{}, &models.UserIdentity{}, &models.OidcLoginState{}, &models.AuthSettings{}, &models.Permission{}, &models.RolePermission{}, &models.Organization{}, &models.ServiceAccount{}, &models.ApiKey{}, models.PhishSettings{}, models.ActivityLog{}, models.RoleMenuAccess{}, models.RoleSubmenuAccess{}, models.Campaign{}, models.Event{}, models.Recipient{}, models.RefreshToken{},
 	)
+
+	// AutoMigrate tidak mendeteksi perubahan nilai enum, jadi kolom events.type diselaraskan manual
+	DB.Migrator().AlterColumn(&models.Event{}, "Type")
+
+	// Tautkan member/recipient lama ke direktori people
+	if err := models.BackfillPeople(DB); err != nil {
+		log.Printf("Failed to backfill people: %v", err)
+	}
+
+	// Beri organisasi ke user dan data yang dibuat sebelum multi-tenant
+	if err := models.BackfillOrganizations(DB); err != nil {
+		log.Printf("Failed to backfill organizations: %v", err)
+	}
 }
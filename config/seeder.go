@@ -18,7 +18,17 @@ func HashPassword(password string) (string, error) {
 	return string(hash), nil
 }
 
+// defaultOrganizationID adalah organisasi data hasil seeder (dibuat oleh models.BackfillOrganizations saat migrasi).
+func defaultOrganizationID(db *gorm.DB) uint {
+	var org models.Organization
+	if err := db.Where("slug = ?", models.DefaultOrganizationSlug).First(&org).Error; err != nil {
+		log.Fatalf("Default organization not found: %v", err)
+	}
+	return org.ID
+}
+
 func SeedUsers(db *gorm.DB) {
+	organizationID := defaultOrganizationID(db)
 	usersToSeed := []models.User{
 		{
 			Name:         "Marco Antonio",
@@ -55,6 +65,7 @@ func SeedUsers(db *gorm.DB) {
 			}
 			// Update field PasswordHash dengan hasil hash
 			userData.PasswordHash = hashedPassword
+			userData.OrganizationID = organizationID
 
 			// Buat user di database
 			if err := db.Create(&userData).Error; err != nil {
@@ -77,6 +88,7 @@ func SeedRoles(db *gorm.DB) {
 		{
 			Name:      "Super Admin",
 			IsActive:  1,
+			BuiltIn:   true,
 			CreatedAt: time.Now(),
 			CreatedBy: 0,
 			UpdatedAt: time.Now(),
@@ -85,6 +97,7 @@ func SeedRoles(db *gorm.DB) {
 		{
 			Name:      "Admin",
 			IsActive:  1,
+			BuiltIn:   true,
 			CreatedAt: time.Now(),
 			CreatedBy: 0,
 			UpdatedAt: time.Now(),
@@ -93,6 +106,7 @@ func SeedRoles(db *gorm.DB) {
 		{
 			Name:      "Engineer",
 			IsActive:  1,
+			BuiltIn:   true,
 			CreatedAt: time.Now(),
 			CreatedBy: 0,
 			UpdatedAt: time.Now(),
@@ -116,6 +130,10 @@ func SeedRoles(db *gorm.DB) {
 		} else if err != nil {
 			log.Fatalf("Error checking for role '%s': %v", roleData.Name, err)
 		} else {
+			// Tandai role bawaan yang dibuat sebelum kolom built_in ada
+			if !existingRole.BuiltIn {
+				db.Model(&existingRole).Update("built_in", true)
+			}
 			log.Printf("Role with name '%s' already exists. Seeder skipped.", roleData.Name)
 		}
 	}
@@ -1126,6 +1144,7 @@ func SeedEmailTemplates(db *gorm.DB) {
 		},
 	}
 
+	organizationID := defaultOrganizationID(db)
 	for _, emailTemplateData := range emailTemplates {
 		var existingEmailTemplate models.EmailTemplate
 		err := db.Where("name = ?", emailTemplateData.Name).First(&existingEmailTemplate).Error
@@ -1134,6 +1153,7 @@ func SeedEmailTemplates(db *gorm.DB) {
 			log.Printf("Seeding email template '%s'...", emailTemplateData.Name)
 
 			// Buat email template di database
+			emailTemplateData.OrganizationID = organizationID
 			if err := db.Create(&emailTemplateData).Error; err != nil {
 				log.Fatalf("Failed to seed email template '%s': %v", emailTemplateData.Name, err)
 			}
@@ -2584,6 +2604,7 @@ func SeedLandingPages(db *gorm.DB) {
 		},
 	}
 
+	organizationID := defaultOrganizationID(db)
 	for _, landingPageData := range landingPages {
 		var existingLandingPage models.LandingPage
 		err := db.Where("name = ?", landingPageData.Name).First(&existingLandingPage).Error
@@ -2592,6 +2613,7 @@ func SeedLandingPages(db *gorm.DB) {
 			log.Printf("Seeding landing page '%s'...", landingPageData.Name)
 
 			// Buat landing page di database
+			landingPageData.OrganizationID = organizationID
 			if err := db.Create(&landingPageData).Error; err != nil {
 				log.Fatalf("Failed to seed landing page '%s': %v", landingPageData.Name, err)
 			}
@@ -2780,6 +2802,53 @@ func SeedRoleSubmenuAccess(db *gorm.DB) {
 	log.Println("Role Submenu Access seeding completed.")
 }
 
+// SeedPermissions menyinkronkan tabel permissions dengan models.PermissionCatalog. Izin baru diberikan
+// ke DefaultRoles-nya sekali saat dibuat; izin yang sudah ada tidak diberikan ulang.
+func SeedPermissions(db *gorm.DB) {
+	log.Println("Seeding Permissions...")
+
+	for _, def := range models.PermissionCatalog {
+		var permission models.Permission
+		err := db.Where("`key` = ?", def.Key).First(&permission).Error
+
+		if err != nil && err == gorm.ErrRecordNotFound {
+			permission = models.Permission{
+				Key:         def.Key,
+				Resource:    def.Resource(),
+				Action:      def.Action(),
+				Description: def.Description,
+				CreatedAt:   time.Now(),
+			}
+			if err := db.Create(&permission).Error; err != nil {
+				log.Fatalf("Failed to seed permission '%s': %v", def.Key, err)
+			}
+
+			for _, roleName := range def.DefaultRoles {
+				roleID, err := getRoleIDByName(db, roleName)
+				if err != nil {
+					log.Printf("Warning: Role '%s' not found for permission '%s'. Skipping.", roleName, def.Key)
+					continue
+				}
+				grant := models.RolePermission{
+					RoleID:       roleID,
+					PermissionID: permission.ID,
+					CreatedAt:    time.Now(),
+					UpdatedAt:    time.Now(),
+				}
+				if err := db.Create(&grant).Error; err != nil {
+					log.Fatalf("Failed to seed RolePermission for Role '%s', Permission '%s': %v", roleName, def.Key, err)
+				}
+			}
+			log.Printf("Permission '%s' seeded.", def.Key)
+		} else if err != nil {
+			log.Fatalf("Error checking permission '%s': %v", def.Key, err)
+		} else if permission.Description != def.Description {
+			db.Model(&permission).Update("description", def.Description)
+		}
+	}
+	log.Println("Permission seeding completed.")
+}
+
 // getRoleIDByName adalah fungsi helper untuk mendapatkan ID Role dari namanya
 func getRoleIDByName(db *gorm.DB, name string) (uint, error) {
 	var role models.Role
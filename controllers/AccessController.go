@@ -3,6 +3,8 @@ package controllers
 import (
 	"be-awarenix/config"
 	"be-awarenix/models"
+	"be-awarenix/services"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -45,12 +47,93 @@ func GetUserAccessPermissions(c *gin.Context) {
 		allowedMenuNames = append(allowedMenuNames, m.Name)
 	}
 
+	permissions, err := services.RolePermissionKeys(config.DB, roleID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to get permissions: " + err.Error(), "data": nil})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "User access permissions fetched successfully",
 		"data": gin.H{
 			"allowed_menus":    allowedMenuNames,
 			"allowed_submenus": allowedSubmenuUrls,
+			"permissions":      permissions,
 		},
 	})
 }
+
+// GetPermissionCatalog mengembalikan semua izin yang bisa diberikan ke role, dikelompokkan per resource.
+func GetPermissionCatalog(c *gin.Context) {
+	var permissions []models.Permission
+	if err := config.DB.Order("resource, id").Find(&permissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to get permissions: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Permission catalog fetched successfully",
+		"data":    permissions,
+	})
+}
+
+// GetRolePermissions mengembalikan key izin milik satu role.
+func GetRolePermissions(c *gin.Context) {
+	var role models.Role
+	if err := config.DB.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Role not found", "data": nil})
+		return
+	}
+	permissions, err := services.RolePermissionKeys(config.DB, role.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to get permissions: " + err.Error(), "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Role permissions fetched successfully",
+		"data":    gin.H{"roleId": role.ID, "roleName": role.Name, "permissions": permissions},
+	})
+}
+
+// UpdateRolePermissions mengganti seluruh izin role dengan daftar key pada body.
+func UpdateRolePermissions(c *gin.Context) {
+	idParam := c.Param("id")
+	var role models.Role
+	if err := config.DB.First(&role, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Role not found", "data": nil})
+		return
+	}
+	if err := services.RoleAccessEditable(role); err != nil {
+		services.LogActivity(config.DB, c, "Update Permissions", "Role", idParam, nil, nil, "failed", err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": err.Error(), "data": nil})
+		return
+	}
+	var input models.RolePermissionsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+
+	old, _ := services.RolePermissionKeys(config.DB, role.ID)
+	added, removed, err := services.SetRolePermissions(config.DB, role.ID, input.Permissions)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Update Permissions", "Role", idParam, old, input, "failed", "Failed to update role permissions: "+err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrUnknownPermission) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"status": "error", "message": "Failed to update role permissions: " + err.Error(), "data": nil})
+		return
+	}
+	current, _ := services.RolePermissionKeys(config.DB, role.ID)
+
+	services.LogActivity(config.DB, c, "Update Permissions", "Role", idParam, old, gin.H{"permissions": current, "added": added, "removed": removed}, "success",
+		"Permissions of role "+role.Name+" updated")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Role permissions updated successfully",
+		"data":    gin.H{"roleId": role.ID, "permissions": current, "added": added, "removed": removed},
+	})
+}
@@ -19,12 +19,17 @@ func GetActivityLogs(c *gin.Context) {
 	}
 
 	loggedInUserRole := userIDScope
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "10")
 	search := c.DefaultQuery("search", "")
 	actionFilter := c.DefaultQuery("action", "all")
 	userFilter := c.DefaultQuery("user", "all")
+	serviceAccountFilter := c.DefaultQuery("service_account", "all")
 	timeRangeFilter := c.DefaultQuery("time_range", "all")
 
 	page, err := strconv.Atoi(pageStr)
@@ -46,11 +51,16 @@ func GetActivityLogs(c *gin.Context) {
 	// Alias the primary users join to avoid conflicts and allow filtering
 	query = query.Joins("LEFT JOIN users AS users_for_name ON users_for_name.id = activity_logs.user_id")
 	query = query.Joins("LEFT JOIN users AS record_users ON record_users.id = activity_logs.record_id")
+	query = query.Joins("LEFT JOIN service_accounts ON service_accounts.id = activity_logs.service_account_id")
 
 	// Conditional filtering based on user role
 	if loggedInUserRole != 1 { // If the logged-in user is not admin
-		// Filter activity logs only for users whose role is NOT 1
-		query = query.Where("users_for_name.role != ?", 1)
+		// Filter activity logs only for users whose role is NOT 1 (service account tidak punya role)
+		query = query.Where("users_for_name.role != ? OR activity_logs.service_account_id IS NOT NULL", 1)
+	}
+	// Log hanya terlihat untuk organisasi pelaku aksi (user atau service account)
+	if !tenant.All {
+		query = query.Where("users_for_name.organization_id = ? OR service_accounts.organization_id = ?", tenant.OrganizationID, tenant.OrganizationID)
 	}
 
 	// Add search condition if provided
@@ -72,6 +82,11 @@ func GetActivityLogs(c *gin.Context) {
 		query = query.Where("activity_logs.user_id = ?", userFilter)
 	}
 
+	// Add Service Account filter
+	if serviceAccountFilter != "all" {
+		query = query.Where("activity_logs.service_account_id = ?", serviceAccountFilter)
+	}
+
 	// Add Time Range filter
 	if timeRangeFilter != "all" {
 		now := time.Now()
@@ -91,7 +106,7 @@ func GetActivityLogs(c *gin.Context) {
 	query.Count(&total)
 
 	// Fetch activity logs
-	if err := query.Select(`activity_logs.*, users_for_name.name AS user_name, record_users.name as record_name`).
+	if err := query.Select(`activity_logs.*, users_for_name.name AS user_name, service_accounts.name AS service_account_name, record_users.name as record_name`).
 		Offset(offset).
 		Limit(limit).
 		Order("activity_logs.timestamp DESC").
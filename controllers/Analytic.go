@@ -3,6 +3,7 @@ package controllers
 import (
 	"be-awarenix/config"
 	"be-awarenix/models"
+	"be-awarenix/services"
 	"net/http"
 	"time"
 
@@ -26,6 +27,11 @@ type MonthlyStatsResponse struct {
 
 func GetGrowthPercentage(c *gin.Context) {
 	dataType := c.DefaultQuery("type", "users")
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	tenantGroups := config.DB.Model(&models.Group{}).Select("id").Scopes(tenant.Scope)
 
 	now := time.Now()
 
@@ -44,14 +50,14 @@ func GetGrowthPercentage(c *gin.Context) {
 	switch dataType {
 	case "users":
 		// Hitung user bulan ini
-		err = config.DB.Model(&models.User{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.User{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count current month users"})
 			return
 		}
 
 		// Hitung user bulan lalu
-		err = config.DB.Model(&models.User{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.User{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count previous month users"})
 			return
@@ -59,14 +65,14 @@ func GetGrowthPercentage(c *gin.Context) {
 
 	case "groups":
 		// Hitung group bulan ini
-		err = config.DB.Model(&models.Group{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.Group{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count current month groups"})
 			return
 		}
 
 		// Hitung group bulan lalu
-		err = config.DB.Model(&models.Group{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.Group{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count previous month groups"})
 			return
@@ -74,14 +80,14 @@ func GetGrowthPercentage(c *gin.Context) {
 
 	case "emailtemplates":
 		// Hitung email template bulan ini
-		err = config.DB.Model(&models.EmailTemplate{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.EmailTemplate{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count current month email templates"})
 			return
 		}
 
 		// Hitung email template bulan lalu
-		err = config.DB.Model(&models.EmailTemplate{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.EmailTemplate{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count previous month email templates"})
 			return
@@ -89,14 +95,14 @@ func GetGrowthPercentage(c *gin.Context) {
 
 	case "landingpages":
 		// Hitung landing page bulan ini
-		err = config.DB.Model(&models.LandingPage{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.LandingPage{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count current month email templates"})
 			return
 		}
 
 		// Hitung landing page bulan lalu
-		err = config.DB.Model(&models.LandingPage{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.LandingPage{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count previous month email templates"})
 			return
@@ -104,14 +110,14 @@ func GetGrowthPercentage(c *gin.Context) {
 
 	case "sendingprofiles":
 		// Hitung landing page bulan ini
-		err = config.DB.Model(&models.SendingProfiles{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.SendingProfiles{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count current month email templates"})
 			return
 		}
 
 		// Hitung landing page bulan lalu
-		err = config.DB.Model(&models.SendingProfiles{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
+		err = config.DB.Scopes(tenant.Scope).Model(&models.SendingProfiles{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count previous month email templates"})
 			return
@@ -119,14 +125,14 @@ func GetGrowthPercentage(c *gin.Context) {
 
 	case "members":
 		// Hitung landing page bulan ini
-		err = config.DB.Model(&models.Member{}).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
+		err = config.DB.Model(&models.Member{}).Where("group_id IN (?)", tenantGroups).Where("created_at BETWEEN ? AND ?", currentMonthStart, currentMonthEnd).Count(&currentCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count current month member"})
 			return
 		}
 
 		// Hitung landing page bulan lalu
-		err = config.DB.Model(&models.Member{}).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
+		err = config.DB.Model(&models.Member{}).Where("group_id IN (?)", tenantGroups).Where("created_at BETWEEN ? AND ?", previousMonthStart, previousMonthEnd).Count(&previousCount).Error
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count previous month member"})
 			return
@@ -6,8 +6,12 @@ import (
 	"be-awarenix/services"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -28,8 +32,17 @@ func AuthLogin(c *gin.Context) {
 		return
 	}
 
+	// Batasi brute-force per akun dan per IP sebelum menyentuh data user
+	wait, err := services.CheckLoginAllowed(config.DB, input.Email, c.ClientIP(), time.Now())
+	if err != nil {
+		log.Printf("Failed to check login throttle: %v", err)
+	} else if wait > 0 {
+		tooManyLoginAttempts(c, input.Email, wait)
+		return
+	}
+
 	// Coba cari user dengan data role
-	err := config.DB.Table("users").
+	err = config.DB.Table("users").
 		Select(`users.*, roles.name AS role_name`).
 		Joins(`LEFT JOIN roles ON roles.id = users.role`).
 		Where("users.email = ?", input.Email).
@@ -37,13 +50,9 @@ func AuthLogin(c *gin.Context) {
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			// Log ketika user tidak ditemukan, recordID kosong atau bisa pakai email jika mau
-			services.LogActivity(config.DB, c, "Login", "Auth", "", nil, input, "failed", "Account haven't registered yet")
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"status":  "error",
-				"message": "Account haven't registered yet",
-				"error":   "User not found",
-			})
+			// Bandingkan dengan hash dummy agar waktu respons sama dengan akun yang ada
+			services.ComparePasswordDummy(input.Password)
+			loginFailed(c, input.Email, "", "Account haven't registered yet")
 		} else {
 			// Log error database lain
 			log.Printf("Database error during login: %v", err)
@@ -102,8 +111,16 @@ func AuthLogin(c *gin.Context) {
 	fullUserData.IsActive = userResp.IsActive
 	fullUserData.PasswordHash = userWithHash.PasswordHash
 
+	if err := services.ComparePassword(fullUserData.PasswordHash, input.Password); err != nil {
+		loginFailed(c, input.Email, fmt.Sprintf("%v", fullUserData.ID), "Invalid credentials")
+		return
+	}
+	// Hitungan kegagalan akun baru direset setelah semua faktor terverifikasi, agar password yang
+	// benar tidak membuka kesempatan menebak kode MFA tanpa batas.
+
+	// Status akun baru diungkap setelah password terbukti benar
 	if fullUserData.IsActive == 0 {
-		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, input, "failed", "Account is not active")
+		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, gin.H{"email": input.Email}, "failed", "Account is not active")
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"status":  "error",
 			"message": "Account is not active",
@@ -112,12 +129,118 @@ func AuthLogin(c *gin.Context) {
 		return
 	}
 
-	if err := services.ComparePassword(fullUserData.PasswordHash, input.Password); err != nil {
-		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, input, "failed", "Invalid credentials")
-		c.JSON(http.StatusUnauthorized, gin.H{
+	// Organisasi user dapat mewajibkan login lewat SSO
+	if allowed, err := services.LocalLoginAllowed(config.DB, userWithHash.OrganizationID); err != nil {
+		log.Printf("Failed to load auth settings: %v", err)
+	} else if !allowed {
+		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, gin.H{"email": input.Email}, "failed", "Password login is disabled")
+		c.JSON(http.StatusForbidden, gin.H{
 			"status":  "error",
-			"message": "Invalid credentials",
-			"error":   "Password mismatch",
+			"message": "Password login is disabled, please sign in with SSO",
+			"error":   "Local login disabled",
+		})
+		return
+	}
+
+	// MFA: password benar hanya menghasilkan challenge; JWT baru diterbitkan lewat /auth/mfa/verify
+	mfaEnabled, err := services.MfaEnabled(config.DB, fullUserData.ID)
+	if err == nil && !mfaEnabled {
+		var required bool
+		required, err = services.MfaRequiredForRole(config.DB, fullUserData.Role)
+		if err == nil && !required {
+			resetLoginFailures(fullUserData.Email)
+			completeLogin(c, fullUserData, input.Status, input.RefreshCookie, nil)
+			return
+		}
+	}
+	if err != nil {
+		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, nil, "failed", "Failed to check MFA: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to process login",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	mfaToken, mfaExp, err := services.CreateMfaChallenge(config.DB, fullUserData.ID, input.Status, input.RefreshCookie, !mfaEnabled, c.ClientIP())
+	if err != nil {
+		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, nil, "failed", "Could not create MFA challenge: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Could not create MFA challenge",
+			"error":   err.Error(),
+		})
+		return
+	}
+	services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, nil, "success", "Password verified, MFA challenge issued")
+	c.JSON(http.StatusOK, gin.H{
+		"status":              "mfa_required",
+		"message":             "Verification code required",
+		"mfa_token":           mfaToken,
+		"mfa_expires_at":      mfaExp.Unix(),
+		"enrollment_required": !mfaEnabled,
+	})
+}
+
+// loginFailed mencatat percobaan gagal dan mengirim pesan yang sama untuk semua penyebab
+// (email tidak terdaftar atau password salah) agar keberadaan akun tidak bocor.
+func loginFailed(c *gin.Context, email, recordID, reason string) {
+	recordLoginFailure(c, email, recordID, reason)
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"status":  "error",
+		"message": "Invalid email or password",
+		"error":   "Invalid credentials",
+	})
+}
+
+// resetLoginFailures mereset hitungan kegagalan akun setelah login berhasil sepenuhnya.
+func resetLoginFailures(email string) {
+	if err := services.ResetLoginFailures(config.DB, email); err != nil {
+		log.Printf("Failed to reset login failures: %v", err)
+	}
+}
+
+// recordLoginFailure menambah hitungan kegagalan akun dan IP (password maupun kode MFA) lalu mencatat lockout.
+func recordLoginFailure(c *gin.Context, email, recordID, reason string) {
+	accountLockedUntil, ipLockedUntil, err := services.RecordLoginFailure(config.DB, email, c.ClientIP(), time.Now())
+	if err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+	}
+	services.LogActivity(config.DB, c, "Login", "Auth", recordID, nil, gin.H{"email": email}, "failed", reason)
+	if accountLockedUntil != nil {
+		services.LogActivity(config.DB, c, "Lockout", "Auth", recordID, nil, gin.H{"email": email, "lockedUntil": accountLockedUntil}, "warning",
+			"Account locked after repeated failed logins until "+accountLockedUntil.Format(time.RFC3339))
+	}
+	if ipLockedUntil != nil {
+		services.LogActivity(config.DB, c, "Lockout", "Auth", "", nil, gin.H{"ip": c.ClientIP(), "lockedUntil": ipLockedUntil}, "warning",
+			"IP "+c.ClientIP()+" locked after repeated failed logins until "+ipLockedUntil.Format(time.RFC3339))
+	}
+}
+
+// tooManyLoginAttempts menolak login selama jeda progresif atau lockout masih berlaku.
+func tooManyLoginAttempts(c *gin.Context, email string, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	services.LogActivity(config.DB, c, "Login", "Auth", "", nil, gin.H{"email": email}, "failed", fmt.Sprintf("Login throttled, retry after %ds", seconds))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"status":      "error",
+		"message":     "Too many login attempts, please try again later",
+		"error":       "Too many login attempts",
+		"retry_after": seconds,
+	})
+}
+
+// completeLogin membuat session dan menerbitkan JWT serta refresh token untuk user yang sudah terverifikasi.
+// extra digabung ke response (mis. recovery code setelah enrollment MFA saat login).
+func completeLogin(c *gin.Context, fullUserData models.FullUserLoginData, status string, refreshCookie bool, extra gin.H) {
+	// User organisasi nonaktif tidak bisa login (kecuali pemegang izin lintas tenant)
+	if _, err := services.ResolveTenant(config.DB, &fullUserData.User, ""); err != nil {
+		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, nil, "failed", "Organization check failed: "+err.Error())
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  "error",
+			"message": "Your organization is not active",
+			"error":   err.Error(),
 		})
 		return
 	}
@@ -147,10 +270,29 @@ func AuthLogin(c *gin.Context) {
 		allowedMenuNames = append(allowedMenuNames, m.Name)
 	}
 
+	// Izin API role (resource:action) agar frontend bisa menyembunyikan aksi yang tidak diizinkan
+	permissions, err := services.RolePermissionKeys(config.DB, uint(fullUserData.Role))
+	if err != nil {
+		log.Printf("Failed to load permissions for role %d: %v", fullUserData.Role, err)
+	}
+
+	// Setiap login dicatat sebagai session dengan refresh token sendiri
+	keepLoggedIn := status == "KeepMeLoggedIn"
+	session, refreshToken, refreshExp, err := services.StartSession(config.DB, fullUserData.ID, keepLoggedIn, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, nil, "failed", "Could not create session: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Could not create session",
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	// GENERATE TOKEN
-	token, exp, err := services.GenerateJWT(fullUserData.ID, fullUserData.Email, input.Status)
+	token, exp, err := services.GenerateJWT(fullUserData.ID, fullUserData.Email, status, session.UID)
 	if err != nil {
-		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, input, "failed", "Could not create token: "+err.Error())
+		services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, nil, "failed", "Could not create token: "+err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "error",
 			"message": "Could not create token",
@@ -159,23 +301,6 @@ func AuthLogin(c *gin.Context) {
 		return
 	}
 
-	// // GENERATE REFRESH TOKEN
-	// refreshToken, refreshTokenExp, err := services.GenerateRefreshToken(fullUserData.ID)
-	// if err != nil {
-	// 	services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, input, "failed", "Could not create refresh token: "+err.Error())
-	// 	c.JSON(http.StatusInternalServerError, gin.H{
-	// 		"status":  "error",
-	// 		"message": "Could not create refresh token",
-	// 		"error":   err.Error(),
-	// 	})
-	// }
-
-	// // SAVE REFRESH TOKEN
-	// if err := services.SaveRefreshTokenToDB(config.DB, fullUserData.ID, refreshToken, refreshTokenExp); err != nil {
-	// 	log.Printf("Failed to save refresh token: %v", err)
-	// 	services.LogActivity(config.DB, c, "Login", "Auth", fmt.Sprintf("%v", fullUserData.ID), nil, input, "warning", "Failed to save refresh token: "+err.Error())
-	// }
-
 	// fullUserData.LastLogin = time.Now()
 	// if err := config.DB.Save(&fullUserData.User).Error; err != nil {
 	// 	log.Printf("Failed to update last_login: %v", err)
@@ -191,47 +316,312 @@ func AuthLogin(c *gin.Context) {
 		"role":             fullUserData.Role,
 		"role_name":        fullUserData.RoleName,
 		"company":          fullUserData.Company,
+		"organization_id":  fullUserData.OrganizationID,
 		"country":          fullUserData.Country,
 		"last_login":       fullUserData.LastLogin,
 		"allowed_menus":    allowedMenuNames,
 		"allowed_submenus": allowedSubmenuUrls,
+		"permissions":      permissions,
 	}
 
 	userid := int(fullUserData.ID)
 	services.LogActivity(config.DB, c, "Login", "Auth", strconv.Itoa(userid), nil, userdata, "success", "Login successful")
-	c.JSON(http.StatusOK, gin.H{
-		"status":     "success",
-		"message":    "Login successful",
-		"token":      token,
-		"user":       userdata,
-		"expires_at": exp,
-	})
+	response := gin.H{
+		"status":             "success",
+		"message":            "Login successful",
+		"token":              token,
+		"user":               userdata,
+		"expires_at":         exp,
+		"session_id":         session.ID,
+		"refresh_expires_at": refreshExp.Unix(),
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	writeRefreshToken(c, response, refreshToken, refreshExp, refreshCookie)
+	c.JSON(http.StatusOK, response)
 }
 
-func AuthLogout(c *gin.Context) {
+// loadMfaChallenge mengambil challenge dan user-nya; user harus masih ada dan aktif.
+func loadMfaChallenge(c *gin.Context, token string) (*models.MfaChallenge, *models.FullUserLoginData, bool) {
+	challenge, err := services.LoadMfaChallenge(config.DB, token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == services.ErrMfaChallengeInvalid {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"status":  "error",
+			"message": "MFA challenge is invalid or expired, please login again",
+			"error":   err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	var fullUserData models.FullUserLoginData
+	err = config.DB.Table("users").
+		Select(`users.*, roles.name AS role_name`).
+		Joins(`LEFT JOIN roles ON roles.id = users.role`).
+		Where("users.id = ?", challenge.UserID).
+		First(&fullUserData).Error
+	if err != nil || fullUserData.IsActive == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "Account is not active",
+			"error":   "Account is inactive",
+		})
+		return nil, nil, false
+	}
+	return challenge, &fullUserData, true
+}
+
+// MFA SETUP: user yang wajib MFA tapi belum mendaftar memakai challenge login untuk mendapatkan secret TOTP.
+func AuthMfaSetup(c *gin.Context) {
+	var input models.MfaChallengeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid input",
+			"error":   err.Error(),
+		})
+		return
+	}
+	challenge, user, ok := loadMfaChallenge(c, input.MfaToken)
+	if !ok {
+		return
+	}
+	if !challenge.Enrollment {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "MFA is already enabled for this account",
+			"error":   services.ErrMfaAlreadyEnabled.Error(),
+		})
+		return
+	}
+
+	enrollment, err := services.BeginMfaEnrollment(config.DB, user.ID, user.Email)
+	if err != nil {
+		services.LogActivity(config.DB, c, "MFA Setup", "Auth", fmt.Sprintf("%v", user.ID), nil, nil, "failed", "Failed to start MFA enrollment: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to start MFA enrollment",
+			"error":   err.Error(),
+		})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Successfully logged out",
+		"status":  "success",
+		"message": "Scan the QR code with your authenticator app, then verify a code to finish login",
+		"data":    enrollment,
 	})
 }
 
-func GetUserSession(c *gin.Context) {
-	var input models.GetUserSession
-
+// MFA VERIFY: tukar challenge + kode TOTP (atau recovery code) dengan JWT. Untuk challenge enrollment,
+// kode pertama sekaligus mengaktifkan MFA dan recovery code dikembalikan satu kali.
+func AuthMfaVerify(c *gin.Context) {
+	var input models.MfaChallengeInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"Success": false,
-			"Message": "Invalid request body",
-			"Error":   err.Error(),
+			"status":  "error",
+			"message": "Invalid input",
+			"error":   err.Error(),
 		})
 		return
 	}
+	challenge, user, ok := loadMfaChallenge(c, input.MfaToken)
+	if !ok {
+		return
+	}
+	userID := fmt.Sprintf("%v", user.ID)
+
+	// Kode MFA tunduk pada throttle akun/IP yang sama dengan password
+	wait, err := services.CheckLoginAllowed(config.DB, user.Email, c.ClientIP(), time.Now())
+	if err != nil {
+		log.Printf("Failed to check login throttle: %v", err)
+	} else if wait > 0 {
+		tooManyLoginAttempts(c, user.Email, wait)
+		return
+	}
+
+	var extra gin.H
+	if challenge.Enrollment {
+		var codes []string
+		if codes, err = services.ActivateMfa(config.DB, user.ID, input.Code); err == nil {
+			extra = gin.H{"recovery_codes": codes}
+		}
+	} else {
+		err = services.VerifyMfa(config.DB, user.ID, input.Code, input.RecoveryCode)
+	}
+	if err != nil {
+		switch err {
+		case services.ErrMfaCodeInvalid:
+			services.FailMfaChallenge(config.DB, challenge)
+			recordLoginFailure(c, user.Email, userID, "Invalid MFA code")
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"status":  "error",
+				"message": "Invalid verification code",
+				"error":   err.Error(),
+			})
+		case services.ErrMfaNotEnrolled, services.ErrMfaAlreadyEnabled:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Start MFA setup before verifying a code",
+				"error":   err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to verify code",
+				"error":   err.Error(),
+			})
+		}
+		return
+	}
+
+	if err := services.ConsumeMfaChallenge(config.DB, challenge); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "MFA challenge is invalid or expired, please login again",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if challenge.Enrollment {
+		services.LogActivity(config.DB, c, "Enable", moduleNameMfa, userID, nil, nil, "success", "MFA enabled during login")
+	}
+	resetLoginFailures(user.Email)
+	completeLogin(c, *user, challenge.LoginStatus, challenge.RefreshCookie, extra)
+}
+
+// writeRefreshToken mengirim refresh token sebagai cookie httpOnly (tidak terbaca JavaScript) atau di body response.
+func writeRefreshToken(c *gin.Context, response gin.H, refreshToken string, expiresAt time.Time, useCookie bool) {
+	if !useCookie {
+		response["refresh_token"] = refreshToken
+		return
+	}
+	secure := strings.HasPrefix(os.Getenv("APP_URL"), "https://")
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(services.RefreshTokenCookieName, refreshToken, int(time.Until(expiresAt).Seconds()), services.RefreshTokenCookiePath, "", secure, true)
+}
+
+// REFRESH: tukar refresh token (body "refresh_token" atau cookie) dengan access token dan refresh token baru.
+func AuthRefresh(c *gin.Context) {
+	var input models.RefreshTokenInput
+	_ = c.ShouldBindJSON(&input) // body boleh kosong jika token dikirim lewat cookie
+
+	fromCookie := false
+	refreshToken := strings.TrimSpace(input.RefreshToken)
+	if refreshToken == "" {
+		if cookie, err := c.Cookie(services.RefreshTokenCookieName); err == nil && cookie != "" {
+			refreshToken, fromCookie = cookie, true
+		}
+	}
+	if refreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Refresh token is required",
+			"error":   "Missing refresh token",
+		})
+		return
+	}
+
+	session, nextToken, refreshExp, err := services.RotateRefreshToken(config.DB, refreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		recordID := ""
+		if session != nil {
+			recordID = strconv.Itoa(int(session.UserID))
+		}
+		switch err {
+		case services.ErrRefreshTokenReused:
+			services.LogActivity(config.DB, c, "Refresh", "Auth", recordID, nil, gin.H{"session_id": session.ID}, "failed", "Refresh token reuse detected, session revoked")
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"status":  "error",
+				"message": "Refresh token was already used. The session has been revoked, please log in again.",
+				"error":   err.Error(),
+			})
+		case services.ErrRefreshTokenInvalid:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"status":  "error",
+				"message": "Invalid or expired refresh token",
+				"error":   err.Error(),
+			})
+		default:
+			log.Printf("Failed to rotate refresh token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to refresh session",
+				"error":   err.Error(),
+			})
+		}
+		return
+	}
 
 	var user models.User
-	if err := config.DB.Select("id", "name", "email", "position", "role").First(&user, input.ID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+	if err := config.DB.First(&user, session.UserID).Error; err != nil || user.IsActive == 0 {
+		services.RevokeSession(config.DB, session.ID, models.SessionRevokedInactive)
+		services.LogActivity(config.DB, c, "Refresh", "Auth", strconv.Itoa(int(session.UserID)), nil, nil, "failed", "Account is not active")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "Account is not active",
+			"error":   "Account is inactive",
+		})
+		return
+	}
+
+	status := ""
+	if session.KeepLoggedIn {
+		status = "KeepMeLoggedIn"
+	}
+	token, exp, err := services.GenerateJWT(user.ID, user.Email, status, session.UID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Could not create token",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"status":             "success",
+		"message":            "Token refreshed",
+		"token":              token,
+		"expires_at":         exp,
+		"session_id":         session.ID,
+		"refresh_expires_at": refreshExp.Unix(),
+	}
+	writeRefreshToken(c, response, nextToken, refreshExp, fromCookie)
+	c.JSON(http.StatusOK, response)
+}
+
+// LOGOUT: cabut session saat ini sehingga access token dan refresh token-nya langsung tidak berlaku.
+func AuthLogout(c *gin.Context) {
+	_, session, ok := currentSession(c)
+	if !ok {
+		return
+	}
+	if err := services.RevokeSession(config.DB, session.ID, models.SessionRevokedLogout); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to logout",
+		})
+		return
+	}
+	clearRefreshCookie(c)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully logged out",
+	})
+}
+
+// GetUserSession mengembalikan profil user yang sedang login. User diambil dari token (context), bukan dari
+// body request, sehingga endpoint self-service ini tidak bisa dipakai membaca user lain.
+func GetUserSession(c *gin.Context) {
+	value, _ := c.Get("user")
+	user, ok := value.(*models.User)
+	if !ok || user.ID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
 			"Success": false,
-			"Message": "User not found",
-			"Error":   err.Error(),
+			"Message": "User not authenticated",
 		})
 		return
 	}
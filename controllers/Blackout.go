@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Blackout window berlaku untuk semua campaign organisasi, jadi hanya admin yang boleh mengubahnya.
+const moduleNameBlackout = "Blackout Window"
+
+const maxBlackoutImportSize = 2 << 20 // 2 MB
+
+func findBlackoutWindow(c *gin.Context) (*models.BlackoutWindow, bool) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return nil, false
+	}
+	var window models.BlackoutWindow
+	if err := config.DB.Scopes(tenant.Scope).First(&window, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Blackout window not found", "data": nil})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch blackout window", "data": err.Error()})
+		return nil, false
+	}
+	return &window, true
+}
+
+func blackoutWindowResponse(w models.BlackoutWindow, now time.Time) models.BlackoutWindowResponse {
+	return models.BlackoutWindowResponse{
+		BlackoutWindow: w,
+		Active:         services.BlackoutActiveAt(w, now) != nil,
+		NextOccurrence: services.NextBlackoutOccurrence(w, now),
+	}
+}
+
+// CREATE
+func RegisterBlackoutWindow(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var input models.BlackoutWindowInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameBlackout, "", nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	window := models.BlackoutWindow{
+		OrganizationID: tenant.OrganizationID,
+		Name:           strings.TrimSpace(input.Name),
+		Reason:         strings.TrimSpace(input.Reason),
+		StartAt:        input.StartAt,
+		EndAt:          input.EndAt,
+		Frequency:      input.Frequency,
+		Interval:       input.Interval,
+		RepeatUntil:    input.RepeatUntil,
+		Source:         models.BlackoutSourceManual,
+		CreatedAt:      time.Now(),
+		CreatedBy:      userID,
+		UpdatedAt:      time.Now(),
+		UpdatedBy:      userID,
+	}
+	if err := services.ValidateBlackoutWindow(&window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := config.DB.Create(&window).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameBlackout, "", nil, window, "error", "Failed to create blackout window: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to create blackout window",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameBlackout, strconv.FormatUint(uint64(window.ID), 10), nil, window, "success", "Blackout window created: "+window.Name)
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Blackout window created successfully",
+		"data":    blackoutWindowResponse(window, time.Now()),
+	})
+}
+
+// READ: ?status=upcoming (default, sedang berlangsung atau akan datang) | active | all
+func GetBlackoutWindows(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	status := c.DefaultQuery("status", "upcoming")
+	if status != "upcoming" && status != "active" && status != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid status. Use upcoming, active or all.", "data": nil})
+		return
+	}
+
+	var windows []models.BlackoutWindow
+	if err := config.DB.Scopes(tenant.Scope).Order("start_at").Find(&windows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch blackout windows",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	responses := make([]models.BlackoutWindowResponse, 0, len(windows))
+	for _, w := range windows {
+		resp := blackoutWindowResponse(w, now)
+		if (status == "active" && !resp.Active) || (status == "upcoming" && resp.NextOccurrence == nil) {
+			continue
+		}
+		responses = append(responses, resp)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Blackout windows retrieved successfully",
+		"data":    responses,
+		"total":   len(responses),
+	})
+}
+
+// UPDATE
+func UpdateBlackoutWindow(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	window, ok := findBlackoutWindow(c)
+	if !ok {
+		return
+	}
+
+	var input models.BlackoutWindowInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameBlackout, c.Param("id"), nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	old := *window
+	window.Name = strings.TrimSpace(input.Name)
+	window.Reason = strings.TrimSpace(input.Reason)
+	window.StartAt = input.StartAt
+	window.EndAt = input.EndAt
+	window.Frequency = input.Frequency
+	window.Interval = input.Interval
+	window.RepeatUntil = input.RepeatUntil
+	window.UpdatedAt = time.Now()
+	window.UpdatedBy = userID
+	if err := services.ValidateBlackoutWindow(window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := config.DB.Save(window).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameBlackout, c.Param("id"), old, window, "error", "Failed to update blackout window: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to update blackout window",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameBlackout, c.Param("id"), old, window, "success", "Blackout window updated: "+window.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Blackout window updated successfully",
+		"data":    blackoutWindowResponse(*window, time.Now()),
+	})
+}
+
+// DELETE
+func DeleteBlackoutWindow(c *gin.Context) {
+	window, ok := findBlackoutWindow(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(window).Error; err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameBlackout, c.Param("id"), window, nil, "error", "Failed to delete blackout window: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete blackout window",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameBlackout, c.Param("id"), window, nil, "success", "Blackout window deleted: "+window.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Blackout window deleted successfully",
+		"data":    nil,
+	})
+}
+
+// IMPORT: multipart field "file" berisi file iCalendar (.ics). Event dengan UID yang sudah pernah
+// diimpor diperbarui, sehingga kalender yang sama bisa diimpor ulang setiap tahun.
+func ImportBlackoutWindows(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "File is required.",
+			"data":    err.Error(),
+		})
+		return
+	}
+	if fileHeader.Size > maxBlackoutImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"status":  "error",
+			"message": "File is too large. Maximum size is 2 MB.",
+			"data":    nil,
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Failed to read uploaded file.",
+			"data":    err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	events, err := services.ParseICalendar(file)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Import", moduleNameBlackout, "", nil, fileHeader.Filename, "error", "Invalid iCalendar file: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid iCalendar file: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var result models.BlackoutImportResult
+	now := time.Now()
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for i, ev := range events {
+			window, err := services.BlackoutFromICalEvent(ev)
+			if err != nil {
+				result.Skipped++
+				result.Errors = append(result.Errors, fmt.Sprintf("event %d (%s): %v", i+1, window.Name, err))
+				continue
+			}
+			window.OrganizationID = tenant.OrganizationID
+			window.UpdatedAt, window.UpdatedBy = now, userID
+
+			var existing models.BlackoutWindow
+			if window.ExternalUID != "" &&
+				tx.Scopes(services.InOrganization(tenant.OrganizationID)).Where("source = ? AND external_uid = ?", models.BlackoutSourceICal, window.ExternalUID).First(&existing).Error == nil {
+				window.ID, window.CreatedAt, window.CreatedBy = existing.ID, existing.CreatedAt, existing.CreatedBy
+				if err := tx.Save(&window).Error; err != nil {
+					return err
+				}
+				result.Updated++
+				continue
+			}
+			window.CreatedAt, window.CreatedBy = now, userID
+			if err := tx.Create(&window).Error; err != nil {
+				return err
+			}
+			result.Created++
+		}
+		return nil
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Import", moduleNameBlackout, "", nil, fileHeader.Filename, "error", "Failed to import blackout windows: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to import blackout windows",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Import", moduleNameBlackout, "", nil, result, "success",
+		fmt.Sprintf("Imported %s: %d created, %d updated, %d skipped", fileHeader.Filename, result.Created, result.Updated, result.Skipped))
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Blackout windows imported",
+		"data":    result,
+	})
+}
@@ -19,6 +19,10 @@ import (
 
 // Create
 func RegisterCampaign(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 	var input models.CampaignRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		validationErrors := services.ParseValidationErrors(err)
@@ -68,9 +72,21 @@ func RegisterCampaign(c *gin.Context) {
 		sendEmailBy = nil
 	}
 
-	// Verifikasi keberadaan Group, EmailTemplate, LandingPage, SendingProfile
+	delivery, err := services.NormalizeDeliveryWindow(input.Delivery)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Create", "Campaign", "", input, nil, "error", "Invalid delivery window: "+err.Error()) // Log Error
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid delivery window: " + err.Error(),
+			"fields":  map[string]string{"delivery": err.Error()},
+		})
+		return
+	}
+
+	// Verifikasi keberadaan Group, EmailTemplate, LandingPage, SendingProfile di organisasi campaign
+	orgID := tenant.OrganizationID
 	var group models.Group
-	if err := config.DB.First(&group, input.GroupID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganization(orgID)).First(&group, input.GroupID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Create", "Campaign", "", input, nil, "error", "Group ID not found") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -81,7 +97,7 @@ func RegisterCampaign(c *gin.Context) {
 	}
 
 	var emailTemplate models.EmailTemplate
-	if err := config.DB.First(&emailTemplate, input.EmailTemplateID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganizationOrSystem(orgID)).First(&emailTemplate, input.EmailTemplateID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Create", "Campaign", "", input, nil, "error", "Email Template ID not found") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -92,7 +108,7 @@ func RegisterCampaign(c *gin.Context) {
 	}
 
 	var landingPage models.LandingPage
-	if err := config.DB.First(&landingPage, input.LandingPageID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganizationOrSystem(orgID)).First(&landingPage, input.LandingPageID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Create", "Campaign", "", input, nil, "error", "Landing Page ID not found") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -103,7 +119,7 @@ func RegisterCampaign(c *gin.Context) {
 	}
 
 	var sendingProfile models.SendingProfiles
-	if err := config.DB.First(&sendingProfile, input.SendingProfileID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganization(orgID)).First(&sendingProfile, input.SendingProfileID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Create", "Campaign", "", input, nil, "error", "Sending Profile ID not found") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -122,7 +138,10 @@ func RegisterCampaign(c *gin.Context) {
 		LandingPageID:    input.LandingPageID,
 		SendingProfileID: input.SendingProfileID,
 		URL:              input.URL,
+		Delivery:         delivery,
+		OrganizationID:   orgID,
 		CreatedBy:        int(input.CreatedBy),
+		ServiceAccountID: services.ActingServiceAccountID(c),
 		CreatedAt:        time.Now(),
 		Status:           "pending",
 	}
@@ -147,6 +166,7 @@ func RegisterCampaign(c *gin.Context) {
 			SendEmailBy: campaign.SendEmailBy,
 			URL:         campaign.URL,
 			Status:      campaign.Status,
+			Delivery:    campaign.Delivery,
 		},
 	})
 }
@@ -170,14 +190,11 @@ func GetCampaigns(c *gin.Context) {
 		db = db.Where("name LIKE ?", "%"+search+"%")
 	}
 
-	userIDScope, roleScope, errorStatus := services.GetRoleScope(c)
-	if !errorStatus {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
 		return
 	}
-
-	if roleScope != 1 {
-		db = db.Where("created_by = ?", userIDScope)
-	}
+	db = db.Scopes(tenant.Scope)
 
 	// 4. Hitung total data (after filter)
 	var total int64
@@ -243,19 +260,18 @@ func GetCampaigns(c *gin.Context) {
 		config.DB.Model(&models.Event{}).Where("campaign_id = ? AND type = ?", camp.ID, models.Reported).Count(&reportedCount)
 		reported = int(reportedCount)
 
+		var attachmentOpenedCount int64
+		config.DB.Model(&models.Event{}).Where("campaign_id = ? AND type = ?", camp.ID, models.AttachmentOpened).Count(&attachmentOpenedCount)
+
+		var qrScannedCount int64
+		config.DB.Model(&models.Event{}).Where("campaign_id = ? AND type = ?", camp.ID, models.Scanned).Count(&qrScannedCount)
+
 		CampaignUID := services.EncodeID(int(camp.ID))
 
 		// Ambil createdByName dan updatedByName
-		createdByName := ""
+		createdByName := services.CreatorName(config.DB, camp.CreatedBy, camp.ServiceAccountID)
 		updatedByName := ""
 
-		if camp.CreatedBy != 0 {
-			var createdByUser models.User
-			if err := config.DB.Select("name").First(&createdByUser, camp.CreatedBy).Error; err == nil {
-				createdByName = createdByUser.Name
-			}
-		}
-
 		if camp.UpdatedBy != 0 {
 			var updatedByUser models.User
 			if err := config.DB.Select("name").First(&updatedByUser, camp.UpdatedBy).Error; err == nil {
@@ -285,11 +301,14 @@ func GetCampaigns(c *gin.Context) {
 			UpdatedBy:          int(camp.UpdatedBy),
 			UpdatedByName:      updatedByName,
 			Status:             camp.Status,
+			Delivery:           camp.Delivery,
 			EmailSent:          emailSent,
 			EmailOpened:        emailOpened,
 			EmailClicks:        clicks,
 			EmailSubmitted:     submitted,
 			EmailReported:      reported,
+			AttachmentOpened:   int(attachmentOpenedCount),
+			QRScanned:          int(qrScannedCount),
 			Participants:       nil,
 			TimelineEvents:     nil,
 		})
@@ -323,21 +342,13 @@ func GetCampaignsRoleScopeParent(c *gin.Context) {
 		db = db.Where("name LIKE ?", "%"+search+"%")
 	}
 
-	// 4. Retrieve role-scope: userIDScope, roleScope, parentID, ok
-	userIDScope, roleScope, parentID, ok := services.GetRoleScopeDashboard(c)
+	// 4-5. Batasi ke campaign organisasi user (super admin: semua organisasi)
+	tenant, ok := services.GetTenant(c)
 	if !ok {
 		// service menangani error response
 		return
 	}
-
-	// 5. Apply permission filter: non-admin sees own + parent’s campaigns
-	if roleScope != 1 {
-		scopeIDs := []int{userIDScope}
-		if parentID > 0 {
-			scopeIDs = append(scopeIDs, parentID)
-		}
-		db = db.Where("created_by IN ?", scopeIDs)
-	}
+	db = db.Scopes(tenant.Scope)
 
 	// 6. Count total data after filters
 	var total int64
@@ -371,7 +382,7 @@ func GetCampaignsRoleScopeParent(c *gin.Context) {
 	out := make([]models.CampaignResponse, 0, len(campaigns))
 	for _, camp := range campaigns {
 		// Counts per event type
-		var sentCount, openedCount, clickedCount, submittedCount, reportedCount int64
+		var sentCount, openedCount, clickedCount, submittedCount, reportedCount, attachmentOpenedCount, qrScannedCount int64
 		config.DB.
 			Model(&models.Recipient{}).
 			Where("campaign_id = ? AND status = ?", camp.ID, "sent").
@@ -392,16 +403,18 @@ func GetCampaignsRoleScopeParent(c *gin.Context) {
 			Model(&models.Event{}).
 			Where("campaign_id = ? AND type = ?", camp.ID, models.Reported).
 			Count(&reportedCount)
+		config.DB.
+			Model(&models.Event{}).
+			Where("campaign_id = ? AND type = ?", camp.ID, models.AttachmentOpened).
+			Count(&attachmentOpenedCount)
+		config.DB.
+			Model(&models.Event{}).
+			Where("campaign_id = ? AND type = ?", camp.ID, models.Scanned).
+			Count(&qrScannedCount)
 
 		// Resolve createdByName & updatedByName
-		createdByName, updatedByName := "", ""
-		if camp.CreatedBy != 0 {
-			var u models.User
-			if err := config.DB.Select("name").
-				First(&u, camp.CreatedBy).Error; err == nil {
-				createdByName = u.Name
-			}
-		}
+		createdByName := services.CreatorName(config.DB, camp.CreatedBy, camp.ServiceAccountID)
+		updatedByName := ""
 		if camp.UpdatedBy != 0 {
 			var u models.User
 			if err := config.DB.Select("name").
@@ -432,11 +445,14 @@ func GetCampaignsRoleScopeParent(c *gin.Context) {
 			UpdatedBy:          int(camp.UpdatedBy),
 			UpdatedByName:      updatedByName,
 			Status:             camp.Status,
+			Delivery:           camp.Delivery,
 			EmailSent:          int(sentCount),
 			EmailOpened:        int(openedCount),
 			EmailClicks:        int(clickedCount),
 			EmailSubmitted:     int(submittedCount),
 			EmailReported:      int(reportedCount),
+			AttachmentOpened:   int(attachmentOpenedCount),
+			QRScanned:          int(qrScannedCount),
 			Participants:       nil,
 			TimelineEvents:     nil,
 		})
@@ -464,7 +480,7 @@ func GetCampaignDetail(c *gin.Context) {
 	}
 
 	// 2. Permission scope
-	userIDScope, roleScope, parentID, ok := services.GetRoleScopeDashboard(c)
+	tenant, ok := services.GetTenant(c)
 	if !ok {
 		return
 	}
@@ -473,15 +489,8 @@ func GetCampaignDetail(c *gin.Context) {
 	var campaign models.Campaign
 	db := config.DB.Debug().
 		Model(&models.Campaign{}).
-		Where("id = ?", idCampaign)
-
-	if roleScope != 1 {
-		scopeIDs := []int{userIDScope}
-		if parentID > 0 {
-			scopeIDs = append(scopeIDs, parentID)
-		}
-		db = db.Where("created_by IN ?", scopeIDs)
-	}
+		Where("id = ?", idCampaign).
+		Scopes(tenant.Scope)
 
 	if err := db.
 		Preload("Group").
@@ -499,12 +508,14 @@ func GetCampaignDetail(c *gin.Context) {
 
 	// 4. Compute high-level metrics
 	var (
-		sentCount      int64
-		openedCount    int64
-		clickedCount   int64
-		submittedCount int64
-		reportedCount  int64
-		totalMembers   int64
+		sentCount             int64
+		openedCount           int64
+		clickedCount          int64
+		submittedCount        int64
+		reportedCount         int64
+		attachmentOpenedCount int64
+		qrScannedCount        int64
+		totalMembers          int64
 	)
 
 	config.DB.Model(&models.Recipient{}).
@@ -522,6 +533,12 @@ func GetCampaignDetail(c *gin.Context) {
 	config.DB.Model(&models.Event{}).
 		Where("campaign_id = ? AND type = ?", campaign.ID, models.Reported).
 		Count(&reportedCount)
+	config.DB.Model(&models.Event{}).
+		Where("campaign_id = ? AND type = ?", campaign.ID, models.AttachmentOpened).
+		Count(&attachmentOpenedCount)
+	config.DB.Model(&models.Event{}).
+		Where("campaign_id = ? AND type = ?", campaign.ID, models.Scanned).
+		Count(&qrScannedCount)
 	config.DB.Model(&models.Member{}).
 		Where("group_id = ?", campaign.GroupID).
 		Count(&totalMembers)
@@ -562,8 +579,11 @@ func GetCampaignDetail(c *gin.Context) {
 		status := "-"
 		browser := ""
 		os := ""
+		var scheduledAt *time.Time
+		var timezone string
 		if exists {
 			status = r.Status
+			scheduledAt, timezone = r.ScheduledAt, r.Timezone
 			if len(r.Events) > 0 {
 				browser = r.Events[0].Browser
 				os = r.Events[0].OS
@@ -571,13 +591,15 @@ func GetCampaignDetail(c *gin.Context) {
 		}
 
 		participants = append(participants, models.ParticipantDetail{
-			ID:       m.ID,
-			Name:     m.Name,
-			Email:    m.Email,
-			Status:   status,
-			Position: m.Position,
-			Browser:  browser,
-			OS:       os,
+			ID:          m.ID,
+			Name:        m.Name,
+			Email:       m.Email,
+			Status:      status,
+			Position:    m.Position,
+			Browser:     browser,
+			OS:          os,
+			Timezone:    timezone,
+			ScheduledAt: scheduledAt,
 		})
 	}
 
@@ -625,6 +647,7 @@ func GetCampaignDetail(c *gin.Context) {
 		SendingProfileName: campaign.SendingProfile.Name,
 		URL:                campaign.URL,
 		Status:             campaign.Status,
+		Delivery:           campaign.Delivery,
 		CreatedAt:          campaign.CreatedAt,
 		CreatedBy:          int(campaign.CreatedBy),
 		UpdatedAt:          campaign.UpdatedAt,
@@ -634,11 +657,17 @@ func GetCampaignDetail(c *gin.Context) {
 		EmailClicks:        int(clickedCount),
 		EmailSubmitted:     int(submittedCount),
 		EmailReported:      int(reportedCount),
+		AttachmentOpened:   int(attachmentOpenedCount),
+		QRScanned:          int(qrScannedCount),
 		TotalParticipants:  int(totalMembers),
 		Participants:       participants,
 		TimelineEvents:     timeline,
 		CompletedDate:      completeDate,
 	}
+	// Alasan campaign masih menunggu (launch date, blackout, slot pengiriman recipient)
+	if waiting, err := services.CampaignWaitingStatus(config.DB, campaign, time.Now()); err == nil {
+		resp.Waiting = waiting
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
@@ -650,9 +679,13 @@ func GetCampaignDetail(c *gin.Context) {
 // Update
 func UpdateCampaign(c *gin.Context) {
 	id := c.Param("id")
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 
 	var existingCampaign models.Campaign
-	if err := config.DB.First(&existingCampaign, id).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).First(&existingCampaign, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Update", "Campaign", id, nil, nil, "error", "Kampanye tidak ditemukan") // Log Error
 			c.JSON(http.StatusNotFound, gin.H{
@@ -718,9 +751,24 @@ func UpdateCampaign(c *gin.Context) {
 		sendEmailBy = nil
 	}
 
-	// Verifikasi keberadaan Group, EmailTemplate, LandingPage, SendingProfile
+	// Tanpa field delivery, opsi pengiriman yang tersimpan dipertahankan
+	delivery := existingCampaign.Delivery
+	if input.Delivery != nil {
+		if delivery, err = services.NormalizeDeliveryWindow(input.Delivery); err != nil {
+			services.LogActivity(config.DB, c, "Update", "Campaign", id, existingCampaign, input, "error", "Opsi pengiriman tidak valid: "+err.Error()) // Log Error
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Opsi pengiriman tidak valid: " + err.Error(),
+				"fields":  map[string]string{"delivery": err.Error()},
+			})
+			return
+		}
+	}
+
+	// Verifikasi keberadaan Group, EmailTemplate, LandingPage, SendingProfile di organisasi campaign
+	orgID := existingCampaign.OrganizationID
 	var group models.Group
-	if err := config.DB.First(&group, input.GroupID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganization(orgID)).First(&group, input.GroupID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Update", "Campaign", id, existingCampaign, input, "error", "Group ID tidak ditemukan") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -731,7 +779,7 @@ func UpdateCampaign(c *gin.Context) {
 	}
 
 	var emailTemplate models.EmailTemplate
-	if err := config.DB.First(&emailTemplate, input.EmailTemplateID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganizationOrSystem(orgID)).First(&emailTemplate, input.EmailTemplateID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Update", "Campaign", id, existingCampaign, input, "error", "Email Template ID tidak ditemukan") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -742,7 +790,7 @@ func UpdateCampaign(c *gin.Context) {
 	}
 
 	var landingPage models.LandingPage
-	if err := config.DB.First(&landingPage, input.LandingPageID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganizationOrSystem(orgID)).First(&landingPage, input.LandingPageID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Update", "Campaign", id, existingCampaign, input, "error", "Landing Page ID tidak ditemukan") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -753,7 +801,7 @@ func UpdateCampaign(c *gin.Context) {
 	}
 
 	var sendingProfile models.SendingProfiles
-	if err := config.DB.First(&sendingProfile, input.SendingProfileID).Error; err != nil {
+	if err := config.DB.Scopes(services.InOrganization(orgID)).First(&sendingProfile, input.SendingProfileID).Error; err != nil {
 		services.LogActivity(config.DB, c, "Update", "Campaign", id, existingCampaign, input, "error", "Sending Profile ID tidak ditemukan") // Log Error
 		c.JSON(http.StatusNotFound, gin.H{
 			"status":  "error",
@@ -775,6 +823,7 @@ func UpdateCampaign(c *gin.Context) {
 	existingCampaign.LandingPageID = input.LandingPageID
 	existingCampaign.SendingProfileID = input.SendingProfileID
 	existingCampaign.URL = input.URL
+	existingCampaign.Delivery = delivery
 	existingCampaign.UpdatedAt = time.Now()
 	existingCampaign.UpdatedBy = int(input.UpdatedBy)
 
@@ -805,6 +854,7 @@ func UpdateCampaign(c *gin.Context) {
 			CreatedAt:        existingCampaign.CreatedAt,
 			UpdatedAt:        existingCampaign.UpdatedAt,
 			Status:           existingCampaign.Status,
+			Delivery:         existingCampaign.Delivery,
 		},
 	})
 }
@@ -812,9 +862,13 @@ func UpdateCampaign(c *gin.Context) {
 // Delete
 func DeleteCampaign(c *gin.Context) {
 	id := c.Param("id")
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 
 	var campaign models.Campaign
-	if err := config.DB.First(&campaign, id).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).First(&campaign, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			services.LogActivity(config.DB, c, "Delete", "Campaign", id, nil, nil, "error", "Campaign not found")
 			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Campaign not found"})
@@ -848,6 +902,13 @@ func DeleteCampaign(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Where("campaign_id = ?", campaign.ID).Delete(&models.CampaignGroupSnapshot{}).Error; err != nil {
+		tx.Rollback()
+		services.LogActivity(config.DB, c, "Delete", "Campaign", id, campaign, nil, "error", "Failed to delete group snapshot") // Log Error
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to delete group snapshot"})
+		return
+	}
+
 	// Hapus Campaign
 	if err := tx.Delete(&campaign).Error; err != nil {
 		tx.Rollback()
@@ -862,19 +923,136 @@ func DeleteCampaign(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Campaign and related data successfully deleted"})
 }
 
+// GetCampaignGroupSnapshot mengembalikan anggota group yang dipakai saat campaign diluncurkan.
+func GetCampaignGroupSnapshot(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var campaign models.Campaign
+	if err := config.DB.Scopes(tenant.Scope).Select("id").First(&campaign, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Campaign not found"})
+		return
+	}
+	var snapshot models.CampaignGroupSnapshot
+	if err := config.DB.Where("campaign_id = ?", campaign.ID).First(&snapshot).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Campaign has not been launched yet"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch group snapshot"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Group snapshot retrieved successfully", "data": snapshot})
+}
+
+// GetCampaignBreakdown mengembalikan statistik campaign per nilai dimensi (?dimension=position|company|country|attr.<key>).
+func GetCampaignBreakdown(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var campaign models.Campaign
+	if err := config.DB.Scopes(tenant.Scope).First(&campaign, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Campaign not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch campaign"})
+		return
+	}
+
+	dimension := c.DefaultQuery("dimension", "position")
+	rows, err := services.CampaignBreakdown(config.DB, campaign, dimension)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Campaign breakdown retrieved successfully",
+		"data":    gin.H{"dimension": dimension, "rows": rows},
+	})
+}
+
 func SendCampaign(camp models.Campaign) {
-	for _, member := range camp.Group.Members {
+	// Anggota group (termasuk group dynamic) dihitung sekali saat launch dan disimpan sebagai snapshot
+	members, err := services.SnapshotCampaignGroup(config.DB, camp)
+	if err != nil {
+		log.Printf("Failed to resolve members for campaign %d: %v", camp.ID, err)
+		return
+	}
+
+	// Tanpa exclusion list campaign tidak dikirim, agar orang yang dikecualikan tidak ikut menerima email
+	exclusions, err := services.LoadExclusionMatcher(config.DB, camp.OrganizationID, time.Now())
+	if err != nil {
+		log.Printf("Failed to load exclusion list for campaign %d: %v", camp.ID, err)
+		return
+	}
+	// Hanya domain target yang kepemilikannya sudah diverifikasi yang boleh dikirimi email
+	allowedDomains, err := services.LoadDomainAllowList(config.DB, camp.OrganizationID)
+	if err != nil {
+		log.Printf("Failed to load verified domains for campaign %d: %v", camp.ID, err)
+		return
+	}
+
+	// Slot pengiriman dihitung per recipient sesuai zona waktunya; hari libur hanya dimuat bila dipakai
+	var holidays *services.HolidayCalendar
+	if camp.Delivery.SkipHolidays {
+		if holidays, err = services.LoadHolidayCalendar(config.DB, time.Now()); err != nil {
+			log.Printf("Failed to load holiday calendar for campaign %d: %v", camp.ID, err)
+			return
+		}
+	}
+
+	// Slot yang jatuh dalam blackout organisasi digeser ke setelah blackout berakhir
+	blackouts, err := services.LoadBlackoutCalendar(config.DB, camp.OrganizationID, time.Now())
+	if err != nil {
+		log.Printf("Failed to load blackout calendar for campaign %d: %v", camp.ID, err)
+		return
+	}
+
+	for _, member := range members {
 		rid := uuid.NewString()
 		rec := models.Recipient{
 			UID:        rid,
 			CampaignID: camp.ID,
 			UserID:     member.ID,
+			PersonID:   member.PersonID,
 			Email:      member.Email,
+			Attributes: services.RecipientAttributes(member),
+			Timezone:   services.MemberTimezone(member.Timezone, member.Country),
 			Status:     "pending",
 			CreatedAt:  time.Now(),
 		}
+		excluded := exclusions.Match(member.Email)
+		if excluded != nil {
+			rec.Status = models.RecipientExcluded
+			rec.ExclusionID = &excluded.ID
+			rec.Error = services.ExclusionReason(excluded)
+		} else if !allowedDomains.Allows(member.Email) {
+			rec.Status = models.RecipientDomainUnverified
+			rec.Error = "Domain " + services.EmailDomain(member.Email) + " is not a verified target domain"
+		} else {
+			now := time.Now()
+			slot, err := services.ScheduleDelivery(camp.Delivery, services.MemberLocation(member), member.Country, holidays, blackouts, now)
+			switch {
+			case err != nil:
+				rec.Status = "failed"
+				rec.Error = "Failed to schedule delivery: " + err.Error()
+			case camp.SendEmailBy != nil && slot.After(*camp.SendEmailBy):
+				rec.Status = "failed"
+				rec.Error = "No delivery slot before the campaign deadline (delivery window or blackout)"
+			case slot.After(now):
+				rec.Status = models.RecipientScheduled
+				rec.ScheduledAt = &slot
+			}
+		}
 		config.DB.Create(&rec)
 
+		if rec.Status != "pending" {
+			continue
+		}
 		// kirim async
 		go services.SendEmailToRecipient(rec, camp)
 	}
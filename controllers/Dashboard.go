@@ -58,27 +58,19 @@ type BrowserStats struct {
 
 func GetDashboardMetrics(c *gin.Context) {
 	// 1. Ambil currentUser dari Context
-	userIDScope, roleScope, userCreatedBy, errorStatus := services.GetRoleScopeDashboard(c)
-	if !errorStatus {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
 		return
 	}
 
 	db := config.DB
 
 	// 2. Build subquery campaignSub: pilih hanya kolom id
+	//    dibatasi ke campaign organisasi user (super admin: semua organisasi)
 	campaignSub := db.
 		Model(&models.Campaign{}).
-		Select("id")
-
-	//    Jika bukan admin (role != 1), batasi ke self dan parent
-	if roleScope != 1 {
-		scopeIDs := []int{userIDScope}
-		if userCreatedBy > 0 {
-			scopeIDs = append(scopeIDs, int(userCreatedBy))
-		}
-		campaignSub = campaignSub.
-			Where("created_by IN ?", scopeIDs)
-	}
+		Select("id").
+		Scopes(tenant.Scope)
 
 	// 3. Hitung total campaign
 	var totalCampaign int64
@@ -112,6 +104,18 @@ func GetDashboardMetrics(c *gin.Context) {
 	eventsBase.Where("type = ?", models.Submitted).Count(&submittedCount)
 	eventsBase.Where("type = ?", models.Reported).Count(&reportedCount)
 
+	var attachmentOpenedCount int64
+	db.Model(&models.Event{}).
+		Where("campaign_id IN (?)", campaignSub).
+		Where("type = ?", models.AttachmentOpened).
+		Count(&attachmentOpenedCount)
+
+	var qrScannedCount int64
+	db.Model(&models.Event{}).
+		Where("campaign_id IN (?)", campaignSub).
+		Where("type = ?", models.Scanned).
+		Count(&qrScannedCount)
+
 	// helper percentage
 	pct := func(val, tot int64) int {
 		if tot == 0 {
@@ -124,6 +128,8 @@ func GetDashboardMetrics(c *gin.Context) {
 		{"Campaign", int(totalCampaign), "#009ac9ff", pct(totalCampaign, totalCampaign)},
 		{"Sent", int(totalSent), "#10B981", pct(totalSent, totalSent)},
 		{"Opened", int(openedCount), "#F59E0B", pct(openedCount, totalSent)},
+		{"Attachment Opened", int(attachmentOpenedCount), "#F97316", pct(attachmentOpenedCount, totalSent)},
+		{"QR Scanned", int(qrScannedCount), "#0EA5E9", pct(qrScannedCount, totalSent)},
 		{"Clicked", int(clickedCount), "#9b29ff", pct(clickedCount, totalSent)},
 		{"Submitted", int(submittedCount), "#DC2626", pct(submittedCount, totalSent)},
 		{"Reported", int(reportedCount), "#2934ff", pct(reportedCount, totalSent)},
@@ -133,6 +139,7 @@ func GetDashboardMetrics(c *gin.Context) {
 	funnelData := []FunnelStep{
 		{"Email Sent", int(totalSent), "#10B981"},
 		{"Email Opened", int(openedCount), "#F59E0B"},
+		{"Attachment Opened", int(attachmentOpenedCount), "#F97316"},
 		{"Clicked Link", int(clickedCount), "#EF4444"},
 		{"Submitted", int(submittedCount), "#DC2626"},
 	}
@@ -148,6 +155,7 @@ func GetDashboardMetrics(c *gin.Context) {
 		db.Model(&models.Recipient{}).
 			Where("campaign_id IN (?)", campaignSub).
 			Where("created_at >= ? AND created_at < ?", start, end).
+			Where("status NOT IN ?", []string{models.RecipientExcluded, models.RecipientDomainUnverified}).
 			Count(&hSent)
 		db.Model(&models.Event{}).
 			Where("campaign_id IN (?)", campaignSub).
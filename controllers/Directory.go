@@ -0,0 +1,588 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ldap/ldap/v3"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const moduleNameDirectory = "Directory Sync"
+
+func validateDirectoryURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "ldap" && u.Scheme != "ldaps") {
+		return errors.New("URL must be ldap://host[:port] or ldaps://host[:port]")
+	}
+	return nil
+}
+
+// CREATE
+func RegisterDirectoryProfile(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var input models.DirectoryProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameDirectory, "", nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid request body: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err := validateDirectoryURL(input.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var existing models.DirectoryProfile
+	if config.DB.Where("name = ? AND organization_id = ?", input.Name, tenant.OrganizationID).First(&existing).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "Directory profile with this name already exists",
+			"data":    nil,
+		})
+		return
+	}
+
+	encryptedPassword, err := services.EncryptSecret(input.BindPassword)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameDirectory, "", nil, nil, "failed", "Failed to encrypt bind password: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to encrypt bind password",
+			"data":    nil,
+		})
+		return
+	}
+
+	profile := models.DirectoryProfile{
+		OrganizationID:     tenant.OrganizationID,
+		Name:               input.Name,
+		URL:                input.URL,
+		StartTLS:           input.StartTLS,
+		InsecureSkipVerify: input.InsecureSkipVerify,
+		BindDN:             input.BindDN,
+		BindPassword:       encryptedPassword,
+		BaseDN:             input.BaseDN,
+		ChangeAttribute:    input.ChangeAttribute,
+		CreatedAt:          time.Now(),
+		CreatedBy:          userID,
+	}
+	if profile.ChangeAttribute == "" {
+		profile.ChangeAttribute = "modifyTimestamp"
+	}
+
+	if err := config.DB.Create(&profile).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameDirectory, "", nil, profile, "failed", "Failed to create directory profile: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to create directory profile",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameDirectory, strconv.FormatUint(uint64(profile.ID), 10), nil, profile, "success", "Directory profile created successfully")
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Directory profile created successfully",
+		"data":    profile,
+	})
+}
+
+// READ
+func GetDirectoryProfiles(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.DirectoryProfile{}).Scopes(tenant.Scope)
+
+	var profiles []models.DirectoryProfile
+	if err := query.Order("name").Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch directory profiles",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory profiles retrieved successfully",
+		"data":    profiles,
+	})
+}
+
+// UPDATE
+func UpdateDirectoryProfile(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var profile models.DirectoryProfile
+	if err := config.DB.Scopes(tenant.Scope).First(&profile, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Directory profile not found",
+			"data":    nil,
+		})
+		return
+	}
+	oldProfile := profile
+
+	var input models.DirectoryProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameDirectory, idParam, nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid request body: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if err := validateDirectoryURL(input.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	profile.Name = input.Name
+	profile.URL = input.URL
+	profile.StartTLS = input.StartTLS
+	profile.InsecureSkipVerify = input.InsecureSkipVerify
+	profile.BindDN = input.BindDN
+	profile.BaseDN = input.BaseDN
+	if input.ChangeAttribute != "" {
+		profile.ChangeAttribute = input.ChangeAttribute
+	}
+	// Password kosong berarti tidak diubah
+	if input.BindPassword != "" {
+		encryptedPassword, err := services.EncryptSecret(input.BindPassword)
+		if err != nil {
+			services.LogActivity(config.DB, c, "Update", moduleNameDirectory, idParam, oldProfile, nil, "failed", "Failed to encrypt bind password: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to encrypt bind password",
+				"data":    nil,
+			})
+			return
+		}
+		profile.BindPassword = encryptedPassword
+	}
+	profile.UpdatedAt = time.Now()
+	profile.UpdatedBy = userID
+
+	if err := config.DB.Save(&profile).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameDirectory, idParam, oldProfile, profile, "failed", "Failed to update directory profile: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to update directory profile",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameDirectory, idParam, oldProfile, profile, "success", "Directory profile updated successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory profile updated successfully",
+		"data":    profile,
+	})
+}
+
+// DELETE
+func DeleteDirectoryProfile(c *gin.Context) {
+	idParam := c.Param("id")
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var profile models.DirectoryProfile
+	if err := config.DB.Scopes(tenant.Scope).First(&profile, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Directory profile not found",
+			"data":    nil,
+		})
+		return
+	}
+
+	var used int64
+	config.DB.Model(&models.GroupDirectorySync{}).Where("directory_profile_id = ?", profile.ID).Count(&used)
+	if used > 0 {
+		services.LogActivity(config.DB, c, "Delete", moduleNameDirectory, idParam, profile, nil, "failed", "Directory profile is still used by groups")
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "Directory profile is still used by " + strconv.FormatInt(used, 10) + " group(s)",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := config.DB.Delete(&profile).Error; err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameDirectory, idParam, profile, nil, "failed", "Failed to delete directory profile: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete directory profile",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameDirectory, idParam, profile, nil, "success", "Directory profile deleted successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory profile deleted successfully",
+		"data":    nil,
+	})
+}
+
+// TEST CONNECTION
+func TestDirectoryProfile(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var profile models.DirectoryProfile
+	if err := config.DB.Scopes(tenant.Scope).First(&profile, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Directory profile not found",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := services.TestDirectoryProfile(profile); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"status":  "error",
+			"message": "Directory connection failed: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory connection successful",
+		"data":    nil,
+	})
+}
+
+// GET GROUP SYNC CONFIG
+func GetGroupDirectorySync(c *gin.Context) {
+	if _, ok := findTenantGroup(c, c.Param("id")); !ok {
+		return
+	}
+
+	var syncCfg models.GroupDirectorySync
+	if err := config.DB.Where("group_id = ?", c.Param("id")).First(&syncCfg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusOK, gin.H{
+				"status":  "success",
+				"message": "Directory sync is not configured for this group",
+				"data":    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch directory sync",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory sync retrieved successfully",
+		"data":    syncCfg,
+	})
+}
+
+// SAVE GROUP SYNC CONFIG
+func UpdateGroupDirectorySync(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	group, ok := findTenantGroup(c, idParam)
+	if !ok {
+		return
+	}
+
+	var input models.GroupDirectorySyncInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameDirectory, idParam, nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid request body: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	filter := input.Filter
+	if len(filter) > 0 && filter[0] != '(' {
+		filter = "(" + filter + ")"
+	}
+	if _, err := ldap.CompileFilter(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid LDAP filter: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	for field := range input.AttributeMap {
+		if _, known := services.DefaultDirectoryAttributeMap[field]; !known {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Unknown member field in attribute map: " + field,
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	var profile models.DirectoryProfile
+	if err := config.DB.Scopes(services.InOrganization(group.OrganizationID)).First(&profile, input.DirectoryProfileID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Directory profile not found",
+			"data":    nil,
+		})
+		return
+	}
+
+	attrMap, _ := json.Marshal(input.AttributeMap)
+	if input.IntervalMinutes == 0 {
+		input.IntervalMinutes = 60
+	}
+
+	var syncCfg models.GroupDirectorySync
+	isNew := config.DB.Where("group_id = ?", group.ID).First(&syncCfg).Error != nil
+	oldCfg := syncCfg
+
+	// Sumber data berubah: sync berikutnya harus full
+	if isNew || syncCfg.DirectoryProfileID != input.DirectoryProfileID || syncCfg.BaseDN != input.BaseDN || syncCfg.Filter != filter || string(syncCfg.AttributeMap) != string(attrMap) {
+		syncCfg.HighWaterMark = ""
+	}
+	// Konfigurasi baru mungkin memperbaiki penyebab kegagalan: hentikan backoff
+	syncCfg.FailureCount = 0
+
+	syncCfg.GroupID = group.ID
+	syncCfg.DirectoryProfileID = input.DirectoryProfileID
+	syncCfg.BaseDN = input.BaseDN
+	syncCfg.Filter = filter
+	syncCfg.AttributeMap = datatypes.JSON(attrMap)
+	syncCfg.Enabled = input.Enabled
+	syncCfg.IntervalMinutes = input.IntervalMinutes
+	if isNew {
+		syncCfg.CreatedAt = time.Now()
+		syncCfg.CreatedBy = userID
+	}
+	syncCfg.UpdatedAt = time.Now()
+	syncCfg.UpdatedBy = userID
+
+	if err := config.DB.Save(&syncCfg).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameDirectory, idParam, oldCfg, syncCfg, "failed", "Failed to save directory sync: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to save directory sync",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameDirectory, idParam, oldCfg, syncCfg, "success", "Directory sync saved successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory sync saved successfully",
+		"data":    syncCfg,
+	})
+}
+
+// REMOVE GROUP SYNC CONFIG (anggota tetap dipertahankan)
+func DeleteGroupDirectorySync(c *gin.Context) {
+	idParam := c.Param("id")
+	if _, ok := findTenantGroup(c, idParam); !ok {
+		return
+	}
+
+	var syncCfg models.GroupDirectorySync
+	if err := config.DB.Where("group_id = ?", idParam).First(&syncCfg).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Directory sync is not configured for this group",
+			"data":    nil,
+		})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&syncCfg).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Member{}).Where("group_id = ?", syncCfg.GroupID).Update("directory_dn", "").Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameDirectory, idParam, syncCfg, nil, "failed", "Failed to remove directory sync: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to remove directory sync",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameDirectory, idParam, syncCfg, nil, "success", "Directory sync removed successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory sync removed successfully",
+		"data":    nil,
+	})
+}
+
+// RUN SYNC NOW
+func RunGroupDirectorySync(c *gin.Context) {
+	idParam := c.Param("id")
+	groupID, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid group ID format",
+			"data":    nil,
+		})
+		return
+	}
+	if _, ok := findTenantGroup(c, idParam); !ok {
+		return
+	}
+
+	syncLog, err := services.SyncGroupDirectory(config.DB, uint(groupID), "manual", c.Query("full") == "true")
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Directory sync is not configured for this group",
+			"data":    nil,
+		})
+		return
+	case errors.Is(err, services.ErrDirectorySyncRunning):
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	case err != nil:
+		services.LogActivity(config.DB, c, "Sync", moduleNameDirectory, idParam, nil, syncLog, "failed", "Directory sync failed: "+err.Error())
+		c.JSON(http.StatusBadGateway, gin.H{
+			"status":  "error",
+			"message": "Directory sync failed: " + err.Error(),
+			"data":    syncLog,
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Sync", moduleNameDirectory, idParam, nil, syncLog, "success", "Directory sync completed")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory sync completed",
+		"data":    syncLog,
+	})
+}
+
+// SYNC HISTORY
+func GetGroupDirectorySyncLogs(c *gin.Context) {
+	if _, ok := findTenantGroup(c, c.Param("id")); !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := config.DB.Model(&models.DirectorySyncLog{}).Where("group_id = ?", c.Param("id"))
+
+	var total int64
+	query.Count(&total)
+
+	var logs []models.DirectorySyncLog
+	if err := query.Order("started_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch directory sync logs",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Directory sync logs retrieved successfully",
+		"data":    logs,
+		"total":   total,
+	})
+}
+
+// findTenantGroup memuat group dalam cakupan tenant request; menulis 404 jika tidak ditemukan.
+func findTenantGroup(c *gin.Context, idParam string) (models.Group, bool) {
+	var group models.Group
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return group, false
+	}
+	if err := config.DB.Scopes(tenant.Scope).First(&group, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Group not found",
+			"data":    nil,
+		})
+		return group, false
+	}
+	return group, true
+}
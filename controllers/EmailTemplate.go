@@ -16,27 +16,18 @@ const moduleNameEmailTemplate = "Email Template"
 
 // GET ALL DATA EMAIL TEMPLATE
 func GetEmailTemplates(c *gin.Context) {
-	userIDScope, roleScope, errorStatus := services.GetRoleScope(c)
-	if !errorStatus {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
 		return
 	}
 
-	var query *gorm.DB
-	if roleScope == 1 {
-		query = config.DB.Table("email_templates").
-			Select(`email_templates.*, 
-				created_by_user.name AS created_by_name, 
-				updated_by_user.name AS updated_by_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = email_templates.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = email_templates.updated_by`)
-	} else {
-		query = config.DB.Table("email_templates").
-			Select(`email_templates.*, 
-				created_by_user.name AS created_by_name, 
-				updated_by_user.name AS updated_by_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = email_templates.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = email_templates.updated_by`).Where("email_templates.created_by = ? OR email_templates.is_system_template = ?", userIDScope, 1)
-	}
+	query := config.DB.Table("email_templates").
+		Select(`email_templates.*, 
+			created_by_user.name AS created_by_name, 
+			updated_by_user.name AS updated_by_name`).
+		Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = email_templates.created_by`).
+		Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = email_templates.updated_by`).
+		Scopes(tenant.ScopeWithSystem)
 
 	var total int64
 	query.Count(&total)
@@ -94,6 +85,11 @@ func GetDefaultEmailTemplates(c *gin.Context) {
 }
 
 func RegisterEmailTemplate(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var input models.EmailTemplateInput
 
 	// Bind dan validasi input JSON
@@ -107,10 +103,25 @@ func RegisterEmailTemplate(c *gin.Context) {
 		return
 	}
 
+	// Template sistem terlihat oleh semua organisasi, jadi hanya boleh dibuat dari tampilan lintas tenant
+	organizationID := tenant.OrganizationID
+	if input.IsSystemTemplate == 1 {
+		if !tenant.All {
+			services.LogActivity(config.DB, c, "Create", moduleNameEmailTemplate, "", nil, input, "error", "System template requires cross-tenant access")
+			c.JSON(http.StatusForbidden, gin.H{
+				"status":  "error",
+				"message": "Only cross-tenant administrators can create system templates",
+				"data":    nil,
+			})
+			return
+		}
+		organizationID = 0
+	}
+
 	// CEK DUPLIKASI EMAIL TEMPLATE
 	var existingEmailTemplate models.EmailTemplate
 	if err := config.DB.
-		Where("name = ? AND subject = ? AND envelope_sender = ? AND created_by = ?", input.Name, input.Subject, input.EnvelopeSender, input.CreatedBy).
+		Where("name = ? AND subject = ? AND envelope_sender = ? AND organization_id = ?", input.Name, input.Subject, input.EnvelopeSender, organizationID).
 		First(&existingEmailTemplate).Error; err == nil {
 		services.LogActivity(config.DB, c, "Create", moduleNameEmailTemplate, "", nil, input, "error", "Email Template already exists with this Subject and Envelope Sender.")
 		c.JSON(http.StatusConflict, gin.H{
@@ -123,6 +134,7 @@ func RegisterEmailTemplate(c *gin.Context) {
 
 	// BUAT EMAIL TEMPLATE BARU
 	newEmailTemplate := models.EmailTemplate{
+		OrganizationID:   organizationID,
 		Name:             input.Name,
 		EnvelopeSender:   input.EnvelopeSender,
 		Subject:          input.Subject,
@@ -167,8 +179,13 @@ func UpdateEmailTemplate(c *gin.Context) {
 		return
 	}
 
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var emailTemplate models.EmailTemplate
-	if err := config.DB.First(&emailTemplate, id).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).First(&emailTemplate, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Update", moduleNameEmailTemplate, idParam, nil, nil, "failed", "Email template not found.")
 			c.JSON(http.StatusNotFound, gin.H{
@@ -201,8 +218,16 @@ func UpdateEmailTemplate(c *gin.Context) {
 		return
 	}
 
-	// Convert IsSystemTemplate from int32 to int
+	// Convert IsSystemTemplate from int32 to int. Status template sistem hanya bisa diubah dari tampilan
+	// lintas tenant; template sistem tidak dimiliki organisasi mana pun.
 	isSystemTemplateInt := int(updatedData.IsSystemTemplate)
+	if !tenant.All {
+		isSystemTemplateInt = emailTemplate.IsSystemTemplate
+	} else if isSystemTemplateInt == 1 {
+		emailTemplate.OrganizationID = 0
+	} else if emailTemplate.OrganizationID == 0 {
+		emailTemplate.OrganizationID = tenant.OrganizationID
+	}
 
 	emailTemplate.Name = updatedData.Name
 	emailTemplate.EnvelopeSender = updatedData.EnvelopSender
@@ -247,8 +272,13 @@ func DeleteEmailTemplate(c *gin.Context) {
 	}
 
 	// CHECK IF EMAIL TEMPLATE THAT WANT TO BE DELETE EXIST
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var emailTemplateDelete models.EmailTemplate
-	if err := config.DB.First(&emailTemplateDelete, id).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).First(&emailTemplateDelete, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Delete", moduleNameEmailTemplate, emailTemplateIDParam, nil, nil, "failed", "Email Template not found.")
 			c.JSON(http.StatusNotFound, gin.H{
@@ -281,6 +311,18 @@ func DeleteEmailTemplate(c *gin.Context) {
 		return
 	}
 
+	// Hapus attachment milik template
+	if err := tx.Where("email_template_id = ?", emailTemplateDelete.ID).Delete(&models.EmailAttachment{}).Error; err != nil {
+		tx.Rollback()
+		services.LogActivity(config.DB, c, "Delete", moduleNameEmailTemplate, emailTemplateIDParam, oldEmailTemplateData, nil, "failed", "Failed to delete email template attachments: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete email template attachments",
+			"data":    err.Error(),
+		})
+		return
+	}
+
 	// Hard Delete Email Template (permanently remove from database)
 	if err := tx.Unscoped().Delete(&emailTemplateDelete).Error; err != nil {
 		tx.Rollback()
@@ -318,3 +360,114 @@ func DeleteEmailTemplate(c *gin.Context) {
 		},
 	})
 }
+
+// GET ATTACHMENTS
+func GetEmailTemplateAttachments(c *gin.Context) {
+	idParam := c.Param("id")
+	emailTemplateID, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid Email Template ID format",
+			"data":    nil,
+		})
+		return
+	}
+
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var emailTemplate models.EmailTemplate
+	if err := config.DB.Scopes(tenant.ScopeWithSystem).First(&emailTemplate, emailTemplateID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Email template not found",
+			"data":    nil,
+		})
+		return
+	}
+
+	var attachments []models.EmailAttachment
+	if err := config.DB.Where("email_template_id = ?", emailTemplateID).Find(&attachments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch attachments: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Attachments retrieved successfully",
+		"data":    attachments,
+	})
+}
+
+// UPDATE ATTACHMENTS
+// Mengganti seluruh attachment milik template dengan daftar baru
+func UpdateEmailTemplateAttachments(c *gin.Context) {
+	idParam := c.Param("id")
+	emailTemplateID, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameEmailTemplate, idParam, nil, nil, "failed", "Invalid Email Template ID format: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid Email Template ID", "data": nil})
+		return
+	}
+
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var emailTemplate models.EmailTemplate
+	if err := config.DB.Scopes(tenant.Scope).First(&emailTemplate, emailTemplateID).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameEmailTemplate, idParam, nil, nil, "failed", "Email template not found.")
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Email template not found", "data": nil})
+		return
+	}
+
+	var newAttachments []models.EmailAttachment
+	if err := c.ShouldBindJSON(&newAttachments); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameEmailTemplate, idParam, nil, newAttachments, "failed", "Invalid request payload: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error(), "data": nil})
+		return
+	}
+
+	// Validasi template dengan membangkitkan file contoh
+	for _, att := range newAttachments {
+		if _, _, err := services.BuildAttachment(att, "preview", map[string]interface{}{}); err != nil {
+			services.LogActivity(config.DB, c, "Update", moduleNameEmailTemplate, idParam, nil, newAttachments, "failed", "Invalid attachment "+att.FileName+": "+err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid attachment " + att.FileName + ": " + err.Error(), "data": nil})
+			return
+		}
+	}
+
+	var oldAttachments []models.EmailAttachment
+	config.DB.Where("email_template_id = ?", emailTemplateID).Find(&oldAttachments)
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("email_template_id = ?", emailTemplateID).Delete(&models.EmailAttachment{}).Error; err != nil {
+			return err
+		}
+		if len(newAttachments) == 0 {
+			return nil
+		}
+		for i := range newAttachments {
+			newAttachments[i].ID = 0
+			newAttachments[i].EmailTemplateID = uint(emailTemplateID)
+			newAttachments[i].CreatedAt = time.Now()
+			newAttachments[i].UpdatedAt = time.Now()
+		}
+		return tx.Create(&newAttachments).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameEmailTemplate, idParam, oldAttachments, newAttachments, "failed", "Failed to update attachments: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to update attachments: " + err.Error(), "data": nil})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameEmailTemplate, idParam, oldAttachments, newAttachments, "success", "Email template attachments updated successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Email template attachments updated successfully", "data": newAttachments})
+}
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const moduleNameExclusion = "Exclusion List"
+
+// CREATE
+func RegisterExclusion(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateExclusionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameExclusion, "", nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	value, err := services.NormalizeExclusionValue(input.Type, input.Value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "expiresAt must be in the future",
+			"data":    nil,
+		})
+		return
+	}
+
+	var existing models.Exclusion
+	if config.DB.Scopes(services.InOrganization(tenant.OrganizationID)).Where("type = ? AND value = ?", input.Type, value).First(&existing).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "This " + input.Type + " is already on the exclusion list",
+			"data":    existing,
+		})
+		return
+	}
+
+	exclusion := models.Exclusion{
+		OrganizationID: tenant.OrganizationID,
+		Type:           input.Type,
+		Value:          value,
+		Reason:         strings.TrimSpace(input.Reason),
+		ExpiresAt:      input.ExpiresAt,
+		CreatedAt:      time.Now(),
+		CreatedBy:      userID,
+		UpdatedAt:      time.Now(),
+		UpdatedBy:      userID,
+	}
+	if err := config.DB.Create(&exclusion).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameExclusion, "", nil, exclusion, "error", "Failed to create exclusion: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to create exclusion",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameExclusion, strconv.FormatUint(uint64(exclusion.ID), 10), nil, exclusion, "success", "Exclusion added: "+exclusion.Type+" "+exclusion.Value)
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Exclusion created successfully",
+		"data":    exclusion,
+	})
+}
+
+// READ
+// ?status=active (default) | expired | deleted | all
+func GetExclusions(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	now := time.Now()
+	query := config.DB.Unscoped().Model(&models.Exclusion{}).Scopes(tenant.Scope).
+		Select("exclusions.*, created_by_user.name AS created_by_name, updated_by_user.name AS updated_by_name, deleted_by_user.name AS deleted_by_name").
+		Joins("LEFT JOIN users AS created_by_user ON created_by_user.id = exclusions.created_by").
+		Joins("LEFT JOIN users AS updated_by_user ON updated_by_user.id = exclusions.updated_by").
+		Joins("LEFT JOIN users AS deleted_by_user ON deleted_by_user.id = exclusions.deleted_by")
+
+	switch c.DefaultQuery("status", "active") {
+	case "active":
+		query = query.Where("exclusions.deleted_at IS NULL AND (exclusions.expires_at IS NULL OR exclusions.expires_at > ?)", now)
+	case "expired":
+		query = query.Where("exclusions.deleted_at IS NULL AND exclusions.expires_at <= ?", now)
+	case "deleted":
+		query = query.Where("exclusions.deleted_at IS NOT NULL")
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid status. Use active, expired, deleted or all.",
+			"data":    nil,
+		})
+		return
+	}
+	if t := c.Query("type"); t != "" {
+		query = query.Where("exclusions.type = ?", t)
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("exclusions.value LIKE ? OR exclusions.reason LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to count exclusions",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	var exclusions []models.ExclusionWithUserNames
+	if err := query.Order("exclusions.created_at DESC").Offset((page - 1) * limit).Limit(limit).Scan(&exclusions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch exclusions",
+			"data":    err.Error(),
+		})
+		return
+	}
+	for i := range exclusions {
+		e := &exclusions[i]
+		e.Active = !e.DeletedAt.Valid && (e.ExpiresAt == nil || e.ExpiresAt.After(now))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Exclusions retrieved successfully",
+		"data":    exclusions,
+		"total":   total,
+	})
+}
+
+// UPDATE
+func UpdateExclusion(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var exclusion models.Exclusion
+	if err := config.DB.Scopes(tenant.Scope).First(&exclusion, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Exclusion not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch exclusion", "data": err.Error()})
+		return
+	}
+
+	var input models.UpdateExclusionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameExclusion, c.Param("id"), nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	old := exclusion
+	exclusion.Reason = strings.TrimSpace(input.Reason)
+	exclusion.ExpiresAt = input.ExpiresAt
+	exclusion.UpdatedAt = time.Now()
+	exclusion.UpdatedBy = userID
+	if err := config.DB.Save(&exclusion).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameExclusion, c.Param("id"), old, exclusion, "error", "Failed to update exclusion: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to update exclusion",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameExclusion, c.Param("id"), old, exclusion, "success", "Exclusion updated successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Exclusion updated successfully",
+		"data":    exclusion,
+	})
+}
+
+// DELETE (soft delete, entri tetap terlihat dengan ?status=deleted)
+func DeleteExclusion(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var exclusion models.Exclusion
+	if err := config.DB.Scopes(tenant.Scope).First(&exclusion, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Exclusion not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch exclusion", "data": err.Error()})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&exclusion).Update("deleted_by", userID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&exclusion).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameExclusion, c.Param("id"), exclusion, nil, "error", "Failed to delete exclusion: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete exclusion",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameExclusion, c.Param("id"), exclusion, nil, "success", "Exclusion removed: "+exclusion.Type+" "+exclusion.Value)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Exclusion deleted successfully",
+		"data":    nil,
+	})
+}
+
+// CHECK: apakah sebuah email saat ini dikecualikan oleh exclusion list organisasi
+func CheckExclusion(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var input models.CheckExclusionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	exclusion, err := services.CheckExclusion(config.DB, tenant.OrganizationID, input.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to check exclusion list",
+			"data":    err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Exclusion check completed",
+		"data":    gin.H{"email": input.Email, "excluded": exclusion != nil, "exclusion": exclusion},
+	})
+}
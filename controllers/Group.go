@@ -4,39 +4,34 @@ import (
 	"be-awarenix/config"
 	"be-awarenix/models"
 	"be-awarenix/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 const moduleName = "Group"
 
 func GetGroups(c *gin.Context) {
-	// Pastikan GetRoleScope mengembalikan ID pengguna dan role sebagai tipe yang sesuai
-	// userIDScope: uint atau int (sesuai models.User.ID)
-	// roleScope: string (sesuai models.User.Role)
-	// errorStatus: bool (true jika berhasil, false jika ada error dan sudah kirim JSON)
-	userIDScope, roleScope, errorStatus := services.GetRoleScope(c)
-	if !errorStatus {
-		return // GetRoleScope sudah menangani response error
+	// Group dibatasi ke organisasi user (super admin: semua organisasi)
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return // GetTenant sudah menangani response error
 	}
 
-	var query *gorm.DB
-
-	baseQuery := config.DB.Model(&models.Group{}).
+	query := config.DB.Model(&models.Group{}).
 		Select("groups.*, created_by_user.name AS created_by_name, updated_by_user.name AS updated_by_name").
 		Joins("LEFT JOIN users AS created_by_user ON created_by_user.id = groups.created_by").
 		Joins("LEFT JOIN users AS updated_by_user ON updated_by_user.id = groups.updated_by").
-		Preload("Members")
-
-	if roleScope == 1 {
-		query = baseQuery
-	} else {
-		query = baseQuery.Where("groups.created_by = ?", userIDScope)
-	}
+		Preload("Members").
+		Scopes(tenant.Scope)
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -63,14 +58,16 @@ func GetGroups(c *gin.Context) {
 		var membersResponse []models.MemberResponse
 		for _, member := range groupData.Members {
 			membersResponse = append(membersResponse, models.MemberResponse{
-				ID:        member.ID,
-				Name:      member.Name,
-				Email:     member.Email,
-				Position:  member.Position,
-				Company:   member.Company,
-				Country:   member.Country,
-				CreatedAt: member.CreatedAt,
-				UpdatedAt: member.UpdatedAt,
+				ID:         member.ID,
+				Name:       member.Name,
+				Email:      member.Email,
+				Position:   member.Position,
+				Company:    member.Company,
+				Country:    member.Country,
+				Timezone:   member.Timezone,
+				Attributes: member.Attributes,
+				CreatedAt:  member.CreatedAt,
+				UpdatedAt:  member.UpdatedAt,
 			})
 		}
 
@@ -78,6 +75,8 @@ func GetGroups(c *gin.Context) {
 			ID:            groupData.ID,
 			Name:          groupData.Name,
 			DomainStatus:  groupData.DomainStatus,
+			Type:          groupData.Type,
+			Rule:          groupData.Rule,
 			CreatedAt:     groupData.CreatedAt,
 			UpdatedAt:     groupData.UpdatedAt,
 			MemberCount:   len(groupData.Members),
@@ -96,22 +95,15 @@ func GetGroups(c *gin.Context) {
 }
 
 func GetMembers(c *gin.Context) {
-	// 1. Ambil ID pengguna dan peran dari konteks
-	userIDScope, roleScope, errorStatus := services.GetRoleScope(c)
-	if !errorStatus {
+	// 1. Ambil organisasi dari konteks
+	tenant, ok := services.GetTenant(c)
+	if !ok {
 		return
 	}
 
-	// Inisialisasi base query
-	baseQuery := config.DB.Model(&models.Member{})
-
-	// 2. Terapkan filter berdasarkan peran
-	var finalQuery *gorm.DB
-	if roleScope == 1 {
-		finalQuery = baseQuery
-	} else {
-		finalQuery = baseQuery.Where("created_by = ?", userIDScope)
-	}
+	// 2. Anggota dari group milik organisasi
+	finalQuery := config.DB.Model(&models.Member{}).
+		Where("group_id IN (?)", config.DB.Model(&models.Group{}).Select("id").Scopes(tenant.Scope))
 
 	// 3. Hitung total anggota dengan filter yang diterapkan
 	var total int64
@@ -146,11 +138,15 @@ func GetMembers(c *gin.Context) {
 
 func GetGroupDetail(c *gin.Context) {
 	groupID := c.Param("id") // Ambil ID grup dari URL parameter
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 
 	var group models.Group
 	// Gunakan Preload("Members") untuk memuat anggota terkait
 	// Pastikan GroupID di model Member sudah benar dan Group memiliki `Members []Member` tag GORM
-	if err := config.DB.Preload("Members").First(&group, groupID).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).Preload("Members").First(&group, groupID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"Success": false,
@@ -166,18 +162,34 @@ func GetGroupDetail(c *gin.Context) {
 		return
 	}
 
+	// Group dynamic: tampilkan anggota hasil rule saat ini
+	if group.Type == models.GroupTypeDynamic {
+		resolved, err := services.ResolveGroupMembers(config.DB, &group)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"Success": false,
+				"Message": "Failed to resolve dynamic group members",
+				"Error":   err.Error(),
+			})
+			return
+		}
+		group.Members = resolved
+	}
+
 	// Siapkan response untuk grup dan anggotanya
 	var membersResponse []models.MemberResponse
 	for _, member := range group.Members {
 		membersResponse = append(membersResponse, models.MemberResponse{
-			ID:        member.ID,
-			Name:      member.Name,
-			Email:     member.Email,
-			Position:  member.Position,
-			Company:   member.Company,
-			Country:   member.Country,
-			CreatedAt: member.CreatedAt,
-			UpdatedAt: member.UpdatedAt,
+			ID:         member.ID,
+			Name:       member.Name,
+			Email:      member.Email,
+			Position:   member.Position,
+			Company:    member.Company,
+			Country:    member.Country,
+			Timezone:   member.Timezone,
+			Attributes: member.Attributes,
+			CreatedAt:  member.CreatedAt,
+			UpdatedAt:  member.UpdatedAt,
 		})
 	}
 
@@ -185,6 +197,8 @@ func GetGroupDetail(c *gin.Context) {
 		ID:           group.ID,
 		Name:         group.Name,
 		DomainStatus: group.DomainStatus,
+		Type:         group.Type,
+		Rule:         group.Rule,
 		CreatedAt:    group.CreatedAt,
 		UpdatedAt:    group.UpdatedAt,
 		MemberCount:  len(group.Members),
@@ -200,6 +214,10 @@ func GetGroupDetail(c *gin.Context) {
 
 // CREATE
 func RegisterGroup(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 	var input models.CreateGroupInput
 
 	// BIND VALIDATE INPUT JSON
@@ -213,6 +231,58 @@ func RegisterGroup(c *gin.Context) {
 		return
 	}
 
+	// Group dynamic: anggota ditentukan rule, bukan daftar members
+	if input.Type == "" {
+		input.Type = models.GroupTypeStatic
+	}
+	var ruleJSON datatypes.JSON
+	if input.Type == models.GroupTypeDynamic {
+		if err := services.ValidateGroupRule(config.DB, input.Rule, tenant.OrganizationID); err != nil {
+			services.LogActivity(config.DB, c, "Create", moduleName, "", nil, input, "error", "Invalid group rule: "+err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Invalid group rule: " + err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+		if len(input.Members) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Dynamic groups cannot have static members",
+				"data":    nil,
+			})
+			return
+		}
+		ruleJSON, _ = json.Marshal(input.Rule)
+	}
+
+	// Validasi custom attribute member sebelum transaksi dimulai
+	attributeInputs := make([]map[string]interface{}, len(input.Members))
+	for i, m := range input.Members {
+		attributeInputs[i] = m.Attributes
+		timezone, err := services.NormalizeTimezone(m.Timezone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Invalid member timezone: " + err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+		input.Members[i].Timezone = timezone
+	}
+	memberAttributes, err := buildMembersAttributes(tenant.OrganizationID, attributeInputs)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleName, "", nil, input, "error", "Invalid member attributes: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid member attributes: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
 	// Start a database transaction
 	tx := config.DB.Begin()
 	if tx.Error != nil {
@@ -236,15 +306,18 @@ func RegisterGroup(c *gin.Context) {
 
 	// CREATE NEW GROUP
 	newGroup := models.Group{
-		Name:         input.Name,
-		DomainStatus: input.DomainStatus,
-		CreatedBy:    input.CreatedBy,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Name:           input.Name,
+		DomainStatus:   input.DomainStatus,
+		Type:           input.Type,
+		Rule:           ruleJSON,
+		OrganizationID: tenant.OrganizationID,
+		CreatedBy:      input.CreatedBy,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
 	var existingGroup models.Group
-	if err := tx.Where("name = ? AND created_by = ?", input.Name, input.CreatedBy).First(&existingGroup).Error; err == nil {
+	if err := tx.Where("name = ? AND organization_id = ?", input.Name, tenant.OrganizationID).First(&existingGroup).Error; err == nil {
 		tx.Rollback()
 		errorMessage := "Group Name already exists"
 		services.LogActivity(config.DB, c, "Create", moduleName, "", nil, input, "error", errorMessage)
@@ -272,7 +345,7 @@ func RegisterGroup(c *gin.Context) {
 	var createdMembers []models.Member
 	var memberResponses []models.MemberResponse
 
-	for _, memberInput := range input.Members {
+	for i, memberInput := range input.Members {
 		var existingMember models.Member
 		if err := tx.Where("email = ? AND group_id = ?", memberInput.Email, newGroup.ID).First(&existingMember).Error; err == nil {
 			tx.Rollback()
@@ -297,15 +370,17 @@ func RegisterGroup(c *gin.Context) {
 		}
 
 		newMember := models.Member{
-			GroupID:   newGroup.ID,
-			Name:      memberInput.Name,
-			Email:     memberInput.Email,
-			Position:  memberInput.Position,
-			Company:   memberInput.Company,
-			Country:   memberInput.Country,
-			CreatedBy: input.CreatedBy,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			GroupID:    newGroup.ID,
+			Name:       memberInput.Name,
+			Email:      memberInput.Email,
+			Position:   memberInput.Position,
+			Company:    memberInput.Company,
+			Country:    memberInput.Country,
+			Timezone:   memberInput.Timezone,
+			Attributes: memberAttributes[i],
+			CreatedBy:  input.CreatedBy,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
 		}
 
 		if err := tx.Create(&newMember).Error; err != nil {
@@ -321,14 +396,16 @@ func RegisterGroup(c *gin.Context) {
 		}
 		createdMembers = append(createdMembers, newMember)
 		memberResponses = append(memberResponses, models.MemberResponse{
-			ID:        newMember.ID,
-			Name:      newMember.Name,
-			Email:     newMember.Email,
-			Position:  newMember.Position,
-			Company:   newMember.Company,
-			Country:   newMember.Country,
-			CreatedAt: newMember.CreatedAt,
-			UpdatedAt: newMember.UpdatedAt,
+			ID:         newMember.ID,
+			Name:       newMember.Name,
+			Email:      newMember.Email,
+			Position:   newMember.Position,
+			Company:    newMember.Company,
+			Country:    newMember.Country,
+			Timezone:   newMember.Timezone,
+			Attributes: newMember.Attributes,
+			CreatedAt:  newMember.CreatedAt,
+			UpdatedAt:  newMember.UpdatedAt,
 		})
 	}
 
@@ -349,6 +426,8 @@ func RegisterGroup(c *gin.Context) {
 		ID:           newGroup.ID,
 		Name:         newGroup.Name,
 		DomainStatus: newGroup.DomainStatus,
+		Type:         newGroup.Type,
+		Rule:         newGroup.Rule,
 		CreatedAt:    newGroup.CreatedAt,
 		UpdatedAt:    newGroup.UpdatedAt,
 		Members:      memberResponses,
@@ -388,6 +467,10 @@ func UpdateGroup(c *gin.Context) {
 	}
 
 	var updatedBy = int(req.UpdatedBy)
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 
 	// Start a database transaction
 	tx := config.DB.Begin()
@@ -405,7 +488,7 @@ func UpdateGroup(c *gin.Context) {
 
 	// Ambil grup yang saat ini akan diperbarui terlebih dahulu
 	// Ini penting agar kita punya objek existingGroup dengan ID yang benar untuk pengecualian
-	if err := tx.First(&existingGroup, groupID).Error; err != nil {
+	if err := tx.Scopes(tenant.Scope).First(&existingGroup, groupID).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Update", moduleName, idParam, nil, req, "error", "Group not found")
@@ -429,7 +512,7 @@ func UpdateGroup(c *gin.Context) {
 	// Hanya cek jika nama grup berubah. Jika nama grup sama dengan yang lama, tidak perlu cek duplikasi.
 	if req.GroupName != existingGroup.Name { // Tambahkan kondisi ini
 		var duplicateGroup models.Group
-		if err := tx.Where("name = ? AND created_by = ? AND id != ?", req.GroupName, req.UpdatedBy, groupID).First(&duplicateGroup).Error; err == nil {
+		if err := tx.Where("name = ? AND organization_id = ? AND id != ?", req.GroupName, existingGroup.OrganizationID, groupID).First(&duplicateGroup).Error; err == nil {
 			tx.Rollback()
 			errorMessage := "Group Name already exists."
 			services.LogActivity(config.DB, c, "Update", moduleName, idParam, nil, req, "error", errorMessage)
@@ -442,6 +525,41 @@ func UpdateGroup(c *gin.Context) {
 		}
 	}
 
+	// Tipe group: request lama tanpa type mempertahankan tipe yang tersimpan
+	groupType := req.Type
+	if groupType == "" {
+		groupType = existingGroup.Type
+	}
+	if groupType == "" {
+		groupType = models.GroupTypeStatic
+	}
+	ruleJSON := datatypes.JSON(nil)
+	if groupType == models.GroupTypeDynamic {
+		ruleJSON = existingGroup.Rule
+		if req.Rule != nil || len(ruleJSON) == 0 {
+			if err := services.ValidateGroupRule(config.DB, req.Rule, existingGroup.OrganizationID); err != nil {
+				tx.Rollback()
+				services.LogActivity(config.DB, c, "Update", moduleName, idParam, nil, req, "error", "Invalid group rule: "+err.Error())
+				c.JSON(http.StatusBadRequest, gin.H{
+					"status":  "error",
+					"message": "Invalid group rule: " + err.Error(),
+					"data":    nil,
+				})
+				return
+			}
+			ruleJSON, _ = json.Marshal(req.Rule)
+		}
+		if len(req.Members) > 0 {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Dynamic groups cannot have static members",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
 	oldGroupValue := existingGroup // Gunakan existingGroup yang sudah diambil dari DB
 	oldMembersValue := []models.Member{}
 	tx.Where("group_id = ?", groupID).Find(&oldMembersValue) // Ambil anggota lama untuk log
@@ -449,6 +567,8 @@ func UpdateGroup(c *gin.Context) {
 	// Update group details
 	existingGroup.Name = req.GroupName
 	existingGroup.DomainStatus = req.DomainStatus
+	existingGroup.Type = groupType
+	existingGroup.Rule = ruleJSON
 	existingGroup.UpdatedAt = time.Now()
 	existingGroup.UpdatedBy = updatedBy
 
@@ -478,6 +598,33 @@ func UpdateGroup(c *gin.Context) {
 
 	// 2. Create new members from the request payload
 	if len(req.Members) > 0 {
+		attributeInputs := make([]map[string]interface{}, len(req.Members))
+		for i, m := range req.Members {
+			attributeInputs[i] = m.Attributes
+			timezone, err := services.NormalizeTimezone(m.Timezone)
+			if err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusBadRequest, gin.H{
+					"status":  "error",
+					"message": "Invalid member timezone: " + err.Error(),
+					"data":    nil,
+				})
+				return
+			}
+			req.Members[i].Timezone = timezone
+		}
+		memberAttributes, err := buildMembersAttributes(existingGroup.OrganizationID, attributeInputs)
+		if err != nil {
+			tx.Rollback()
+			services.LogActivity(config.DB, c, "Update", moduleName, idParam, oldMembersValue, req.Members, "error", "Invalid member attributes: "+err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Invalid member attributes: " + err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+
 		newMembers := make([]models.Member, len(req.Members))
 		for i, m := range req.Members {
 			// Check for duplicate emails for *new* members within the request
@@ -495,16 +642,18 @@ func UpdateGroup(c *gin.Context) {
 			}
 
 			newMembers[i] = models.Member{
-				GroupID:   uint(groupID),
-				Name:      m.Name,
-				Email:     m.Email,
-				Position:  m.Position,
-				Company:   m.Company,
-				Country:   m.Country,
-				CreatedBy: updatedBy,
-				UpdatedBy: updatedBy,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
+				GroupID:    uint(groupID),
+				Name:       m.Name,
+				Email:      m.Email,
+				Position:   m.Position,
+				Company:    m.Company,
+				Country:    m.Country,
+				Timezone:   m.Timezone,
+				Attributes: memberAttributes[i],
+				CreatedBy:  updatedBy,
+				UpdatedBy:  updatedBy,
+				CreatedAt:  time.Now(),
+				UpdatedAt:  time.Now(),
 			}
 		}
 
@@ -537,20 +686,24 @@ func UpdateGroup(c *gin.Context) {
 	var updatedMembersResponse []models.MemberResponse
 	for _, member := range updatedGroup.Members {
 		updatedMembersResponse = append(updatedMembersResponse, models.MemberResponse{
-			ID:        member.ID,
-			Name:      member.Name,
-			Email:     member.Email,
-			Position:  member.Position,
-			Company:   member.Company,
-			Country:   member.Country,
-			CreatedAt: member.CreatedAt,
-			UpdatedAt: member.UpdatedAt,
+			ID:         member.ID,
+			Name:       member.Name,
+			Email:      member.Email,
+			Position:   member.Position,
+			Company:    member.Company,
+			Country:    member.Country,
+			Timezone:   member.Timezone,
+			Attributes: member.Attributes,
+			CreatedAt:  member.CreatedAt,
+			UpdatedAt:  member.UpdatedAt,
 		})
 	}
 	updatedGroupResponse := models.GroupResponse{
 		ID:           updatedGroup.ID,
 		Name:         updatedGroup.Name,
 		DomainStatus: updatedGroup.DomainStatus,
+		Type:         updatedGroup.Type,
+		Rule:         updatedGroup.Rule,
 		CreatedAt:    updatedGroup.CreatedAt,
 		UpdatedAt:    updatedGroup.UpdatedAt,
 		Members:      updatedMembersResponse,
@@ -578,6 +731,11 @@ func DeleteGroup(c *gin.Context) {
 		return
 	}
 
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	// Mulai transaksi database untuk memastikan atomicity
 	// Artinya, jika ada langkah yang gagal, semua perubahan akan di-rollback
 	tx := config.DB.Begin()
@@ -593,7 +751,7 @@ func DeleteGroup(c *gin.Context) {
 
 	var group models.Group
 	// Periksa apakah grup ada sebelum menghapus
-	if err := tx.First(&group, groupID).Error; err != nil {
+	if err := tx.Scopes(tenant.Scope).First(&group, groupID).Error; err != nil {
 		tx.Rollback() // Rollback jika grup tidak ditemukan atau error
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Delete", moduleName, idParam, nil, nil, "error", "Group not found")
@@ -630,6 +788,19 @@ func DeleteGroup(c *gin.Context) {
 		return
 	}
 
+	// --- Hapus konfigurasi dan log directory sync ---
+	if err := tx.Where("group_id = ?", groupID).Delete(&models.GroupDirectorySync{}).Error; err != nil {
+		tx.Rollback()
+		services.LogActivity(config.DB, c, "Delete", moduleName, idParam, oldGroupData, nil, "error", "Failed to delete group directory sync: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete group directory sync",
+			"data":    err.Error(),
+		})
+		return
+	}
+	tx.Where("group_id = ?", groupID).Delete(&models.DirectorySyncLog{})
+
 	// --- Kemudian, hapus grup itu sendiri ---
 	if err := tx.Delete(&group).Error; err != nil {
 		tx.Rollback() // Rollback jika gagal menghapus grup
@@ -660,3 +831,478 @@ func DeleteGroup(c *gin.Context) {
 		"data":    nil,
 	})
 }
+
+const maxMemberImportSize = 10 << 20 // 10 MB
+
+// IMPORT MEMBERS (CSV / XLSX)
+func ImportGroupMembers(c *gin.Context) {
+	idParam := c.Param("id")
+	groupID, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Import", moduleName, idParam, nil, nil, "error", "Invalid group ID format: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid group ID format. Please provide a valid numeric ID.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var group models.Group
+	if err := config.DB.Scopes(tenant.Scope).First(&group, groupID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			services.LogActivity(config.DB, c, "Import", moduleName, idParam, nil, nil, "error", "Group not found")
+			c.JSON(http.StatusNotFound, gin.H{
+				"status":  "error",
+				"message": "Group not found. It may have been deleted or never existed.",
+				"data":    nil,
+			})
+			return
+		}
+		services.LogActivity(config.DB, c, "Import", moduleName, idParam, nil, nil, "error", "Failed to retrieve group: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to retrieve group. Please try again.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	if group.Type == models.GroupTypeDynamic {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Members of a dynamic group are defined by its rule and cannot be imported.",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 1. Baca parameter form
+	mode := c.DefaultPostForm("mode", models.MemberImportMerge)
+	if mode != models.MemberImportMerge && mode != models.MemberImportReplace && mode != models.MemberImportAppend {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid mode. Use merge, replace or append.",
+			"data":    nil,
+		})
+		return
+	}
+	preview := c.PostForm("preview") == "true"
+
+	mapping := map[string]string{}
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "Invalid mapping. Expected a JSON object of field to column name.",
+				"data":    err.Error(),
+			})
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "File is required.",
+			"data":    err.Error(),
+		})
+		return
+	}
+	if fileHeader.Size > maxMemberImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"status":  "error",
+			"message": "File is too large. Maximum size is 10 MB.",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 2. Parse file dan validasi setiap baris
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Failed to read uploaded file.",
+			"data":    err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	rows, err := services.ReadMemberFile(fileHeader.Filename, file)
+	if err == nil && len(rows) == 0 {
+		err = fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		services.LogActivity(config.DB, c, "Import", moduleName, idParam, nil, fileHeader.Filename, "error", "Failed to parse import file: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	attrs, err := services.LoadMemberAttributes(config.DB, group.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to load member attributes.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	columns, resolvedMapping, err := services.ResolveMemberMapping(rows[0], mapping, attrs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    gin.H{"header": rows[0]},
+		})
+		return
+	}
+
+	validRows, rowErrors := services.ValidateMemberRows(rows[1:], columns, attrs)
+
+	// 3. Tentukan aksi per baris berdasarkan anggota yang sudah ada
+	var existingMembers []models.Member
+	config.DB.Where("group_id = ?", groupID).Find(&existingMembers)
+	existingByEmail := make(map[string]models.Member, len(existingMembers))
+	for _, m := range existingMembers {
+		existingByEmail[strings.ToLower(strings.TrimSpace(m.Email))] = m
+	}
+
+	result := models.MemberImportResult{
+		Mode:      mode,
+		Preview:   preview,
+		Mapping:   resolvedMapping,
+		ValidRows: len(validRows),
+		Errors:    rowErrors,
+	}
+	invalid := map[int]bool{}
+	for _, e := range rowErrors {
+		invalid[e.Row] = true
+	}
+	result.InvalidRows = len(invalid)
+	result.TotalRows = result.ValidRows + result.InvalidRows
+	if result.Errors == nil {
+		result.Errors = []models.MemberImportRowError{}
+	}
+
+	inFile := map[string]bool{}
+	for i, r := range validRows {
+		key := strings.ToLower(r.Member.Email)
+		inFile[key] = true
+		if _, exists := existingByEmail[key]; !exists {
+			validRows[i].Action = "create"
+			result.Created++
+		} else if mode == models.MemberImportAppend {
+			validRows[i].Action = "skip"
+			result.Skipped++
+		} else {
+			validRows[i].Action = "update"
+			result.Updated++
+		}
+	}
+	var deleteIDs []uint
+	if mode == models.MemberImportReplace {
+		for key, m := range existingByEmail {
+			if !inFile[key] {
+				deleteIDs = append(deleteIDs, m.ID)
+			}
+		}
+		result.Deleted = len(deleteIDs)
+	}
+
+	if preview {
+		result.Rows = validRows
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Import preview generated",
+			"data":    result,
+		})
+		return
+	}
+
+	if len(rowErrors) > 0 {
+		services.LogActivity(config.DB, c, "Import", moduleName, idParam, nil, result, "error", "Import file contains invalid rows")
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"status":  "error",
+			"message": "Import file contains invalid rows. Fix them and try again.",
+			"data":    result,
+		})
+		return
+	}
+
+	// 4. Simpan perubahan dalam satu transaksi
+	now := time.Now()
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var newMembers []models.Member
+		for _, r := range validRows {
+			switch r.Action {
+			case "create":
+				attributes, err := services.MergeMemberAttributes(nil, r.Member.Attributes)
+				if err != nil {
+					return err
+				}
+				newMembers = append(newMembers, models.Member{
+					GroupID:    uint(groupID),
+					Name:       r.Member.Name,
+					Email:      r.Member.Email,
+					Position:   r.Member.Position,
+					Company:    r.Member.Company,
+					Country:    r.Member.Country,
+					Timezone:   r.Member.Timezone,
+					Attributes: attributes,
+					CreatedBy:  userID,
+					UpdatedBy:  userID,
+					CreatedAt:  now,
+					UpdatedAt:  now,
+				})
+			case "update":
+				existing := existingByEmail[strings.ToLower(r.Member.Email)]
+				updates := map[string]interface{}{
+					"name":       r.Member.Name,
+					"position":   r.Member.Position,
+					"company":    r.Member.Company,
+					"country":    r.Member.Country,
+					"updated_by": userID,
+					"updated_at": now,
+				}
+				// File lama tanpa kolom timezone tidak menghapus timezone yang sudah diisi
+				if _, ok := columns["timezone"]; ok {
+					updates["timezone"] = r.Member.Timezone
+				}
+				// Hanya attribute yang ada kolomnya di file yang diubah
+				if len(r.Member.Attributes) > 0 {
+					attributes, err := services.MergeMemberAttributes(existing.Attributes, r.Member.Attributes)
+					if err != nil {
+						return err
+					}
+					updates["attributes"] = attributes
+				}
+				if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if len(newMembers) > 0 {
+			if err := tx.CreateInBatches(&newMembers, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(deleteIDs) > 0 {
+			if err := tx.Where("group_id = ? AND id IN ?", groupID, deleteIDs).Delete(&models.Member{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&group).Updates(map[string]interface{}{"updated_by": userID, "updated_at": now}).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Import", moduleName, idParam, nil, result, "error", "Failed to import members: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to import members. Please try again.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Import", moduleName, idParam, nil, result, "success",
+		fmt.Sprintf("Imported members from %s (%s): %d created, %d updated, %d deleted, %d skipped",
+			fileHeader.Filename, mode, result.Created, result.Updated, result.Deleted, result.Skipped))
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Members imported successfully",
+		"data":    result,
+	})
+}
+
+// EXPORT MEMBERS (CSV / XLSX)
+func ExportGroupMembers(c *gin.Context) {
+	idParam := c.Param("id")
+	groupID, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid group ID format. Please provide a valid numeric ID.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid format. Use csv or xlsx.",
+			"data":    nil,
+		})
+		return
+	}
+
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var group models.Group
+	if err := config.DB.Scopes(tenant.Scope).Preload("Members", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("id ASC")
+	}).First(&group, groupID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"status":  "error",
+				"message": "Group not found. It may have been deleted or never existed.",
+				"data":    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to retrieve group. Please try again.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	attrs, err := services.LoadMemberAttributes(config.DB, group.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to load member attributes.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = services.WriteMembersXLSX(&buf, group.Members, attrs)
+	} else {
+		err = services.WriteMembersCSV(&buf, group.Members, attrs)
+	}
+	if err != nil {
+		services.LogActivity(config.DB, c, "Export", moduleName, idParam, nil, nil, "error", "Failed to export members: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to export members.",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	// Nama file hanya berisi karakter aman
+	safeName := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, group.Name)
+	filename := fmt.Sprintf("%s-members.%s", safeName, format)
+
+	services.LogActivity(config.DB, c, "Export", moduleName, idParam, nil, nil, "success", fmt.Sprintf("Exported %d members as %s", len(group.Members), format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// PREVIEW RULE
+// PreviewGroupRule menampilkan anggota yang akan masuk group dynamic dengan rule yang dikirim (belum disimpan).
+func PreviewGroupRule(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var req models.GroupRulePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid request payload. Please check your input.",
+			"data":    err.Error(),
+		})
+		return
+	}
+	if err := services.ValidateGroupRule(config.DB, &req.Rule, tenant.OrganizationID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid group rule: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	members, err := services.ResolveGroupRule(config.DB, &req.Rule, tenant.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to resolve group rule",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	membersResponse := make([]models.MemberResponse, 0, len(members))
+	for _, member := range members {
+		membersResponse = append(membersResponse, models.MemberResponse{
+			ID:         member.ID,
+			Name:       member.Name,
+			Email:      member.Email,
+			Position:   member.Position,
+			Company:    member.Company,
+			Country:    member.Country,
+			Timezone:   member.Timezone,
+			Attributes: member.Attributes,
+			CreatedAt:  member.CreatedAt,
+			UpdatedAt:  member.UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Group rule resolved successfully",
+		"data": gin.H{
+			"memberCount": len(membersResponse),
+			"members":     membersResponse,
+		},
+	})
+}
+
+// buildMembersAttributes memvalidasi custom attribute setiap member sesuai urutan input.
+// Definisi attribute hanya dimuat jika ada member yang mengisinya.
+func buildMembersAttributes(organizationID uint, inputs []map[string]interface{}) ([]datatypes.JSON, error) {
+	result := make([]datatypes.JSON, len(inputs))
+	var defs map[string]models.MemberAttribute
+	for i, input := range inputs {
+		if len(input) == 0 {
+			continue
+		}
+		if defs == nil {
+			loaded, err := services.LoadMemberAttributes(config.DB, organizationID)
+			if err != nil {
+				return nil, err
+			}
+			defs = loaded
+		}
+		attrs, err := services.BuildMemberAttributes(defs, input)
+		if err != nil {
+			return nil, fmt.Errorf("member %d: %w", i+1, err)
+		}
+		result[i] = attrs
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Kalender hari libur dipakai oleh semua campaign, jadi hanya admin yang boleh mengubahnya.
+const moduleNameHoliday = "Holiday"
+
+// CREATE
+func RegisterHoliday(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateHolidayInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameHoliday, "", nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	holiday := models.Holiday{
+		Date:      input.Date,
+		Country:   strings.TrimSpace(input.Country),
+		Name:      strings.TrimSpace(input.Name),
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+
+	var existing models.Holiday
+	if config.DB.Where("date = ? AND country = ?", holiday.Date, holiday.Country).First(&existing).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "Holiday already registered for this date and country",
+			"data":    existing,
+		})
+		return
+	}
+
+	if err := config.DB.Create(&holiday).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameHoliday, "", nil, holiday, "error", "Failed to create holiday: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to create holiday",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameHoliday, strconv.FormatUint(uint64(holiday.ID), 10), nil, holiday, "success", "Holiday created: "+holiday.Date+" "+holiday.Name)
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Holiday created successfully",
+		"data":    holiday,
+	})
+}
+
+// READ: ?year=2026&country=ID (country juga mengembalikan hari libur yang berlaku untuk semua negara)
+func GetHolidays(c *gin.Context) {
+	query := config.DB.Model(&models.Holiday{})
+	if year := c.Query("year"); year != "" {
+		if _, err := strconv.Atoi(year); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid year", "data": nil})
+			return
+		}
+		query = query.Where("date LIKE ?", year+"-%")
+	}
+	if country := c.Query("country"); country != "" {
+		query = query.Where("country = ? OR country = ''", country)
+	}
+
+	var holidays []models.Holiday
+	if err := query.Order("date, country").Find(&holidays).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch holidays",
+			"data":    err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Holidays retrieved successfully",
+		"data":    holidays,
+		"total":   len(holidays),
+	})
+}
+
+// DELETE
+func DeleteHoliday(c *gin.Context) {
+	var holiday models.Holiday
+	if err := config.DB.First(&holiday, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Holiday not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch holiday", "data": err.Error()})
+		return
+	}
+
+	if err := config.DB.Delete(&holiday).Error; err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameHoliday, c.Param("id"), holiday, nil, "error", "Failed to delete holiday: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete holiday",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameHoliday, c.Param("id"), holiday, nil, "success", "Holiday deleted: "+holiday.Date+" "+holiday.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Holiday deleted successfully",
+		"data":    nil,
+	})
+}
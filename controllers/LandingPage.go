@@ -100,6 +100,11 @@ func CloneSite(c *gin.Context) {
 
 // CREATE
 func RegisterLandingPage(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var input models.LandingPageInput
 
 	// Bind dan validasi input JSON
@@ -113,10 +118,25 @@ func RegisterLandingPage(c *gin.Context) {
 		return
 	}
 
+	// Landing page sistem terlihat oleh semua organisasi, jadi hanya boleh dibuat dari tampilan lintas tenant
+	organizationID := tenant.OrganizationID
+	if input.IsSystemTemplate == 1 {
+		if !tenant.All {
+			services.LogActivity(config.DB, c, "Create", moduleNameLandingPage, "", nil, input, "error", "System landing page requires cross-tenant access")
+			c.JSON(http.StatusForbidden, gin.H{
+				"status":  "error",
+				"message": "Only cross-tenant administrators can create system landing pages",
+				"data":    nil,
+			})
+			return
+		}
+		organizationID = 0
+	}
+
 	// CEK DUPLIKASI LANDING PAGE
 	var existingLandingPage models.LandingPage
 	if err := config.DB.
-		Where("name = ? AND created_by = ?", input.Name, input.CreatedBy).
+		Where("name = ? AND organization_id = ?", input.Name, organizationID).
 		First(&existingLandingPage).Error; err == nil {
 		services.LogActivity(config.DB, c, "Create", moduleNameLandingPage, "", nil, input, "error", "Landing Page with this name already registered.")
 		c.JSON(http.StatusConflict, gin.H{
@@ -129,6 +149,7 @@ func RegisterLandingPage(c *gin.Context) {
 
 	// BUAT LANDING PAGE BARU
 	newLandingPage := models.LandingPage{
+		OrganizationID:   organizationID,
 		Name:             input.Name,
 		Body:             input.Body,
 		IsSystemTemplate: input.IsSystemTemplate,
@@ -158,27 +179,18 @@ func RegisterLandingPage(c *gin.Context) {
 
 // READ
 func GetLandingPages(c *gin.Context) {
-	userIDScope, roleScope, errorStatus := services.GetRoleScope(c)
-	if !errorStatus {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
 		return
 	}
 
-	var query *gorm.DB
-	if roleScope == 1 {
-		query = config.DB.Table("landing_pages").
-			Select(`landing_pages.*, 
-				created_by_user.name AS created_by_name, 
-				updated_by_user.name AS updated_by_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = landing_pages.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = landing_pages.updated_by`)
-	} else {
-		query = config.DB.Table("landing_pages").
-			Select(`landing_pages.*, 
-				created_by_user.name AS created_by_name, 
-				updated_by_user.name AS updated_by_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = landing_pages.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = landing_pages.updated_by`).Where("landing_pages.created_by = ? OR landing_pages.is_system_template = ?", userIDScope, 1)
-	}
+	query := config.DB.Table("landing_pages").
+		Select(`landing_pages.*, 
+			created_by_user.name AS created_by_name, 
+			updated_by_user.name AS updated_by_name`).
+		Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = landing_pages.created_by`).
+		Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = landing_pages.updated_by`).
+		Scopes(tenant.ScopeWithSystem)
 
 	var total int64
 	query.Count(&total)
@@ -249,8 +261,13 @@ func UpdateLandingPage(c *gin.Context) {
 		return
 	}
 
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var landingPage models.LandingPage
-	if err := config.DB.First(&landingPage, id).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).First(&landingPage, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Update", moduleNameLandingPage, idParam, nil, nil, "error", "Landing Page not found.")
 			c.JSON(http.StatusNotFound, gin.H{
@@ -285,7 +302,15 @@ func UpdateLandingPage(c *gin.Context) {
 
 	landingPage.Name = updatedData.Name
 	landingPage.Body = updatedData.Body
-	landingPage.IsSystemTemplate = int(updatedData.IsSystemTemplate)
+	// Status landing page sistem hanya bisa diubah dari tampilan lintas tenant
+	if tenant.All {
+		landingPage.IsSystemTemplate = int(updatedData.IsSystemTemplate)
+		if landingPage.IsSystemTemplate == 1 {
+			landingPage.OrganizationID = 0
+		} else if landingPage.OrganizationID == 0 {
+			landingPage.OrganizationID = tenant.OrganizationID
+		}
+	}
 	landingPage.UpdatedBy = int(updatedData.UpdatedBy)
 	landingPage.UpdatedAt = time.Now()
 
@@ -324,8 +349,13 @@ func DeleteLandingPage(c *gin.Context) {
 	}
 
 	// CHECK IF LANDING PAGE THAT WANT TO BE DELETE EXIST
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var landingPageDelete models.LandingPage
-	if err := config.DB.First(&landingPageDelete, id).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).First(&landingPageDelete, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Delete", moduleNameLandingPage, landingPageIDParam, nil, nil, "error", "Landing Page not found.")
 			c.JSON(http.StatusNotFound, gin.H{
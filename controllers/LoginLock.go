@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Lockout login akun/IP hanya bisa dilihat dan dibuka oleh admin.
+const moduleNameLoginLock = "Login Lock"
+
+// READ: ?status=locked (default, sedang dikunci atau dalam jeda) | all; ?kind=account|ip
+func GetLoginLocks(c *gin.Context) {
+	status := c.DefaultQuery("status", "locked")
+	if status != "locked" && status != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid status. Use locked or all.", "data": nil})
+		return
+	}
+
+	query := config.DB.Model(&models.LoginThrottle{}).Where("failures > 0 OR locked_until IS NOT NULL")
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var throttles []models.LoginThrottle
+	if err := query.Order("updated_at DESC").Find(&throttles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch login locks",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	responses := make([]models.LoginThrottleResponse, 0, len(throttles))
+	for _, t := range throttles {
+		retry := services.LoginRetryAt(t, now)
+		if status == "locked" && retry == nil {
+			continue
+		}
+		responses = append(responses, models.LoginThrottleResponse{
+			LoginThrottle: t,
+			Locked:        t.LockedUntil != nil && now.Before(*t.LockedUntil),
+			RetryAfter:    retry,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Login locks retrieved successfully",
+		"data":    responses,
+		"total":   len(responses),
+	})
+}
+
+// UNLOCK: buka lockout akun atau IP dan reset hitungan kegagalannya
+func UnlockLogin(c *gin.Context) {
+	var throttle models.LoginThrottle
+	if err := config.DB.First(&throttle, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Login lock not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch login lock", "data": err.Error()})
+		return
+	}
+
+	if err := services.UnlockLoginThrottle(config.DB, throttle.ID); err != nil {
+		services.LogActivity(config.DB, c, "Unlock", moduleNameLoginLock, c.Param("id"), throttle, nil, "error", "Failed to unlock login: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to unlock login",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Unlock", moduleNameLoginLock, c.Param("id"), throttle, nil, "success", "Login unlocked for "+throttle.Kind+" "+throttle.Key)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Login unlocked successfully",
+		"data":    nil,
+	})
+}
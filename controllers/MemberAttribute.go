@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Definisi attribute berlaku untuk semua group, jadi hanya admin yang boleh mengubahnya.
+const moduleNameMemberAttribute = "Member Attribute"
+
+// CREATE
+func RegisterMemberAttribute(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateMemberAttributeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameMemberAttribute, "", nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+	input.Key = strings.TrimSpace(input.Key)
+	if err := services.ValidateMemberAttributeKey(input.Key); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var existing models.MemberAttribute
+	if config.DB.Scopes(services.InOrganization(tenant.OrganizationID)).Where("`key` = ?", input.Key).First(&existing).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "Member attribute with this key already exists",
+			"data":    nil,
+		})
+		return
+	}
+
+	attribute := models.MemberAttribute{
+		OrganizationID: tenant.OrganizationID,
+		Key:            input.Key,
+		Label:          strings.TrimSpace(input.Label),
+		Type:           input.Type,
+		Description:    input.Description,
+		CreatedAt:      time.Now(),
+		CreatedBy:      userID,
+		UpdatedAt:      time.Now(),
+		UpdatedBy:      userID,
+	}
+	if err := config.DB.Create(&attribute).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameMemberAttribute, "", nil, attribute, "error", "Failed to create member attribute: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to create member attribute",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameMemberAttribute, strconv.FormatUint(uint64(attribute.ID), 10), nil, attribute, "success", "Member attribute created successfully")
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Member attribute created successfully",
+		"data":    attribute,
+	})
+}
+
+// READ
+func GetMemberAttributes(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var attributes []models.MemberAttribute
+	if err := config.DB.Scopes(tenant.Scope).Order("`key`").Find(&attributes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch member attributes",
+			"data":    err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Member attributes retrieved successfully",
+		"data":    attributes,
+		"total":   len(attributes),
+	})
+}
+
+// UPDATE
+func UpdateMemberAttribute(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var attribute models.MemberAttribute
+	if err := config.DB.Scopes(tenant.Scope).First(&attribute, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Member attribute not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch member attribute", "data": err.Error()})
+		return
+	}
+
+	var input models.UpdateMemberAttributeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameMemberAttribute, c.Param("id"), nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	old := attribute
+	attribute.Label = strings.TrimSpace(input.Label)
+	attribute.Description = input.Description
+	attribute.UpdatedAt = time.Now()
+	attribute.UpdatedBy = userID
+	if err := config.DB.Save(&attribute).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameMemberAttribute, c.Param("id"), old, attribute, "error", "Failed to update member attribute: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to update member attribute",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameMemberAttribute, c.Param("id"), old, attribute, "success", "Member attribute updated successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Member attribute updated successfully",
+		"data":    attribute,
+	})
+}
+
+// DELETE
+func DeleteMemberAttribute(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var attribute models.MemberAttribute
+	if err := config.DB.Scopes(tenant.Scope).First(&attribute, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Member attribute not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch member attribute", "data": err.Error()})
+		return
+	}
+
+	// Rule group dynamic yang masih memakai attribute ini akan gagal di-resolve
+	var groups []models.Group
+	config.DB.Select("id", "name").Scopes(services.InOrganization(attribute.OrganizationID)).
+		Where("type = ? AND JSON_SEARCH(rule, 'one', ?) IS NOT NULL", models.GroupTypeDynamic, "attr."+attribute.Key).
+		Find(&groups)
+	if len(groups) > 0 {
+		names := make([]string, 0, len(groups))
+		for _, g := range groups {
+			names = append(names, g.Name)
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "Member attribute is used by dynamic group rules: " + strings.Join(names, ", "),
+			"data":    nil,
+		})
+		return
+	}
+
+	// Hapus definisi beserta nilainya di semua member group organisasi yang sama
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE members SET attributes = JSON_REMOVE(attributes, ?) WHERE JSON_CONTAINS_PATH(attributes, 'one', ?) "+
+			"AND group_id IN (SELECT id FROM `groups` WHERE organization_id = ?)",
+			`$."`+attribute.Key+`"`, `$."`+attribute.Key+`"`, attribute.OrganizationID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&attribute).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameMemberAttribute, c.Param("id"), attribute, nil, "error", "Failed to delete member attribute: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete member attribute",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameMemberAttribute, c.Param("id"), attribute, nil, "success", "Member attribute deleted successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Member attribute deleted successfully",
+		"data":    nil,
+	})
+}
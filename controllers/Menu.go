@@ -1,83 +1,289 @@
 package controllers
 
-// func GetMenusAndSubmenus(c *gin.Context) {
-// 	var menus []models.Menu
-// 	// Preload Submenus untuk setiap Menu
-// 	if err := config.DB.Preload("Submenus").Where("is_active = ?", 1).Find(&menus).Error; err != nil {
-// 		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch menus"})
-// 		return
-// 	}
-
-// 	// Konversi ke format yang diharapkan frontend (MenuFromBackend)
-// 	// Kita perlu menguraikan string JSON allowed_roles menjadi []int
-// 	var responseData []struct {
-// 		ID       uint   `json:"id"`
-// 		Name     string `json:"name"`
-// 		Submenus []struct {
-// 			MenuID       uint   `json:"menu_id"`
-// 			Name         string `json:"name"`
-// 			Icon         string `json:"icon"`
-// 			Url          string `json:"url"`
-// 			IsActive     int    `json:"is_active"`
-// 			AllowedRoles []int  `json:"allowed_roles"` // Ini akan menjadi array int
-// 		} `json:"submenus"`
-// 	}
-
-// 	for _, menu := range menus {
-// 		menuItem := struct {
-// 			ID       uint   `json:"id"`
-// 			Name     string `json:"name"`
-// 			Submenus []struct {
-// 				MenuID       uint   `json:"menu_id"`
-// 				Name         string `json:"name"`
-// 				Icon         string `json:"icon"`
-// 				Url          string `json:"url"`
-// 				IsActive     int    `json:"is_active"`
-// 				AllowedRoles []int  `json:"allowed_roles"`
-// 			} `json:"submenus"`
-// 		}{
-// 			ID:   menu.ID,
-// 			Name: menu.Name,
-// 			Submenus: []struct {
-// 				MenuID       uint   `json:"menu_id"`
-// 				Name         string `json:"name"`
-// 				Icon         string `json:"icon"`
-// 				Url          string `json:"url"`
-// 				IsActive     int    `json:"is_active"`
-// 				AllowedRoles []int  `json:"allowed_roles"`
-// 			}{},
-// 		}
-
-// 		for _, submenu := range menu.Submenus {
-// 			var roles []int
-// 			// Unmarshal string JSON allowed_roles menjadi []int
-// 			if err := json.Unmarshal([]byte(submenu.AllowedRoles), &roles); err != nil {
-// 				log.Printf("Error unmarshalling allowed_roles for submenu %s: %v", submenu.Name, err)
-// 				roles = []int{} // Default ke array kosong jika gagal
-// 			}
-
-// 			menuItem.Submenus = append(menuItem.Submenus, struct {
-// 				MenuID       uint   `json:"menu_id"`
-// 				Name         string `json:"name"`
-// 				Icon         string `json:"icon"`
-// 				Url          string `json:"url"`
-// 				IsActive     int    `json:"is_active"`
-// 				AllowedRoles []int  `json:"allowed_roles"`
-// 			}{
-// 				MenuID:       submenu.MenuID,
-// 				Name:         submenu.Name,
-// 				Icon:         submenu.Icon,
-// 				Url:          submenu.Url,
-// 				IsActive:     submenu.IsActive,
-// 				AllowedRoles: roles, // Sudah berupa []int
-// 			})
-// 		}
-// 		responseData = append(responseData, menuItem)
-// 	}
-
-// 	c.JSON(http.StatusOK, gin.H{
-// 		"status":  "success",
-// 		"message": "Menus and submenus fetched successfully",
-// 		"data":    responseData,
-// 	})
-// }
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	moduleNameMenu    = "Menu"
+	moduleNameSubmenu = "Submenu"
+)
+
+// CREATE MENU
+func RegisterMenu(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var input models.MenuInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameMenu, "", nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Menu{}).Where("name = ?", input.Name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Menu with this name already exists", "data": nil})
+		return
+	}
+
+	menu := models.Menu{
+		Name:      input.Name,
+		IsActive:  input.IsActive,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+	if err := createWithActiveFlag(&menu, input.IsActive); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameMenu, "", nil, menu, "failed", "Failed to create menu: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to create menu", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameMenu, strconv.Itoa(int(menu.ID)), nil, menu, "success", "Menu created successfully")
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Menu created successfully", "data": menu})
+}
+
+// createWithActiveFlag menyimpan record baru; is_active 0 ditulis eksplisit karena kolomnya ber-default 1
+func createWithActiveFlag(record interface{}, isActive int) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(record).Error; err != nil {
+			return err
+		}
+		if isActive == 0 {
+			return tx.Model(record).Update("is_active", 0).Error
+		}
+		return nil
+	})
+}
+
+// READ MENU (beserta submenu-nya)
+func GetMenus(c *gin.Context) {
+	var menus []models.Menu
+	if err := config.DB.Preload("Submenus", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).Order("id").Find(&menus).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch menus", "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Menus retrieved successfully", "data": menus})
+}
+
+// UPDATE MENU
+func UpdateMenu(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var menu models.Menu
+	if err := config.DB.First(&menu, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Menu not found", "data": nil})
+		return
+	}
+	oldMenu := menu
+
+	var input models.MenuInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameMenu, idParam, oldMenu, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	var count int64
+	config.DB.Model(&models.Menu{}).Where("name = ? AND id <> ?", input.Name, menu.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Menu with this name already exists", "data": nil})
+		return
+	}
+
+	menu.Name = input.Name
+	menu.IsActive = input.IsActive
+	menu.UpdatedAt = time.Now()
+	menu.UpdatedBy = userID
+	if err := config.DB.Omit("Submenus").Save(&menu).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameMenu, idParam, oldMenu, menu, "failed", "Failed to update menu: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to update menu", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameMenu, idParam, oldMenu, menu, "success", "Menu updated successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Menu updated successfully", "data": menu})
+}
+
+// DELETE MENU: submenu harus dihapus atau dipindahkan dulu; akses role ke menu ikut dihapus
+func DeleteMenu(c *gin.Context) {
+	idParam := c.Param("id")
+	var menu models.Menu
+	if err := config.DB.First(&menu, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Menu not found", "data": nil})
+		return
+	}
+
+	var submenus int64
+	config.DB.Model(&models.Submenu{}).Where("menu_id = ?", menu.ID).Count(&submenus)
+	if submenus > 0 {
+		services.LogActivity(config.DB, c, "Delete", moduleNameMenu, idParam, menu, nil, "failed", "Menu still has submenus")
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "Menu still has " + strconv.FormatInt(submenus, 10) + " submenu(s)",
+			"data":    nil,
+		})
+		return
+	}
+
+	var accessRoles []uint
+	config.DB.Model(&models.RoleMenuAccess{}).Where("menu_id = ?", menu.ID).Pluck("role_id", &accessRoles)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_id = ?", menu.ID).Delete(&models.RoleMenuAccess{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&menu).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameMenu, idParam, menu, nil, "failed", "Failed to delete menu: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to delete menu", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameMenu, idParam, gin.H{"menu": menu, "roleIds": accessRoles}, nil, "success", "Menu deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Menu deleted successfully", "data": nil})
+}
+
+// CREATE SUBMENU
+func RegisterSubmenu(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var input models.SubmenuInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameSubmenu, "", nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	if config.DB.First(&models.Menu{}, input.MenuID).Error != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Menu does not exist", "data": nil})
+		return
+	}
+	var count int64
+	config.DB.Model(&models.Submenu{}).Where("name = ? OR url = ?", input.Name, input.Url).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Submenu with this name or URL already exists", "data": nil})
+		return
+	}
+
+	submenu := models.Submenu{
+		MenuID:    input.MenuID,
+		Name:      input.Name,
+		Icon:      input.Icon,
+		Url:       input.Url,
+		IsActive:  input.IsActive,
+		CreatedAt: time.Now(),
+		CreatedBy: userID,
+	}
+	if err := createWithActiveFlag(&submenu, input.IsActive); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameSubmenu, "", nil, submenu, "failed", "Failed to create submenu: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to create submenu", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameSubmenu, strconv.Itoa(int(submenu.ID)), nil, submenu, "success", "Submenu created successfully")
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Submenu created successfully", "data": submenu})
+}
+
+// READ SUBMENU: ?menuId= untuk satu menu
+func GetSubmenus(c *gin.Context) {
+	query := config.DB.Model(&models.Submenu{})
+	if menuID := c.Query("menuId"); menuID != "" {
+		query = query.Where("menu_id = ?", menuID)
+	}
+	var submenus []models.Submenu
+	if err := query.Order("menu_id, id").Find(&submenus).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch submenus", "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Submenus retrieved successfully", "data": submenus})
+}
+
+// UPDATE SUBMENU
+func UpdateSubmenu(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var submenu models.Submenu
+	if err := config.DB.First(&submenu, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Submenu not found", "data": nil})
+		return
+	}
+	oldSubmenu := submenu
+
+	var input models.SubmenuInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameSubmenu, idParam, oldSubmenu, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	if config.DB.First(&models.Menu{}, input.MenuID).Error != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Menu does not exist", "data": nil})
+		return
+	}
+	var count int64
+	config.DB.Model(&models.Submenu{}).Where("(name = ? OR url = ?) AND id <> ?", input.Name, input.Url, submenu.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Submenu with this name or URL already exists", "data": nil})
+		return
+	}
+
+	submenu.MenuID = input.MenuID
+	submenu.Name = input.Name
+	submenu.Icon = input.Icon
+	submenu.Url = input.Url
+	submenu.IsActive = input.IsActive
+	submenu.UpdatedAt = time.Now()
+	submenu.UpdatedBy = userID
+	if err := config.DB.Omit("Menu").Save(&submenu).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameSubmenu, idParam, oldSubmenu, submenu, "failed", "Failed to update submenu: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to update submenu", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameSubmenu, idParam, oldSubmenu, submenu, "success", "Submenu updated successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Submenu updated successfully", "data": submenu})
+}
+
+// DELETE SUBMENU: akses role ke submenu ikut dihapus
+func DeleteSubmenu(c *gin.Context) {
+	idParam := c.Param("id")
+	var submenu models.Submenu
+	if err := config.DB.First(&submenu, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Submenu not found", "data": nil})
+		return
+	}
+
+	var accessRoles []uint
+	config.DB.Model(&models.RoleSubmenuAccess{}).Where("submenu_id = ?", submenu.ID).Pluck("role_id", &accessRoles)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("submenu_id = ?", submenu.ID).Delete(&models.RoleSubmenuAccess{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&submenu).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameSubmenu, idParam, submenu, nil, "failed", "Failed to delete submenu: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to delete submenu", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameSubmenu, idParam, gin.H{"submenu": submenu, "roleIds": accessRoles}, nil, "success", "Submenu deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Submenu deleted successfully", "data": nil})
+}
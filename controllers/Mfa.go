@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Endpoint /mfa/* dipakai user untuk mengelola MFA miliknya; policy dan reset hanya untuk admin.
+const moduleNameMfa = "MFA"
+
+// mfaError menerjemahkan error service MFA menjadi response JSON.
+func mfaError(c *gin.Context, err error, message string) {
+	switch err {
+	case services.ErrMfaCodeInvalid:
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "Invalid verification code", "data": nil})
+	case services.ErrMfaNotEnrolled:
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "MFA is not set up for this account", "data": nil})
+	case services.ErrMfaAlreadyEnabled:
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "MFA is already enabled", "data": nil})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": message, "data": err.Error()})
+	}
+}
+
+// READ: status MFA user yang sedang login
+func GetMfaStatus(c *gin.Context) {
+	user, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	var status models.MfaStatusResponse
+	var mfa models.UserMfa
+	if err := config.DB.Where("user_id = ? AND enabled = ?", user.ID, true).First(&mfa).Error; err == nil {
+		status.Enabled, status.EnabledAt = true, mfa.EnabledAt
+	} else if err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch MFA status", "data": err.Error()})
+		return
+	}
+	required, err := services.MfaRequiredForRole(config.DB, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch MFA policy", "data": err.Error()})
+		return
+	}
+	status.Required = required
+	if status.Enabled {
+		status.RecoveryCodesRemaining, _ = services.RemainingRecoveryCodes(config.DB, user.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "MFA status retrieved successfully",
+		"data":    status,
+	})
+}
+
+// ENROLL: buat secret TOTP baru (belum aktif sampai diverifikasi lewat /mfa/activate)
+func EnrollMfa(c *gin.Context) {
+	user, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := services.BeginMfaEnrollment(config.DB, user.ID, user.Email)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Enroll", moduleNameMfa, strconv.Itoa(int(user.ID)), nil, nil, "error", "Failed to start MFA enrollment: "+err.Error())
+		mfaError(c, err, "Failed to start MFA enrollment")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Scan the QR code with your authenticator app, then verify a code to activate MFA",
+		"data":    enrollment,
+	})
+}
+
+// ACTIVATE: verifikasi kode pertama, aktifkan MFA dan kembalikan recovery code (hanya ditampilkan sekali)
+func ActivateMfa(c *gin.Context) {
+	user, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+	var input models.MfaCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Validation failed", "data": err.Error()})
+		return
+	}
+
+	codes, err := services.ActivateMfa(config.DB, user.ID, input.Code)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Enable", moduleNameMfa, strconv.Itoa(int(user.ID)), nil, nil, "error", "Failed to activate MFA: "+err.Error())
+		mfaError(c, err, "Failed to activate MFA")
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Enable", moduleNameMfa, strconv.Itoa(int(user.ID)), nil, nil, "success", "MFA enabled")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "MFA enabled. Store the recovery codes in a safe place, they will not be shown again.",
+		"data":    gin.H{"recoveryCodes": codes},
+	})
+}
+
+// REGENERATE RECOVERY CODES: butuh kode TOTP saat ini; recovery code lama tidak berlaku lagi
+func RegenerateMfaRecoveryCodes(c *gin.Context) {
+	user, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+	var input models.MfaCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Validation failed", "data": err.Error()})
+		return
+	}
+
+	if err := services.VerifyMfa(config.DB, user.ID, input.Code, ""); err != nil {
+		mfaError(c, err, "Failed to verify code")
+		return
+	}
+	codes, err := services.RegenerateRecoveryCodes(config.DB, user.ID)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Regenerate Recovery Codes", moduleNameMfa, strconv.Itoa(int(user.ID)), nil, nil, "error", "Failed to regenerate recovery codes: "+err.Error())
+		mfaError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Regenerate Recovery Codes", moduleNameMfa, strconv.Itoa(int(user.ID)), nil, nil, "success", "Recovery codes regenerated")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Recovery codes regenerated",
+		"data":    gin.H{"recoveryCodes": codes},
+	})
+}
+
+// DISABLE: butuh kode TOTP atau recovery code; ditolak jika role user wajib MFA
+func DisableMfa(c *gin.Context) {
+	user, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+	var input models.MfaDisableInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Validation failed", "data": err.Error()})
+		return
+	}
+
+	required, err := services.MfaRequiredForRole(config.DB, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch MFA policy", "data": err.Error()})
+		return
+	}
+	if required {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "MFA is required for your role and cannot be disabled", "data": nil})
+		return
+	}
+	if err := services.VerifyMfa(config.DB, user.ID, input.Code, input.RecoveryCode); err != nil {
+		mfaError(c, err, "Failed to verify code")
+		return
+	}
+	if err := services.DisableMfa(config.DB, user.ID); err != nil {
+		services.LogActivity(config.DB, c, "Disable", moduleNameMfa, strconv.Itoa(int(user.ID)), nil, nil, "error", "Failed to disable MFA: "+err.Error())
+		mfaError(c, err, "Failed to disable MFA")
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Disable", moduleNameMfa, strconv.Itoa(int(user.ID)), nil, nil, "success", "MFA disabled")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "MFA disabled",
+		"data":    nil,
+	})
+}
+
+// RESET (admin): hapus MFA user lain yang kehilangan authenticator dan recovery code-nya.
+// Jika role-nya wajib MFA, user akan diminta mendaftar ulang saat login berikutnya.
+func ResetUserMfa(c *gin.Context) {
+	var target models.User
+	if err := config.DB.First(&target, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "User not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch user", "data": err.Error()})
+		return
+	}
+
+	if err := services.DisableMfa(config.DB, target.ID); err != nil {
+		services.LogActivity(config.DB, c, "Reset", moduleNameMfa, c.Param("id"), nil, nil, "error", "Failed to reset MFA: "+err.Error())
+		mfaError(c, err, "Failed to reset MFA")
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Reset", moduleNameMfa, c.Param("id"), nil, nil, "success", "MFA reset for "+target.Email)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "MFA reset successfully",
+		"data":    nil,
+	})
+}
+
+// READ POLICY: role yang wajib MFA
+func GetMfaPolicy(c *gin.Context) {
+	var policies []models.MfaRolePolicy
+	if err := config.DB.Order("role_id").Find(&policies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch MFA policy", "data": err.Error()})
+		return
+	}
+	roleIDs := make([]uint, 0, len(policies))
+	for _, p := range policies {
+		roleIDs = append(roleIDs, p.RoleID)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "MFA policy retrieved successfully",
+		"data":    gin.H{"roleIds": roleIDs},
+	})
+}
+
+// UPDATE POLICY (admin): ganti daftar role yang wajib MFA
+func UpdateMfaPolicy(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var input models.MfaPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Validation failed", "data": err.Error()})
+		return
+	}
+
+	seen := map[uint]bool{}
+	roleIDs := make([]uint, 0, len(input.RoleIDs))
+	for _, id := range input.RoleIDs {
+		if !seen[id] {
+			seen[id] = true
+			roleIDs = append(roleIDs, id)
+		}
+	}
+	if len(roleIDs) > 0 {
+		var count int64
+		if err := config.DB.Model(&models.Role{}).Where("id IN ?", roleIDs).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to validate roles", "data": err.Error()})
+			return
+		}
+		if int(count) != len(roleIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "One or more roles do not exist", "data": nil})
+			return
+		}
+	}
+
+	var old []models.MfaRolePolicy
+	config.DB.Find(&old)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.MfaRolePolicy{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, id := range roleIDs {
+			if err := tx.Create(&models.MfaRolePolicy{RoleID: id, UpdatedAt: now, UpdatedBy: userID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Update Policy", moduleNameMfa, "", old, roleIDs, "error", "Failed to update MFA policy: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to update MFA policy", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update Policy", moduleNameMfa, "", old, roleIDs, "success", "MFA policy updated")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "MFA policy updated successfully",
+		"data":    gin.H{"roleIds": roleIDs},
+	})
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const moduleNameOrganization = "Organization"
+
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// validateOrganizationInput menormalkan input dan memastikan slug valid dan unik. Mengembalikan status dan pesan jika tidak valid.
+func validateOrganizationInput(input *models.OrganizationInput, exceptID uint) (int, string) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Slug = strings.ToLower(strings.TrimSpace(input.Slug))
+	if !organizationSlugPattern.MatchString(input.Slug) {
+		return http.StatusBadRequest, "Slug may only contain lowercase letters, digits and dashes"
+	}
+	var count int64
+	config.DB.Model(&models.Organization{}).Where("slug = ? AND id <> ?", input.Slug, exceptID).Count(&count)
+	if count > 0 {
+		return http.StatusConflict, "Organization with this slug already exists"
+	}
+	return 0, ""
+}
+
+// GET /organizations/current: organisasi user yang sedang login
+func GetCurrentOrganization(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var org models.Organization
+	if err := config.DB.First(&org, tenant.OrganizationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Organization not found", "data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Organization retrieved successfully",
+		"data":    gin.H{"organization": org, "crossTenant": tenant.All},
+	})
+}
+
+// CREATE
+func RegisterOrganization(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var input models.OrganizationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameOrganization, "", nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	if status, msg := validateOrganizationInput(&input, 0); msg != "" {
+		services.LogActivity(config.DB, c, "Create", moduleNameOrganization, "", nil, input, "failed", msg)
+		c.JSON(status, gin.H{"status": "error", "message": msg, "data": nil})
+		return
+	}
+
+	now := time.Now()
+	org := models.Organization{
+		Name:      input.Name,
+		Slug:      input.Slug,
+		IsActive:  1,
+		CreatedAt: now,
+		CreatedBy: userID,
+		UpdatedAt: now,
+		UpdatedBy: userID,
+	}
+	if err := config.DB.Create(&org).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameOrganization, "", nil, org, "failed", "Failed to create organization: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to create organization", "data": err.Error()})
+		return
+	}
+	if input.IsActive == 0 {
+		config.DB.Model(&org).Update("is_active", 0)
+		org.IsActive = 0
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameOrganization, strconv.Itoa(int(org.ID)), nil, org, "success", "Organization created successfully")
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Organization created successfully", "data": org})
+}
+
+// READ: tampilan lintas tenant untuk super admin, beserta jumlah user dan campaign per organisasi
+func GetOrganizations(c *gin.Context) {
+	query := config.DB.Model(&models.Organization{})
+	if search := c.Query("search"); search != "" {
+		query = query.Where("name LIKE ? OR slug LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+	var orgs []models.Organization
+	if err := query.Order("id").Find(&orgs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch organizations", "data": err.Error()})
+		return
+	}
+
+	type orgCount struct {
+		OrganizationID uint
+		Total          int64
+	}
+	countBy := func(model interface{}) map[uint]int64 {
+		var rows []orgCount
+		config.DB.Model(model).Select("organization_id, COUNT(*) AS total").Group("organization_id").Scan(&rows)
+		result := map[uint]int64{}
+		for _, row := range rows {
+			result[row.OrganizationID] = row.Total
+		}
+		return result
+	}
+	users, campaigns := countBy(&models.User{}), countBy(&models.Campaign{})
+
+	data := make([]models.OrganizationResponse, 0, len(orgs))
+	for _, org := range orgs {
+		data = append(data, models.OrganizationResponse{Organization: org, UserCount: users[org.ID], CampaignCount: campaigns[org.ID]})
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Organizations retrieved successfully", "data": data, "total": len(data)})
+}
+
+// UPDATE
+func UpdateOrganization(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var org models.Organization
+	if err := config.DB.First(&org, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Organization not found", "data": nil})
+		return
+	}
+	oldOrg := org
+
+	var input models.OrganizationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameOrganization, idParam, oldOrg, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	if status, msg := validateOrganizationInput(&input, org.ID); msg != "" {
+		services.LogActivity(config.DB, c, "Update", moduleNameOrganization, idParam, oldOrg, input, "failed", msg)
+		c.JSON(status, gin.H{"status": "error", "message": msg, "data": nil})
+		return
+	}
+	// Organisasi default menampung super admin, jadi slug dan statusnya tetap
+	if org.Slug == models.DefaultOrganizationSlug && (input.Slug != org.Slug || input.IsActive == 0) {
+		services.LogActivity(config.DB, c, "Update", moduleNameOrganization, idParam, oldOrg, input, "failed", "Default organization cannot be renamed or deactivated")
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "The default organization's slug and status cannot be changed", "data": nil})
+		return
+	}
+
+	org.Name = input.Name
+	org.Slug = input.Slug
+	org.IsActive = input.IsActive
+	org.UpdatedAt = time.Now()
+	org.UpdatedBy = userID
+	if err := config.DB.Save(&org).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameOrganization, idParam, oldOrg, org, "failed", "Failed to update organization: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to update organization", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameOrganization, idParam, oldOrg, org, "success", "Organization updated successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Organization updated successfully", "data": org})
+}
+
+// DELETE: hanya organisasi tanpa user dan data; organisasi berisi data cukup dinonaktifkan
+func DeleteOrganization(c *gin.Context) {
+	idParam := c.Param("id")
+	var org models.Organization
+	if err := config.DB.First(&org, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Organization not found", "data": nil})
+		return
+	}
+	if org.Slug == models.DefaultOrganizationSlug {
+		services.LogActivity(config.DB, c, "Delete", moduleNameOrganization, idParam, org, nil, "failed", "Default organization cannot be deleted")
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "The default organization cannot be deleted", "data": nil})
+		return
+	}
+
+	var users int64
+	config.DB.Model(&models.User{}).Where("organization_id = ?", org.ID).Count(&users)
+	if users > 0 {
+		services.LogActivity(config.DB, c, "Delete", moduleNameOrganization, idParam, org, nil, "failed", "Organization still has users")
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Organization still has " + strconv.FormatInt(users, 10) + " user(s)", "data": nil})
+		return
+	}
+	for _, table := range append([]string{"scim_users"}, models.TenantTables...) {
+		var count int64
+		config.DB.Table(table).Where("organization_id = ?", org.ID).Count(&count)
+		if count > 0 {
+			services.LogActivity(config.DB, c, "Delete", moduleNameOrganization, idParam, org, nil, "failed", "Organization still owns data in "+table)
+			c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Organization still owns data; deactivate it instead", "data": gin.H{"table": strings.Trim(table, "`")}})
+			return
+		}
+	}
+
+	if err := config.DB.Delete(&org).Error; err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameOrganization, idParam, org, nil, "failed", "Failed to delete organization: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to delete organization", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameOrganization, idParam, org, nil, "success", "Organization deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Organization deleted successfully", "data": nil})
+}
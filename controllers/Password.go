@@ -0,0 +1,417 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const moduleNamePasswordPolicy = "Password Policy"
+
+// passwordResetCooldown membatasi email reset untuk akun yang sama agar endpoint publik tidak dipakai spam.
+const passwordResetCooldown = time.Minute
+
+// respondPasswordError mengirim 400 untuk pelanggaran kebijakan password, selain itu 500.
+func respondPasswordError(c *gin.Context, err error) {
+	var policyErr *services.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": policyErr.Error(),
+			"data":    nil,
+			"fields": map[string]string{
+				"password": strings.Join(policyErr.Violations, "; "),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"status":  "error",
+		"message": "Failed to process password",
+		"data":    err.Error(),
+	})
+}
+
+// INVITE: buat user tanpa password dan kirim link untuk mengatur password sendiri
+func InviteUser(c *gin.Context) {
+	inviterID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var input models.InviteUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Invite", moduleNameUser, "", nil, input, "error", "Invalid input: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid input",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	if !roleAssignableInTenant(c, tenant, uint(input.Role)) {
+		services.LogActivity(config.DB, c, "Invite", moduleNameUser, "", nil, input, "error", "Cross-tenant role requires cross-tenant access")
+		return
+	}
+
+	var existingUser models.User
+	if err := config.DB.Where("email = ?", input.Email).First(&existingUser).Error; err == nil {
+		services.LogActivity(config.DB, c, "Invite", moduleNameUser, "", nil, input, "error", "Email already exists")
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "User with this email already registered",
+			"data":    "Email already exists",
+			"fields": map[string]string{
+				"email": "Email is already taken",
+			},
+		})
+		return
+	} else if err != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to check existing user",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	// Password kosong: user belum bisa login sampai mengatur password lewat link undangan
+	newUser := models.User{
+		OrganizationID: tenant.OrganizationID,
+		Name:           input.Name,
+		Email:          input.Email,
+		Position:       input.Position,
+		Role:           input.Role,
+		Company:        input.Company,
+		IsActive:       1,
+		CreatedAt:      time.Now(),
+		CreatedBy:      inviterID,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+		token, _, err := services.IssueUserToken(tx, newUser.ID, models.UserTokenInvite, services.InviteTokenTTL, inviterID)
+		if err != nil {
+			return err
+		}
+		// Email dikirim di dalam transaksi: jika gagal, user tidak dibuat dan admin bisa mencoba lagi
+		return services.SendInviteEmail(newUser.Name, newUser.Email, token)
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Invite", moduleNameUser, "", nil, sanitizeUserForLog(newUser), "error", "Failed to invite user: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to invite user",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Invite", moduleNameUser, strconv.Itoa(int(newUser.ID)), nil, sanitizeUserForLog(newUser), "success", "User invited: "+newUser.Email)
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Invitation sent successfully",
+		"data": models.UserResponse{
+			ID:        newUser.ID,
+			Name:      newUser.Name,
+			Email:     newUser.Email,
+			Position:  newUser.Position,
+			CreatedAt: newUser.CreatedAt,
+			UpdatedAt: newUser.UpdatedAt,
+		},
+	})
+}
+
+// RESEND INVITE: terbitkan link baru untuk user yang belum mengatur password; link lama tidak berlaku lagi
+func ResendInvite(c *gin.Context) {
+	inviterID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Scopes(tenant.Scope).First(&user, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "User not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch user", "data": err.Error()})
+		return
+	}
+	if user.PasswordHash != "" {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "User has already set a password", "data": nil})
+		return
+	}
+
+	token, _, err := services.IssueUserToken(config.DB, user.ID, models.UserTokenInvite, services.InviteTokenTTL, inviterID)
+	if err == nil {
+		err = services.SendInviteEmail(user.Name, user.Email, token)
+	}
+	if err != nil {
+		services.LogActivity(config.DB, c, "Invite", moduleNameUser, c.Param("id"), nil, nil, "error", "Failed to resend invitation: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to resend invitation",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Invite", moduleNameUser, c.Param("id"), nil, nil, "success", "Invitation resent: "+user.Email)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Invitation resent successfully",
+		"data":    nil,
+	})
+}
+
+// FORGOT PASSWORD: selalu membalas pesan yang sama agar keberadaan akun tidak bocor
+func ForgotPassword(c *gin.Context) {
+	var input models.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid input",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"status":  "success",
+		"message": "If the email is registered, a password reset link has been sent",
+	}
+
+	var user models.User
+	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil || user.IsActive == 0 {
+		services.LogActivity(config.DB, c, "Forgot Password", "Auth", "", nil, gin.H{"email": input.Email}, "failed", "Password reset requested for unknown or inactive account")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	userID := strconv.Itoa(int(user.ID))
+
+	var recent int64
+	config.DB.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", user.ID, models.UserTokenPasswordReset, time.Now().Add(-passwordResetCooldown)).
+		Count(&recent)
+	if recent > 0 {
+		services.LogActivity(config.DB, c, "Forgot Password", "Auth", userID, nil, nil, "failed", "Password reset requested again within cooldown")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, _, err := services.IssueUserToken(config.DB, user.ID, models.UserTokenPasswordReset, services.PasswordResetTokenTTL, 0)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Forgot Password", "Auth", userID, nil, nil, "error", "Failed to issue reset token: "+err.Error())
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	services.LogActivity(config.DB, c, "Forgot Password", "Auth", userID, nil, nil, "success", "Password reset link issued")
+
+	// Kirim di background agar waktu respons tidak membedakan akun yang ada dan yang tidak
+	go func(name, email string) {
+		if err := services.SendPasswordResetEmail(name, email, token); err != nil {
+			log.Printf("Failed to send password reset email to %s: %v", email, err)
+		}
+	}(user.Name, user.Email)
+	c.JSON(http.StatusOK, response)
+}
+
+// CHECK TOKEN: dipakai halaman set/reset password untuk memvalidasi link sebelum menampilkan form
+func CheckPasswordToken(c *gin.Context) {
+	token, err := services.LookupUserToken(config.DB, c.Query("token"))
+	if err != nil {
+		respondUserTokenError(c, err)
+		return
+	}
+	var user models.User
+	if err := config.DB.First(&user, token.UserID).Error; err != nil {
+		respondUserTokenError(c, services.ErrUserTokenInvalid)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Link is valid",
+		"data": gin.H{
+			"purpose":   token.Purpose,
+			"email":     user.Email,
+			"name":      user.Name,
+			"expiresAt": token.ExpiresAt,
+		},
+	})
+}
+
+func respondUserTokenError(c *gin.Context, err error) {
+	if err == services.ErrUserTokenInvalid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "This link is invalid or has expired",
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"status":  "error",
+		"message": "Failed to verify link",
+		"error":   err.Error(),
+	})
+}
+
+// RESET PASSWORD: atur password dari link undangan atau lupa password. Semua session lama dicabut.
+func ResetPassword(c *gin.Context) {
+	var input models.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid input",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	token, err := services.LookupUserToken(config.DB, input.Token)
+	if err != nil {
+		respondUserTokenError(c, err)
+		return
+	}
+	var user models.User
+	if err := config.DB.First(&user, token.UserID).Error; err != nil || user.IsActive == 0 {
+		respondUserTokenError(c, services.ErrUserTokenInvalid)
+		return
+	}
+	userID := strconv.Itoa(int(user.ID))
+
+	hashedPassword, err := services.ValidateAndHashPassword(config.DB, input.Password, user.Email)
+	if err != nil {
+		respondPasswordError(c, err)
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.ConsumeUserToken(tx, token); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Updates(map[string]interface{}{"password_hash": hashedPassword, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if _, err := services.RevokeUserSessions(tx, user.ID, models.SessionRevokedPasswordChange, 0); err != nil {
+			return err
+		}
+		return services.ResetLoginFailures(tx, user.Email)
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Reset Password", "Auth", userID, nil, nil, "error", "Failed to set password: "+err.Error())
+		if err == services.ErrUserTokenInvalid {
+			respondUserTokenError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to set password",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	action, message := "Reset Password", "Password reset via email link"
+	if token.Purpose == models.UserTokenInvite {
+		action, message = "Accept Invite", "Invitation accepted, password set"
+	}
+	services.LogActivity(config.DB, c, action, "Auth", userID, nil, nil, "success", message)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Password has been set, you can now log in",
+	})
+}
+
+// READ POLICY: publik agar halaman set/reset password bisa menampilkan aturan
+func GetPasswordPolicy(c *gin.Context) {
+	policy, err := services.LoadPasswordPolicy(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch password policy", "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Password policy retrieved successfully",
+		"data":    policy,
+	})
+}
+
+// UPDATE POLICY (admin)
+func UpdatePasswordPolicy(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	var input models.PasswordPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Validation failed", "data": err.Error()})
+		return
+	}
+
+	policy, err := services.LoadPasswordPolicy(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch password policy", "data": err.Error()})
+		return
+	}
+	old := policy
+	policy.MinLength = input.MinLength
+	policy.RequireUppercase = input.RequireUppercase
+	policy.RequireLowercase = input.RequireLowercase
+	policy.RequireDigit = input.RequireDigit
+	policy.RequireSymbol = input.RequireSymbol
+	policy.CheckBreached = input.CheckBreached
+	policy.UpdatedAt = time.Now()
+	policy.UpdatedBy = userID
+
+	// Save dengan map agar nilai false tetap tersimpan (gorm melewati zero value pada struct)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if policy.ID == 0 {
+			if err := tx.Create(&policy).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&policy).Updates(map[string]interface{}{
+			"min_length":        policy.MinLength,
+			"require_uppercase": policy.RequireUppercase,
+			"require_lowercase": policy.RequireLowercase,
+			"require_digit":     policy.RequireDigit,
+			"require_symbol":    policy.RequireSymbol,
+			"check_breached":    policy.CheckBreached,
+			"updated_at":        policy.UpdatedAt,
+			"updated_by":        policy.UpdatedBy,
+		}).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNamePasswordPolicy, "", old, policy, "error", "Failed to update password policy: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to update password policy", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNamePasswordPolicy, strconv.Itoa(int(policy.ID)), old, policy, "success", "Password policy updated")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Password policy updated successfully",
+		"data":    policy,
+	})
+}
@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const moduleNamePeople = "People"
+
+// peopleScope membatasi person yang terlihat: person bersifat global (per email), jadi yang terlihat
+// hanya person yang menjadi member group organisasi atau ditargetkan campaign organisasi.
+func peopleScope(db *gorm.DB, tenant services.Tenant) *gorm.DB {
+	if tenant.All {
+		return db
+	}
+	return db.Where(
+		"people.id IN (?) OR people.id IN (?)",
+		config.DB.Model(&models.Member{}).Select("person_id").
+			Where("person_id IS NOT NULL AND group_id IN (?)", tenantGroupIDs(tenant)),
+		config.DB.Table("recipients").Select("recipients.person_id").
+			Joins("JOIN campaigns ON campaigns.id = recipients.campaign_id").
+			Where("campaigns.organization_id = ? AND recipients.person_id IS NOT NULL", tenant.OrganizationID),
+	)
+}
+
+// tenantGroupIDs adalah subquery id group milik tenant.
+func tenantGroupIDs(tenant services.Tenant) *gorm.DB {
+	return config.DB.Model(&models.Group{}).Select("id").Scopes(tenant.Scope)
+}
+
+// tenantMemberColumn adalah subquery berkorelasi yang mengambil kolom dari member group tenant
+// yang paling baru diperbarui untuk person pada query luar.
+func tenantMemberColumn(column string, tenant services.Tenant) *gorm.DB {
+	return config.DB.Model(&models.Member{}).Select("members."+column).
+		Where("members.person_id = people.id AND members.group_id IN (?)", tenantGroupIDs(tenant)).
+		Order("members.updated_at DESC, members.id DESC").
+		Limit(1)
+}
+
+// applyTenantProfile mengganti profil person dengan data member group tenant. Name, Position, Company
+// dan Country pada tabel people bisa berasal dari organisasi lain, jadi tidak ditampilkan ke tenant.
+func applyTenantProfile(person *models.Person, tenant services.Tenant) error {
+	if tenant.All {
+		return nil
+	}
+	var member models.Member
+	err := config.DB.Where("person_id = ? AND group_id IN (?)", person.ID, tenantGroupIDs(tenant)).
+		Order("updated_at DESC, id DESC").
+		Limit(1).
+		Find(&member).Error
+	if err != nil {
+		return err
+	}
+	person.Name, person.Position, person.Company, person.Country = member.Name, member.Position, member.Company, member.Country
+	return nil
+}
+
+func findScopedPerson(c *gin.Context) (*models.Person, services.Tenant, bool) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return nil, tenant, false
+	}
+
+	var person models.Person
+	err := peopleScope(config.DB.Model(&models.Person{}), tenant).
+		Where("people.id = ?", c.Param("id")).
+		First(&person).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Person not found", "data": nil})
+			return nil, tenant, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch person", "data": err.Error()})
+		return nil, tenant, false
+	}
+	return &person, tenant, true
+}
+
+// READ
+func GetPeople(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := peopleScope(config.DB.Model(&models.Person{}), tenant)
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		like := "%" + search + "%"
+		if tenant.All {
+			query = query.Where("people.email LIKE ? OR people.name LIKE ?", like, like)
+		} else {
+			query = query.Where("people.email LIKE ? OR people.id IN (?)", like,
+				config.DB.Model(&models.Member{}).Select("person_id").
+					Where("person_id IS NOT NULL AND name LIKE ? AND group_id IN (?)", like, tenantGroupIDs(tenant)))
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to count people", "data": err.Error()})
+		return
+	}
+
+	if tenant.All {
+		query = query.Select("people.*, " +
+			"(SELECT COUNT(*) FROM members WHERE members.person_id = people.id) AS group_count, " +
+			"(SELECT COUNT(*) FROM recipients WHERE recipients.person_id = people.id) AS campaign_count")
+	} else {
+		// Profil dan jumlah group/campaign hanya dihitung dari data milik organisasi tenant
+		query = query.Select("people.id, people.email, people.created_at, people.updated_at, "+
+			"COALESCE((?), '') AS name, COALESCE((?), '') AS position, COALESCE((?), '') AS company, COALESCE((?), '') AS country, "+
+			"(?) AS group_count, (?) AS campaign_count",
+			tenantMemberColumn("name", tenant), tenantMemberColumn("position", tenant),
+			tenantMemberColumn("company", tenant), tenantMemberColumn("country", tenant),
+			config.DB.Model(&models.Member{}).Select("COUNT(*)").
+				Where("members.person_id = people.id AND members.group_id IN (?)", tenantGroupIDs(tenant)),
+			config.DB.Table("recipients").Select("COUNT(*)").
+				Joins("JOIN campaigns ON campaigns.id = recipients.campaign_id").
+				Where("recipients.person_id = people.id AND campaigns.organization_id = ?", tenant.OrganizationID))
+	}
+
+	var people []models.PersonListItem
+	err := query.
+		Order("people.email").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&people).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch people", "data": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "People retrieved successfully",
+		"data":    people,
+		"total":   total,
+	})
+}
+
+// DETAIL
+func GetPersonDetail(c *gin.Context) {
+	person, tenant, ok := findScopedPerson(c)
+	if !ok {
+		return
+	}
+
+	if err := applyTenantProfile(person, tenant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch person profile", "data": err.Error()})
+		return
+	}
+
+	// 1. Keanggotaan group
+	memberships := []models.PersonMembership{}
+	if err := config.DB.Table("members").
+		Select("members.id AS member_id, members.group_id, groups.name AS group_name, members.name, members.position").
+		Joins("JOIN `groups` ON `groups`.id = members.group_id").
+		Where("members.person_id = ?", person.ID).
+		Where("members.group_id IN (?)", tenantGroupIDs(tenant)).
+		Order("members.group_id").
+		Scan(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch memberships", "data": err.Error()})
+		return
+	}
+
+	// 2. Riwayat campaign dan interaksi
+	history, err := services.PersonCampaignHistory(config.DB, person.ID, tenant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch campaign history", "data": err.Error()})
+		return
+	}
+
+	// 3. Training yang sudah diselesaikan, dicatat oleh organisasi ini (user maupun service account)
+	trainings := []models.TrainingCompletion{}
+	trainingQuery := config.DB.Scopes(tenant.Scope).Where("person_id = ?", person.ID)
+	if err := trainingQuery.Order("completed_at DESC").Find(&trainings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch training completions", "data": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Person retrieved successfully",
+		"data": models.PersonDetailResponse{
+			Person:      *person,
+			Memberships: memberships,
+			Campaigns:   history,
+			Trainings:   trainings,
+			RiskScore:   services.CalculateRiskScore(history, trainings, time.Now()),
+		},
+	})
+}
+
+// CREATE TRAINING COMPLETION
+func CreateTrainingCompletion(c *gin.Context) {
+	person, tenant, ok := findScopedPerson(c)
+	if !ok {
+		return
+	}
+	userID, _, _ := services.GetRoleScope(c)
+
+	var input models.CreateTrainingCompletionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNamePeople, c.Param("id"), nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Validation failed", "data": err.Error()})
+		return
+	}
+
+	completedAt := time.Now()
+	if input.CompletedAt != nil {
+		if input.CompletedAt.After(completedAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "completedAt must not be in the future", "data": nil})
+			return
+		}
+		completedAt = *input.CompletedAt
+	}
+
+	completion := models.TrainingCompletion{
+		OrganizationID:   tenant.OrganizationID,
+		PersonID:         person.ID,
+		Module:           strings.TrimSpace(input.Module),
+		CampaignID:       input.CampaignID,
+		Score:            input.Score,
+		CompletedAt:      completedAt,
+		CreatedBy:        userID,
+		ServiceAccountID: services.ActingServiceAccountID(c),
+		CreatedAt:        time.Now(),
+	}
+	if err := config.DB.Create(&completion).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNamePeople, c.Param("id"), nil, input, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to record training completion", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNamePeople, strconv.Itoa(int(completion.ID)), nil, completion, "success", "Training completion recorded")
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Training completion recorded successfully", "data": completion})
+}
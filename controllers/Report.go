@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportPhish menerima laporan dari add-in "Report Phishing" di mail client.
+// Jika pesan cocok dengan simulasi Awarenix, event "reported" dicatat untuk recipient tersebut.
+// Jika tidak, pesan diteruskan ke SOC webhook.
+func ReportPhish(c *gin.Context) {
+	var input models.PhishReportRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid request body: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if input.Headers == "" && input.MessageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Either headers or message_id is required",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 1. Ambil Message-ID dari input
+	headers := services.ParseReportedHeaders(input.Headers)
+	messageID := services.ReportedMessageID(input, headers)
+
+	// 2. Cocokkan dengan recipient simulasi
+	rec, err := services.FindRecipientByMessageID(config.DB, messageID)
+	if err == nil {
+		if err := services.RecordEvent(c, *rec, models.Reported); err != nil {
+			log.Printf("Failed to record reported event for recipient %d: %v", rec.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to record report",
+				"data":    nil,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "This was a simulation, well done",
+			"data": gin.H{
+				"simulation":    true,
+				"campaign_id":   rec.CampaignID,
+				"campaign_name": services.ReportedCampaignName(rec),
+			},
+		})
+		return
+	}
+	if err != services.ErrReportNotSimulation {
+		log.Printf("Failed to match reported message %s: %v", messageID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to process report",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 3. Bukan simulasi: teruskan ke SOC
+	forwarded, err := services.ForwardReportToSOC(models.SOCReportPayload{
+		Source:        "awarenix-report-addin",
+		ReporterEmail: input.ReporterEmail,
+		MessageID:     messageID,
+		Subject:       headers.Get("Subject"),
+		From:          headers.Get("From"),
+		Headers:       input.Headers,
+		RawMessage:    input.RawMessage,
+		ReportedAt:    time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Printf("Failed to forward report %s to SOC: %v", messageID, err)
+	}
+
+	message := "This was not a simulation"
+	if forwarded {
+		message = "This was not a simulation. The message has been forwarded to the security team"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message,
+		"data": gin.H{
+			"simulation": false,
+			"forwarded":  forwarded,
+		},
+	})
+}
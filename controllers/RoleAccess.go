@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const moduleNameRoleAccess = "Role Access"
+
+// loadEditableRole mengambil role dan memastikan aksesnya boleh diubah. Menulis response dan mengembalikan false jika tidak.
+func loadEditableRole(c *gin.Context, action string, roleID uint) (models.Role, bool) {
+	var role models.Role
+	if err := config.DB.First(&role, roleID).Error; err != nil {
+		services.LogActivity(config.DB, c, action, moduleNameRoleAccess, strconv.Itoa(int(roleID)), nil, nil, "failed", "Role not found")
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Role not found", "data": nil})
+		return role, false
+	}
+	if err := services.RoleAccessEditable(role); err != nil {
+		services.LogActivity(config.DB, c, action, moduleNameRoleAccess, strconv.Itoa(int(roleID)), nil, nil, "failed", err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": err.Error(), "data": nil})
+		return role, false
+	}
+	return role, true
+}
+
+// GET /role-menu-access/all?roleId=
+func GetRoleMenuAccess(c *gin.Context) {
+	query := config.DB.Model(&models.RoleMenuAccess{})
+	if roleID := c.Query("roleId"); roleID != "" {
+		query = query.Where("role_id = ?", roleID)
+	}
+	var accesses []models.RoleMenuAccess
+	if err := query.Order("role_id, menu_id").Find(&accesses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch role menu access", "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Role menu access retrieved successfully", "data": accesses})
+}
+
+// POST /role-menu-access/create
+func CreateRoleMenuAccess(c *gin.Context) {
+	var input models.RoleMenuAccessInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Grant Menu", moduleNameRoleAccess, "", nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	role, ok := loadEditableRole(c, "Grant Menu", input.RoleID)
+	if !ok {
+		return
+	}
+	var menu models.Menu
+	if err := config.DB.First(&menu, input.MenuID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Menu does not exist", "data": nil})
+		return
+	}
+	var count int64
+	config.DB.Model(&models.RoleMenuAccess{}).Where("role_id = ? AND menu_id = ?", role.ID, menu.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Role already has access to this menu", "data": nil})
+		return
+	}
+
+	now := time.Now()
+	access := models.RoleMenuAccess{RoleID: role.ID, MenuID: menu.ID, CreatedAt: now, UpdatedAt: now}
+	if err := config.DB.Omit("Role", "Menu").Create(&access).Error; err != nil {
+		services.LogActivity(config.DB, c, "Grant Menu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), nil, access, "failed", "Failed to grant menu access: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to grant menu access", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Grant Menu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), nil, access, "success",
+		"Menu "+menu.Name+" granted to role "+role.Name)
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Menu access granted successfully", "data": access})
+}
+
+// DELETE /role-menu-access/:id
+func DeleteRoleMenuAccess(c *gin.Context) {
+	idParam := c.Param("id")
+	var access models.RoleMenuAccess
+	if err := config.DB.First(&access, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Role menu access not found", "data": nil})
+		return
+	}
+	role, ok := loadEditableRole(c, "Revoke Menu", access.RoleID)
+	if !ok {
+		return
+	}
+	if err := config.DB.Delete(&access).Error; err != nil {
+		services.LogActivity(config.DB, c, "Revoke Menu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), access, nil, "failed", "Failed to revoke menu access: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to revoke menu access", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Revoke Menu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), access, nil, "success",
+		"Menu access revoked from role "+role.Name)
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Menu access revoked successfully", "data": nil})
+}
+
+// GET /role-submenu-access/all?roleId=
+func GetRoleSubmenuAccess(c *gin.Context) {
+	query := config.DB.Model(&models.RoleSubmenuAccess{})
+	if roleID := c.Query("roleId"); roleID != "" {
+		query = query.Where("role_id = ?", roleID)
+	}
+	var accesses []models.RoleSubmenuAccess
+	if err := query.Order("role_id, submenu_id").Find(&accesses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch role submenu access", "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Role submenu access retrieved successfully", "data": accesses})
+}
+
+// POST /role-submenu-access/create
+func CreateRoleSubmenuAccess(c *gin.Context) {
+	var input models.RoleSubmenuAccessInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Grant Submenu", moduleNameRoleAccess, "", nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	role, ok := loadEditableRole(c, "Grant Submenu", input.RoleID)
+	if !ok {
+		return
+	}
+	var submenu models.Submenu
+	if err := config.DB.First(&submenu, input.SubmenuID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Submenu does not exist", "data": nil})
+		return
+	}
+	var count int64
+	config.DB.Model(&models.RoleSubmenuAccess{}).Where("role_id = ? AND submenu_id = ?", role.ID, submenu.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Role already has access to this submenu", "data": nil})
+		return
+	}
+
+	now := time.Now()
+	access := models.RoleSubmenuAccess{RoleID: role.ID, SubmenuID: submenu.ID, CreatedAt: now, UpdatedAt: now}
+	if err := config.DB.Omit("Role", "Submenu").Create(&access).Error; err != nil {
+		services.LogActivity(config.DB, c, "Grant Submenu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), nil, access, "failed", "Failed to grant submenu access: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to grant submenu access", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Grant Submenu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), nil, access, "success",
+		"Submenu "+submenu.Name+" granted to role "+role.Name)
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Submenu access granted successfully", "data": access})
+}
+
+// DELETE /role-submenu-access/:id
+func DeleteRoleSubmenuAccess(c *gin.Context) {
+	idParam := c.Param("id")
+	var access models.RoleSubmenuAccess
+	if err := config.DB.First(&access, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Role submenu access not found", "data": nil})
+		return
+	}
+	role, ok := loadEditableRole(c, "Revoke Submenu", access.RoleID)
+	if !ok {
+		return
+	}
+	if err := config.DB.Delete(&access).Error; err != nil {
+		services.LogActivity(config.DB, c, "Revoke Submenu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), access, nil, "failed", "Failed to revoke submenu access: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to revoke submenu access", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Revoke Submenu", moduleNameRoleAccess, strconv.Itoa(int(role.ID)), access, nil, "success",
+		"Submenu access revoked from role "+role.Name)
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Submenu access revoked successfully", "data": nil})
+}
+
+// GET /user-roles/:id/access: seluruh akses menu, submenu dan izin satu role
+func GetRoleAccess(c *gin.Context) {
+	var role models.Role
+	if err := config.DB.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Role not found", "data": nil})
+		return
+	}
+	menuIDs, submenuIDs, err := services.RoleAccessIDs(config.DB, role.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to get role access: " + err.Error(), "data": nil})
+		return
+	}
+	permissions, err := services.RolePermissionKeys(config.DB, role.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to get role access: " + err.Error(), "data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Role access fetched successfully",
+		"data": models.RoleAccessResponse{
+			RoleID:      role.ID,
+			RoleName:    role.Name,
+			BuiltIn:     role.BuiltIn,
+			Editable:    services.RoleAccessEditable(role) == nil,
+			MenuIDs:     menuIDs,
+			SubmenuIDs:  submenuIDs,
+			Permissions: permissions,
+		},
+	})
+}
+
+// PUT /user-roles/:id/access: mengganti seluruh akses role sekaligus. Izin hanya diganti jika field permissions dikirim.
+func UpdateRoleAccess(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid role ID", "data": nil})
+		return
+	}
+	role, ok := loadEditableRole(c, "Update Access", uint(id))
+	if !ok {
+		return
+	}
+	var input models.RoleAccessInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update Access", moduleNameRoleAccess, idParam, nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+
+	oldMenus, oldSubmenus, _ := services.RoleAccessIDs(config.DB, role.ID)
+	oldValue := gin.H{"menuIds": oldMenus, "submenuIds": oldSubmenus}
+	if input.Permissions != nil {
+		oldValue["permissions"], _ = services.RolePermissionKeys(config.DB, role.ID)
+	}
+
+	change, err := services.SetRoleAccess(config.DB, role.ID, input.MenuIDs, input.SubmenuIDs, input.Permissions)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Update Access", moduleNameRoleAccess, idParam, oldValue, input, "failed", "Failed to update role access: "+err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrRoleAccessInvalid) || errors.Is(err, services.ErrUnknownPermission) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"status": "error", "message": "Failed to update role access: " + err.Error(), "data": nil})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update Access", moduleNameRoleAccess, idParam, oldValue, change, "success",
+		"Access of role "+role.Name+" updated")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Role access updated successfully", "data": change})
+}
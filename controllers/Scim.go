@@ -0,0 +1,793 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const moduleNameScim = "SCIM"
+
+// ---- Manajemen token (JWT) ----
+
+// CREATE TOKEN
+func CreateScimToken(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateScimTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid request body: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to generate token",
+			"data":    nil,
+		})
+		return
+	}
+	plain := "scim_" + base64.RawURLEncoding.EncodeToString(raw)
+	sum := sha256.Sum256([]byte(plain))
+
+	token := models.ScimToken{
+		OrganizationID: tenant.OrganizationID,
+		Name:           input.Name,
+		TokenHash:      hex.EncodeToString(sum[:]),
+		TokenPrefix:    plain[:12],
+		CreatedAt:      time.Now(),
+		CreatedBy:      userID,
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := config.DB.Create(&token).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameScim, "", nil, token, "failed", "Failed to create SCIM token: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to create SCIM token",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameScim, strconv.FormatUint(uint64(token.ID), 10), nil, token, "success", "SCIM token created successfully")
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "SCIM token created successfully. Copy the token now, it will not be shown again.",
+		"data": gin.H{
+			"token":   plain,
+			"details": token,
+		},
+	})
+}
+
+// READ TOKENS
+func GetScimTokens(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var tokens []models.ScimToken
+	if err := config.DB.Scopes(tenant.Scope).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch SCIM tokens",
+			"data":    err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "SCIM tokens retrieved successfully",
+		"data":    tokens,
+	})
+}
+
+// REVOKE TOKEN
+func RevokeScimToken(c *gin.Context) {
+	idParam := c.Param("id")
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var token models.ScimToken
+	if err := config.DB.Scopes(tenant.Scope).First(&token, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "SCIM token not found",
+			"data":    nil,
+		})
+		return
+	}
+	if token.RevokedAt == nil {
+		now := time.Now()
+		token.RevokedAt = &now
+		if err := config.DB.Model(&token).Update("revoked_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to revoke SCIM token",
+				"data":    err.Error(),
+			})
+			return
+		}
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameScim, idParam, nil, token, "success", "SCIM token revoked")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "SCIM token revoked successfully",
+		"data":    token,
+	})
+}
+
+// ---- Endpoint SCIM 2.0 (bearer token) ----
+
+func scimJSON(c *gin.Context, status int, obj interface{}) {
+	c.Header("Content-Type", "application/scim+json")
+	c.JSON(status, obj)
+}
+
+func scimFail(c *gin.Context, err error) {
+	var apiErr *services.ScimAPIError
+	switch {
+	case errors.As(err, &apiErr):
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apiErr = &services.ScimAPIError{Status: http.StatusNotFound, Detail: "Resource not found"}
+	default:
+		apiErr = &services.ScimAPIError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+	scimJSON(c, apiErr.Status, models.ScimError{
+		Schemas:  []string{models.ScimSchemaError},
+		Status:   strconv.Itoa(apiErr.Status),
+		ScimType: apiErr.ScimType,
+		Detail:   apiErr.Detail,
+	})
+}
+
+func scimTokenOwner(c *gin.Context) int {
+	if v, ok := c.Get("scimToken"); ok {
+		return v.(models.ScimToken).CreatedBy
+	}
+	return 0
+}
+
+// scimTokenOrganization adalah organisasi token SCIM; semua resource SCIM dibatasi ke organisasi ini.
+func scimTokenOrganization(c *gin.Context) uint {
+	if v, ok := c.Get("scimToken"); ok {
+		return v.(models.ScimToken).OrganizationID
+	}
+	return 0
+}
+
+func scimDecode(c *gin.Context, out interface{}) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 10<<20))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidSyntax", Detail: "Invalid JSON body: " + err.Error()}
+	}
+	return nil
+}
+
+// Atribut SCIM yang filter "eq"-nya dijalankan di database.
+var (
+	scimUserColumns  = map[string]string{"id": "id", "userName": "user_name", "externalId": "external_id"}
+	scimGroupColumns = map[string]string{"id": "id", "displayName": "name", "externalId": "external_id"}
+)
+
+// scimPageQuery menerapkan startIndex (berbasis 1) dan count sebagai OFFSET/LIMIT.
+func scimPageQuery(query *gorm.DB, startIndex, count int) *gorm.DB {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	return query.Offset(startIndex - 1).Limit(count)
+}
+
+// scimListParams membaca filter, startIndex dan count dari query string.
+func scimListParams(c *gin.Context) (services.ScimFilter, int, int, error) {
+	var filter services.ScimFilter
+	if raw := strings.TrimSpace(c.Query("filter")); raw != "" {
+		f, err := services.ParseScimFilter(raw)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		filter = f
+	}
+	startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil {
+		return nil, 0, 0, &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "startIndex must be a number"}
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "100"))
+	if err != nil {
+		return nil, 0, 0, &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "count must be a number"}
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > 200 {
+		count = 200
+	}
+	return filter, startIndex, count, nil
+}
+
+// SERVICE PROVIDER CONFIG
+func GetScimServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, gin.H{
+		"schemas":        []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": 200},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Authentication with a SCIM bearer token",
+			"primary":     true,
+		}},
+		"meta": gin.H{"resourceType": "ServiceProviderConfig", "location": services.ScimBaseURL + "/ServiceProviderConfig"},
+	})
+}
+
+// RESOURCE TYPES
+func GetScimResourceTypes(c *gin.Context) {
+	resourceTypes := []interface{}{
+		gin.H{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   models.ScimSchemaUser,
+			"schemaExtensions": []gin.H{
+				{"schema": models.ScimSchemaEnterpriseUser, "required": false},
+			},
+			"meta": gin.H{"resourceType": "ResourceType", "location": services.ScimBaseURL + "/ResourceTypes/User"},
+		},
+		gin.H{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   models.ScimSchemaGroup,
+			"meta":     gin.H{"resourceType": "ResourceType", "location": services.ScimBaseURL + "/ResourceTypes/Group"},
+		},
+	}
+	scimJSON(c, http.StatusOK, models.ScimListResponse{
+		Schemas:      []string{models.ScimSchemaListResponse},
+		TotalResults: len(resourceTypes),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	})
+}
+
+// ---- Users ----
+
+func findScimUser(db *gorm.DB, organizationID uint, id string) (*models.ScimUser, error) {
+	var user models.ScimUser
+	if err := db.Scopes(services.InOrganization(organizationID)).Preload("Groups").First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// saveScimUser memvalidasi resource, menyimpan user, lalu menyesuaikan Member di semua group-nya.
+func saveScimUser(tx *gorm.DB, user *models.ScimUser, res *models.ScimUserResource, organizationID uint, createdBy int) error {
+	res.UserName = strings.TrimSpace(res.UserName)
+	if res.UserName == "" {
+		return &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "userName is required"}
+	}
+	if len(res.UserName) > 255 || len(res.ExternalID) > 255 {
+		return &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "userName and externalId must be at most 255 characters"}
+	}
+	if _, err := services.ScimMemberValues(res); err != nil {
+		return err
+	}
+
+	var conflict int64
+	tx.Model(&models.ScimUser{}).Scopes(services.InOrganization(organizationID)).
+		Where("user_name = ? AND id <> ?", res.UserName, user.ID).Count(&conflict)
+	if conflict > 0 {
+		return &services.ScimAPIError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: "userName is already in use"}
+	}
+
+	user.UserName = res.UserName
+	user.ExternalID = res.ExternalID
+	if res.Active != nil {
+		user.Active = bool(*res.Active)
+	} else if user.ID == 0 {
+		user.Active = true
+	}
+
+	// Atribut yang dikelola server tidak ikut disimpan
+	res.ID, res.Meta, res.Groups, res.Active = "", nil, nil, nil
+	stored, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	user.Resource = string(stored)
+	user.UpdatedAt = time.Now()
+
+	if user.ID == 0 {
+		user.CreatedAt = user.UpdatedAt
+		user.OrganizationID = organizationID
+		user.Version = 1
+		if err := tx.Omit("Groups").Create(user).Error; err != nil {
+			return err
+		}
+	} else {
+		user.Version++
+		if err := tx.Omit("Groups").Save(user).Error; err != nil {
+			return err
+		}
+	}
+	return services.SyncScimUserMembers(tx, user, createdBy)
+}
+
+func respondScimUser(c *gin.Context, status int, id uint) {
+	user, err := findScimUser(config.DB, scimTokenOrganization(c), strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	res := services.ScimUserToResource(user)
+	if status == http.StatusCreated {
+		c.Header("Location", res.Meta.Location)
+	}
+	scimJSON(c, status, res)
+}
+
+// LIST USERS
+func ListScimUsers(c *gin.Context) {
+	filter, startIndex, count, err := scimListParams(c)
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+
+	sqlFilter, rest := services.ScimFilterSQL(filter, scimUserColumns)
+	query := config.DB.Model(&models.ScimUser{}).Scopes(services.InOrganization(scimTokenOrganization(c)), sqlFilter)
+
+	var total int64
+	if rest == nil {
+		if err := query.Count(&total).Error; err != nil {
+			scimFail(c, err)
+			return
+		}
+		query = scimPageQuery(query, startIndex, count)
+	}
+	var users []models.ScimUser
+	if rest != nil || count > 0 {
+		if err := query.Preload("Groups").Order("id").Find(&users).Error; err != nil {
+			scimFail(c, err)
+			return
+		}
+	}
+	resources := make([]interface{}, 0, len(users))
+	for i := range users {
+		resources = append(resources, services.ScimUserToResource(&users[i]))
+	}
+	if rest == nil {
+		scimJSON(c, http.StatusOK, services.ScimListPage(resources, total, startIndex))
+		return
+	}
+	scimJSON(c, http.StatusOK, services.ScimPage(resources, rest, startIndex, count))
+}
+
+// GET USER
+func GetScimUser(c *gin.Context) {
+	user, err := findScimUser(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	scimJSON(c, http.StatusOK, services.ScimUserToResource(user))
+}
+
+// CREATE USER
+func CreateScimUser(c *gin.Context) {
+	var res models.ScimUserResource
+	if err := scimDecode(c, &res); err != nil {
+		scimFail(c, err)
+		return
+	}
+
+	var user models.ScimUser
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return saveScimUser(tx, &user, &res, scimTokenOrganization(c), scimTokenOwner(c))
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameScim, "", nil, res, "failed", "SCIM user provisioning failed: "+err.Error())
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Create", moduleNameScim, strconv.FormatUint(uint64(user.ID), 10), nil, res, "success", "SCIM user provisioned")
+	respondScimUser(c, http.StatusCreated, user.ID)
+}
+
+// REPLACE USER
+func ReplaceScimUser(c *gin.Context) {
+	user, err := findScimUser(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	old := services.ScimUserToResource(user)
+
+	var res models.ScimUserResource
+	if err := scimDecode(c, &res); err != nil {
+		scimFail(c, err)
+		return
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return saveScimUser(tx, user, &res, scimTokenOrganization(c), scimTokenOwner(c))
+	})
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Update", moduleNameScim, c.Param("id"), old, res, "success", "SCIM user replaced")
+	respondScimUser(c, http.StatusOK, user.ID)
+}
+
+// PATCH USER
+func PatchScimUser(c *gin.Context) {
+	user, err := findScimUser(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	old := services.ScimUserToResource(user)
+
+	var patch models.ScimPatchRequest
+	if err := scimDecode(c, &patch); err != nil {
+		scimFail(c, err)
+		return
+	}
+	current := services.ScimResourceMap(old)
+	if err := services.ApplyScimPatch(current, patch.Operations); err != nil {
+		scimFail(c, err)
+		return
+	}
+
+	var res models.ScimUserResource
+	patched, _ := json.Marshal(current)
+	if err := json.Unmarshal(patched, &res); err != nil {
+		scimFail(c, &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "Patched resource is invalid: " + err.Error()})
+		return
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return saveScimUser(tx, user, &res, scimTokenOrganization(c), scimTokenOwner(c))
+	})
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Update", moduleNameScim, c.Param("id"), old, patch, "success", "SCIM user patched")
+	respondScimUser(c, http.StatusOK, user.ID)
+}
+
+// DELETE USER
+func DeleteScimUser(c *gin.Context) {
+	user, err := findScimUser(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scim_user_id = ?", user.ID).Delete(&models.Member{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(user).Association("Groups").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Delete", moduleNameScim, c.Param("id"), services.ScimUserToResource(user), nil, "success", "SCIM user deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// ---- Groups ----
+
+func findScimGroup(db *gorm.DB, organizationID uint, id string) (*models.Group, error) {
+	var group models.Group
+	if err := db.Scopes(services.InOrganization(organizationID)).Preload("ScimUsers").Where("scim_managed = ?", true).First(&group, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// saveScimGroup menyimpan group beserta keanggotaannya lalu menyesuaikan Member user yang berubah.
+func saveScimGroup(tx *gorm.DB, group *models.Group, res *models.ScimGroupResource, organizationID uint, createdBy int) error {
+	res.DisplayName = strings.TrimSpace(res.DisplayName)
+	if res.DisplayName == "" {
+		return &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "displayName is required"}
+	}
+	if len([]rune(res.DisplayName)) > 30 {
+		return &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "displayName must be at most 30 characters"}
+	}
+	var conflict int64
+	tx.Model(&models.Group{}).Scopes(services.InOrganization(organizationID)).
+		Where("scim_managed = ? AND name = ? AND id <> ?", true, res.DisplayName, group.ID).Count(&conflict)
+	if conflict > 0 {
+		return &services.ScimAPIError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: "displayName is already in use"}
+	}
+
+	memberIDs, err := services.ScimMemberIDs(tx, organizationID, res.Members)
+	if err != nil {
+		return err
+	}
+
+	affected := map[uint]bool{}
+	for _, u := range group.ScimUsers {
+		affected[u.ID] = true
+	}
+
+	now := time.Now()
+	group.Name = res.DisplayName
+	group.ExternalID = res.ExternalID
+	group.UpdatedAt = now
+	if group.ID == 0 {
+		group.DomainStatus = "scim"
+		group.ScimManaged = true
+		group.OrganizationID = organizationID
+		group.CreatedAt = now
+		group.CreatedBy = createdBy
+		if err := tx.Omit("Members", "ScimUsers").Create(group).Error; err != nil {
+			return err
+		}
+	} else if err := tx.Omit("Members", "ScimUsers").Save(group).Error; err != nil {
+		return err
+	}
+
+	var users []models.ScimUser
+	if len(memberIDs) > 0 {
+		if err := tx.Scopes(services.InOrganization(organizationID)).Where("id IN ?", memberIDs).Find(&users).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(group).Association("ScimUsers").Replace(users); err != nil {
+		return err
+	}
+	for _, u := range users {
+		affected[u.ID] = true
+	}
+
+	for id := range affected {
+		var user models.ScimUser
+		if err := tx.First(&user, id).Error; err != nil {
+			return err
+		}
+		if err := services.SyncScimUserMembers(tx, &user, createdBy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func respondScimGroup(c *gin.Context, status int, id uint) {
+	group, err := findScimGroup(config.DB, scimTokenOrganization(c), strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	res := services.ScimGroupToResource(group, group.ScimUsers, true)
+	if status == http.StatusCreated {
+		c.Header("Location", res.Meta.Location)
+	}
+	scimJSON(c, status, res)
+}
+
+// LIST GROUPS
+func ListScimGroups(c *gin.Context) {
+	filter, startIndex, count, err := scimListParams(c)
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	includeMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+
+	sqlFilter, rest := services.ScimFilterSQL(filter, scimGroupColumns)
+	query := config.DB.Model(&models.Group{}).Scopes(services.InOrganization(scimTokenOrganization(c)), sqlFilter).
+		Where("scim_managed = ?", true)
+
+	var total int64
+	if rest == nil {
+		if err := query.Count(&total).Error; err != nil {
+			scimFail(c, err)
+			return
+		}
+		query = scimPageQuery(query, startIndex, count)
+	}
+	// Member dimuat bila ditampilkan atau bila sisa filter (mis. members.value) perlu dievaluasi
+	if includeMembers || rest != nil {
+		query = query.Preload("ScimUsers")
+	}
+	var groups []models.Group
+	if rest != nil || count > 0 {
+		if err := query.Order("id").Find(&groups).Error; err != nil {
+			scimFail(c, err)
+			return
+		}
+	}
+	resources := make([]interface{}, 0, len(groups))
+	for i := range groups {
+		resources = append(resources, services.ScimGroupToResource(&groups[i], groups[i].ScimUsers, true))
+	}
+	var list models.ScimListResponse
+	if rest == nil {
+		list = services.ScimListPage(resources, total, startIndex)
+	} else {
+		list = services.ScimPage(resources, rest, startIndex, count)
+	}
+	if !includeMembers {
+		for i, r := range list.Resources {
+			g := r.(models.ScimGroupResource)
+			g.Members = nil
+			list.Resources[i] = g
+		}
+	}
+	scimJSON(c, http.StatusOK, list)
+}
+
+// GET GROUP
+func GetScimGroup(c *gin.Context) {
+	group, err := findScimGroup(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	includeMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	scimJSON(c, http.StatusOK, services.ScimGroupToResource(group, group.ScimUsers, includeMembers))
+}
+
+// CREATE GROUP
+func CreateScimGroup(c *gin.Context) {
+	var res models.ScimGroupResource
+	if err := scimDecode(c, &res); err != nil {
+		scimFail(c, err)
+		return
+	}
+
+	var group models.Group
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return saveScimGroup(tx, &group, &res, scimTokenOrganization(c), scimTokenOwner(c))
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameScim, "", nil, res, "failed", "SCIM group provisioning failed: "+err.Error())
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Create", moduleNameScim, strconv.FormatUint(uint64(group.ID), 10), nil, res, "success", "SCIM group provisioned")
+	respondScimGroup(c, http.StatusCreated, group.ID)
+}
+
+// REPLACE GROUP
+func ReplaceScimGroup(c *gin.Context) {
+	group, err := findScimGroup(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	old := services.ScimGroupToResource(group, group.ScimUsers, true)
+
+	var res models.ScimGroupResource
+	if err := scimDecode(c, &res); err != nil {
+		scimFail(c, err)
+		return
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return saveScimGroup(tx, group, &res, scimTokenOrganization(c), scimTokenOwner(c))
+	})
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Update", moduleNameScim, c.Param("id"), old, res, "success", "SCIM group replaced")
+	respondScimGroup(c, http.StatusOK, group.ID)
+}
+
+// PATCH GROUP
+func PatchScimGroup(c *gin.Context) {
+	group, err := findScimGroup(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	old := services.ScimGroupToResource(group, group.ScimUsers, true)
+
+	var patch models.ScimPatchRequest
+	if err := scimDecode(c, &patch); err != nil {
+		scimFail(c, err)
+		return
+	}
+	current := services.ScimResourceMap(old)
+	if err := services.ApplyScimPatch(current, patch.Operations); err != nil {
+		scimFail(c, err)
+		return
+	}
+
+	var res models.ScimGroupResource
+	patched, _ := json.Marshal(current)
+	if err := json.Unmarshal(patched, &res); err != nil {
+		scimFail(c, &services.ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "Patched resource is invalid: " + err.Error()})
+		return
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return saveScimGroup(tx, group, &res, scimTokenOrganization(c), scimTokenOwner(c))
+	})
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Update", moduleNameScim, c.Param("id"), old, patch, "success", "SCIM group patched")
+
+	// RFC 7644 3.5.2: 204 jika client tidak meminta atribut kembali
+	if c.Query("attributes") == "" && c.Query("excludedAttributes") == "" {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	respondScimGroup(c, http.StatusOK, group.ID)
+}
+
+// DELETE GROUP
+func DeleteScimGroup(c *gin.Context) {
+	group, err := findScimGroup(config.DB, scimTokenOrganization(c), c.Param("id"))
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Association("ScimUsers").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.Member{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Members", "ScimUsers").Delete(group).Error
+	})
+	if err != nil {
+		scimFail(c, err)
+		return
+	}
+	services.LogActivity(config.DB, c, "Delete", moduleNameScim, c.Param("id"), services.ScimGroupToResource(group, group.ScimUsers, true), nil, "success", "SCIM group deleted")
+	c.Status(http.StatusNoContent)
+}
@@ -14,11 +14,34 @@ import (
 
 const moduleNameSendingProfile = "Sending Profile"
 
+// sanitizeCreateSendingProfileInputForLog membuat salinan CreateSendingProfileRequest dan menghapus Password
+func sanitizeCreateSendingProfileInputForLog(input models.CreateSendingProfileRequest) models.CreateSendingProfileRequest {
+	input.Password = "[REDACTED]"
+	return input
+}
+
+// sanitizeUpdateSendingProfileInputForLog membuat salinan UpdateSendingProfileRequest dan menghapus Password
+func sanitizeUpdateSendingProfileInputForLog(input models.UpdateSendingProfileRequest) models.UpdateSendingProfileRequest {
+	input.Password = "[REDACTED]"
+	return input
+}
+
+// sanitizeTestEmailRequestForLog membuat salinan SendTestEmailRequest dan menghapus Password
+func sanitizeTestEmailRequestForLog(req models.SendTestEmailRequest) models.SendTestEmailRequest {
+	req.SendingProfile.Password = "[REDACTED]"
+	return req
+}
+
 // CREATE
 func RegisterSendingProfile(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var input models.CreateSendingProfileRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		services.LogActivity(config.DB, c, "Create", moduleNameSendingProfile, "", nil, input, "failed", "Invalid request body: "+err.Error())
+		services.LogActivity(config.DB, c, "Create", moduleNameSendingProfile, "", nil, sanitizeCreateSendingProfileInputForLog(input), "failed", "Invalid request body: "+err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
 			"message": "Invalid request body: " + err.Error(),
@@ -29,9 +52,9 @@ func RegisterSendingProfile(c *gin.Context) {
 
 	// CHECK DUPLICATE
 	var existingSendingProfiles models.SendingProfiles
-	checkDuplicate := config.DB.Where("name = ?", input.Name).First(&existingSendingProfiles)
+	checkDuplicate := config.DB.Where("name = ? AND organization_id = ?", input.Name, tenant.OrganizationID).First(&existingSendingProfiles)
 	if checkDuplicate.Error == nil {
-		services.LogActivity(config.DB, c, "Create", moduleNameSendingProfile, "", nil, input, "failed", "Sending profile with this name already exists.")
+		services.LogActivity(config.DB, c, "Create", moduleNameSendingProfile, "", nil, sanitizeCreateSendingProfileInputForLog(input), "failed", "Sending profile with this name already exists.")
 		c.JSON(http.StatusConflict, gin.H{
 			"status":  "error",
 			"message": "Sending profile with this name already exists",
@@ -40,20 +63,32 @@ func RegisterSendingProfile(c *gin.Context) {
 		return
 	}
 
+	encryptedPassword, err := services.EncryptSecret(input.Password)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameSendingProfile, "", nil, sanitizeCreateSendingProfileInputForLog(input), "failed", "Failed to encrypt SMTP password: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to encrypt SMTP password",
+			"data":    nil,
+		})
+		return
+	}
+
 	// port, _ := strconv.Atoi(input.Port)
 	sendingProfile := models.SendingProfiles{
-		Name:          input.Name,
-		InterfaceType: input.InterfaceType,
-		SmtpFrom:      input.SmtpFrom,
-		Host:          input.Host,
-		Port:          input.Port,
-		Username:      input.Username,
-		Password:      input.Password,
-		CreatedAt:     time.Now(),
-		CreatedBy:     input.CreatedBy,
-	}
-
-	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		OrganizationID: tenant.OrganizationID,
+		Name:           input.Name,
+		InterfaceType:  input.InterfaceType,
+		SmtpFrom:       input.SmtpFrom,
+		Host:           input.Host,
+		Port:           input.Port,
+		Username:       input.Username,
+		Password:       encryptedPassword,
+		CreatedAt:      time.Now(),
+		CreatedBy:      input.CreatedBy,
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
 		// Buat SendingProfile terlebih dahulu untuk mendapatkan ID-nya
 		if result := tx.Create(&sendingProfile); result.Error != nil {
 			return result.Error
@@ -100,27 +135,18 @@ func RegisterSendingProfile(c *gin.Context) {
 
 // READ
 func GetSendingProfiles(c *gin.Context) {
-	userIDScope, roleScope, errorStatus := services.GetRoleScope(c)
-	if !errorStatus {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
 		return
 	}
 
-	var query *gorm.DB
-	if roleScope == 1 {
-		query = config.DB.Table("sending_profiles").
-			Select(`sending_profiles.*, 
-				created_by_user.name AS created_by_name, 
-				updated_by_user.name AS updated_by_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = sending_profiles.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = sending_profiles.updated_by`)
-	} else {
-		query = config.DB.Table("sending_profiles").
-			Select(`sending_profiles.*, 
-				created_by_user.name AS created_by_name, 
-				updated_by_user.name AS updated_by_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = sending_profiles.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = sending_profiles.updated_by`).Where("sending_profiles.created_by = ?", userIDScope)
-	}
+	query := config.DB.Table("sending_profiles").
+		Select(`sending_profiles.*, 
+			created_by_user.name AS created_by_name, 
+			updated_by_user.name AS updated_by_name`).
+		Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = sending_profiles.created_by`).
+		Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = sending_profiles.updated_by`).
+		Scopes(tenant.Scope)
 
 	var total int64
 	query.Count(&total)
@@ -157,6 +183,10 @@ func GetEmailHeaderDetail(c *gin.Context) {
 		return
 	}
 
+	if !sendingProfileInTenant(c, uint(sendingProfileID)) {
+		return
+	}
+
 	var emailHeaders []models.EmailHeader
 	// Cari semua EmailHeader yang memiliki SendingProfileID yang cocok
 	if result := config.DB.Where("sending_profile_id = ?", sendingProfileID).Find(&emailHeaders); result.Error != nil {
@@ -197,7 +227,7 @@ func UpdateSendingProfile(c *gin.Context) {
 
 	var requestBody models.UpdateSendingProfileRequest
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
-		services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, idStr, nil, requestBody, "failed", "Invalid request payload: "+err.Error())
+		services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, idStr, nil, sanitizeUpdateSendingProfileInputForLog(requestBody), "failed", "Invalid request payload: "+err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
 			"message": err.Error(),
@@ -206,12 +236,17 @@ func UpdateSendingProfile(c *gin.Context) {
 		return
 	}
 
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var sendingProfile models.SendingProfiles
-	result := config.DB.First(&sendingProfile, idStr)
+	result := config.DB.Scopes(tenant.Scope).First(&sendingProfile, idStr)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, idStr, nil, requestBody, "failed", "Sending profile not found.")
+			services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, idStr, nil, sanitizeUpdateSendingProfileInputForLog(requestBody), "failed", "Sending profile not found.")
 			c.JSON(http.StatusNotFound, gin.H{
 				"status":  "error",
 				"message": "Sending profile not found",
@@ -219,7 +254,7 @@ func UpdateSendingProfile(c *gin.Context) {
 			})
 			return
 		}
-		services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, idStr, nil, requestBody, "failed", "Failed to retrieve sending profile: "+result.Error.Error())
+		services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, idStr, nil, sanitizeUpdateSendingProfileInputForLog(requestBody), "failed", "Failed to retrieve sending profile: "+result.Error.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "error",
 			"message": "Failed to retrieve sending profile",
@@ -242,7 +277,17 @@ func UpdateSendingProfile(c *gin.Context) {
 
 	// Logika update password: hanya update jika password baru diberikan
 	if requestBody.Password != "" {
-		updates["password"] = requestBody.Password
+		encryptedPassword, err := services.EncryptSecret(requestBody.Password)
+		if err != nil {
+			services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, idStr, oldSendingProfile, nil, "failed", "Failed to encrypt SMTP password: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to encrypt SMTP password",
+				"data":    nil,
+			})
+			return
+		}
+		updates["password"] = encryptedPassword
 	}
 
 	// Lakukan update di database
@@ -277,6 +322,10 @@ func UpdateEmailHeadersForProfile(c *gin.Context) {
 		return
 	}
 
+	if !sendingProfileInTenant(c, uint(profileID)) {
+		return
+	}
+
 	var newHeaders []models.EmailHeader
 	if err := c.ShouldBindJSON(&newHeaders); err != nil {
 		services.LogActivity(config.DB, c, "Update", moduleNameSendingProfile, profileIDStr, nil, newHeaders, "failed", "Invalid request payload: "+err.Error())
@@ -330,9 +379,14 @@ func DeleteSendingProfile(c *gin.Context) {
 		return
 	}
 
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var sendingProfileToDelete models.SendingProfiles
 	// Ambil data sending profile dan headers terkait sebelum dihapus untuk logging
-	if err := config.DB.Preload("EmailHeaders").First(&sendingProfileToDelete, sendingProfileID).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).Preload("EmailHeaders").First(&sendingProfileToDelete, sendingProfileID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Delete", moduleNameSendingProfile, sendingProfileIDStr, nil, nil, "failed", "Sending Profile not found for deletion.")
 			c.JSON(http.StatusNotFound, gin.H{
@@ -394,7 +448,7 @@ func SendTestEmail(c *gin.Context) {
 
 	// Log activity for initial request binding
 	if err := c.ShouldBindJSON(&req); err != nil {
-		services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, "", nil, req, "failed", "Invalid request body: "+err.Error())
+		services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, "", nil, sanitizeTestEmailRequestForLog(req), "failed", "Invalid request body: "+err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{
 			"status":  "error",
 			"message": err.Error(),
@@ -403,12 +457,18 @@ func SendTestEmail(c *gin.Context) {
 		return
 	}
 
-	// If password is not provided in request, retrieve it from existing profile
-	if req.SendingProfile.Password == "" {
-		result := config.DB.Where("id = ?", req.SendingProfile.ID).First(&existingSendingProfiles)
+	// If password is not provided in request, retrieve it from existing profile.
+	// Password hasil dekripsi hanya disimpan di variabel lokal agar tidak ikut tercatat di log aktivitas.
+	password := req.SendingProfile.Password
+	if password == "" {
+		tenant, ok := services.GetTenant(c)
+		if !ok {
+			return
+		}
+		result := config.DB.Scopes(tenant.Scope).Where("id = ?", req.SendingProfile.ID).First(&existingSendingProfiles)
 		if result.Error != nil {
 			logMessage := "Sending profile not found for test email: " + result.Error.Error()
-			services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, strconv.FormatUint(uint64(req.SendingProfile.ID), 10), nil, req, "failed", logMessage)
+			services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, strconv.FormatUint(uint64(req.SendingProfile.ID), 10), nil, sanitizeTestEmailRequestForLog(req), "failed", logMessage)
 			c.JSON(http.StatusNotFound, gin.H{
 				"status":  "error",
 				"message": "Sending profile not found",
@@ -416,7 +476,17 @@ func SendTestEmail(c *gin.Context) {
 			})
 			return
 		}
-		req.SendingProfile.Password = existingSendingProfiles.Password
+		decrypted, err := services.DecryptSecret(existingSendingProfiles.Password)
+		if err != nil {
+			services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, strconv.FormatUint(uint64(req.SendingProfile.ID), 10), nil, sanitizeTestEmailRequestForLog(req), "failed", "Failed to decrypt SMTP password: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to decrypt SMTP password",
+				"data":    nil,
+			})
+			return
+		}
+		password = decrypted
 	}
 
 	// port, _ := strconv.Atoi(req.SendingProfile.Port)
@@ -425,7 +495,7 @@ func SendTestEmail(c *gin.Context) {
 		InterfaceType: req.SendingProfile.InterfaceType,
 		SmtpFrom:      req.SendingProfile.SmtpFrom,
 		Username:      req.SendingProfile.Username,
-		Password:      req.SendingProfile.Password,
+		Password:      password,
 		Port:          req.SendingProfile.Port,
 		Host:          req.SendingProfile.Host,
 		EmailHeaders:  req.SendingProfile.EmailHeaders,
@@ -441,7 +511,7 @@ func SendTestEmail(c *gin.Context) {
 
 	if err != nil {
 		logMessage := "Failed to send test email: " + err.Error()
-		services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, strconv.FormatUint(uint64(req.SendingProfile.ID), 10), req, nil, "failed", logMessage)
+		services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, strconv.FormatUint(uint64(req.SendingProfile.ID), 10), sanitizeTestEmailRequestForLog(req), nil, "failed", logMessage)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status":  "error",
 			"message": logMessage,
@@ -451,10 +521,143 @@ func SendTestEmail(c *gin.Context) {
 	}
 
 	// Log activity for successful test email send
-	services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, strconv.FormatUint(uint64(req.SendingProfile.ID), 10), req, nil, "success", "Test email sent successfully to "+req.Recipient.Email)
+	services.LogActivity(config.DB, c, "Send Test Email", moduleNameSendingProfile, strconv.FormatUint(uint64(req.SendingProfile.ID), 10), sanitizeTestEmailRequestForLog(req), nil, "success", "Test email sent successfully to "+req.Recipient.Email)
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "Test email sent successfully!",
 		"data":    nil,
 	})
 }
+
+// DIAGNOSE
+func DiagnoseSendingProfile(c *gin.Context) {
+	sendingProfileIDStr := c.Param("id")
+
+	sendingProfileID, err := strconv.ParseUint(sendingProfileIDStr, 10, 32)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Diagnose", moduleNameSendingProfile, sendingProfileIDStr, nil, nil, "failed", "Invalid Sending Profile ID format: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Invalid Sending Profile ID format. ID must be a valid number.",
+			"data":    nil,
+		})
+		return
+	}
+
+	// Body bersifat opsional, hanya berisi recipient untuk uji RCPT TO
+	var req models.DiagnoseSendingProfileRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			services.LogActivity(config.DB, c, "Diagnose", moduleNameSendingProfile, sendingProfileIDStr, nil, req, "failed", "Invalid request body: "+err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var sendingProfile models.SendingProfiles
+	if err := config.DB.Scopes(tenant.Scope).First(&sendingProfile, sendingProfileID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			services.LogActivity(config.DB, c, "Diagnose", moduleNameSendingProfile, sendingProfileIDStr, nil, req, "failed", "Sending profile not found.")
+			c.JSON(http.StatusNotFound, gin.H{
+				"status":  "error",
+				"message": "Sending profile not found",
+				"data":    nil,
+			})
+			return
+		}
+		services.LogActivity(config.DB, c, "Diagnose", moduleNameSendingProfile, sendingProfileIDStr, nil, req, "failed", "Failed to retrieve sending profile: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to retrieve sending profile",
+			"data":    nil,
+		})
+		return
+	}
+
+	report := services.DiagnoseSendingProfile(&sendingProfile, req.Recipient)
+
+	message := "SMTP diagnostics completed successfully"
+	logStatus := "success"
+	if !report.Success {
+		message = "SMTP diagnostics found problems"
+		logStatus = "failed"
+		for _, step := range report.Steps {
+			if step.Status == "failed" {
+				message = "SMTP diagnostics failed at step " + step.Name + ": " + step.Error
+				break
+			}
+		}
+	}
+
+	services.LogActivity(config.DB, c, "Diagnose", moduleNameSendingProfile, sendingProfileIDStr, nil, req, logStatus, message)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": message,
+		"data":    report,
+	})
+}
+
+// REVEAL SECRET: tampilkan password SMTP tersimpan (izin sending-profile:read-secret), selalu dicatat
+func RevealSendingProfileSecret(c *gin.Context) {
+	idParam := c.Param("id")
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var sendingProfile models.SendingProfiles
+	if err := config.DB.Scopes(tenant.Scope).First(&sendingProfile, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Sending Profile not found",
+			"data":    nil,
+		})
+		return
+	}
+
+	password, err := services.DecryptSecret(sendingProfile.Password)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Read Secret", moduleNameSendingProfile, idParam, nil, nil, "failed", "Failed to decrypt SMTP password: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to decrypt SMTP password",
+			"data":    nil,
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Read Secret", moduleNameSendingProfile, idParam, nil, gin.H{"name": sendingProfile.Name}, "success", "SMTP password revealed")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "SMTP password retrieved successfully",
+		"data":    gin.H{"password": password},
+	})
+}
+
+// sendingProfileInTenant memastikan sending profile termasuk organisasi request; menulis 404 jika tidak.
+func sendingProfileInTenant(c *gin.Context, sendingProfileID uint) bool {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return false
+	}
+	var count int64
+	config.DB.Model(&models.SendingProfiles{}).Scopes(tenant.Scope).Where("id = ?", sendingProfileID).Count(&count)
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": "Sending profile not found",
+			"data":    nil,
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const moduleNameServiceAccount = "Service Account"
+
+// findTenantServiceAccount memuat service account dalam cakupan tenant request; menulis 404 jika tidak ditemukan.
+func findTenantServiceAccount(c *gin.Context, idParam string) (*models.ServiceAccount, bool) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return nil, false
+	}
+	var account models.ServiceAccount
+	if err := config.DB.Scopes(tenant.Scope).First(&account, idParam).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Service account not found", "data": nil})
+		return nil, false
+	}
+	return &account, true
+}
+
+// serviceAccountNameTaken memeriksa nama service account unik per organisasi.
+func serviceAccountNameTaken(organizationID uint, name string, exceptID uint) bool {
+	var count int64
+	config.DB.Model(&models.ServiceAccount{}).
+		Where("organization_id = ? AND name = ? AND id <> ?", organizationID, name, exceptID).
+		Count(&count)
+	return count > 0
+}
+
+// CREATE
+func RegisterServiceAccount(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var input models.ServiceAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, "", nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if serviceAccountNameTaken(tenant.OrganizationID, input.Name, 0) {
+		services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, "", nil, input, "failed", "Service account name already exists")
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Service account with this name already exists", "data": nil})
+		return
+	}
+
+	now := time.Now()
+	account := models.ServiceAccount{
+		OrganizationID: tenant.OrganizationID,
+		Name:           input.Name,
+		Description:    input.Description,
+		IsActive:       1,
+		CreatedAt:      now,
+		CreatedBy:      userID,
+		UpdatedAt:      now,
+		UpdatedBy:      userID,
+	}
+	if err := config.DB.Omit("ApiKeys").Create(&account).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, "", nil, account, "failed", "Failed to create service account: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to create service account", "data": err.Error()})
+		return
+	}
+	if input.IsActive == 0 {
+		config.DB.Model(&account).Update("is_active", 0)
+		account.IsActive = 0
+	}
+	account.ApiKeys = []models.ApiKey{}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, strconv.Itoa(int(account.ID)), nil, account, "success", "Service account created successfully")
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Service account created successfully", "data": account})
+}
+
+// READ: beserta API key (hanya prefix, scope, pemakaian terakhir dan status; hash tidak pernah dikirim)
+func GetServiceAccounts(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	var accounts []models.ServiceAccount
+	err := config.DB.Scopes(tenant.Scope).
+		Preload("ApiKeys", func(tx *gorm.DB) *gorm.DB { return tx.Order("created_at DESC") }).
+		Order("name").Find(&accounts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch service accounts", "data": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Service accounts retrieved successfully", "data": accounts, "total": len(accounts)})
+}
+
+// UPDATE: nonaktifkan service account untuk menolak semua key-nya sekaligus
+func UpdateServiceAccount(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	account, ok := findTenantServiceAccount(c, idParam)
+	if !ok {
+		return
+	}
+	oldAccount := *account
+
+	var input models.ServiceAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameServiceAccount, idParam, oldAccount, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if serviceAccountNameTaken(account.OrganizationID, input.Name, account.ID) {
+		services.LogActivity(config.DB, c, "Update", moduleNameServiceAccount, idParam, oldAccount, input, "failed", "Service account name already exists")
+		c.JSON(http.StatusConflict, gin.H{"status": "error", "message": "Service account with this name already exists", "data": nil})
+		return
+	}
+
+	account.Name = input.Name
+	account.Description = input.Description
+	account.IsActive = input.IsActive
+	account.UpdatedAt = time.Now()
+	account.UpdatedBy = userID
+	if err := config.DB.Omit("ApiKeys").Save(account).Error; err != nil {
+		services.LogActivity(config.DB, c, "Update", moduleNameServiceAccount, idParam, oldAccount, account, "failed", "Failed to update service account: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to update service account", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Update", moduleNameServiceAccount, idParam, oldAccount, account, "success", "Service account updated successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Service account updated successfully", "data": account})
+}
+
+// DELETE: menghapus service account beserta semua API key-nya; log aktivitas tetap menyimpan ID-nya
+func DeleteServiceAccount(c *gin.Context) {
+	idParam := c.Param("id")
+	account, ok := findTenantServiceAccount(c, idParam)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("service_account_id = ?", account.ID).Delete(&models.ApiKey{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(account).Error
+	})
+	if err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameServiceAccount, idParam, account, nil, "failed", "Failed to delete service account: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to delete service account", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameServiceAccount, idParam, account, nil, "success", "Service account deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Service account deleted successfully", "data": nil})
+}
+
+// CREATE API KEY: key asli hanya ditampilkan sekali; scope dibatasi izin milik pembuat
+func CreateApiKey(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, role, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	account, ok := findTenantServiceAccount(c, idParam)
+	if !ok {
+		return
+	}
+
+	var input models.CreateApiKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, idParam, nil, nil, "failed", "Invalid request body: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid request body: " + err.Error(), "data": nil})
+		return
+	}
+	scopes, err := services.NormalizeApiKeyScopes(config.DB, uint(role), input.Scopes)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrApiKeyScope) {
+			status = http.StatusBadRequest
+		}
+		services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, idParam, nil, input, "failed", "API key scope rejected: "+err.Error())
+		c.JSON(status, gin.H{"status": "error", "message": err.Error(), "data": nil})
+		return
+	}
+
+	plain, hash, prefix, err := services.GenerateApiKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to generate API key", "data": nil})
+		return
+	}
+	key := models.ApiKey{
+		ServiceAccountID: account.ID,
+		Name:             strings.TrimSpace(input.Name),
+		TokenHash:        hash,
+		TokenPrefix:      prefix,
+		Scopes:           scopes,
+		CreatedAt:        time.Now(),
+		CreatedBy:        userID,
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := config.DB.Create(&key).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, idParam, nil, key, "failed", "Failed to create API key: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to create API key", "data": err.Error()})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameServiceAccount, idParam, nil, key, "success", "API key "+key.TokenPrefix+" issued")
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "API key created successfully. Copy the key now, it will not be shown again.",
+		"data": gin.H{
+			"key":     plain,
+			"details": key,
+		},
+	})
+}
+
+// REVOKE API KEY
+func RevokeApiKey(c *gin.Context) {
+	idParam := c.Param("id")
+	account, ok := findTenantServiceAccount(c, idParam)
+	if !ok {
+		return
+	}
+	var key models.ApiKey
+	if err := config.DB.Where("service_account_id = ?", account.ID).First(&key, c.Param("keyId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "API key not found", "data": nil})
+		return
+	}
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		if err := config.DB.Model(&key).Update("revoked_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to revoke API key", "data": err.Error()})
+			return
+		}
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameServiceAccount, idParam, nil, key, "success", "API key "+key.TokenPrefix+" revoked")
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "API key revoked successfully", "data": key})
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Session hanya bisa dilihat dan dicabut oleh pemiliknya sendiri.
+const moduleNameSession = "Session"
+
+// currentSession mengambil user dan session dari context yang diisi JWTAuth.
+func currentSession(c *gin.Context) (*models.User, *models.UserSession, bool) {
+	userVal, userOK := c.Get("user")
+	sessionVal, sessionOK := c.Get("session")
+	if !userOK || !sessionOK {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "Unauthorized", "data": nil})
+		return nil, nil, false
+	}
+	return userVal.(*models.User), sessionVal.(*models.UserSession), true
+}
+
+// clearRefreshCookie menghapus cookie refresh token di browser.
+func clearRefreshCookie(c *gin.Context) {
+	c.SetCookie(services.RefreshTokenCookieName, "", -1, services.RefreshTokenCookiePath, "", false, true)
+}
+
+// READ: session aktif milik user yang sedang login
+func GetMySessions(c *gin.Context) {
+	user, current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	var sessions []models.UserSession
+	if err := config.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch sessions",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	responses := make([]models.UserSessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		responses = append(responses, models.UserSessionResponse{UserSession: s, Current: s.ID == current.ID})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Sessions retrieved successfully",
+		"data":    responses,
+		"total":   len(responses),
+	})
+}
+
+// REVOKE ONE
+func RevokeMySession(c *gin.Context) {
+	user, current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	var session models.UserSession
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Session not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch session", "data": err.Error()})
+		return
+	}
+
+	if err := services.RevokeSession(config.DB, session.ID, models.SessionRevokedByUser); err != nil {
+		services.LogActivity(config.DB, c, "Revoke", moduleNameSession, c.Param("id"), nil, nil, "error", "Failed to revoke session: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to revoke session",
+			"data":    err.Error(),
+		})
+		return
+	}
+	if session.ID == current.ID {
+		clearRefreshCookie(c)
+	}
+
+	services.LogActivity(config.DB, c, "Revoke", moduleNameSession, c.Param("id"), nil, nil, "success", "Session revoked")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Session revoked successfully",
+		"data":    nil,
+	})
+}
+
+// REVOKE ALL: semua session lain; ?includeCurrent=true ikut mencabut session saat ini
+func RevokeMySessions(c *gin.Context) {
+	user, current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	includeCurrent := c.Query("includeCurrent") == "true"
+	except := current.ID
+	if includeCurrent {
+		except = 0
+	}
+	revoked, err := services.RevokeUserSessions(config.DB, user.ID, models.SessionRevokedByUser, except)
+	if err != nil {
+		services.LogActivity(config.DB, c, "Revoke", moduleNameSession, "", nil, nil, "error", "Failed to revoke sessions: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to revoke sessions",
+			"data":    err.Error(),
+		})
+		return
+	}
+	if includeCurrent {
+		clearRefreshCookie(c)
+	}
+
+	services.LogActivity(config.DB, c, "Revoke", moduleNameSession, "", nil, nil, "success", "Revoked "+strconv.FormatInt(revoked, 10)+" session(s)")
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Sessions revoked successfully",
+		"data":    gin.H{"revoked": revoked},
+	})
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Domain target berlaku untuk semua campaign organisasi, jadi hanya admin yang boleh mendaftarkan dan memverifikasinya.
+const moduleNameTargetDomain = "Target Domain"
+
+func findTargetDomain(c *gin.Context) (*models.TargetDomain, bool) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return nil, false
+	}
+	var domain models.TargetDomain
+	if err := config.DB.Scopes(tenant.Scope).First(&domain, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Target domain not found", "data": nil})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch target domain", "data": err.Error()})
+		return nil, false
+	}
+	return &domain, true
+}
+
+// CREATE
+func RegisterTargetDomain(c *gin.Context) {
+	userID, _, ok := services.GetRoleScope(c)
+	if !ok {
+		return
+	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateTargetDomainInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameTargetDomain, "", nil, input, "error", "Validation failed: "+err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "Validation failed",
+			"data":    err.Error(),
+		})
+		return
+	}
+	name, err := services.NormalizeTargetDomain(input.Domain)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	var existing models.TargetDomain
+	if config.DB.Scopes(services.InOrganization(tenant.OrganizationID)).Where("domain = ?", name).First(&existing).Error == nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"status":  "error",
+			"message": "Target domain already registered",
+			"data":    services.TargetDomainResponse(existing),
+		})
+		return
+	}
+
+	token, err := services.NewDomainVerificationToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to generate verification token",
+			"data":    nil,
+		})
+		return
+	}
+
+	domain := models.TargetDomain{
+		OrganizationID:    tenant.OrganizationID,
+		Domain:            name,
+		VerificationToken: token,
+		Status:            models.DomainPending,
+		CreatedAt:         time.Now(),
+		CreatedBy:         userID,
+		UpdatedAt:         time.Now(),
+		UpdatedBy:         userID,
+	}
+	if err := config.DB.Create(&domain).Error; err != nil {
+		services.LogActivity(config.DB, c, "Create", moduleNameTargetDomain, "", nil, domain, "error", "Failed to create target domain: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to create target domain",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Create", moduleNameTargetDomain, strconv.FormatUint(uint64(domain.ID), 10), nil, domain, "success", "Target domain registered: "+domain.Domain)
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "Target domain registered. Create the TXT record, then verify the domain.",
+		"data":    services.TargetDomainResponse(domain),
+	})
+}
+
+// READ
+func GetTargetDomains(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+	query := config.DB.Model(&models.TargetDomain{}).Scopes(tenant.Scope)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var domains []models.TargetDomain
+	if err := query.Order("domain").Find(&domains).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to fetch target domains",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	responses := make([]models.TargetDomainResponse, 0, len(domains))
+	for _, d := range domains {
+		responses = append(responses, services.TargetDomainResponse(d))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Target domains retrieved successfully",
+		"data":    responses,
+		"total":   len(responses),
+	})
+}
+
+// VERIFY: cek TXT record sekarang
+func VerifyTargetDomain(c *gin.Context) {
+	domain, ok := findTargetDomain(c)
+	if !ok {
+		return
+	}
+
+	old := *domain
+	if err := services.VerifyTargetDomain(config.DB, domain); err != nil {
+		services.LogActivity(config.DB, c, "Verify", moduleNameTargetDomain, c.Param("id"), old, nil, "error", "Failed to save verification result: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to save verification result",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	if domain.Status != models.DomainVerified {
+		services.LogActivity(config.DB, c, "Verify", moduleNameTargetDomain, c.Param("id"), old, domain, "error", domain.LastError)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"status":  "error",
+			"message": "Domain verification failed: " + domain.LastError,
+			"data":    services.TargetDomainResponse(*domain),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Verify", moduleNameTargetDomain, c.Param("id"), old, domain, "success", "Target domain verified: "+domain.Domain)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Domain verified successfully",
+		"data":    services.TargetDomainResponse(*domain),
+	})
+}
+
+// DELETE
+func DeleteTargetDomain(c *gin.Context) {
+	domain, ok := findTargetDomain(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(domain).Error; err != nil {
+		services.LogActivity(config.DB, c, "Delete", moduleNameTargetDomain, c.Param("id"), domain, nil, "error", "Failed to delete target domain: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to delete target domain",
+			"data":    err.Error(),
+		})
+		return
+	}
+
+	services.LogActivity(config.DB, c, "Delete", moduleNameTargetDomain, c.Param("id"), domain, nil, "success", "Target domain deleted: "+domain.Domain)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Target domain deleted successfully",
+		"data":    nil,
+	})
+}
+
+// GROUP DOMAIN CHECK: domain anggota group yang belum terverifikasi tidak akan dikirimi email
+func GetGroupDomainCheck(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
+	var group models.Group
+	if err := config.DB.Scopes(tenant.Scope).First(&group, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"status": "error", "message": "Group not found", "data": nil})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to fetch group", "data": err.Error()})
+		return
+	}
+
+	members, err := services.ResolveGroupMembers(config.DB, &group)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to resolve group members", "data": err.Error()})
+		return
+	}
+	allowed, err := services.LoadDomainAllowList(config.DB, group.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to load verified domains", "data": err.Error()})
+		return
+	}
+
+	byDomain := map[string]*models.GroupDomainCheck{}
+	unverified := 0
+	for _, m := range members {
+		domain := services.EmailDomain(m.Email)
+		check, exists := byDomain[domain]
+		if !exists {
+			check = &models.GroupDomainCheck{Domain: domain, Verified: allowed.Allows(m.Email)}
+			byDomain[domain] = check
+		}
+		check.Members++
+		if !check.Verified {
+			unverified++
+		}
+	}
+	domains := make([]models.GroupDomainCheck, 0, len(byDomain))
+	for _, check := range byDomain {
+		domains = append(domains, *check)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Domain < domains[j].Domain })
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Group domain check completed",
+		"data": gin.H{
+			"domains":           domains,
+			"totalMembers":      len(members),
+			"unverifiedMembers": unverified,
+		},
+	})
+}
@@ -4,10 +4,8 @@ import (
 	"be-awarenix/config"
 	"be-awarenix/models"
 	"be-awarenix/services"
-	"fmt"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -59,23 +57,71 @@ func HandleReportTracker(c *gin.Context) {
 	services.LogEventByRID(c, rid, "reported", campaignLanguage)
 }
 
+// TrackAttachment mencatat attachment_opened dari beacon di dalam attachment.
+// Dengan go=1 (link di PDF) recipient diarahkan ke landing page, selain itu dikirim pixel.
 func TrackAttachment(c *gin.Context) {
-	// uid := c.Query("uid")
-	campaign, _ := strconv.Atoi(c.Query("campaign"))
-	filename := c.Query("file")
-
-	// Log event “attachment_clicked”
-	e := models.Event{
-		// UID:        uid,
-		CampaignID: uint(campaign),
-		Type:       "attachment_clicked",
-		Timestamp:  time.Now(),
-		// Metadata:   collectMetadata(c.Request),
+	rid := c.Query("rid")
+	attID, err := strconv.ParseUint(c.Query("att"), 10, 32)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	// 1. Cari Recipient dan Campaign
+	var rec models.Recipient
+	if err := config.DB.Where("uid = ?", rid).First(&rec).Error; err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	var camp models.Campaign
+	if err := config.DB.First(&camp, rec.CampaignID).Error; err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	// 2. Pastikan attachment milik template campaign
+	var att models.EmailAttachment
+	if err := config.DB.
+		Where("id = ? AND email_template_id = ?", attID, camp.EmailTemplateID).
+		First(&att).Error; err != nil {
+		c.Status(http.StatusNotFound)
+		return
 	}
-	config.DB.Create(&e)
 
-	// Kirim file setelah logging
-	c.File(fmt.Sprintf("assets/attachments/%s", filename))
+	// 3. Simpan event
+	services.RecordEvent(c, rec, models.AttachmentOpened)
+
+	// 4. Response
+	if c.Query("go") == "1" {
+		c.Redirect(http.StatusFound, services.LandingPageURL(rec.UID, camp.ID, camp.LandingPageID))
+		return
+	}
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.File("pixel.gif")
+}
+
+// HandleQRScan mencatat event scanned dari QR code lalu mengarahkan ke landing page.
+// Parameter src=qr diteruskan agar landing page tahu recipient datang dari QR.
+func HandleQRScan(c *gin.Context) {
+	rid := c.Param("rid")
+
+	// 1. Cari Recipient dan Campaign
+	var rec models.Recipient
+	if err := config.DB.Where("uid = ?", rid).First(&rec).Error; err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	var camp models.Campaign
+	if err := config.DB.First(&camp, rec.CampaignID).Error; err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	// 2. Simpan event
+	services.RecordEvent(c, rec, models.Scanned)
+
+	// 3. Redirect ke landing page
+	c.Redirect(http.StatusFound, services.LandingPageURL(rec.UID, camp.ID, camp.LandingPageID)+"&src=qr")
 }
 
 func GetLandingPageBody(c *gin.Context) {
@@ -10,7 +10,6 @@ import (
 	"be-awarenix/services"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +35,11 @@ func sanitizeUpdateUserInputForLog(input models.UpdateUserInput) models.UpdateUs
 
 // CREATE
 func RegisterUser(c *gin.Context) {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
+
 	var input models.CreateUserInput
 
 	// BIND & VALIDASI INPUT JSON
@@ -50,6 +54,10 @@ func RegisterUser(c *gin.Context) {
 	}
 
 	sanitizedInput := sanitizeCreateUserInputForLog(input)
+	if !roleAssignableInTenant(c, tenant, uint(input.Role)) {
+		services.LogActivity(config.DB, c, "Create", moduleNameUser, "", nil, sanitizedInput, "error", "Cross-tenant role requires cross-tenant access")
+		return
+	}
 
 	// MULAI TRANSAKSI
 	tx := config.DB.Begin()
@@ -97,29 +105,26 @@ func RegisterUser(c *gin.Context) {
 		return
 	}
 
-	// HASH PASSWORD
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	// VALIDASI KEBIJAKAN & HASH PASSWORD
+	hashedPassword, err := services.ValidateAndHashPassword(config.DB, input.Password, input.Email)
 	if err != nil {
 		tx.Rollback()
-		services.LogActivity(config.DB, c, "Create", moduleNameUser, "", nil, sanitizedInput, "error", "Password hashing failed: "+err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to process password",
-			"data":    "Password hashing failed",
-		})
+		services.LogActivity(config.DB, c, "Create", moduleNameUser, "", nil, sanitizedInput, "error", "Password rejected: "+err.Error())
+		respondPasswordError(c, err)
 		return
 	}
 
 	// BUAT USER BARU
 	newUser := models.User{
-		Name:         input.Name,
-		Email:        input.Email,
-		Position:     input.Position,
-		Role:         input.Role,
-		Company:      input.Company,
-		PasswordHash: string(hashedPassword),
-		CreatedAt:    time.Now(),
-		CreatedBy:    input.CreatedBy,
+		OrganizationID: tenant.OrganizationID,
+		Name:           input.Name,
+		Email:          input.Email,
+		Position:       input.Position,
+		Role:           input.Role,
+		Company:        input.Company,
+		PasswordHash:   hashedPassword,
+		CreatedAt:      time.Now(),
+		CreatedBy:      input.CreatedBy,
 	}
 
 	// SIMPAN KE DATABASE (menggunakan transaksi)
@@ -169,26 +174,17 @@ func RegisterUser(c *gin.Context) {
 
 // READ
 func GetUsers(c *gin.Context) {
-	userIDScope, roleScope, errorStatus := services.GetRoleScope(c)
-	if !errorStatus {
+	tenant, ok := services.GetTenant(c)
+	if !ok {
 		return
 	}
 
-	var query *gorm.DB
-	if roleScope == 1 {
-		query = config.DB.Table("users").
-			Select(`users.*, created_by_user.name AS created_by_name, updated_by_user.name AS updated_by_name, roles_user.name AS role_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = users.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = users.updated_by`).
-			Joins(`LEFT JOIN roles AS roles_user ON roles_user.id = users.role`)
-	} else {
-		query = config.DB.Table("users").
-			Select(`users.*, created_by_user.name AS created_by_name, updated_by_user.name AS updated_by_name, roles_user.name AS role_name`).
-			Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = users.created_by`).
-			Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = users.updated_by`).
-			Joins(`LEFT JOIN roles AS roles_user ON roles_user.id = users.role`).
-			Where(`users.created_by = ?`, userIDScope)
-	}
+	query := config.DB.Table("users").
+		Select(`users.*, created_by_user.name AS created_by_name, updated_by_user.name AS updated_by_name, roles_user.name AS role_name`).
+		Joins(`LEFT JOIN users AS created_by_user ON created_by_user.id = users.created_by`).
+		Joins(`LEFT JOIN users AS updated_by_user ON updated_by_user.id = users.updated_by`).
+		Joins(`LEFT JOIN roles AS roles_user ON roles_user.id = users.role`).
+		Scopes(tenant.Scope)
 
 	var total int64
 	query.Count(&total)
@@ -221,6 +217,10 @@ func UpdateUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid user ID format"})
 		return
 	}
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 
 	// Mulai transaksi
 	tx := config.DB.Begin()
@@ -246,7 +246,7 @@ func UpdateUser(c *gin.Context) {
 	var user models.User
 	// Ambil data user sebelum diupdate untuk oldValue
 	// Menggunakan userID (uint64) untuk query Find
-	if err := tx.First(&user, userID).Error; err != nil {
+	if err := tx.Scopes(tenant.Scope).First(&user, userID).Error; err != nil {
 		tx.Rollback() // Rollback karena user tidak ditemukan
 		services.LogActivity(config.DB, c, "Update", moduleNameUser, idParam, nil, nil, "error", "User not found for update: "+err.Error())
 		c.JSON(http.StatusNotFound, gin.H{
@@ -302,6 +302,12 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if user.Role != int(updatedData.Role) && !roleAssignableInTenant(c, tenant, uint(updatedData.Role)) {
+		tx.Rollback()
+		services.LogActivity(config.DB, c, "Update", moduleNameUser, idParam, nil, nil, "error", "Cross-tenant role requires cross-tenant access")
+		return
+	}
+
 	// Perbarui data user
 	user.Name = updatedData.Name
 	user.Email = updatedData.Email
@@ -312,33 +318,17 @@ func UpdateUser(c *gin.Context) {
 	user.UpdatedAt = time.Now()
 	user.UpdatedBy = updatedData.UpdatedBy
 
-	// Hash password jika diisi
+	// Validasi kebijakan dan hash password jika diisi
 	if updatedData.Password != "" {
-		if len(updatedData.Password) < 6 {
-			tx.Rollback()
-			logInput := sanitizeUpdateUserInputForLog(updatedData)
-			services.LogActivity(config.DB, c, "Update", moduleNameUser, idParam, oldUserValue, logInput, "error", "Password too short during update")
-			c.JSON(http.StatusBadRequest, gin.H{
-				"status":  "error",
-				"message": "Password must be at least 6 characters",
-				"data":    nil,
-			})
-			return
-		}
-
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updatedData.Password), bcrypt.DefaultCost)
+		hashedPassword, err := services.ValidateAndHashPassword(config.DB, updatedData.Password, user.Email)
 		if err != nil {
 			tx.Rollback()
 			logInput := sanitizeUpdateUserInputForLog(updatedData)
-			services.LogActivity(config.DB, c, "Update", moduleNameUser, idParam, oldUserValue, logInput, "error", "Password hashing failed during update: "+err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"status":  "error",
-				"message": "Password hashing failed",
-				"data":    err.Error(),
-			})
+			services.LogActivity(config.DB, c, "Update", moduleNameUser, idParam, oldUserValue, logInput, "error", "Password rejected during update: "+err.Error())
+			respondPasswordError(c, err)
 			return
 		}
-		user.PasswordHash = string(hashedPassword)
+		user.PasswordHash = hashedPassword
 	}
 
 	// Simpan perubahan ke database (menggunakan transaksi)
@@ -354,6 +344,31 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	// Ganti password atau nonaktifkan user langsung mematikan semua session-nya.
+	// Admin yang mengganti password sendiri tetap login di session saat ini.
+	revokeReason, exceptSession := "", uint(0)
+	if user.IsActive == 0 && oldUserValue.IsActive != 0 {
+		revokeReason = models.SessionRevokedInactive
+	} else if updatedData.Password != "" {
+		revokeReason = models.SessionRevokedPasswordChange
+		if current, ok := c.Get("session"); ok && current.(*models.UserSession).UserID == user.ID {
+			exceptSession = current.(*models.UserSession).ID
+		}
+	}
+	if revokeReason != "" {
+		if _, err := services.RevokeUserSessions(tx, user.ID, revokeReason, exceptSession); err != nil {
+			tx.Rollback()
+			logInput := sanitizeUpdateUserInputForLog(updatedData)
+			services.LogActivity(config.DB, c, "Update", moduleNameUser, idParam, oldUserValue, logInput, "error", "Failed to revoke user sessions: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status":  "error",
+				"message": "Failed to revoke user sessions",
+				"data":    err.Error(),
+			})
+			return
+		}
+	}
+
 	// COMMIT TRANSAKSI jika semua operasi berhasil
 	if err := tx.Commit().Error; err != nil {
 		logInput := sanitizeUpdateUserInputForLog(updatedData)
@@ -430,8 +445,12 @@ func DeleteUser(c *gin.Context) {
 	}
 
 	// Check if user to be deleted exists
+	tenant, ok := services.GetTenant(c)
+	if !ok {
+		return
+	}
 	var userToDelete models.User
-	if err := config.DB.First(&userToDelete, id).Error; err != nil {
+	if err := config.DB.Scopes(tenant.Scope).First(&userToDelete, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			services.LogActivity(config.DB, c, "Delete", moduleNameUser, userIDParam, nil, nil, "error", "User not found for deletion: "+err.Error())
 			c.JSON(http.StatusNotFound, gin.H{
@@ -484,6 +503,18 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
+	// Cabut session user yang dihapus agar refresh token-nya tidak bisa dipakai lagi
+	if _, err := services.RevokeUserSessions(tx, userToDelete.ID, models.SessionRevokedUserDeleted, 0); err != nil {
+		tx.Rollback()
+		services.LogActivity(config.DB, c, "Delete", moduleNameUser, userIDParam, oldUserToDeleteValue, nil, "error", "Failed to revoke user sessions: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "Failed to revoke user sessions",
+			"data":    err.Error(),
+		})
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		services.LogActivity(config.DB, c, "Delete", moduleNameUser, userIDParam, oldUserToDeleteValue, nil, "error", "Failed to commit delete transaction: "+err.Error())
@@ -511,3 +542,21 @@ func DeleteUser(c *gin.Context) {
 		},
 	})
 }
+
+// roleAssignableInTenant mencegah admin organisasi memberi role lintas tenant (mis. Super Admin)
+// kepada user; hanya tampilan lintas tenant yang boleh. Menulis 403 jika ditolak.
+func roleAssignableInTenant(c *gin.Context, tenant services.Tenant, roleID uint) bool {
+	if tenant.All {
+		return true
+	}
+	crossTenant, err := services.RoleHasPermission(config.DB, roleID, services.PermissionCrossTenant)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to check role permissions", "data": err.Error()})
+		return false
+	}
+	if crossTenant {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "message": "This role can only be assigned by a cross-tenant administrator", "data": nil})
+		return false
+	}
+	return true
+}
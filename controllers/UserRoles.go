@@ -198,6 +198,16 @@ func UpdateRole(c *gin.Context) {
 		return
 	}
 
+	// Role bawaan direferensikan lewat namanya (seeder, katalog izin), jadi tidak boleh diganti
+	if role.BuiltIn && updatedData.Name != role.Name {
+		services.LogActivity(config.DB, c, "Update", "Role", id, oldRoleValue, updatedData, "failed", services.ErrBuiltInRole.Error())
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  "error",
+			"message": services.ErrBuiltInRole.Error(),
+		})
+		return
+	}
+
 	// CEK APAKAH NAMA SUDAH DIPAKAI OLEH ROLE LAIN (menggunakan transaksi)
 	var existingRole models.Role
 	if err := tx.Where("name = ? AND id <> ?", updatedData.Name, id).First(&existingRole).Error; err == nil {
@@ -297,6 +307,29 @@ func DeleteRole(c *gin.Context) {
 		return
 	}
 
+	if roleToDelete.BuiltIn {
+		services.LogActivity(config.DB, c, "Delete", "Role", roleID, roleToDelete, nil, "failed", services.ErrBuiltInRole.Error())
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": services.ErrBuiltInRole.Error(),
+			"error":   "Built-in role",
+		})
+		return
+	}
+
+	// Role yang masih dipakai user tidak boleh dihapus
+	var userCount int64
+	config.DB.Model(&models.User{}).Where("role = ?", roleToDelete.ID).Count(&userCount)
+	if userCount > 0 {
+		services.LogActivity(config.DB, c, "Delete", "Role", roleID, roleToDelete, nil, "failed", "Role is still assigned to users")
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": "Role is still assigned to " + strconv.FormatInt(userCount, 10) + " user(s)",
+			"error":   "Role in use",
+		})
+		return
+	}
+
 	// Start database transaction for safe deletion
 	tx := config.DB.Begin()
 	if tx.Error != nil {
@@ -317,6 +350,19 @@ func DeleteRole(c *gin.Context) {
 		}
 	}()
 
+	// Hapus izin dan akses menu/submenu role sebelum role-nya
+	for _, access := range []interface{}{&models.RolePermission{}, &models.RoleMenuAccess{}, &models.RoleSubmenuAccess{}} {
+		if err := tx.Where("role_id = ?", roleToDelete.ID).Delete(access).Error; err != nil {
+			services.LogActivity(config.DB, c, "Delete", "Role", roleID, roleToDelete, nil, "failed", "Failed to delete role access: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Failed to delete role access",
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
 	// Hard Delete role (permanently remove from database)
 	if err := tx.Unscoped().Delete(&roleToDelete).Error; err != nil {
 		services.LogActivity(config.DB, c, "Delete", "Role", roleID, roleToDelete, nil, "failed", "Failed to delete role from DB: "+err.Error())
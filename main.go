@@ -10,6 +10,7 @@ import (
 	"be-awarenix/middlewares"
 	"be-awarenix/routes"
 	"be-awarenix/scheduler"
+	"be-awarenix/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -21,8 +22,7 @@ func main() {
 		log.Fatalf("Error loading .env: %v", err)
 	}
 
-	// Set timezone to Jakarta
-	// Jakarta is UTC+7
+	// Zona waktu aplikasi (default Asia/Jakarta); juga dipakai untuk member tanpa timezone/negara yang dikenali
 	timezone := os.Getenv("APP_TIMEZONE")
 	if timezone == "" {
 		timezone = "Asia/Jakarta"
@@ -36,6 +36,18 @@ func main() {
 	// Init DB
 	config.InitDatabase()
 	config.Migrations()
+
+	// Command: go run . rotate-secrets
+	// Enkripsi ulang semua secret tersimpan dengan SECRETS_ACTIVE_KEY_ID lalu keluar
+	if len(os.Args) > 1 && os.Args[1] == "rotate-secrets" {
+		count, err := services.RotateSecrets(config.DB)
+		if err != nil {
+			log.Fatalf("Failed to rotate secrets: %v", err)
+		}
+		log.Printf("Re-encrypted %d secret(s)", count)
+		return
+	}
+
 	config.RunSeeder()
 
 	// Setup Gin engine
@@ -55,5 +67,7 @@ func main() {
 
 	// SCHEDULER
 	scheduler.StartCampaignDispatcher()
+	scheduler.StartRecipientDispatcher()
+	scheduler.StartDirectorySync()
 	app.Run(fmt.Sprintf("0.0.0.0:%s", port))
 }
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportAPIKeyAuth memvalidasi API key untuk integrasi add-in report phishing.
+// Key dibaca dari header X-API-Key atau Authorization: Bearer <key> dan dibandingkan dengan REPORT_API_KEY.
+func ReportAPIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := os.Getenv("REPORT_API_KEY")
+		if expected == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"success": false, "error": "Report API key is not configured"})
+			return
+		}
+
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			auth := c.GetHeader("Authorization")
+			if strings.HasPrefix(auth, "Bearer ") {
+				key = strings.TrimPrefix(auth, "Bearer ")
+			}
+		}
+
+		if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(expected)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Missing or invalid API key"})
+			return
+		}
+
+		c.Next()
+	}
+}
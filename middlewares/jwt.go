@@ -3,6 +3,7 @@ package middlewares
 import (
 	"be-awarenix/config"
 	"be-awarenix/models"
+	"be-awarenix/services"
 	"net/http"
 	"os"
 	"strings"
@@ -21,6 +22,21 @@ func JWTAuth() gin.HandlerFunc {
 
 		tokenStr := strings.TrimPrefix(auth, "Bearer ")
 
+		// API key service account diterima di semua route yang sama dengan JWT; izinnya dibatasi scope key
+		if strings.HasPrefix(tokenStr, models.ApiKeyPrefix) {
+			key, account, err := services.AuthenticateApiKey(config.DB, tokenStr, c.ClientIP())
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "error": err.Error()})
+				return
+			}
+			c.Set("user", services.ServiceAccountPrincipal(account))
+			c.Set("apiKey", key)
+			c.Set("serviceAccount", account)
+			c.Set("roleID", uint(0))
+			c.Next()
+			return
+		}
+
 		// Parse token and extract claims
 		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
 			return []byte(os.Getenv("JWT_SECRET")), nil
@@ -50,9 +66,23 @@ func JWTAuth() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not found"})
 			return
 		}
+		if user.IsActive == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User is inactive"})
+			return
+		}
+
+		// Token harus terikat ke session yang belum dicabut (logout, ganti password, user dinonaktifkan)
+		sessionUID, _ := claims["jti"].(string)
+		session, err := services.ActiveSession(config.DB, sessionUID, user.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Session has been revoked or expired"})
+			return
+		}
 
-		// ⛳ Masukkan user ke context
+		// ⛳ Masukkan user, role dan session ke context
 		c.Set("user", &user)
+		c.Set("session", session)
+		c.Set("roleID", uint(user.Role))
 
 		c.Next()
 	}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission menolak request jika role user tidak memiliki izin key (mis. "campaign:create"),
+// atau untuk API key service account, jika key tidak punya scope tersebut. Dipasang per route setelah JWTAuth; key yang tidak ada di models.PermissionCatalog membuat panic saat startup.
+func RequirePermission(key string) gin.HandlerFunc {
+	if !models.IsKnownPermission(key) {
+		panic("unknown permission " + key)
+	}
+	return func(c *gin.Context) {
+		value, _ := c.Get("user")
+		user, ok := value.(*models.User)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "User not authenticated", "data": nil})
+			return
+		}
+
+		var allowed bool
+		var err error
+		if apiKey, isApiKey := c.Get("apiKey"); isApiKey {
+			allowed = apiKey.(*models.ApiKey).HasScope(key)
+		} else {
+			allowed, err = services.RoleHasPermission(config.DB, uint(user.Role), key)
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to check permission", "data": err.Error()})
+			return
+		}
+		if !allowed {
+			services.LogActivity(config.DB, c, "Access Denied", "Authorization", c.Param("id"), nil,
+				gin.H{"permission": key, "method": c.Request.Method, "path": c.FullPath()}, "failed", "Missing permission "+key)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"status":  "error",
+				"message": "Forbidden: you do not have permission to perform this action",
+				"data":    gin.H{"permission": key},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireUser menolak API key service account pada route self-service (profil, session, MFA),
+// yang hanya bermakna untuk user manusia.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isApiKey := c.Get("apiKey"); isApiKey {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"status":  "error",
+				"message": "Forbidden: this endpoint is not available to API keys",
+				"data":    nil,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScimTokenAuth memvalidasi bearer token SCIM yang dibuat lewat /api/v1/scim-tokens.
+func ScimTokenAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth := c.GetHeader("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			scimUnauthorized(c, "Missing or invalid Authorization header")
+			return
+		}
+
+		sum := sha256.Sum256([]byte(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))))
+		now := time.Now()
+
+		var token models.ScimToken
+		err := config.DB.
+			Where("token_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", hex.EncodeToString(sum[:]), now).
+			First(&token).Error
+		if err != nil {
+			scimUnauthorized(c, "Invalid or expired token")
+			return
+		}
+
+		config.DB.Model(&token).UpdateColumn("last_used_at", now)
+		c.Set("scimToken", token)
+		c.Next()
+	}
+}
+
+func scimUnauthorized(c *gin.Context, detail string) {
+	c.Header("Content-Type", "application/scim+json")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, models.ScimError{
+		Schemas: []string{models.ScimSchemaError},
+		Status:  "401",
+		Detail:  detail,
+	})
+}
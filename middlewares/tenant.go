@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"be-awarenix/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantContext menentukan organisasi request (lihat services.ResolveTenant) dan menyimpannya
+// di context sebagai "tenant". Dipasang setelah JWTAuth.
+func TenantContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("user")
+		user, ok := value.(*models.User)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "User not authenticated", "data": nil})
+			return
+		}
+
+		tenant, err := services.ResolveTenant(config.DB, user, c.GetHeader(services.OrganizationHeader))
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, services.ErrOrganizationForbidden), errors.Is(err, services.ErrOrganizationInactive):
+				status = http.StatusForbidden
+			case errors.Is(err, services.ErrOrganizationNotFound):
+				status = http.StatusNotFound
+			}
+			c.AbortWithStatusJSON(status, gin.H{"status": "error", "message": err.Error(), "data": nil})
+			return
+		}
+
+		c.Set("tenant", tenant)
+		c.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Frekuensi pengulangan blackout window
+const (
+	BlackoutDaily   = "daily"
+	BlackoutWeekly  = "weekly"
+	BlackoutMonthly = "monthly"
+	BlackoutYearly  = "yearly"
+)
+
+// Asal blackout window
+const (
+	BlackoutSourceManual = "manual"
+	BlackoutSourceICal   = "ical"
+)
+
+// BlackoutWindow adalah periode tingkat organisasi di mana tidak ada email simulasi yang dikirim
+// (hari raya, minggu payroll, penanganan insiden). Campaign yang jatuh tempo ditunda dan recipient
+// campaign yang sedang berjalan dijadwalkan ulang setelah blackout berakhir.
+// Jika Frequency diisi, rentang StartAt-EndAt berulang setiap Interval hari/minggu/bulan/tahun
+// sampai RepeatUntil (kosong = selamanya).
+type BlackoutWindow struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint       `gorm:"not null;default:0;index" json:"organizationId"`
+	Name           string     `gorm:"type:varchar(100);not null" json:"name"`
+	Reason         string     `gorm:"type:varchar(255);null" json:"reason"`
+	StartAt        time.Time  `gorm:"type:datetime;not null;index" json:"startAt"`
+	EndAt          time.Time  `gorm:"type:datetime;not null" json:"endAt"`
+	Frequency      string     `gorm:"type:varchar(10);null" json:"frequency,omitempty"`
+	Interval       int        `gorm:"default:1" json:"interval"`
+	RepeatUntil    *time.Time `gorm:"type:datetime;null" json:"repeatUntil,omitempty"`
+	Source         string     `gorm:"type:varchar(10);default:'manual'" json:"source"`
+	ExternalUID    string     `gorm:"type:varchar(255);null;index" json:"externalUid,omitempty"` // UID VEVENT untuk import ulang
+	CreatedAt      time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int        `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt      time.Time  `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy      int        `gorm:"type:tinyint(3);null" json:"updatedBy"`
+}
+
+type BlackoutWindowInput struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	Reason      string     `json:"reason" binding:"max=255"`
+	StartAt     time.Time  `json:"startAt" binding:"required"`
+	EndAt       time.Time  `json:"endAt" binding:"required"`
+	Frequency   string     `json:"frequency" binding:"omitempty,oneof=daily weekly monthly yearly"`
+	Interval    int        `json:"interval" binding:"omitempty,min=1,max=100"`
+	RepeatUntil *time.Time `json:"repeatUntil"`
+}
+
+// BlackoutOccurrence adalah satu kemunculan konkret dari sebuah BlackoutWindow.
+type BlackoutOccurrence struct {
+	WindowID uint      `json:"windowId"`
+	Name     string    `json:"name"`
+	Reason   string    `json:"reason,omitempty"`
+	StartAt  time.Time `json:"startAt"`
+	EndAt    time.Time `json:"endAt"`
+}
+
+type BlackoutWindowResponse struct {
+	BlackoutWindow
+	Active         bool                `json:"active"`
+	NextOccurrence *BlackoutOccurrence `json:"nextOccurrence,omitempty"` // kemunculan yang sedang berlangsung atau berikutnya
+}
+
+type BlackoutImportResult struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// CampaignWaiting menjelaskan mengapa campaign belum (selesai) mengirim email.
+type CampaignWaiting struct {
+	Reason              string              `json:"reason"`
+	Until               *time.Time          `json:"until,omitempty"`
+	Blackout            *BlackoutOccurrence `json:"blackout,omitempty"`
+	ScheduledRecipients int64               `json:"scheduledRecipients,omitempty"`
+}
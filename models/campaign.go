@@ -6,20 +6,23 @@ import (
 )
 
 type Campaign struct {
-	ID               uint       `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name             string     `gorm:"type:varchar(100);not null"   json:"name"`
-	LaunchDate       time.Time  `gorm:"type:datetime;not null"       json:"launchDate"`
-	SendEmailBy      *time.Time `gorm:"type:datetime"                json:"sendEmailBy,omitempty"`
-	GroupID          uint       `gorm:"not null;index"               json:"groupId"`
-	EmailTemplateID  uint       `gorm:"not null;index"               json:"emailTemplateId"`
-	LandingPageID    uint       `gorm:"not null;index"               json:"landingPageId"`
-	SendingProfileID uint       `gorm:"not null;index"               json:"sendingProfileId"`
-	URL              string     `gorm:"type:varchar(255);not null"   json:"url"`
-	Status           string     `gorm:"type:varchar(20);default:'pending'" json:"status"`
-	CreatedAt        time.Time  `gorm:"type:datetime;null" json:"createdAt"`
-	CreatedBy        int        `gorm:"type:tinyint(3);null" json:"createdBy"`
-	UpdatedAt        time.Time  `gorm:"type:datetime;null" json:"updatedAt"`
-	UpdatedBy        int        `gorm:"type:tinyint(3);null" json:"updatedBy"`
+	ID               uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID   uint           `gorm:"not null;default:0;index" json:"organizationId"`
+	Name             string         `gorm:"type:varchar(100);not null"   json:"name"`
+	LaunchDate       time.Time      `gorm:"type:datetime;not null"       json:"launchDate"`
+	SendEmailBy      *time.Time     `gorm:"type:datetime"                json:"sendEmailBy,omitempty"`
+	GroupID          uint           `gorm:"not null;index"               json:"groupId"`
+	EmailTemplateID  uint           `gorm:"not null;index"               json:"emailTemplateId"`
+	LandingPageID    uint           `gorm:"not null;index"               json:"landingPageId"`
+	SendingProfileID uint           `gorm:"not null;index"               json:"sendingProfileId"`
+	URL              string         `gorm:"type:varchar(255);not null"   json:"url"`
+	Status           string         `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	Delivery         DeliveryWindow `gorm:"embedded;embeddedPrefix:delivery_" json:"delivery"`
+	CreatedAt        time.Time      `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy        int            `gorm:"type:tinyint(3);null" json:"createdBy"`
+	ServiceAccountID *uint          `gorm:"index" json:"serviceAccountId,omitempty"` // terisi jika dibuat lewat API key
+	UpdatedAt        time.Time      `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy        int            `gorm:"type:tinyint(3);null" json:"updatedBy"`
 
 	// Relasi untuk preload
 	Group          Group           `gorm:"foreignKey:GroupID" json:"group"`
@@ -30,41 +33,44 @@ type Campaign struct {
 }
 
 type CampaignRequest struct {
-	Name             string  `json:"name" binding:"required"`
-	LaunchDate       string  `json:"launch_date" binding:"required"`
-	SendEmailBy      *string `json:"send_email_by,omitempty"`
-	GroupID          uint    `json:"group_id" binding:"required"`
-	EmailTemplateID  uint    `json:"email_template_id" binding:"required"`
-	LandingPageID    uint    `json:"landing_page_id" binding:"required"`
-	SendingProfileID uint    `json:"sending_profile_id" binding:"required"`
-	URL              string  `json:"url" binding:"required,url"`
-	CreatedBy        uint    `json:"created_by"`
-	UpdatedBy        uint    `json:"updated_by"`
+	Name             string          `json:"name" binding:"required"`
+	LaunchDate       string          `json:"launch_date" binding:"required"`
+	SendEmailBy      *string         `json:"send_email_by,omitempty"`
+	GroupID          uint            `json:"group_id" binding:"required"`
+	EmailTemplateID  uint            `json:"email_template_id" binding:"required"`
+	LandingPageID    uint            `json:"landing_page_id" binding:"required"`
+	SendingProfileID uint            `json:"sending_profile_id" binding:"required"`
+	URL              string          `json:"url" binding:"required,url"`
+	Delivery         *DeliveryWindow `json:"delivery,omitempty"`
+	CreatedBy        uint            `json:"created_by"`
+	UpdatedBy        uint            `json:"updated_by"`
 }
 
 type CampaignResponse struct {
-	ID                 int        `json:"id"`
-	UID                string     `json:"uid"`
-	Name               string     `json:"name"`
-	LaunchDate         time.Time  `json:"launch_date"`
-	SendEmailBy        *time.Time `json:"send_email_by,omitempty"`
-	GroupID            int        `json:"group_id"`
-	EmailTemplateID    int        `json:"email_template_id"`
-	LandingPageID      int        `json:"landing_page_id"`
-	SendingProfileID   int        `json:"sending_profile_id"`
-	URL                string     `json:"url"`
-	CreatedAt          time.Time  `json:"createdAt"`
-	CreatedBy          int        `json:"createdBy"`
-	CreatedByName      string     `json:"createdByName"`
-	UpdatedAt          time.Time  `json:"updatedAt"`
-	UpdatedBy          int        `json:"updatedBy"`
-	UpdatedByName      string     `json:"updatedByName"`
-	Status             string     `json:"status"`
-	GroupName          string     `json:"groupName"`
-	EmailTemplateName  string     `json:"emailTemplateName"`
-	LandingPageName    string     `json:"landingPageName"`
-	SendingProfileName string     `json:"sendingProfileName"`
-	CompletedDate      *time.Time `json:"completed_date,omitempty"`
+	ID                 int              `json:"id"`
+	UID                string           `json:"uid"`
+	Name               string           `json:"name"`
+	LaunchDate         time.Time        `json:"launch_date"`
+	SendEmailBy        *time.Time       `json:"send_email_by,omitempty"`
+	GroupID            int              `json:"group_id"`
+	EmailTemplateID    int              `json:"email_template_id"`
+	LandingPageID      int              `json:"landing_page_id"`
+	SendingProfileID   int              `json:"sending_profile_id"`
+	URL                string           `json:"url"`
+	CreatedAt          time.Time        `json:"createdAt"`
+	CreatedBy          int              `json:"createdBy"`
+	CreatedByName      string           `json:"createdByName"`
+	UpdatedAt          time.Time        `json:"updatedAt"`
+	UpdatedBy          int              `json:"updatedBy"`
+	UpdatedByName      string           `json:"updatedByName"`
+	Status             string           `json:"status"`
+	GroupName          string           `json:"groupName"`
+	EmailTemplateName  string           `json:"emailTemplateName"`
+	LandingPageName    string           `json:"landingPageName"`
+	SendingProfileName string           `json:"sendingProfileName"`
+	CompletedDate      *time.Time       `json:"completed_date,omitempty"`
+	Delivery           DeliveryWindow   `json:"delivery"`
+	Waiting            *CampaignWaiting `json:"waiting,omitempty"`
 	// Tambahan field untuk statistik kampanye
 	EmailSent      int `json:"email_sent"`
 	EmailOpened    int `json:"email_opened"`
@@ -72,19 +78,24 @@ type CampaignResponse struct {
 	EmailSubmitted int `json:"email_submitted"`
 	EmailReported  int `json:"email_reported"`
 
+	AttachmentOpened int `json:"email_attachment_opened"`
+	QRScanned        int `json:"email_qr_scanned"`
+
 	TotalParticipants int                 `json:"total_participants"`
 	Participants      []ParticipantDetail `json:"participants,omitempty"`
 	TimelineEvents    []TimelineEvent     `json:"timeline_events,omitempty"`
 }
 
 type ParticipantDetail struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Status   string `json:"status"`
-	Position string `json:"position"`
-	Browser  string `json:"browser"`
-	OS       string `json:"os"`
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Status      string     `json:"status"`
+	Position    string     `json:"position"`
+	Browser     string     `json:"browser"`
+	OS          string     `json:"os"`
+	Timezone    string     `json:"timezone,omitempty"`
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"` // slot pengiriman untuk recipient berstatus scheduled
 }
 
 type TimelineEvent struct {
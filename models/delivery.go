@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Mode pengiriman campaign
+const (
+	DeliveryImmediate     = "immediate"      // semua email dikirim saat campaign launch
+	DeliveryLocalTime     = "local_time"     // dikirim pada jam tertentu di zona waktu masing-masing recipient
+	DeliveryBusinessHours = "business_hours" // dikirim dalam jam kerja lokal recipient
+)
+
+// RecipientScheduled adalah status recipient yang menunggu slot pengirimannya (Recipient.ScheduledAt).
+const RecipientScheduled = "scheduled"
+
+// DeliveryWindow menentukan kapan email campaign sampai ke recipient, dihitung per zona waktu recipient.
+// Jam memakai format "HH:MM"; Days berisi hari kerja (1 = Senin ... 7 = Minggu) dipisah koma.
+type DeliveryWindow struct {
+	Mode         string `gorm:"type:varchar(20);default:'immediate'" json:"mode"`
+	LocalTime    string `gorm:"type:varchar(5);null" json:"localTime,omitempty"`
+	Start        string `gorm:"type:varchar(5);null" json:"start,omitempty"`
+	End          string `gorm:"type:varchar(5);null" json:"end,omitempty"`
+	Days         string `gorm:"type:varchar(20);null" json:"days,omitempty"`
+	SkipHolidays bool   `gorm:"default:false" json:"skipHolidays"`
+}
+
+// Holiday adalah hari libur pada kalender pengiriman. Country kosong berarti berlaku untuk semua recipient;
+// jika diisi, hanya recipient dengan negara tersebut (kode ISO atau nama, sama seperti Member.Country).
+type Holiday struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Date      string    `gorm:"type:char(10);not null;index" json:"date"` // YYYY-MM-DD
+	Country   string    `gorm:"type:varchar(50);null" json:"country"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	CreatedAt time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+}
+
+type CreateHolidayInput struct {
+	Date    string `json:"date" binding:"required,datetime=2006-01-02"`
+	Country string `json:"country" binding:"max=50"`
+	Name    string `json:"name" binding:"required,max=100"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// DirectoryProfile adalah profil koneksi LDAP / Active Directory untuk sinkronisasi group.
+type DirectoryProfile struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID     uint      `gorm:"not null;default:0;index" json:"organizationId"`
+	Name               string    `gorm:"type:varchar(50);not null" json:"name"`
+	URL                string    `gorm:"type:varchar(255);not null" json:"url"` // ldap://host:389 atau ldaps://host:636
+	StartTLS           bool      `gorm:"default:false" json:"startTls"`
+	InsecureSkipVerify bool      `gorm:"default:false" json:"insecureSkipVerify"`
+	BindDN             string    `gorm:"type:varchar(255);null" json:"bindDn"`
+	BindPassword       string    `gorm:"type:text;null" json:"-"` // terenkripsi, lihat services.EncryptSecret
+	BaseDN             string    `gorm:"type:varchar(255);not null" json:"baseDn"`
+	ChangeAttribute    string    `gorm:"type:varchar(50);default:modifyTimestamp" json:"changeAttribute"` // AD: whenChanged
+	CreatedAt          time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy          int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt          time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy          int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
+}
+
+type DirectoryProfileInput struct {
+	Name               string `json:"name" binding:"required,max=50"`
+	URL                string `json:"url" binding:"required,max=255"`
+	StartTLS           bool   `json:"startTls"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+	BindDN             string `json:"bindDn" binding:"max=255"`
+	BindPassword       string `json:"bindPassword"` // kosong saat update = tidak diubah
+	BaseDN             string `json:"baseDn" binding:"required,max=255"`
+	ChangeAttribute    string `json:"changeAttribute" binding:"max=50"`
+}
+
+// GroupDirectorySync adalah konfigurasi sinkronisasi directory untuk satu group.
+type GroupDirectorySync struct {
+	ID                 uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	GroupID            uint           `gorm:"not null;uniqueIndex" json:"groupId"`
+	DirectoryProfileID uint           `gorm:"not null;index" json:"directoryProfileId"`
+	BaseDN             string         `gorm:"type:varchar(255);null" json:"baseDn"` // kosong = BaseDN profil
+	Filter             string         `gorm:"type:varchar(1024);not null" json:"filter"`
+	AttributeMap       datatypes.JSON `gorm:"type:json" json:"attributeMap"` // field Member -> atribut LDAP
+	Enabled            bool           `gorm:"default:true" json:"enabled"`
+	IntervalMinutes    int            `gorm:"default:60" json:"intervalMinutes"`
+	LastSyncedAt       *time.Time     `gorm:"type:datetime;null" json:"lastSyncedAt"`
+	LastAttemptAt      *time.Time     `gorm:"type:datetime;null" json:"lastAttemptAt"`    // sync terakhir, berhasil maupun gagal
+	FailureCount       int            `gorm:"default:0" json:"failureCount"`              // kegagalan berturut-turut, dasar backoff
+	HighWaterMark      string         `gorm:"type:varchar(32);null" json:"highWaterMark"` // nilai ChangeAttribute terbaru yang sudah diproses
+	CreatedAt          time.Time      `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy          int            `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt          time.Time      `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy          int            `gorm:"type:tinyint(3);null" json:"updatedBy"`
+
+	DirectoryProfile DirectoryProfile `gorm:"foreignKey:DirectoryProfileID" json:"-"`
+}
+
+type GroupDirectorySyncInput struct {
+	DirectoryProfileID uint              `json:"directoryProfileId" binding:"required"`
+	BaseDN             string            `json:"baseDn" binding:"max=255"`
+	Filter             string            `json:"filter" binding:"required,max=1024"`
+	AttributeMap       map[string]string `json:"attributeMap"`
+	Enabled            bool              `json:"enabled"`
+	IntervalMinutes    int               `json:"intervalMinutes" binding:"omitempty,min=5"`
+}
+
+// DirectorySyncLog mencatat hasil satu kali sinkronisasi group.
+type DirectorySyncLog struct {
+	ID                 uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	GroupID            uint           `gorm:"not null;index" json:"groupId"`
+	DirectoryProfileID uint           `gorm:"not null" json:"directoryProfileId"`
+	Trigger            string         `gorm:"type:varchar(20);not null" json:"trigger"` // scheduled, manual
+	Incremental        bool           `json:"incremental"`
+	Status             string         `gorm:"type:varchar(20);not null" json:"status"` // success, failed
+	Added              int            `json:"added"`
+	Updated            int            `json:"updated"`
+	Removed            int            `json:"removed"`
+	Skipped            int            `json:"skipped"`
+	Error              string         `gorm:"type:text" json:"error,omitempty"`
+	Changes            datatypes.JSON `gorm:"type:json" json:"changes,omitempty"`
+	StartedAt          time.Time      `gorm:"type:datetime;not null" json:"startedAt"`
+	FinishedAt         time.Time      `gorm:"type:datetime;null" json:"finishedAt"`
+}
+
+// DirectorySyncChange adalah satu perubahan anggota dalam DirectorySyncLog.Changes.
+type DirectorySyncChange struct {
+	Action string `json:"action"` // added, updated, removed, skipped
+	DN     string `json:"dn,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Jenis attachment yang dapat dibangkitkan per recipient
+const (
+	AttachmentTypeHTML = "html"
+	AttachmentTypePDF  = "pdf"
+	AttachmentTypeDOCX = "docx"
+)
+
+// EmailAttachment adalah lure attachment milik email template.
+// File dibangkitkan ulang untuk setiap recipient dengan tracking token (rid) di dalamnya.
+type EmailAttachment struct {
+	ID              uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EmailTemplateID uint      `gorm:"not null;index" json:"emailTemplateId"`
+	Type            string    `gorm:"type:varchar(10);not null" json:"type" binding:"required,oneof=html pdf docx"`
+	FileName        string    `gorm:"type:varchar(100);not null" json:"fileName" binding:"required,max=100"`
+	Title           string    `gorm:"type:varchar(100);null" json:"title" binding:"max=100"`
+	Content         string    `gorm:"type:longtext;null" json:"content"`                        // HTML untuk tipe html, teks per paragraf untuk pdf/docx
+	LinkText        string    `gorm:"type:varchar(100);null" json:"linkText" binding:"max=100"` // teks link menuju landing page (pdf)
+	CreatedAt       time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy       int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt       time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy       int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
+}
@@ -4,6 +4,7 @@ import "time"
 
 type EmailTemplate struct {
 	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID   uint      `gorm:"not null;default:0;index" json:"organizationId"`
 	Name             string    `gorm:"type:varchar(30);not null" json:"name"`
 	Icon             string    `gorm:"type:varchar(30);null" json:"icon"`
 	EnvelopeSender   string    `gorm:"type:varchar(50);not null" json:"envelopeSender"`
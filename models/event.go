@@ -13,6 +13,9 @@ const (
 	Clicked   EventType = "clicked"
 	Submitted EventType = "submitted"
 	Reported  EventType = "reported"
+
+	AttachmentOpened EventType = "attachment_opened"
+	Scanned          EventType = "scanned"
 )
 
 type Event struct {
@@ -20,7 +23,7 @@ type Event struct {
 	RecipientID  uint           `gorm:"column:recipient_id;not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"recipientId"`
 	RecipientRID string         `gorm:"column:recipient_rid;type:char(36);not null;index"   json:"recipientRid"`
 	CampaignID   uint           `gorm:"column:campaign_id;not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"campaignId"`
-	Type         EventType      `gorm:"type:enum('opened','clicked','submitted', 'reported');not null;index" json:"type"`
+	Type         EventType      `gorm:"type:enum('opened','clicked','submitted','reported','attachment_opened','scanned');not null;index" json:"type"`
 	Timestamp    time.Time      `gorm:"type:datetime(3);not null;default:CURRENT_TIMESTAMP(3)"  json:"timestamp"`
 	IP           string         `gorm:"type:varchar(45);index"           json:"ip,omitempty"`
 	UserAgent    string         `gorm:"type:text"                        json:"userAgent,omitempty"`
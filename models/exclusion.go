@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipe entri exclusion list
+const (
+	ExclusionEmail   = "email"   // alamat email persis
+	ExclusionDomain  = "domain"  // domain beserta subdomain-nya
+	ExclusionPattern = "pattern" // wildcard * dan ? terhadap alamat email, mis. "ceo.*@example.com"
+)
+
+// RecipientExcluded adalah status recipient yang tidak dikirimi email karena exclusion list.
+const RecipientExcluded = "excluded"
+
+// Exclusion adalah entri exclusion list organisasi: orang yang cocok tidak pernah menerima simulasi
+// dari campaign organisasi tersebut. Entri yang dihapus disimpan (soft delete) beserta DeletedBy sebagai jejak audit.
+type Exclusion struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint           `gorm:"not null;default:0;index" json:"organizationId"`
+	Type           string         `gorm:"type:varchar(10);not null;index" json:"type"`
+	Value          string         `gorm:"type:varchar(255);not null" json:"value"`
+	Reason         string         `gorm:"type:varchar(255);not null" json:"reason"`
+	ExpiresAt      *time.Time     `gorm:"type:datetime;null;index" json:"expiresAt"` // kosong = berlaku selamanya
+	CreatedAt      time.Time      `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int            `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt      time.Time      `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy      int            `gorm:"type:tinyint(3);null" json:"updatedBy"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deletedAt,omitempty"`
+	DeletedBy      int            `gorm:"type:tinyint(3);null" json:"deletedBy,omitempty"`
+}
+
+type CreateExclusionInput struct {
+	Type      string     `json:"type" binding:"required,oneof=email domain pattern"`
+	Value     string     `json:"value" binding:"required,max=255"`
+	Reason    string     `json:"reason" binding:"required,max=255"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type UpdateExclusionInput struct {
+	Reason    string     `json:"reason" binding:"required,max=255"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type CheckExclusionInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ExclusionWithUserNames struct {
+	Exclusion
+	CreatedByName string `json:"createdByName"`
+	UpdatedByName string `json:"updatedByName"`
+	DeletedByName string `json:"deletedByName,omitempty"`
+	Active        bool   `json:"active"`
+}
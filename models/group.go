@@ -1,30 +1,51 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	GroupTypeStatic  = "static"  // anggota disimpan di tabel members
+	GroupTypeDynamic = "dynamic" // anggota dihitung dari Rule saat campaign diluncurkan
+)
 
 type Group struct {
-	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string    `gorm:"type:varchar(30);not null" json:"name"`
-	DomainStatus string    `gorm:"type:varchar(50);not null" json:"domainStatus"`
-	CreatedAt    time.Time `gorm:"type:datetime;null" json:"createdAt"`
-	CreatedBy    int       `gorm:"type:tinyint(3);null" json:"createdBy"`
-	UpdatedAt    time.Time `gorm:"type:datetime;null" json:"updatedAt"`
-	UpdatedBy    int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
-	Members      []Member  `gorm:"foreignKey:GroupID"`
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint           `gorm:"not null;default:0;index" json:"organizationId"`
+	Name           string         `gorm:"type:varchar(30);not null" json:"name"`
+	DomainStatus   string         `gorm:"type:varchar(50);not null" json:"domainStatus"`
+	Type           string         `gorm:"type:varchar(10);not null;default:'static'" json:"type"`
+	Rule           datatypes.JSON `gorm:"type:json" json:"rule,omitempty"` // GroupRule, hanya untuk group dynamic
+	ExternalID     string         `gorm:"type:varchar(255);null;index" json:"externalId,omitempty"`
+	ScimManaged    bool           `gorm:"default:false" json:"scimManaged"` // dibuat dan dikelola identity provider via SCIM
+	CreatedAt      time.Time      `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int            `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt      time.Time      `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy      int            `gorm:"type:tinyint(3);null" json:"updatedBy"`
+	Members        []Member       `gorm:"foreignKey:GroupID"`
+
+	ScimUsers []ScimUser `gorm:"many2many:scim_group_memberships" json:"-"`
 }
 
 type Member struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	GroupID   uint      `gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"groupId"`
-	Name      string    `gorm:"type:varchar(30);not null" json:"name"`
-	Email     string    `gorm:"type:varchar(50);not null" json:"email"`
-	Position  string    `gorm:"type:varchar(30);not null" json:"position"`
-	Company   string    `gorm:"type:varchar(50);null" json:"company"`
-	Country   string    `gorm:"type:varchar(50);null" json:"Country"`
-	CreatedAt time.Time `gorm:"type:datetime;null" json:"createdAt"`
-	CreatedBy int       `gorm:"type:tinyint(3);null" json:"createdBy"`
-	UpdatedAt time.Time `gorm:"type:datetime;null" json:"updatedAt"`
-	UpdatedBy int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
+	ID          uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	GroupID     uint           `gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"groupId"`
+	Name        string         `gorm:"type:varchar(30);not null" json:"name"`
+	Email       string         `gorm:"type:varchar(50);not null" json:"email"`
+	Position    string         `gorm:"type:varchar(30);not null" json:"position"`
+	Company     string         `gorm:"type:varchar(50);null" json:"company"`
+	Country     string         `gorm:"type:varchar(50);null" json:"Country"`
+	Timezone    string         `gorm:"type:varchar(64);null" json:"timezone,omitempty"`           // IANA, kosong = diturunkan dari Country
+	DirectoryDN string         `gorm:"type:varchar(255);null;index" json:"directoryDn,omitempty"` // terisi jika dikelola directory sync
+	ScimUserID  *uint          `gorm:"index" json:"scimUserId,omitempty"`                         // terisi jika dikelola SCIM
+	PersonID    *uint          `gorm:"index" json:"personId,omitempty"`
+	Attributes  datatypes.JSON `gorm:"type:json" json:"attributes,omitempty"` // custom attribute, lihat MemberAttribute
+	CreatedAt   time.Time      `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy   int            `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt   time.Time      `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy   int            `gorm:"type:tinyint(3);null" json:"updatedBy"`
 }
 
 type GroupMember struct {
@@ -41,35 +62,43 @@ type GroupMember struct {
 }
 
 type MemberInput struct {
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Position string `json:"position" binding:"required"`
-	Company  string `json:"company"`
-	Country  string `json:"country"`
+	Name       string                 `json:"name" binding:"required"`
+	Email      string                 `json:"email" binding:"required,email"`
+	Position   string                 `json:"position" binding:"required"`
+	Company    string                 `json:"company"`
+	Country    string                 `json:"country"`
+	Timezone   string                 `json:"timezone"`
+	Attributes map[string]interface{} `json:"attributes"`
 }
 
 type CreateGroupInput struct {
 	Name         string        `json:"groupName" binding:"required"`
 	DomainStatus string        `json:"domainStatus" binding:"required"`
 	Members      []MemberInput `json:"members" binding:"dive"`
+	Type         string        `json:"type" binding:"omitempty,oneof=static dynamic"`
+	Rule         *GroupRule    `json:"rule"`
 	CreatedBy    int           `gorm:"null" json:"createdBy"`
 }
 
 type MemberResponse struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Position  string    `json:"position"`
-	Company   string    `json:"company"`
-	Country   string    `json:"Country"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID         uint           `json:"id"`
+	Name       string         `json:"name"`
+	Email      string         `json:"email"`
+	Position   string         `json:"position"`
+	Company    string         `json:"company"`
+	Country    string         `json:"Country"`
+	Timezone   string         `json:"timezone,omitempty"`
+	Attributes datatypes.JSON `json:"attributes,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
 }
 
 type GroupResponse struct {
 	ID            uint             `json:"id"`
 	Name          string           `json:"name"`
 	DomainStatus  string           `json:"domainStatus"`
+	Type          string           `json:"type"`
+	Rule          datatypes.JSON   `json:"rule,omitempty"`
 	CreatedAt     time.Time        `json:"createdAt"`
 	UpdatedAt     time.Time        `json:"updatedAt"`
 	Members       []MemberResponse `json:"members"`
@@ -83,14 +112,18 @@ type UpdateGroupRequest struct {
 	DomainStatus string      `json:"domainStatus" binding:"required"`
 	UpdatedBy    uint        `json:"updatedBy"`
 	Members      []NewMember `json:"members" binding:"required"`
+	Type         string      `json:"type" binding:"omitempty,oneof=static dynamic"`
+	Rule         *GroupRule  `json:"rule"`
 }
 
 type NewMember struct {
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Position string `json:"position" binding:"required"`
-	Company  string `json:"company"`
-	Country  string `json:"country"`
+	Name       string                 `json:"name" binding:"required"`
+	Email      string                 `json:"email" binding:"required,email"`
+	Position   string                 `json:"position" binding:"required"`
+	Company    string                 `json:"company"`
+	Country    string                 `json:"country"`
+	Timezone   string                 `json:"timezone"`
+	Attributes map[string]interface{} `json:"attributes"`
 }
 
 type GroupWithUserNames struct {
@@ -98,3 +131,38 @@ type GroupWithUserNames struct {
 	CreatedByName string `json:"createdByName"`
 	UpdatedByName string `json:"updatedByName"`
 }
+
+// Import/export anggota group dari file CSV atau XLSX
+const (
+	MemberImportMerge   = "merge"   // update anggota dengan email sama, tambah yang baru
+	MemberImportReplace = "replace" // seperti merge, lalu hapus anggota yang tidak ada di file
+	MemberImportAppend  = "append"  // hanya tambah email baru, anggota lama tidak diubah
+)
+
+type MemberImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+type MemberImportRow struct {
+	Row    int       `json:"row"`
+	Member NewMember `json:"member"`
+	Action string    `json:"action,omitempty"` // create, update, skip
+}
+
+type MemberImportResult struct {
+	Mode        string                 `json:"mode"`
+	Preview     bool                   `json:"preview"`
+	Mapping     map[string]string      `json:"mapping"`
+	TotalRows   int                    `json:"totalRows"`
+	ValidRows   int                    `json:"validRows"`
+	InvalidRows int                    `json:"invalidRows"`
+	Created     int                    `json:"created"`
+	Updated     int                    `json:"updated"`
+	Deleted     int                    `json:"deleted"`
+	Skipped     int                    `json:"skipped"`
+	Rows        []MemberImportRow      `json:"rows,omitempty"`
+	Errors      []MemberImportRowError `json:"errors"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// GroupRule mendefinisikan anggota group dynamic.
+//
+//	{"match": "all", "conditions": [
+//	    {"field": "position", "operator": "contains", "value": "Finance"},
+//	    {"field": "country", "operator": "equals", "value": "ID"},
+//	    {"field": "event", "operator": "happened", "value": "clicked", "days": 90},
+//	    {"field": "attr.hire_date", "operator": "gte", "value": "2024-01-01"}
+//	]}
+//
+// Kandidat anggota adalah Member dari group static (dibatasi SourceGroupIDs jika diisi), unik per email.
+type GroupRule struct {
+	Match          string               `json:"match"` // all (AND) atau any (OR)
+	Conditions     []GroupRuleCondition `json:"conditions"`
+	SourceGroupIDs []uint               `json:"sourceGroupIds,omitempty"`
+}
+
+// GroupRuleCondition adalah satu kondisi, atau sub-kelompok kondisi jika Conditions diisi.
+type GroupRuleCondition struct {
+	Field    string   `json:"field,omitempty"` // name, email, position, company, country, event, attr.<key>
+	Operator string   `json:"operator,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Values   []string `json:"values,omitempty"` // untuk operator in / not_in
+	Days     int      `json:"days,omitempty"`   // field event: dalam N hari terakhir, 0 = kapan saja
+
+	Match      string               `json:"match,omitempty"`
+	Conditions []GroupRuleCondition `json:"conditions,omitempty"`
+}
+
+type GroupRulePreviewRequest struct {
+	Rule GroupRule `json:"rule" binding:"required"`
+}
+
+// CampaignGroupSnapshot menyimpan anggota group yang dipakai saat campaign diluncurkan (untuk audit).
+type CampaignGroupSnapshot struct {
+	ID          uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	CampaignID  uint           `gorm:"not null;uniqueIndex" json:"campaignId"`
+	GroupID     uint           `gorm:"not null;index" json:"groupId"`
+	GroupName   string         `gorm:"type:varchar(30)" json:"groupName"`
+	GroupType   string         `gorm:"type:varchar(10);not null" json:"groupType"`
+	Rule        datatypes.JSON `gorm:"type:json" json:"rule,omitempty"`
+	MemberCount int            `json:"memberCount"`
+	Members     datatypes.JSON `gorm:"type:json" json:"members"` // []SnapshotMember
+	ResolvedAt  time.Time      `gorm:"type:datetime;not null" json:"resolvedAt"`
+}
+
+type SnapshotMember struct {
+	MemberID   uint           `json:"memberId"`
+	GroupID    uint           `json:"groupId"`
+	PersonID   *uint          `json:"personId,omitempty"`
+	Name       string         `json:"name"`
+	Email      string         `json:"email"`
+	Position   string         `json:"position"`
+	Company    string         `json:"company"`
+	Country    string         `json:"country"`
+	Timezone   string         `json:"timezone,omitempty"`
+	Attributes datatypes.JSON `json:"attributes,omitempty"`
+}
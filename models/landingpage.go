@@ -4,6 +4,7 @@ import "time"
 
 type LandingPage struct {
 	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID   uint      `gorm:"not null;default:0;index" json:"organizationId"`
 	Name             string    `gorm:"type:varchar(30);not null" json:"name"`
 	Body             string    `gorm:"type=longtext;null" json:"body"`
 	IsSystemTemplate int       `gorm:"type:tinyint(1);default:0" json:"isSystemTemplate"`
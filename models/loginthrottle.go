@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Jenis kunci pelacakan percobaan login
+const (
+	LoginThrottleAccount = "account" // Key = email (lowercase)
+	LoginThrottleIP      = "ip"      // Key = alamat IP klien
+)
+
+// LoginThrottle menghitung percobaan login gagal per akun atau per IP. Failures direset setelah
+// login berhasil (akun) atau setelah tidak ada kegagalan selama jendela pelacakan.
+type LoginThrottle struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Kind          string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_login_throttle_key" json:"kind"`
+	Key           string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_login_throttle_key" json:"key"`
+	Failures      int        `gorm:"default:0" json:"failures"`
+	LockCount     int        `gorm:"default:0" json:"lockCount"` // berapa kali sudah terkunci, memperpanjang lockout berikutnya
+	LastFailureAt *time.Time `gorm:"type:datetime;null" json:"lastFailureAt"`
+	LockedUntil   *time.Time `gorm:"type:datetime;null" json:"lockedUntil"`
+	UpdatedAt     time.Time  `gorm:"type:datetime;null" json:"updatedAt"`
+}
+
+type LoginThrottleResponse struct {
+	LoginThrottle
+	Locked     bool       `json:"locked"`
+	RetryAfter *time.Time `json:"retryAfter"` // waktu paling awal percobaan berikutnya diterima
+}
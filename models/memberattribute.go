@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Tipe nilai custom attribute member
+const (
+	MemberAttributeString  = "string"
+	MemberAttributeNumber  = "number"
+	MemberAttributeDate    = "date" // disimpan sebagai YYYY-MM-DD
+	MemberAttributeBoolean = "boolean"
+)
+
+// MemberAttribute mendefinisikan custom attribute yang dapat diisi pada Member (mis. department, hire_date).
+// Nilainya disimpan di kolom JSON members.attributes dengan Key sebagai kunci. Definisi dimiliki per organisasi.
+type MemberAttribute struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint      `gorm:"not null;default:0;uniqueIndex:idx_member_attributes_org_key" json:"organizationId"`
+	Key            string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_member_attributes_org_key" json:"key"`
+	Label          string    `gorm:"type:varchar(100);not null" json:"label"`
+	Type           string    `gorm:"type:varchar(10);not null" json:"type"`
+	Description    string    `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt      time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt      time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy      int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
+}
+
+type CreateMemberAttributeInput struct {
+	Key         string `json:"key" binding:"required,max=50"`
+	Label       string `json:"label" binding:"required,max=100"`
+	Type        string `json:"type" binding:"required,oneof=string number date boolean"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+// UpdateMemberAttributeInput tidak mengizinkan perubahan Key dan Type agar nilai yang tersimpan tetap valid.
+type UpdateMemberAttributeInput struct {
+	Label       string `json:"label" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+// CampaignBreakdownRow adalah statistik campaign untuk satu nilai dimensi (mis. position = "Finance").
+type CampaignBreakdownRow struct {
+	Value            string `json:"value"`
+	Recipients       int64  `json:"recipients"`
+	Sent             int64  `json:"sent"`
+	Opened           int64  `json:"opened"`
+	Clicked          int64  `json:"clicked"`
+	Submitted        int64  `json:"submitted"`
+	Reported         int64  `json:"reported"`
+	AttachmentOpened int64  `json:"attachmentOpened"`
+	Scanned          int64  `json:"scanned"`
+}
@@ -12,3 +12,8 @@ type Menu struct {
 	UpdatedBy int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
 	Submenus  []Submenu `gorm:"foreignKey:MenuID" json:"submenus,omitempty"`
 }
+
+type MenuInput struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	IsActive int    `json:"isActive" binding:"oneof=0 1"`
+}
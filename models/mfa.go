@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// UserMfa menyimpan secret TOTP user. Secret dienkripsi (lihat services.EncryptSecret) dan baru
+// berlaku setelah Enabled, yaitu saat user memverifikasi kode pertama dari aplikasi authenticator.
+type UserMfa struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID   uint   `gorm:"not null;uniqueIndex" json:"userId"`
+	Secret   string `gorm:"type:varchar(512);not null" json:"-"`
+	Enabled  bool   `gorm:"default:false" json:"enabled"`
+	LastStep int64  `gorm:"default:0" json:"-"` // time step TOTP terakhir yang dipakai, mencegah kode dipakai ulang
+
+	EnabledAt *time.Time `gorm:"type:datetime;null" json:"enabledAt"`
+	CreatedAt time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	UpdatedAt time.Time  `gorm:"type:datetime;null" json:"updatedAt"`
+}
+
+// MfaRecoveryCode adalah kode cadangan sekali pakai. Hanya hash SHA-256 yang disimpan.
+type MfaRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"userId"`
+	CodeHash  string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `gorm:"type:datetime;null" json:"usedAt"`
+	CreatedAt time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+}
+
+// MfaChallenge adalah langkah kedua login: diterbitkan setelah password benar, ditukar dengan JWT
+// setelah kode TOTP atau recovery code valid. Enrollment menandai user yang wajib MFA tapi belum mendaftar.
+type MfaChallenge struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"userId"`
+	TokenHash     string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	LoginStatus   string     `gorm:"type:varchar(50);null" json:"-"` // status login asli (mis. KeepMeLoggedIn)
+	RefreshCookie bool       `gorm:"default:false" json:"-"`
+	Enrollment    bool       `gorm:"default:false" json:"enrollment"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	IPAddress     string     `gorm:"type:varchar(45);null" json:"ipAddress"`
+	ExpiresAt     time.Time  `gorm:"type:datetime;not null" json:"expiresAt"`
+	UsedAt        *time.Time `gorm:"type:datetime;null" json:"usedAt"`
+	CreatedAt     time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+}
+
+// MfaRolePolicy mewajibkan MFA untuk semua user dengan role tertentu.
+type MfaRolePolicy struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleID    uint      `gorm:"not null;uniqueIndex" json:"roleId"`
+	UpdatedAt time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy int       `gorm:"null" json:"updatedBy"`
+}
+
+type MfaCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MfaDisableInput menerima kode TOTP atau recovery code untuk membuktikan kepemilikan authenticator.
+type MfaDisableInput struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MfaChallengeInput struct {
+	MfaToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MfaPolicyInput struct {
+	RoleIDs []uint `json:"roleIds"`
+}
+
+// MfaEnrollment berisi data yang ditampilkan ke user untuk didaftarkan di aplikasi authenticator.
+type MfaEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+	QRCode          string `json:"qrCode"` // PNG base64 (data URI) dari ProvisioningURI
+}
+
+type MfaStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabledAt"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int64      `json:"recoveryCodesRemaining"`
+}
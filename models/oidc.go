@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// OidcProvider adalah konfigurasi identity provider OpenID Connect untuk login admin console.
+type OidcProvider struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name         string `gorm:"type:varchar(100);not null" json:"name"`
+	Issuer       string `gorm:"type:varchar(255);not null" json:"issuer"`
+	ClientID     string `gorm:"type:varchar(255);not null" json:"clientId"`
+	ClientSecret string `gorm:"type:text;null" json:"-"` // terenkripsi; kosong untuk public client (PKCE saja)
+	Scopes       string `gorm:"type:varchar(255);null" json:"scopes"`
+	// RoleClaim adalah path claim (boleh bertitik, mis. realm_access.roles) yang dicocokkan dengan OidcRoleMapping
+	RoleClaim     string `gorm:"type:varchar(100);null" json:"roleClaim"`
+	DefaultRoleID uint   `gorm:"default:0" json:"defaultRoleId"` // 0 = tolak login jika tidak ada mapping yang cocok
+	AutoProvision bool   `gorm:"default:true" json:"autoProvision"`
+	SyncRole      bool   `gorm:"default:true" json:"syncRole"` // perbarui role user dari claim setiap login
+	Enabled       bool   `gorm:"default:true" json:"enabled"`
+	// OrganizationID adalah organisasi user baru hasil AutoProvision; 0 = organisasi default
+	OrganizationID uint `gorm:"default:0" json:"organizationId"`
+
+	RoleMappings []OidcRoleMapping `gorm:"foreignKey:ProviderID" json:"roleMappings"`
+
+	CreatedAt time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy int       `gorm:"null" json:"createdBy"`
+	UpdatedAt time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy int       `gorm:"null" json:"updatedBy"`
+}
+
+// OidcRoleMapping memetakan satu nilai claim ke Role. Mapping dievaluasi berdasarkan Priority (kecil dulu).
+type OidcRoleMapping struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProviderID uint   `gorm:"not null;index" json:"providerId"`
+	ClaimValue string `gorm:"type:varchar(255);not null" json:"claimValue"`
+	RoleID     uint   `gorm:"not null" json:"roleId"`
+	Priority   int    `gorm:"default:0" json:"priority"`
+}
+
+// UserIdentity menautkan User dengan subject di identity provider.
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"userId"`
+	ProviderID  uint       `gorm:"not null;uniqueIndex:idx_user_identity_subject" json:"providerId"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identity_subject" json:"subject"`
+	Email       string     `gorm:"type:varchar(100);null" json:"email"`
+	LastLoginAt *time.Time `gorm:"type:datetime;null" json:"lastLoginAt"`
+	CreatedAt   time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+}
+
+// OidcLoginState menyimpan state, nonce dan PKCE verifier satu percobaan login, lalu kode serah-terima
+// sekali pakai yang ditukar frontend dengan JWT setelah callback berhasil.
+type OidcLoginState struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProviderID    uint       `gorm:"not null" json:"providerId"`
+	StateHash     string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	Nonce         string     `gorm:"type:varchar(64);not null" json:"-"`
+	CodeVerifier  string     `gorm:"type:varchar(128);not null" json:"-"`
+	ExpiresAt     time.Time  `gorm:"type:datetime;not null" json:"expiresAt"`
+	CallbackAt    *time.Time `gorm:"type:datetime;null" json:"callbackAt"`
+	UserID        uint       `gorm:"default:0" json:"userId"`
+	LoginCodeHash *string    `gorm:"type:char(64);null;uniqueIndex" json:"-"`
+	LoginCodeExp  *time.Time `gorm:"type:datetime;null" json:"-"`
+	UsedAt        *time.Time `gorm:"type:datetime;null" json:"usedAt"`
+	CreatedAt     time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+}
+
+// AuthSettings adalah pengaturan autentikasi organisasi (satu baris).
+type AuthSettings struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID     uint      `gorm:"not null;default:0;uniqueIndex" json:"organizationId"`
+	LocalLoginDisabled bool      `gorm:"default:false" json:"localLoginDisabled"`
+	UpdatedAt          time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy          int       `gorm:"null" json:"updatedBy"`
+}
+
+type OidcRoleMappingInput struct {
+	ClaimValue string `json:"claimValue" binding:"required"`
+	RoleID     uint   `json:"roleId" binding:"required"`
+	Priority   int    `json:"priority"`
+}
+
+type OidcProviderInput struct {
+	Name           string                 `json:"name" binding:"required"`
+	Issuer         string                 `json:"issuer" binding:"required,url"`
+	ClientID       string                 `json:"clientId" binding:"required"`
+	ClientSecret   *string                `json:"clientSecret"` // nil = tidak diubah saat update
+	Scopes         string                 `json:"scopes"`
+	RoleClaim      string                 `json:"roleClaim"`
+	DefaultRoleID  uint                   `json:"defaultRoleId"`
+	AutoProvision  bool                   `json:"autoProvision"`
+	SyncRole       bool                   `json:"syncRole"`
+	Enabled        bool                   `json:"enabled"`
+	OrganizationID uint                   `json:"organizationId"`
+	RoleMappings   []OidcRoleMappingInput `json:"roleMappings" binding:"dive"`
+}
+
+type AuthSettingsInput struct {
+	LocalLoginDisabled bool `json:"localLoginDisabled"`
+}
+
+type OidcExchangeInput struct {
+	Code          string `json:"code" binding:"required"`
+	Status        string `json:"status"` // "KeepMeLoggedIn" seperti login biasa
+	RefreshCookie bool   `json:"refresh_cookie"`
+}
+
+// OidcPublicProvider adalah data provider yang boleh dilihat halaman login (tanpa autentikasi).
+type OidcPublicProvider struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// OidcProviderResponse menyembunyikan client secret dan hanya menandai apakah sudah diisi.
+type OidcProviderResponse struct {
+	OidcProvider
+	HasClientSecret bool   `json:"hasClientSecret"`
+	RedirectURI     string `json:"redirectUri"` // didaftarkan di IdP
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultOrganizationSlug adalah organisasi bawaan. Super admin dan data lama tanpa pemilik masuk ke sini.
+const DefaultOrganizationSlug = "default"
+
+// Organization adalah tenant pemilik user, group, template, landing page, profile dan campaign.
+// Data tenant disaring lewat kolom organization_id (lihat services.Tenant).
+type Organization struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Slug      string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"slug"`
+	IsActive  int       `gorm:"type:tinyint(1);default:1" json:"isActive"`
+	CreatedAt time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
+}
+
+type OrganizationInput struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Slug     string `json:"slug" binding:"required,max=50"`
+	IsActive int    `json:"isActive" binding:"oneof=0 1"`
+}
+
+// OrganizationResponse menyertakan jumlah user dan campaign untuk tampilan lintas tenant.
+type OrganizationResponse struct {
+	Organization
+	UserCount     int64 `json:"userCount"`
+	CampaignCount int64 `json:"campaignCount"`
+}
+
+// TenantTables adalah tabel milik organisasi yang punya kolom created_by (scim_users di-backfill terpisah). Template dan landing page sistem (is_system_template = 1)
+// memakai organization_id 0 dan terlihat oleh semua organisasi.
+var TenantTables = []string{
+	"`groups`", "email_templates", "landing_pages", "sending_profiles", "directory_profiles", "campaigns", "scim_tokens", "service_accounts",
+	"target_domains", "exclusions", "blackout_windows", "member_attributes",
+}
+
+// PrepareTenantUniqueIndexes menghapus unique index global lama (domain target, key member attribute dan
+// userName SCIM) sebelum AutoMigrate membuat penggantinya yang unik per organisasi.
+func PrepareTenantUniqueIndexes(db *gorm.DB) error {
+	m := db.Migrator()
+	legacy := []struct {
+		model interface{}
+		index string
+	}{
+		{&TargetDomain{}, "idx_target_domains_domain"},
+		{&MemberAttribute{}, "idx_member_attributes_key"},
+		{&ScimUser{}, "idx_scim_users_user_name"},
+	}
+	for _, l := range legacy {
+		if m.HasTable(l.model) && m.HasIndex(l.model, l.index) {
+			if err := m.DropIndex(l.model, l.index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BackfillOrganizations memberi organisasi ke data lama. Sebelumnya isolasi data mengikuti created_by:
+// setiap user yang dibuat super admin (beserta user yang dibuatnya) menjadi satu organisasi sendiri,
+// super admin masuk organisasi default, lalu data lain mengikuti organisasi pembuatnya.
+func BackfillOrganizations(db *gorm.DB) error {
+	now := time.Now()
+	def := Organization{Name: "Default Organization", Slug: DefaultOrganizationSlug, IsActive: 1, CreatedAt: now, UpdatedAt: now}
+	if err := db.Where(Organization{Slug: DefaultOrganizationSlug}).FirstOrCreate(&def).Error; err != nil {
+		return err
+	}
+
+	var pending int64
+	if err := db.Model(&User{}).Where("organization_id = 0").Count(&pending).Error; err != nil {
+		return err
+	}
+	if pending > 0 {
+		// MySQL tidak mengizinkan subquery ke tabel yang sama pada UPDATE, jadi ID super admin diambil dulu
+		superAdmins := []uint{}
+		if err := db.Table("users").Joins("JOIN roles ON roles.id = users.role").
+			Where("roles.name = ?", SuperAdminRoleName).Pluck("users.id", &superAdmins).Error; err != nil {
+			return err
+		}
+		if len(superAdmins) > 0 {
+			if err := db.Model(&User{}).Where("organization_id = 0 AND id IN ?", superAdmins).
+				Update("organization_id", def.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		var roots []User
+		if err := db.Where("organization_id = 0 AND (created_by IS NULL OR created_by = 0 OR created_by IN ?)", append(superAdmins, 0)).
+			Find(&roots).Error; err != nil {
+			return err
+		}
+		for _, root := range roots {
+			name := root.Company
+			if name == "" {
+				name = root.Name
+			}
+			org := Organization{Name: name, Slug: fmt.Sprintf("org-%d", root.ID), IsActive: 1, CreatedAt: now, UpdatedAt: now}
+			if err := db.Where(Organization{Slug: org.Slug}).FirstOrCreate(&org).Error; err != nil {
+				return err
+			}
+			if err := db.Model(&root).Update("organization_id", org.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// User buatan admin tenant mengikuti organisasi pembuatnya, berjenjang
+		for i := 0; i < 10; i++ {
+			res := db.Exec(`UPDATE users JOIN users AS creator ON creator.id = users.created_by
+				SET users.organization_id = creator.organization_id
+				WHERE users.organization_id = 0 AND creator.organization_id <> 0`)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				break
+			}
+		}
+		if err := db.Model(&User{}).Where("organization_id = 0").Update("organization_id", def.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, table := range TenantTables {
+		system := ""
+		if table == "email_templates" || table == "landing_pages" {
+			system = " AND t.is_system_template = 0"
+		}
+		if err := db.Exec(`UPDATE ` + table + ` AS t JOIN users ON users.id = t.created_by
+			SET t.organization_id = users.organization_id WHERE t.organization_id = 0` + system).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(`UPDATE `+table+` AS t SET t.organization_id = ? WHERE t.organization_id = 0`+system, def.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	// Pengaturan login lama berlaku global; kini menjadi milik organisasi default
+	if err := db.Exec(`UPDATE auth_settings SET organization_id = ? WHERE organization_id = 0`, def.ID).Error; err != nil {
+		return err
+	}
+
+	// Training completion yang dicatat lewat API key punya created_by 0; ikut organisasi campaign-nya jika ada
+	if err := db.Exec(`UPDATE training_completions AS t JOIN campaigns ON campaigns.id = t.campaign_id
+		SET t.organization_id = campaigns.organization_id WHERE t.organization_id = 0`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE training_completions AS t JOIN users ON users.id = t.created_by
+		SET t.organization_id = users.organization_id WHERE t.organization_id = 0`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE training_completions SET organization_id = ? WHERE organization_id = 0`, def.ID).Error; err != nil {
+		return err
+	}
+
+	// User SCIM tidak punya created_by; ikut organisasi group SCIM tempat ia menjadi anggota
+	if err := db.Exec("UPDATE scim_users" +
+		" JOIN scim_group_memberships ON scim_group_memberships.scim_user_id = scim_users.id" +
+		" JOIN `groups` ON `groups`.id = scim_group_memberships.group_id" +
+		" SET scim_users.organization_id = `groups`.organization_id WHERE scim_users.organization_id = 0").Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE scim_users SET organization_id = ? WHERE organization_id = 0`, def.ID).Error; err != nil {
+		return err
+	}
+	return nil
+}
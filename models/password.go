@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Tujuan token satu kali yang dikirim lewat email
+const (
+	UserTokenInvite        = "invite"         // user baru mengatur password pertamanya
+	UserTokenPasswordReset = "password_reset" // lupa password
+)
+
+// UserToken adalah token link email satu kali pakai. Hanya hash SHA-256 yang disimpan;
+// token yang belum dipakai dibatalkan saat token baru dengan tujuan sama diterbitkan.
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"userId"`
+	Purpose   string     `gorm:"type:varchar(20);not null" json:"purpose"`
+	TokenHash string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"type:datetime;not null" json:"expiresAt"`
+	UsedAt    *time.Time `gorm:"type:datetime;null" json:"usedAt"`
+	CreatedAt time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy int        `gorm:"null" json:"createdBy"`
+}
+
+// PasswordPolicy adalah kebijakan password organisasi (satu baris). Jika belum ada baris, default dipakai.
+type PasswordPolicy struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MinLength        int       `gorm:"default:8" json:"minLength"`
+	RequireUppercase bool      `gorm:"default:false" json:"requireUppercase"`
+	RequireLowercase bool      `gorm:"default:false" json:"requireLowercase"`
+	RequireDigit     bool      `gorm:"default:false" json:"requireDigit"`
+	RequireSymbol    bool      `gorm:"default:false" json:"requireSymbol"`
+	CheckBreached    bool      `gorm:"default:true" json:"checkBreached"` // dicek terhadap daftar hash lokal (PASSWORD_BREACHED_HASHES_FILE)
+	UpdatedAt        time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy        int       `gorm:"null" json:"updatedBy"`
+}
+
+type PasswordPolicyInput struct {
+	MinLength        int  `json:"minLength" binding:"min=6,max=72"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSymbol    bool `json:"requireSymbol"`
+	CheckBreached    bool `json:"checkBreached"`
+}
+
+type InviteUserInput struct {
+	Name     string `json:"name"     binding:"required"`
+	Email    string `json:"email"    binding:"required,email"`
+	Position string `json:"position" binding:"required"`
+	Company  string `json:"company"`
+	Role     int    `json:"role"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"    binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
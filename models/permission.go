@@ -0,0 +1,172 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Permission adalah satu izin resource × action (mis. "campaign:create") yang bisa diberikan ke role.
+// Daftar izin berasal dari PermissionCatalog dan disinkronkan oleh seeder.
+type Permission struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Key         string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"key"`
+	Resource    string    `gorm:"type:varchar(50);not null;index" json:"resource"`
+	Action      string    `gorm:"type:varchar(50);not null" json:"action"`
+	Description string    `gorm:"type:varchar(255);null" json:"description"`
+	CreatedAt   time.Time `gorm:"type:datetime;null" json:"createdAt"`
+}
+
+// RolePermission memberikan satu Permission ke Role, sejajar dengan RoleMenuAccess/RoleSubmenuAccess.
+type RolePermission struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleID       uint       `gorm:"not null;uniqueIndex:idx_role_permission" json:"roleId"`
+	Role         Role       `gorm:"foreignKey:RoleID" json:"-"`
+	PermissionID uint       `gorm:"not null;uniqueIndex:idx_role_permission" json:"permissionId"`
+	Permission   Permission `gorm:"foreignKey:PermissionID" json:"-"`
+	CreatedAt    time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	UpdatedAt    time.Time  `gorm:"type:datetime;null" json:"updatedAt"`
+}
+
+type RolePermissionsInput struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// PermissionDef adalah definisi izin di katalog. DefaultRoles (nama role) hanya diberikan sekali,
+// saat izin pertama kali dibuat, sehingga izin yang dicabut admin tidak diberikan ulang oleh seeder.
+type PermissionDef struct {
+	Key          string
+	Description  string
+	DefaultRoles []string
+}
+
+// Resource dan Action dari key "resource:action".
+func (d PermissionDef) Resource() string {
+	resource, _, _ := strings.Cut(d.Key, ":")
+	return resource
+}
+
+func (d PermissionDef) Action() string {
+	_, action, _ := strings.Cut(d.Key, ":")
+	return action
+}
+
+var (
+	superAdminOnly = []string{"Super Admin"}
+	adminRoles     = []string{"Super Admin", "Admin"}
+	allRoles       = []string{"Super Admin", "Admin", "Engineer"}
+)
+
+// PermissionCatalog berisi semua izin yang dipakai routes.SetupRoutes. Default mengikuti akses submenu
+// hasil seeder; pengaturan yang sebelumnya khusus admin utama tetap hanya untuk Super Admin.
+var PermissionCatalog = []PermissionDef{
+	{"dashboard:read", "View dashboard analytics", allRoles},
+	{"activity-log:read", "View activity logs", adminRoles},
+
+	{"campaign:create", "Create campaigns", adminRoles},
+	{"campaign:read", "View campaigns and their results", allRoles},
+	{"campaign:update", "Update campaigns", adminRoles},
+	{"campaign:delete", "Delete campaigns", adminRoles},
+
+	{"group:create", "Create groups", adminRoles},
+	{"group:read", "View groups and members", adminRoles},
+	{"group:update", "Update groups and members", adminRoles},
+	{"group:delete", "Delete groups", adminRoles},
+	{"group:import", "Import group members", adminRoles},
+	{"group:export", "Export group members", adminRoles},
+	{"directory-sync:read", "View group directory sync settings and history", adminRoles},
+	{"directory-sync:update", "Configure group directory sync", adminRoles},
+	{"directory-sync:run", "Run group directory sync", adminRoles},
+	{"person:read", "View people and their history", adminRoles},
+	{"person:update", "Record training completions", adminRoles},
+
+	{"member-attribute:create", "Create custom member attributes", superAdminOnly},
+	{"member-attribute:read", "View custom member attributes", adminRoles},
+	{"member-attribute:update", "Update custom member attributes", superAdminOnly},
+	{"member-attribute:delete", "Delete custom member attributes", superAdminOnly},
+	{"target-domain:create", "Add target domains", superAdminOnly},
+	{"target-domain:read", "View target domains", adminRoles},
+	{"target-domain:verify", "Verify target domain ownership", superAdminOnly},
+	{"target-domain:delete", "Delete target domains", superAdminOnly},
+	{"exclusion:create", "Add recipient exclusions", adminRoles},
+	{"exclusion:read", "View recipient exclusions", adminRoles},
+	{"exclusion:update", "Update recipient exclusions", adminRoles},
+	{"exclusion:delete", "Delete recipient exclusions", adminRoles},
+	{"holiday:create", "Add holidays", superAdminOnly},
+	{"holiday:read", "View holidays", adminRoles},
+	{"holiday:delete", "Delete holidays", superAdminOnly},
+	{"blackout:create", "Add or import blackout windows", superAdminOnly},
+	{"blackout:read", "View blackout windows", adminRoles},
+	{"blackout:update", "Update blackout windows", superAdminOnly},
+	{"blackout:delete", "Delete blackout windows", superAdminOnly},
+
+	{"email-template:create", "Create email templates", adminRoles},
+	{"email-template:read", "View email templates", adminRoles},
+	{"email-template:update", "Update email templates and attachments", adminRoles},
+	{"email-template:delete", "Delete email templates", adminRoles},
+	{"landing-page:create", "Create or clone landing pages", adminRoles},
+	{"landing-page:read", "View landing pages", adminRoles},
+	{"landing-page:update", "Update landing pages", adminRoles},
+	{"landing-page:delete", "Delete landing pages", adminRoles},
+	{"sending-profile:create", "Create sending profiles", superAdminOnly},
+	{"sending-profile:read", "View sending profiles", adminRoles},
+	{"sending-profile:update", "Update sending profiles and their headers", superAdminOnly},
+	{"sending-profile:delete", "Delete sending profiles", superAdminOnly},
+	{"sending-profile:test", "Send test emails and run SMTP diagnostics", superAdminOnly},
+	{"sending-profile:read-secret", "Reveal stored SMTP passwords", superAdminOnly},
+
+	{"directory-profile:create", "Create directory (LDAP) profiles", superAdminOnly},
+	{"directory-profile:read", "View directory (LDAP) profiles", adminRoles},
+	{"directory-profile:update", "Update directory (LDAP) profiles", superAdminOnly},
+	{"directory-profile:delete", "Delete directory (LDAP) profiles", superAdminOnly},
+	{"directory-profile:test", "Test directory (LDAP) connections", superAdminOnly},
+	{"scim-token:create", "Create SCIM provisioning tokens", superAdminOnly},
+	{"scim-token:read", "View SCIM provisioning tokens", superAdminOnly},
+	{"scim-token:delete", "Revoke SCIM provisioning tokens", superAdminOnly},
+	{"service-account:create", "Create service accounts for automation", adminRoles},
+	{"service-account:read", "View service accounts and their API keys", adminRoles},
+	{"service-account:update", "Update service accounts and issue or revoke API keys", adminRoles},
+	{"service-account:delete", "Delete service accounts", adminRoles},
+
+	{"user:create", "Create users", adminRoles},
+	{"user:invite", "Invite users by email", adminRoles},
+	{"user:read", "View users", adminRoles},
+	{"user:update", "Update users", adminRoles},
+	{"user:delete", "Delete users", adminRoles},
+	{"role:create", "Create roles", superAdminOnly},
+	{"role:read", "View roles and their permissions", adminRoles},
+	{"role:update", "Update roles and assign menu access and permissions", superAdminOnly},
+	{"role:delete", "Delete roles", superAdminOnly},
+	{"organization:create", "Create organizations", superAdminOnly},
+	{"organization:read", "View all organizations", superAdminOnly},
+	{"organization:update", "Update and deactivate organizations", superAdminOnly},
+	{"organization:delete", "Delete empty organizations", superAdminOnly},
+	{"organization:cross-tenant", "View and manage the data of every organization", superAdminOnly},
+	{"menu:create", "Create menus and submenus", superAdminOnly},
+	{"menu:read", "View menus and submenus", adminRoles},
+	{"menu:update", "Update menus and submenus", superAdminOnly},
+	{"menu:delete", "Delete menus and submenus", superAdminOnly},
+
+	{"password-policy:update", "Update the password policy", superAdminOnly},
+	{"login-lock:read", "View locked accounts and IP addresses", superAdminOnly},
+	{"login-lock:unlock", "Unlock locked accounts and IP addresses", superAdminOnly},
+	{"mfa:reset", "Reset another user's MFA", superAdminOnly},
+	{"mfa-policy:read", "View MFA requirements per role", adminRoles},
+	{"mfa-policy:update", "Update MFA requirements per role", superAdminOnly},
+	{"sso-provider:create", "Create SSO identity providers", superAdminOnly},
+	{"sso-provider:read", "View SSO identity providers", superAdminOnly},
+	{"sso-provider:update", "Update SSO identity providers", superAdminOnly},
+	{"sso-provider:delete", "Delete SSO identity providers", superAdminOnly},
+	{"sso-provider:test", "Test SSO identity provider discovery", superAdminOnly},
+	{"auth-settings:read", "View authentication settings", superAdminOnly},
+	{"auth-settings:update", "Update authentication settings", superAdminOnly},
+}
+
+// IsKnownPermission melaporkan apakah key ada di PermissionCatalog.
+func IsKnownPermission(key string) bool {
+	for _, d := range PermissionCatalog {
+		if d.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Person adalah identitas kanonik seorang target, dikunci oleh email yang dinormalisasi.
+// Member (per group) dan Recipient (per campaign) merujuk ke Person melalui PersonID.
+type Person struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Email     string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"email"`
+	Name      string    `gorm:"type:varchar(30)" json:"name"`
+	Position  string    `gorm:"type:varchar(30)" json:"position"`
+	Company   string    `gorm:"type:varchar(50)" json:"company"`
+	Country   string    `gorm:"type:varchar(50)" json:"country"`
+	CreatedAt time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+}
+
+func (Person) TableName() string {
+	return "people"
+}
+
+// TrainingCompletion mencatat modul training yang sudah diselesaikan seseorang. Person bersifat global,
+// jadi completion dimiliki organisasi yang mencatatnya.
+type TrainingCompletion struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID   uint      `gorm:"not null;default:0;index" json:"organizationId"`
+	PersonID         uint      `gorm:"not null;index" json:"personId"`
+	Module           string    `gorm:"type:varchar(100);not null" json:"module"`
+	CampaignID       *uint     `gorm:"index" json:"campaignId,omitempty"` // campaign yang memicu training, jika ada
+	Score            *int      `json:"score,omitempty"`
+	CompletedAt      time.Time `gorm:"type:datetime;not null" json:"completedAt"`
+	CreatedBy        int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+	ServiceAccountID *uint     `gorm:"index" json:"serviceAccountId,omitempty"` // terisi jika dicatat lewat API key
+	CreatedAt        time.Time `gorm:"type:datetime;null" json:"createdAt"`
+}
+
+type CreateTrainingCompletionInput struct {
+	Module      string     `json:"module" binding:"required,max=100"`
+	CampaignID  *uint      `json:"campaignId"`
+	Score       *int       `json:"score" binding:"omitempty,min=0,max=100"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+type PersonListItem struct {
+	Person
+	GroupCount    int64 `json:"groupCount"`
+	CampaignCount int64 `json:"campaignCount"`
+}
+
+type PersonMembership struct {
+	MemberID  uint   `json:"memberId"`
+	GroupID   uint   `json:"groupId"`
+	GroupName string `json:"groupName"`
+	Name      string `json:"name"`
+	Position  string `json:"position"`
+}
+
+type PersonInteraction struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type PersonCampaignHistory struct {
+	CampaignID   uint                `json:"campaignId"`
+	CampaignName string              `json:"campaignName"`
+	RecipientUID string              `json:"recipientUid"`
+	Status       string              `json:"status"`
+	TargetedAt   time.Time           `json:"targetedAt"`
+	Interactions []PersonInteraction `json:"interactions"`
+}
+
+type PersonRiskScore struct {
+	Score      int       `json:"score"` // 0 (rendah) - 100 (tinggi)
+	Level      string    `json:"level"` // low, medium, high
+	Campaigns  int       `json:"campaigns"`
+	Compromise int       `json:"compromise"` // campaign dengan klik/submit/scan/lampiran dibuka
+	Reported   int       `json:"reported"`
+	Trainings  int       `json:"trainings"`
+	ScoredAt   time.Time `json:"scoredAt"`
+}
+
+type PersonDetailResponse struct {
+	Person      Person                  `json:"person"`
+	Memberships []PersonMembership      `json:"memberships"`
+	Campaigns   []PersonCampaignHistory `json:"campaigns"`
+	Trainings   []TrainingCompletion    `json:"trainings"`
+	RiskScore   PersonRiskScore         `json:"riskScore"`
+}
+
+// NormalizeEmail menghasilkan kunci Person dari sebuah alamat email.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// EnsurePerson mengembalikan Person untuk email tersebut, membuatnya jika belum ada.
+// Atribut profil yang tidak kosong diperbarui dari data terbaru.
+func EnsurePerson(tx *gorm.DB, email string, profile Person) (*Person, error) {
+	email = NormalizeEmail(email)
+	if email == "" {
+		return nil, nil
+	}
+
+	var person Person
+	err := tx.Where("email = ?", email).First(&person).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile.ID = 0
+		profile.Email = email
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&profile).Error; err != nil {
+			return nil, err
+		}
+		// Baris bisa saja dibuat proses lain bersamaan, jadi selalu baca ulang
+		err = tx.Where("email = ?", email).First(&person).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if profile.Name != "" && profile.Name != person.Name {
+		updates["name"] = profile.Name
+	}
+	if profile.Position != "" && profile.Position != person.Position {
+		updates["position"] = profile.Position
+	}
+	if profile.Company != "" && profile.Company != person.Company {
+		updates["company"] = profile.Company
+	}
+	if profile.Country != "" && profile.Country != person.Country {
+		updates["country"] = profile.Country
+	}
+	if len(updates) > 0 {
+		if err := tx.Model(&person).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &person, nil
+}
+
+// BeforeSave menautkan Member ke Person. Untuk update berbasis map, person_id
+// hanya dihitung ulang jika kolom email ikut diubah.
+func (m *Member) BeforeSave(tx *gorm.DB) error {
+	email, profile := m.Email, Person{Name: m.Name, Position: m.Position, Company: m.Company, Country: m.Country}
+	if dest, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		v, ok := dest["email"].(string)
+		if !ok {
+			return nil
+		}
+		email = v
+		profile = Person{}
+		profile.Name, _ = dest["name"].(string)
+		profile.Position, _ = dest["position"].(string)
+		profile.Company, _ = dest["company"].(string)
+		profile.Country, _ = dest["country"].(string)
+	}
+	if email == "" {
+		return nil
+	}
+
+	person, err := EnsurePerson(tx, email, profile)
+	if err != nil || person == nil {
+		return err
+	}
+	if _, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		tx.Statement.SetColumn("person_id", person.ID)
+	} else {
+		tx.Statement.SetColumn("PersonID", &person.ID)
+	}
+	return nil
+}
+
+// BeforeCreate menautkan Recipient ke Person jika belum diisi.
+func (r *Recipient) BeforeCreate(tx *gorm.DB) error {
+	if r.PersonID != nil {
+		return nil
+	}
+	person, err := EnsurePerson(tx, r.Email, Person{})
+	if err != nil || person == nil {
+		return err
+	}
+	r.PersonID = &person.ID
+	return nil
+}
+
+// BackfillPeople membuat Person untuk Member/Recipient lama yang belum memiliki person_id.
+func BackfillPeople(db *gorm.DB) error {
+	statements := []string{
+		`INSERT INTO people (email, name, position, company, country, created_at, updated_at)
+		SELECT LOWER(TRIM(email)), MAX(name), MAX(position), MAX(company), MAX(country), NOW(), NOW()
+		FROM members WHERE person_id IS NULL AND TRIM(email) <> ''
+		GROUP BY LOWER(TRIM(email))
+		ON DUPLICATE KEY UPDATE id = id`,
+		`INSERT INTO people (email, created_at, updated_at)
+		SELECT LOWER(TRIM(email)), NOW(), NOW()
+		FROM recipients WHERE person_id IS NULL AND TRIM(email) <> ''
+		GROUP BY LOWER(TRIM(email))
+		ON DUPLICATE KEY UPDATE id = id`,
+		`UPDATE members JOIN people ON people.email = LOWER(TRIM(members.email))
+		SET members.person_id = people.id WHERE members.person_id IS NULL`,
+		`UPDATE recipients JOIN people ON people.email = LOWER(TRIM(recipients.email))
+		SET recipients.person_id = people.id WHERE recipients.person_id IS NULL`,
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,16 +1,26 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 type Recipient struct {
-	ID         uint      `gorm:"primaryKey"                     json:"id"`
-	UID        string    `gorm:"type:char(36);uniqueIndex;not null" json:"uid"`
-	CampaignID uint      `gorm:"not null;index"                 json:"campaignId"`
-	UserID     uint      `gorm:"not null;index"                 json:"userId"`
-	Email      string    `gorm:"type:varchar(100);not null"     json:"email"`
-	Status     string    `gorm:"type:varchar(30);not null;default:'pending'" json:"status"`
-	Error      string    `gorm:"type:text"                      json:"error,omitempty"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"                 json:"createdAt"`
-	UpdatedAt  time.Time `gorm:"type:datetime;null"            json:"updatedAt"`
-	Events     []Event   `gorm:"foreignKey:RecipientID"`
+	ID          uint           `gorm:"primaryKey"                     json:"id"`
+	UID         string         `gorm:"type:char(36);uniqueIndex;not null" json:"uid"`
+	CampaignID  uint           `gorm:"not null;index"                 json:"campaignId"`
+	UserID      uint           `gorm:"not null;index"                 json:"userId"`
+	Email       string         `gorm:"type:varchar(100);not null"     json:"email"`
+	PersonID    *uint          `gorm:"index"                          json:"personId,omitempty"`
+	Attributes  datatypes.JSON `gorm:"type:json"                   json:"attributes,omitempty"` // profil member (position, company, country, custom attribute) saat dikirim
+	MessageID   string         `gorm:"type:varchar(255);index"        json:"messageId,omitempty"`
+	Status      string         `gorm:"type:varchar(30);not null;default:'pending'" json:"status"`
+	Error       string         `gorm:"type:text"                      json:"error,omitempty"`
+	ExclusionID *uint          `gorm:"index"                          json:"exclusionId,omitempty"` // terisi jika status excluded
+	Timezone    string         `gorm:"type:varchar(64);null"          json:"timezone,omitempty"`    // zona waktu recipient saat campaign launch
+	ScheduledAt *time.Time     `gorm:"type:datetime;null;index"       json:"scheduledAt,omitempty"` // terisi jika status scheduled
+	CreatedAt   time.Time      `gorm:"autoCreateTime"                 json:"createdAt"`
+	UpdatedAt   time.Time      `gorm:"type:datetime;null"            json:"updatedAt"`
+	Events      []Event        `gorm:"foreignKey:RecipientID"`
 }
@@ -1,12 +1,41 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// RefreshToken adalah refresh token opaque milik sebuah UserSession. Hanya hash SHA-256 yang disimpan.
+// Setiap refresh menandai token lama sebagai terpakai (UsedAt) dan menerbitkan token baru di session yang
+// sama; token terpakai yang dikirim ulang berarti token bocor, sehingga seluruh session dicabut.
 type RefreshToken struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"not null;uniqueIndex" json:"user_id"` // user_id harus unik
-	Token     string    `gorm:"type:text;not null" json:"token"`
-	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null;index:idx_refresh_tokens_user" json:"user_id"`
+	SessionID    uint       `gorm:"not null;index" json:"session_id"`
+	TokenHash    string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time `gorm:"type:datetime;null" json:"used_at"`
+	ReplacedByID *uint      `gorm:"null" json:"replaced_by_id"`
+	RevokedAt    *time.Time `gorm:"type:datetime;null" json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// PrepareRefreshTokens menyesuaikan tabel refresh_tokens versi lama (token plaintext, satu token per user)
+// sebelum AutoMigrate. Token lama tidak pernah diterbitkan oleh login sehingga aman dihapus.
+func PrepareRefreshTokens(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&RefreshToken{}) || !m.HasColumn(&RefreshToken{}, "token") {
+		return nil
+	}
+	if err := db.Exec("DELETE FROM refresh_tokens").Error; err != nil {
+		return err
+	}
+	if m.HasIndex(&RefreshToken{}, "idx_refresh_tokens_user_id") {
+		if err := m.DropIndex(&RefreshToken{}, "idx_refresh_tokens_user_id"); err != nil {
+			return err
+		}
+	}
+	return m.DropColumn(&RefreshToken{}, "token")
 }
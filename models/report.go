@@ -0,0 +1,22 @@
+package models
+
+// PhishReportRequest adalah payload dari add-in "Report Phishing" di mail client.
+// Minimal salah satu dari Headers atau MessageID harus diisi.
+type PhishReportRequest struct {
+	Headers       string `json:"headers"`        // Raw header pesan yang dilaporkan
+	MessageID     string `json:"message_id"`     // Message-ID pesan, jika add-in sudah mengekstraknya
+	ReporterEmail string `json:"reporter_email"` // Email user yang menekan tombol report
+	RawMessage    string `json:"raw_message"`    // (Opsional) pesan lengkap untuk diteruskan ke SOC
+}
+
+// SOCReportPayload dikirim ke SOC webhook ketika pesan yang dilaporkan bukan simulasi.
+type SOCReportPayload struct {
+	Source        string `json:"source"`
+	ReporterEmail string `json:"reporter_email"`
+	MessageID     string `json:"message_id"`
+	Subject       string `json:"subject"`
+	From          string `json:"from"`
+	Headers       string `json:"headers"`
+	RawMessage    string `json:"raw_message,omitempty"`
+	ReportedAt    string `json:"reported_at"`
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	ScimSchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	ScimSchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ScimSchemaEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	ScimSchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	ScimSchemaPatchOp        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ScimSchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// ScimToken adalah bearer token untuk identity provider yang melakukan provisioning via SCIM.
+// Hanya hash SHA-256 token yang disimpan; token asli ditampilkan sekali saat dibuat.
+type ScimToken struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint       `gorm:"not null;default:0;index" json:"organizationId"`
+	Name           string     `gorm:"type:varchar(50);not null" json:"name"`
+	TokenHash      string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	TokenPrefix    string     `gorm:"type:varchar(12);not null" json:"tokenPrefix"`
+	LastUsedAt     *time.Time `gorm:"type:datetime;null" json:"lastUsedAt"`
+	ExpiresAt      *time.Time `gorm:"type:datetime;null" json:"expiresAt"`
+	RevokedAt      *time.Time `gorm:"type:datetime;null" json:"revokedAt"`
+	CreatedAt      time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int        `gorm:"type:tinyint(3);null" json:"createdBy"`
+}
+
+type CreateScimTokenInput struct {
+	Name          string `json:"name" binding:"required,max=50"`
+	ExpiresInDays int    `json:"expiresInDays" binding:"omitempty,min=1"`
+}
+
+// ScimUser adalah identitas yang dikirim identity provider. Untuk setiap group yang diikuti user aktif
+// dibuat satu Member (Member.ScimUserID), sehingga perubahan HR langsung terlihat di target simulasi.
+type ScimUser struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint      `gorm:"not null;default:0;uniqueIndex:idx_scim_users_org_user_name" json:"organizationId"`
+	ExternalID     string    `gorm:"type:varchar(255);null;index" json:"externalId"`
+	UserName       string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_scim_users_org_user_name" json:"userName"` // unik per organisasi
+	Active         bool      `gorm:"default:true" json:"active"`
+	Resource       string    `gorm:"type:json" json:"-"` // representasi SCIM terakhir yang diterima
+	Version        int       `gorm:"default:1" json:"version"`
+	CreatedAt      time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	UpdatedAt      time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+
+	Groups []Group `gorm:"many2many:scim_group_memberships" json:"-"`
+}
+
+// ScimBool menerima boolean JSON maupun string ("True"/"false") yang dikirim sebagian identity provider.
+type ScimBool bool
+
+func (b *ScimBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case bool:
+		*b = ScimBool(t)
+	case string:
+		*b = ScimBool(strings.EqualFold(t, "true"))
+	case nil:
+		*b = false
+	default:
+		return &json.UnmarshalTypeError{Value: string(data)}
+	}
+	return nil
+}
+
+type ScimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+	Version      string    `json:"version,omitempty"`
+}
+
+type ScimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type ScimMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type ScimAddress struct {
+	Type    string `json:"type,omitempty"`
+	Country string `json:"country,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type ScimEnterpriseUser struct {
+	EmployeeNumber string `json:"employeeNumber,omitempty"`
+	Organization   string `json:"organization,omitempty"`
+	Department     string `json:"department,omitempty"`
+}
+
+type ScimUserResource struct {
+	Schemas     []string            `json:"schemas"`
+	ID          string              `json:"id,omitempty"`
+	ExternalID  string              `json:"externalId,omitempty"`
+	UserName    string              `json:"userName"`
+	Name        *ScimName           `json:"name,omitempty"`
+	DisplayName string              `json:"displayName,omitempty"`
+	Title       string              `json:"title,omitempty"`
+	Emails      []ScimMultiValue    `json:"emails,omitempty"`
+	Addresses   []ScimAddress       `json:"addresses,omitempty"`
+	Timezone    string              `json:"timezone,omitempty"`
+	Active      *ScimBool           `json:"active,omitempty"`
+	Groups      []ScimMultiValue    `json:"groups,omitempty"`
+	Enterprise  *ScimEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *ScimMeta           `json:"meta,omitempty"`
+}
+
+type ScimGroupResource struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []ScimMultiValue `json:"members,omitempty"`
+	Meta        *ScimMeta        `json:"meta,omitempty"`
+}
+
+type ScimListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type ScimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type ScimPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []ScimPatchOperation `json:"Operations"`
+}
+
+type ScimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
@@ -14,19 +14,20 @@ type EmailHeader struct {
 }
 
 type SendingProfiles struct {
-	ID            uint          `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name          string        `gorm:"type:varchar(50);not null" json:"name"`
-	InterfaceType string        `gorm:"type:varchar(30);null" json:"interfaceType"`
-	SmtpFrom      string        `gorm:"type:varchar(50);null" json:"smtpFrom"`
-	Username      string        `gorm:"type:varchar(50);null" json:"username"`
-	Password      string        `gorm:"type:varchar(128);null" json:"-"`
-	Host          string        `gorm:"type:varchar(50);null" json:"host"`
-	Port          int           `gorm:"type:int;not null;default:587"   json:"port"`
-	EmailHeaders  []EmailHeader `gorm:"foreignKey:SendingProfileID;references:ID" json:"emailHeaders"`
-	CreatedAt     time.Time     `gorm:"type:datetime;null" json:"createdAt"`
-	CreatedBy     int           `gorm:"type:tinyint(3);null" json:"createdBy"`
-	UpdatedAt     time.Time     `gorm:"type:datetime;null" json:"updatedAt"`
-	UpdatedBy     int           `gorm:"type:tinyint(3);null" json:"updatedBy"`
+	ID             uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint          `gorm:"not null;default:0;index" json:"organizationId"`
+	Name           string        `gorm:"type:varchar(50);not null" json:"name"`
+	InterfaceType  string        `gorm:"type:varchar(30);null" json:"interfaceType"`
+	SmtpFrom       string        `gorm:"type:varchar(50);null" json:"smtpFrom"`
+	Username       string        `gorm:"type:varchar(50);null" json:"username"`
+	Password       string        `gorm:"type:text;null" json:"-"` // terenkripsi, lihat services.EncryptSecret
+	Host           string        `gorm:"type:varchar(50);null" json:"host"`
+	Port           int           `gorm:"type:int;not null;default:587"   json:"port"`
+	EmailHeaders   []EmailHeader `gorm:"foreignKey:SendingProfileID;references:ID" json:"emailHeaders"`
+	CreatedAt      time.Time     `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int           `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt      time.Time     `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy      int           `gorm:"type:tinyint(3);null" json:"updatedBy"`
 }
 
 type UpdateSendingProfileRequest struct {
@@ -78,3 +79,26 @@ type SendTestEmailRequest struct {
 	Recipient TestRecipient `json:"recipient" binding:"required"`
 	EmailBody string        `json:"emailBody" binding:"required"`
 }
+
+type DiagnoseSendingProfileRequest struct {
+	Recipient string `json:"recipient" binding:"omitempty,email"` // RCPT TO yang diuji, default ke SmtpFrom
+}
+
+// SMTPDiagnosticStep adalah hasil satu tahap diagnosa koneksi SMTP
+type SMTPDiagnosticStep struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"` // success, warning, failed, skipped
+	DurationMs int64                  `json:"durationMs"`
+	Detail     map[string]interface{} `json:"detail,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+type SMTPDiagnosticReport struct {
+	SendingProfileID uint                 `json:"sendingProfileId"`
+	Host             string               `json:"host"`
+	Port             string               `json:"port"`
+	Success          bool                 `json:"success"`
+	StartedAt        time.Time            `json:"startedAt"`
+	DurationMs       int64                `json:"durationMs"`
+	Steps            []SMTPDiagnosticStep `json:"steps"`
+}
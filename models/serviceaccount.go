@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ApiKeyPrefix menandai bearer token sebagai API key service account (bukan JWT), sehingga JWTAuth
+// bisa membedakannya dan secret scanner bisa mengenalinya.
+const ApiKeyPrefix = "awx_"
+
+// ServiceAccount adalah identitas non-manusia milik satu organisasi (mis. SOAR atau BI) yang
+// mengakses API lewat ApiKey. Service account tidak punya role; izinnya ditentukan scope tiap key.
+type ServiceAccount struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint      `gorm:"not null;default:0;index" json:"organizationId"`
+	Name           string    `gorm:"type:varchar(100);not null" json:"name"`
+	Description    string    `gorm:"type:varchar(255);null" json:"description"`
+	IsActive       int       `gorm:"type:tinyint(1);default:1" json:"isActive"`
+	ApiKeys        []ApiKey  `gorm:"foreignKey:ServiceAccountID" json:"apiKeys"`
+	CreatedAt      time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt      time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy      int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
+}
+
+// ApiKey adalah kredensial service account. Hanya hash SHA-256 key yang disimpan; key asli
+// ditampilkan sekali saat dibuat dan dikenali lewat TokenPrefix.
+type ApiKey struct {
+	ID               uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	ServiceAccountID uint   `gorm:"not null;index" json:"serviceAccountId"`
+	Name             string `gorm:"type:varchar(50);not null" json:"name"`
+	TokenHash        string `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	TokenPrefix      string `gorm:"type:varchar(12);not null;index" json:"tokenPrefix"`
+	// Scopes adalah key izin (lihat PermissionCatalog) dipisah spasi, mis. "campaign:create campaign:read"
+	Scopes     string     `gorm:"type:text;not null" json:"scopes"`
+	LastUsedAt *time.Time `gorm:"type:datetime;null" json:"lastUsedAt"`
+	LastUsedIP string     `gorm:"type:varchar(45);null" json:"lastUsedIp"`
+	ExpiresAt  *time.Time `gorm:"type:datetime;null" json:"expiresAt"`
+	RevokedAt  *time.Time `gorm:"type:datetime;null" json:"revokedAt"`
+	CreatedAt  time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy  int        `gorm:"type:tinyint(3);null" json:"createdBy"`
+}
+
+// HasScope melaporkan apakah key boleh memakai izin permission.
+func (k ApiKey) HasScope(permission string) bool {
+	for _, scope := range strings.Fields(k.Scopes) {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type ServiceAccountInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+	IsActive    int    `json:"isActive" binding:"oneof=0 1"`
+}
+
+type CreateApiKeyInput struct {
+	Name          string   `json:"name" binding:"required,max=50"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expiresInDays" binding:"omitempty,min=1"`
+}
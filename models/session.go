@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Alasan pencabutan session
+const (
+	SessionRevokedReuse          = "refresh_token_reuse"
+	SessionRevokedInactive       = "user_inactive"
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked_by_user"
+	SessionRevokedPasswordChange = "password_changed"
+	SessionRevokedUserDeleted    = "user_deleted"
+)
+
+// UserSession mencatat satu login (satu keluarga refresh token) beserta perangkat asalnya.
+// Access token membawa UID session pada claim "jti"; JWTAuth menolak token yang session-nya sudah dicabut.
+type UserSession struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UID           string     `gorm:"type:char(36);null;uniqueIndex" json:"-"`
+	UserID        uint       `gorm:"not null;index" json:"userId"`
+	IPAddress     string     `gorm:"type:varchar(45);null" json:"ipAddress"`
+	UserAgent     string     `gorm:"type:varchar(255);null" json:"userAgent"`
+	KeepLoggedIn  bool       `gorm:"default:false" json:"keepLoggedIn"`
+	CreatedAt     time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	LastUsedAt    time.Time  `gorm:"type:datetime;null" json:"lastUsedAt"`
+	ExpiresAt     time.Time  `gorm:"type:datetime;not null" json:"expiresAt"` // batas absolut, tidak diperpanjang saat refresh
+	RevokedAt     *time.Time `gorm:"type:datetime;null" json:"revokedAt,omitempty"`
+	RevokedReason string     `gorm:"type:varchar(50);null" json:"revokedReason,omitempty"`
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type UserSessionResponse struct {
+	UserSession
+	Current bool `json:"current"`
+}
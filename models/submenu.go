@@ -15,3 +15,11 @@ type Submenu struct {
 	UpdatedAt time.Time `gorm:"type:datetime;null" json:"updatedAt"`
 	UpdatedBy int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
 }
+
+type SubmenuInput struct {
+	MenuID   uint   `json:"menuId" binding:"required"`
+	Name     string `json:"name" binding:"required,max=50"`
+	Icon     string `json:"icon" binding:"required,max=20"`
+	Url      string `json:"url" binding:"required,max=100"`
+	IsActive int    `json:"isActive" binding:"oneof=0 1"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Status verifikasi TargetDomain
+const (
+	DomainPending  = "pending"
+	DomainVerified = "verified"
+	DomainFailed   = "failed"
+)
+
+// RecipientDomainUnverified adalah status recipient yang tidak dikirimi email karena domainnya belum terverifikasi.
+const RecipientDomainUnverified = "unverified_domain"
+
+// TargetDomain adalah domain tujuan simulasi. Kepemilikan dibuktikan dengan TXT record
+// "awarenix-verification=<VerificationToken>" pada domain tersebut atau pada _awarenix.<domain>.
+// Domain yang terverifikasi juga mencakup subdomain-nya. Domain dimiliki per organisasi; setiap
+// organisasi membuktikan kepemilikan dengan token-nya sendiri.
+type TargetDomain struct {
+	ID                uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID    uint       `gorm:"not null;default:0;uniqueIndex:idx_target_domains_org_domain" json:"organizationId"`
+	Domain            string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_target_domains_org_domain" json:"domain"`
+	VerificationToken string     `gorm:"type:varchar(64);not null" json:"verificationToken"`
+	Status            string     `gorm:"type:varchar(10);not null;default:'pending'" json:"status"`
+	VerifiedAt        *time.Time `gorm:"type:datetime;null" json:"verifiedAt"`
+	LastCheckedAt     *time.Time `gorm:"type:datetime;null" json:"lastCheckedAt"`
+	LastError         string     `gorm:"type:varchar(255)" json:"lastError,omitempty"`
+	CreatedAt         time.Time  `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy         int        `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt         time.Time  `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy         int        `gorm:"type:tinyint(3);null" json:"updatedBy"`
+}
+
+type CreateTargetDomainInput struct {
+	Domain string `json:"domain" binding:"required,max=255"`
+}
+
+// TargetDomainResponse menyertakan instruksi DNS yang harus dibuat admin.
+type TargetDomainResponse struct {
+	TargetDomain
+	RecordName  string `json:"recordName"`
+	RecordType  string `json:"recordType"`
+	RecordValue string `json:"recordValue"`
+}
+
+// GroupDomainCheck merangkum domain anggota group dan status verifikasinya.
+type GroupDomainCheck struct {
+	Domain   string `json:"domain"`
+	Members  int    `json:"members"`
+	Verified bool   `json:"verified"`
+}
@@ -3,20 +3,21 @@ package models
 import "time"
 
 type User struct {
-	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string    `gorm:"type:varchar(50);not null" json:"name"`
-	Email        string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Position     string    `gorm:"type:varchar(50);not null" json:"position"`
-	PasswordHash string    `gorm:"type:varchar(255);not null" json:"password"`
-	IsActive     int       `gorm:"type:tinyint(1);default:1" json:"isActive"`
-	Role         int       `gorm:"type:tinyint(3);default:3" json:"role"`
-	Company      string    `gorm:"type:varchar(50);null" json:"company"`
-	Country      string    `gorm:"type:varchar(50);null" json:"country"`
-	LastLogin    time.Time `gorm:"type:datetime;null" json:"lastLogin"`
-	CreatedAt    time.Time `gorm:"type:datetime;null" json:"createdAt"`
-	CreatedBy    int       `gorm:"type:tinyint(3);null" json:"createdBy"`
-	UpdatedAt    time.Time `gorm:"type:datetime;null" json:"updatedAt"`
-	UpdatedBy    int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint      `gorm:"not null;default:0;index" json:"organizationId"`
+	Name           string    `gorm:"type:varchar(50);not null" json:"name"`
+	Email          string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
+	Position       string    `gorm:"type:varchar(50);not null" json:"position"`
+	PasswordHash   string    `gorm:"type:varchar(255);not null" json:"password"`
+	IsActive       int       `gorm:"type:tinyint(1);default:1" json:"isActive"`
+	Role           int       `gorm:"type:tinyint(3);default:3" json:"role"`
+	Company        string    `gorm:"type:varchar(50);null" json:"company"`
+	Country        string    `gorm:"type:varchar(50);null" json:"country"`
+	LastLogin      time.Time `gorm:"type:datetime;null" json:"lastLogin"`
+	CreatedAt      time.Time `gorm:"type:datetime;null" json:"createdAt"`
+	CreatedBy      int       `gorm:"type:tinyint(3);null" json:"createdBy"`
+	UpdatedAt      time.Time `gorm:"type:datetime;null" json:"updatedAt"`
+	UpdatedBy      int       `gorm:"type:tinyint(3);null" json:"updatedBy"`
 }
 
 type FullUserLoginData struct {
@@ -28,6 +29,8 @@ type LoginInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 	Status   string `json:"status"`
+	// RefreshCookie mengirim refresh token sebagai cookie httpOnly, bukan di body response
+	RefreshCookie bool `json:"refresh_cookie"`
 }
 type UserLoginResponse struct {
 	ID        uint      `json:"id"`
@@ -92,14 +95,3 @@ type UpdateUserInput struct {
 	UpdatedAt time.Time `gorm:"null"`
 	UpdatedBy int       `gorm:"null" json:"updatedBy"`
 }
-
-type UserSession struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Token     string    `json:"token" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at"`
-}
-
-type GetUserSession struct {
-	ID uint `json:"user_id" gorm:"primaryKey"`
-}
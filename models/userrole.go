@@ -6,6 +6,7 @@ type Role struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	Name      string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
 	IsActive  int       `gorm:"type:tinyint(1);default:1" json:"isActive"`
+	BuiltIn   bool      `gorm:"default:false" json:"builtIn"` // role bawaan seeder: tidak bisa diganti nama atau dihapus
 	CreatedAt time.Time `gorm:"type:datetime;null" json:"createdAt"`
 	CreatedBy uint      `gorm:"type:bigint;null" json:"createdBy"`
 	UpdatedBy uint      `gorm:"type:bigint;null" json:"updatedBy"`
@@ -32,6 +33,10 @@ type RoleSubmenuAccess struct {
 	UpdatedAt time.Time `gorm:"type:datetime;null" json:"updatedAt"` // Perbaiki tag gorm di sini, dari updatedBy menjadi updatedAt
 }
 
+// SuperAdminRoleName adalah role bawaan dengan akses penuh. Akses menu, submenu dan izinnya
+// tidak bisa diubah lewat API agar organisasi tidak mengunci dirinya sendiri.
+const SuperAdminRoleName = "Super Admin"
+
 type RoleResponse struct {
 	ID        uint      `json:"id"`
 	Name      string    `json:"name"`
@@ -55,3 +60,30 @@ type UpdateRoleInput struct {
 	UpdatedAt time.Time `gorm:"null"`
 	UpdatedBy int       `gorm:"null" json:"updatedBy"`
 }
+
+type RoleMenuAccessInput struct {
+	RoleID uint `json:"roleId" binding:"required"`
+	MenuID uint `json:"menuId" binding:"required"`
+}
+
+type RoleSubmenuAccessInput struct {
+	RoleID    uint `json:"roleId" binding:"required"`
+	SubmenuID uint `json:"submenuId" binding:"required"`
+}
+
+// RoleAccessInput mengganti seluruh akses satu role sekaligus. Permissions nil berarti izin API tidak diubah.
+type RoleAccessInput struct {
+	MenuIDs     []uint    `json:"menuIds" binding:"required"`
+	SubmenuIDs  []uint    `json:"submenuIds" binding:"required"`
+	Permissions *[]string `json:"permissions"`
+}
+
+type RoleAccessResponse struct {
+	RoleID      uint     `json:"roleId"`
+	RoleName    string   `json:"roleName"`
+	BuiltIn     bool     `json:"builtIn"`
+	Editable    bool     `json:"editable"`
+	MenuIDs     []uint   `json:"menuIds"`
+	SubmenuIDs  []uint   `json:"submenuIds"`
+	Permissions []string `json:"permissions"`
+}
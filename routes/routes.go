@@ -13,101 +13,317 @@ func SetupRoutes(router *gin.Engine) {
 
 	// Public routes
 	router.POST("/api/v1/auth/login", controllers.AuthLogin)
-	router.POST("/api/v1/auth/logout", middlewares.JWTAuth(), controllers.AuthLogout)
+	router.POST("/api/v1/auth/refresh", controllers.AuthRefresh)
+	router.POST("/api/v1/auth/mfa/setup", controllers.AuthMfaSetup)
+	router.POST("/api/v1/auth/mfa/verify", controllers.AuthMfaVerify)
+	router.POST("/api/v1/auth/password/forgot", controllers.ForgotPassword)
+	router.GET("/api/v1/auth/password/token", controllers.CheckPasswordToken)
+	router.POST("/api/v1/auth/password/reset", controllers.ResetPassword)
+	router.GET("/api/v1/auth/password/policy", controllers.GetPasswordPolicy)
+	router.GET("/api/v1/auth/oidc/providers", controllers.GetOidcLoginOptions)
+	router.GET("/api/v1/auth/oidc/callback", controllers.OidcCallback)
+	router.GET("/api/v1/auth/oidc/:id/login", controllers.OidcLogin)
+	router.POST("/api/v1/auth/oidc/exchange", controllers.OidcExchange)
+	router.POST("/api/v1/auth/logout", middlewares.JWTAuth(), middlewares.RequireUser(), controllers.AuthLogout)
+
+	// Integrasi add-in "Report Phishing" mail client (API key, bukan JWT)
+	router.POST("/api/v1/report", middlewares.ReportAPIKeyAuth(), controllers.ReportPhish)
+
+	// Provisioning SCIM 2.0 dari identity provider (bearer token SCIM, bukan JWT)
+	scim := router.Group("/scim/v2")
+	scim.Use(middlewares.ScimTokenAuth())
+	{
+		scim.GET("/ServiceProviderConfig", controllers.GetScimServiceProviderConfig)
+		scim.GET("/ResourceTypes", controllers.GetScimResourceTypes)
+
+		scim.GET("/Users", controllers.ListScimUsers)
+		scim.POST("/Users", controllers.CreateScimUser)
+		scim.GET("/Users/:id", controllers.GetScimUser)
+		scim.PUT("/Users/:id", controllers.ReplaceScimUser)
+		scim.PATCH("/Users/:id", controllers.PatchScimUser)
+		scim.DELETE("/Users/:id", controllers.DeleteScimUser)
+
+		scim.GET("/Groups", controllers.ListScimGroups)
+		scim.POST("/Groups", controllers.CreateScimGroup)
+		scim.GET("/Groups/:id", controllers.GetScimGroup)
+		scim.PUT("/Groups/:id", controllers.ReplaceScimGroup)
+		scim.PATCH("/Groups/:id", controllers.PatchScimGroup)
+		scim.DELETE("/Groups/:id", controllers.DeleteScimGroup)
+	}
 
 	// Protected API routes (dengan JWT middleware,)
+	// Setiap route dilindungi izin role (lihat models.PermissionCatalog), kecuali route self-service
+	// yang hanya menyentuh data milik user sendiri (profil, session, MFA pribadi). API key service account
+	// diterima JWTAuth dan dibatasi scope key; route self-service menolaknya.
 	api := router.Group("/api/v1")
-	api.Use(middlewares.JWTAuth())
+	api.Use(middlewares.JWTAuth(), middlewares.TenantContext())
+	can := middlewares.RequirePermission
+	self := middlewares.RequireUser()
 	{
 		access := api.Group("/access")
 		{
-			access.GET("/permissions", controllers.GetUserAccessPermissions)
+			access.GET("/permissions", self, controllers.GetUserAccessPermissions)                // SELF: MENUS, SUBMENUS, PERMISSIONS
+			access.GET("/permission-catalog", can("role:read"), controllers.GetPermissionCatalog) // ALL PERMISSIONS
 		}
 
 		groups := api.Group("/groups")
 		{
-			groups.POST("/register", controllers.RegisterGroup) // CREATE
-			groups.GET("/all", controllers.GetGroups)           // READ
-			groups.GET("/members/all", controllers.GetMembers)  // READ
-			groups.GET("/:id", controllers.GetGroupDetail)      // DETAIL
-			groups.PUT("/:id", controllers.UpdateGroup)         // UPATE
-			groups.DELETE("/:id", controllers.DeleteGroup)      // DELETE
+			groups.POST("/register", can("group:create"), controllers.RegisterGroup)            // CREATE
+			groups.GET("/all", can("group:read"), controllers.GetGroups)                        // READ
+			groups.GET("/members/all", can("group:read"), controllers.GetMembers)               // READ
+			groups.POST("/preview-rule", can("group:read"), controllers.PreviewGroupRule)       // PREVIEW DYNAMIC GROUP RULE
+			groups.GET("/:id", can("group:read"), controllers.GetGroupDetail)                   // DETAIL
+			groups.PUT("/:id", can("group:update"), controllers.UpdateGroup)                    // UPATE
+			groups.DELETE("/:id", can("group:delete"), controllers.DeleteGroup)                 // DELETE
+			groups.POST("/:id/import", can("group:import"), controllers.ImportGroupMembers)     // IMPORT MEMBERS
+			groups.GET("/:id/export", can("group:export"), controllers.ExportGroupMembers)      // EXPORT MEMBERS
+			groups.GET("/:id/domain-check", can("group:read"), controllers.GetGroupDomainCheck) // VERIFIED DOMAIN CHECK
+
+			groups.GET("/:id/directory-sync", can("directory-sync:read"), controllers.GetGroupDirectorySync)          // READ DIRECTORY SYNC
+			groups.PUT("/:id/directory-sync", can("directory-sync:update"), controllers.UpdateGroupDirectorySync)     // SAVE DIRECTORY SYNC
+			groups.DELETE("/:id/directory-sync", can("directory-sync:update"), controllers.DeleteGroupDirectorySync)  // REMOVE DIRECTORY SYNC
+			groups.POST("/:id/directory-sync/run", can("directory-sync:run"), controllers.RunGroupDirectorySync)      // RUN DIRECTORY SYNC
+			groups.GET("/:id/directory-sync/logs", can("directory-sync:read"), controllers.GetGroupDirectorySyncLogs) // DIRECTORY SYNC HISTORY
+		}
+
+		memberAttributes := api.Group("/member-attributes")
+		{
+			memberAttributes.POST("/create", can("member-attribute:create"), controllers.RegisterMemberAttribute) // CREATE
+			memberAttributes.GET("/all", can("member-attribute:read"), controllers.GetMemberAttributes)           // READ
+			memberAttributes.PUT("/:id", can("member-attribute:update"), controllers.UpdateMemberAttribute)       // UPDATE
+			memberAttributes.DELETE("/:id", can("member-attribute:delete"), controllers.DeleteMemberAttribute)    // DELETE
+		}
+
+		targetDomains := api.Group("/target-domains")
+		{
+			targetDomains.POST("/create", can("target-domain:create"), controllers.RegisterTargetDomain)   // CREATE
+			targetDomains.GET("/all", can("target-domain:read"), controllers.GetTargetDomains)             // READ
+			targetDomains.POST("/:id/verify", can("target-domain:verify"), controllers.VerifyTargetDomain) // VERIFY DNS TXT
+			targetDomains.DELETE("/:id", can("target-domain:delete"), controllers.DeleteTargetDomain)      // DELETE
+		}
+
+		exclusions := api.Group("/exclusions")
+		{
+			exclusions.POST("/create", can("exclusion:create"), controllers.RegisterExclusion) // CREATE
+			exclusions.GET("/all", can("exclusion:read"), controllers.GetExclusions)           // READ
+			exclusions.POST("/check", can("exclusion:read"), controllers.CheckExclusion)       // CHECK EMAIL
+			exclusions.PUT("/:id", can("exclusion:update"), controllers.UpdateExclusion)       // UPDATE
+			exclusions.DELETE("/:id", can("exclusion:delete"), controllers.DeleteExclusion)    // DELETE
+		}
+
+		holidays := api.Group("/holidays")
+		{
+			holidays.POST("/create", can("holiday:create"), controllers.RegisterHoliday) // CREATE
+			holidays.GET("/all", can("holiday:read"), controllers.GetHolidays)           // READ
+			holidays.DELETE("/:id", can("holiday:delete"), controllers.DeleteHoliday)    // DELETE
+		}
+
+		blackouts := api.Group("/blackouts")
+		{
+			blackouts.POST("/create", can("blackout:create"), controllers.RegisterBlackoutWindow) // CREATE
+			blackouts.POST("/import", can("blackout:create"), controllers.ImportBlackoutWindows)  // IMPORT ICALENDAR
+			blackouts.GET("/all", can("blackout:read"), controllers.GetBlackoutWindows)           // READ
+			blackouts.PUT("/:id", can("blackout:update"), controllers.UpdateBlackoutWindow)       // UPDATE
+			blackouts.DELETE("/:id", can("blackout:delete"), controllers.DeleteBlackoutWindow)    // DELETE
+		}
+
+		people := api.Group("/people")
+		{
+			people.GET("/all", can("person:read"), controllers.GetPeople)                             // READ
+			people.GET("/:id", can("person:read"), controllers.GetPersonDetail)                       // DETAIL + HISTORY
+			people.POST("/:id/trainings", can("person:update"), controllers.CreateTrainingCompletion) // RECORD TRAINING COMPLETION
 		}
 
 		users := api.Group("/users")
 		{
-			users.POST("/session", controllers.GetUserSession)
-			users.POST("/register", controllers.RegisterUser) // CREATE
-			users.GET("/all", controllers.GetUsers)           // READ
-			users.PUT("/:id", controllers.UpdateUser)         // UPDATE
-			users.DELETE("/:id", controllers.DeleteUser)      // DELETE
+			users.POST("/session", self, controllers.GetUserSession)                // SELF
+			users.POST("/register", can("user:create"), controllers.RegisterUser)   // CREATE
+			users.POST("/invite", can("user:invite"), controllers.InviteUser)       // INVITE BY EMAIL
+			users.POST("/:id/invite", can("user:invite"), controllers.ResendInvite) // RESEND INVITE
+			users.GET("/all", can("user:read"), controllers.GetUsers)               // READ
+			users.PUT("/:id", can("user:update"), controllers.UpdateUser)           // UPDATE
+			users.DELETE("/:id", can("user:delete"), controllers.DeleteUser)        // DELETE
 		}
 
 		roles := api.Group("/user-roles")
 		{
-			roles.GET("/all", controllers.GetRoles)         // READ
-			roles.POST("/create", controllers.RegisterRole) // CREATE
-			roles.PUT("/:id", controllers.UpdateRole)       // UPDATE
-			roles.DELETE("/:id", controllers.DeleteRole)    // DELETE
+			roles.GET("/all", can("role:read"), controllers.GetRoles)                            // READ
+			roles.POST("/create", can("role:create"), controllers.RegisterRole)                  // CREATE
+			roles.PUT("/:id", can("role:update"), controllers.UpdateRole)                        // UPDATE
+			roles.DELETE("/:id", can("role:delete"), controllers.DeleteRole)                     // DELETE
+			roles.GET("/:id/permissions", can("role:read"), controllers.GetRolePermissions)      // READ PERMISSIONS
+			roles.PUT("/:id/permissions", can("role:update"), controllers.UpdateRolePermissions) // REPLACE PERMISSIONS
+			roles.GET("/:id/access", can("role:read"), controllers.GetRoleAccess)                // READ MENU, SUBMENU & PERMISSIONS
+			roles.PUT("/:id/access", can("role:update"), controllers.UpdateRoleAccess)           // BULK ASSIGN
+		}
+
+		organizations := api.Group("/organizations")
+		{
+			organizations.GET("/current", self, controllers.GetCurrentOrganization)                     // SELF
+			organizations.POST("/create", can("organization:create"), controllers.RegisterOrganization) // CREATE
+			organizations.GET("/all", can("organization:read"), controllers.GetOrganizations)           // READ (LINTAS TENANT)
+			organizations.PUT("/:id", can("organization:update"), controllers.UpdateOrganization)       // UPDATE
+			organizations.DELETE("/:id", can("organization:delete"), controllers.DeleteOrganization)    // DELETE
+		}
+
+		menus := api.Group("/menus")
+		{
+			menus.POST("/create", can("menu:create"), controllers.RegisterMenu) // CREATE
+			menus.GET("/all", can("menu:read"), controllers.GetMenus)           // READ
+			menus.PUT("/:id", can("menu:update"), controllers.UpdateMenu)       // UPDATE
+			menus.DELETE("/:id", can("menu:delete"), controllers.DeleteMenu)    // DELETE
+		}
+
+		submenus := api.Group("/submenus")
+		{
+			submenus.POST("/create", can("menu:create"), controllers.RegisterSubmenu) // CREATE
+			submenus.GET("/all", can("menu:read"), controllers.GetSubmenus)           // READ
+			submenus.PUT("/:id", can("menu:update"), controllers.UpdateSubmenu)       // UPDATE
+			submenus.DELETE("/:id", can("menu:delete"), controllers.DeleteSubmenu)    // DELETE
+		}
+
+		roleMenuAccess := api.Group("/role-menu-access")
+		{
+			roleMenuAccess.GET("/all", can("role:read"), controllers.GetRoleMenuAccess)          // READ
+			roleMenuAccess.POST("/create", can("role:update"), controllers.CreateRoleMenuAccess) // GRANT
+			roleMenuAccess.DELETE("/:id", can("role:update"), controllers.DeleteRoleMenuAccess)  // REVOKE
+		}
+
+		roleSubmenuAccess := api.Group("/role-submenu-access")
+		{
+			roleSubmenuAccess.GET("/all", can("role:read"), controllers.GetRoleSubmenuAccess)          // READ
+			roleSubmenuAccess.POST("/create", can("role:update"), controllers.CreateRoleSubmenuAccess) // GRANT
+			roleSubmenuAccess.DELETE("/:id", can("role:update"), controllers.DeleteRoleSubmenuAccess)  // REVOKE
 		}
 
 		emailTemplate := api.Group("/email-template")
 		{
-			emailTemplate.POST("/create", controllers.RegisterEmailTemplate)    // CREATE
-			emailTemplate.GET("/all", controllers.GetEmailTemplates)            // READ
-			emailTemplate.GET("/default", controllers.GetDefaultEmailTemplates) // GET DEFAULT EMAIL TEMPLATES
-			emailTemplate.PUT("/:id", controllers.UpdateEmailTemplate)          // UPDATE
-			emailTemplate.DELETE("/:id", controllers.DeleteEmailTemplate)       // DELETE
+			emailTemplate.POST("/create", can("email-template:create"), controllers.RegisterEmailTemplate)                  // CREATE
+			emailTemplate.GET("/all", can("email-template:read"), controllers.GetEmailTemplates)                            // READ
+			emailTemplate.GET("/default", can("email-template:read"), controllers.GetDefaultEmailTemplates)                 // GET DEFAULT EMAIL TEMPLATES
+			emailTemplate.PUT("/:id", can("email-template:update"), controllers.UpdateEmailTemplate)                        // UPDATE
+			emailTemplate.DELETE("/:id", can("email-template:delete"), controllers.DeleteEmailTemplate)                     // DELETE
+			emailTemplate.GET("/attachments/:id", can("email-template:read"), controllers.GetEmailTemplateAttachments)      // READ ATTACHMENTS
+			emailTemplate.PUT("/attachments/:id", can("email-template:update"), controllers.UpdateEmailTemplateAttachments) // UPDATE ATTACHMENTS
 		}
 
 		landingPage := api.Group("/landing-page")
 		{
-			landingPage.POST("/create", controllers.RegisterLandingPage)    // CREATE
-			landingPage.GET("/all", controllers.GetLandingPages)            // READ
-			landingPage.GET("/default", controllers.GetDefaultLandingPages) // GET DEFAULT LANDING PAGE
-			landingPage.PUT("/:id", controllers.UpdateLandingPage)          // UPDATE
-			landingPage.DELETE("/:id", controllers.DeleteLandingPage)       // DELETE
-			landingPage.POST("/clone-site", controllers.CloneSite)          // CLONE SITE
+			landingPage.POST("/create", can("landing-page:create"), controllers.RegisterLandingPage)  // CREATE
+			landingPage.GET("/all", can("landing-page:read"), controllers.GetLandingPages)            // READ
+			landingPage.GET("/default", can("landing-page:read"), controllers.GetDefaultLandingPages) // GET DEFAULT LANDING PAGE
+			landingPage.PUT("/:id", can("landing-page:update"), controllers.UpdateLandingPage)        // UPDATE
+			landingPage.DELETE("/:id", can("landing-page:delete"), controllers.DeleteLandingPage)     // DELETE
+			landingPage.POST("/clone-site", can("landing-page:create"), controllers.CloneSite)        // CLONE SITE
 		}
 
 		sendingprofiles := api.Group("/sending-profile")
 		{
-			sendingprofiles.POST("/send-test-email", controllers.SendTestEmail)                // SEND TEST EMAIL
-			sendingprofiles.POST("/create", controllers.RegisterSendingProfile)                // CREATE
-			sendingprofiles.GET("/all", controllers.GetSendingProfiles)                        // READ
-			sendingprofiles.PUT("/:id", controllers.UpdateSendingProfile)                      // UPDATE
-			sendingprofiles.PUT("/email-header/:id", controllers.UpdateEmailHeadersForProfile) // UPDATE
-			sendingprofiles.GET("/email-header/:id", controllers.GetEmailHeaderDetail)         // DETAIL
-			sendingprofiles.DELETE("/:id", controllers.DeleteSendingProfile)                   // DELETE
+			sendingprofiles.POST("/send-test-email", can("sending-profile:test"), controllers.SendTestEmail)                  // SEND TEST EMAIL
+			sendingprofiles.POST("/create", can("sending-profile:create"), controllers.RegisterSendingProfile)                // CREATE
+			sendingprofiles.GET("/all", can("sending-profile:read"), controllers.GetSendingProfiles)                          // READ
+			sendingprofiles.PUT("/:id", can("sending-profile:update"), controllers.UpdateSendingProfile)                      // UPDATE
+			sendingprofiles.PUT("/email-header/:id", can("sending-profile:update"), controllers.UpdateEmailHeadersForProfile) // UPDATE
+			sendingprofiles.GET("/email-header/:id", can("sending-profile:read"), controllers.GetEmailHeaderDetail)           // DETAIL
+			sendingprofiles.DELETE("/:id", can("sending-profile:delete"), controllers.DeleteSendingProfile)                   // DELETE
+			sendingprofiles.POST("/:id/diagnose", can("sending-profile:test"), controllers.DiagnoseSendingProfile)            // DIAGNOSE SMTP
+			sendingprofiles.GET("/:id/secret", can("sending-profile:read-secret"), controllers.RevealSendingProfileSecret)    // REVEAL SMTP PASSWORD
 
 		}
 
+		directoryProfiles := api.Group("/directory-profile")
+		{
+			directoryProfiles.POST("/create", can("directory-profile:create"), controllers.RegisterDirectoryProfile) // CREATE
+			directoryProfiles.GET("/all", can("directory-profile:read"), controllers.GetDirectoryProfiles)           // READ
+			directoryProfiles.PUT("/:id", can("directory-profile:update"), controllers.UpdateDirectoryProfile)       // UPDATE
+			directoryProfiles.DELETE("/:id", can("directory-profile:delete"), controllers.DeleteDirectoryProfile)    // DELETE
+			directoryProfiles.POST("/:id/test", can("directory-profile:test"), controllers.TestDirectoryProfile)     // TEST CONNECTION
+		}
+
+		scimTokens := api.Group("/scim-tokens")
+		{
+			scimTokens.POST("/create", can("scim-token:create"), controllers.CreateScimToken) // CREATE
+			scimTokens.GET("/all", can("scim-token:read"), controllers.GetScimTokens)         // READ
+			scimTokens.DELETE("/:id", can("scim-token:delete"), controllers.RevokeScimToken)  // REVOKE
+		}
+
+		serviceAccounts := api.Group("/service-accounts")
+		{
+			serviceAccounts.POST("/create", can("service-account:create"), controllers.RegisterServiceAccount)  // CREATE
+			serviceAccounts.GET("/all", can("service-account:read"), controllers.GetServiceAccounts)            // READ (BESERTA API KEY)
+			serviceAccounts.PUT("/:id", can("service-account:update"), controllers.UpdateServiceAccount)        // UPDATE / NONAKTIFKAN
+			serviceAccounts.DELETE("/:id", can("service-account:delete"), controllers.DeleteServiceAccount)     // DELETE
+			serviceAccounts.POST("/:id/keys", can("service-account:update"), controllers.CreateApiKey)          // ISSUE API KEY
+			serviceAccounts.DELETE("/:id/keys/:keyId", can("service-account:update"), controllers.RevokeApiKey) // REVOKE API KEY
+		}
+
 		profiles := api.Group("/profiles")
 		{
-			profiles.POST("/update", controllers.UpdateProfile)                     // UPDATE PROFILE
-			profiles.GET("/phish-settings", controllers.GetPhishSettings)           // READ PHISH SETTINGS
-			profiles.PUT("/update/phish-settings", controllers.UpdatePhishSettings) // UPDATE PHISH SETTINGS
+			profiles.POST("/update", self, controllers.UpdateProfile)                     // SELF: UPDATE PROFILE
+			profiles.GET("/phish-settings", self, controllers.GetPhishSettings)           // SELF: READ PHISH SETTINGS
+			profiles.PUT("/update/phish-settings", self, controllers.UpdatePhishSettings) // SELF: UPDATE PHISH SETTINGS
+		}
+
+		sessions := api.Group("/sessions")
+		{
+			sessions.GET("/all", self, controllers.GetMySessions)      // SELF: READ MY ACTIVE SESSIONS
+			sessions.DELETE("/:id", self, controllers.RevokeMySession) // SELF: REVOKE ONE
+			sessions.DELETE("", self, controllers.RevokeMySessions)    // SELF: REVOKE ALL (OTHERS)
+		}
+
+		api.PUT("/password-policy", can("password-policy:update"), controllers.UpdatePasswordPolicy) // UPDATE PASSWORD POLICY
+
+		oidcProviders := api.Group("/oidc-providers")
+		{
+			oidcProviders.POST("/create", can("sso-provider:create"), controllers.RegisterOidcProvider) // CREATE
+			oidcProviders.GET("/all", can("sso-provider:read"), controllers.GetOidcProviders)           // READ
+			oidcProviders.PUT("/:id", can("sso-provider:update"), controllers.UpdateOidcProvider)       // UPDATE
+			oidcProviders.DELETE("/:id", can("sso-provider:delete"), controllers.DeleteOidcProvider)    // DELETE
+			oidcProviders.POST("/:id/test", can("sso-provider:test"), controllers.TestOidcProvider)     // TEST DISCOVERY
+		}
+
+		api.GET("/auth-settings", can("auth-settings:read"), controllers.GetAuthSettings)      // READ AUTH SETTINGS
+		api.PUT("/auth-settings", can("auth-settings:update"), controllers.UpdateAuthSettings) // UPDATE AUTH SETTINGS (DISABLE PASSWORD LOGIN)
+
+		loginLocks := api.Group("/login-locks")
+		{
+			loginLocks.GET("/all", can("login-lock:read"), controllers.GetLoginLocks)    // READ
+			loginLocks.DELETE("/:id", can("login-lock:unlock"), controllers.UnlockLogin) // UNLOCK
+		}
+
+		mfa := api.Group("/mfa")
+		{
+			mfa.GET("/status", self, controllers.GetMfaStatus)                        // SELF: READ MY MFA STATUS
+			mfa.POST("/enroll", self, controllers.EnrollMfa)                          // SELF: START ENROLLMENT (QR)
+			mfa.POST("/activate", self, controllers.ActivateMfa)                      // SELF: VERIFY FIRST CODE
+			mfa.POST("/recovery-codes", self, controllers.RegenerateMfaRecoveryCodes) // SELF: REGENERATE RECOVERY CODES
+			mfa.POST("/disable", self, controllers.DisableMfa)                        // SELF: DISABLE
+			mfa.DELETE("/users/:id", can("mfa:reset"), controllers.ResetUserMfa)      // ADMIN RESET
+			mfa.GET("/policy", can("mfa-policy:read"), controllers.GetMfaPolicy)      // READ ROLE POLICY
+			mfa.PUT("/policy", can("mfa-policy:update"), controllers.UpdateMfaPolicy) // UPDATE ROLE POLICY
 		}
 
 		analytics := api.Group("/analytics")
 		{
-			analytics.GET("/growth-percentage", controllers.GetGrowthPercentage)
-			analytics.GET("/dashboard-metrics", controllers.GetDashboardMetrics)
+			analytics.GET("/growth-percentage", can("dashboard:read"), controllers.GetGrowthPercentage)
+			analytics.GET("/dashboard-metrics", can("dashboard:read"), controllers.GetDashboardMetrics)
 		}
 
 		activityLogs := api.Group("/activity-logs")
 		{
-			activityLogs.GET("/all", controllers.GetActivityLogs) // READ
+			activityLogs.GET("/all", can("activity-log:read"), controllers.GetActivityLogs) // READ
 		}
 
 		campaigns := api.Group("/campaigns")
 		{
-			campaigns.POST("/create", controllers.RegisterCampaign)
-			campaigns.GET("/all", controllers.GetCampaigns)
-			campaigns.GET("/role-scope-parent/all", controllers.GetCampaignsRoleScopeParent)
-			campaigns.GET("/:id", controllers.GetCampaignDetail)
-			campaigns.PUT("/:id", controllers.UpdateCampaign)
-			campaigns.DELETE("/:id", controllers.DeleteCampaign)
+			campaigns.POST("/create", can("campaign:create"), controllers.RegisterCampaign)
+			campaigns.GET("/all", can("campaign:read"), controllers.GetCampaigns)
+			campaigns.GET("/role-scope-parent/all", can("campaign:read"), controllers.GetCampaignsRoleScopeParent)
+			campaigns.GET("/:id", can("campaign:read"), controllers.GetCampaignDetail)
+			campaigns.GET("/:id/group-snapshot", can("campaign:read"), controllers.GetCampaignGroupSnapshot)
+			campaigns.GET("/:id/breakdown", can("campaign:read"), controllers.GetCampaignBreakdown)
+			campaigns.PUT("/:id", can("campaign:update"), controllers.UpdateCampaign)
+			campaigns.DELETE("/:id", can("campaign:delete"), controllers.DeleteCampaign)
 		}
 
 	}
@@ -119,6 +335,8 @@ func SetupRoutes(router *gin.Engine) {
 		track.GET("/click", controllers.HandleClickTracker)
 		track.POST("/submit", controllers.HandleSubmitTracker)
 		track.GET("/report", controllers.HandleReportTracker)
+		track.GET("/attachment", controllers.TrackAttachment)
+		track.GET("/qr/:rid", controllers.HandleQRScan)
 	}
 
 	// SHOW LANDING PAGE REDIRECT FROM EMAIL
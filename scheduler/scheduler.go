@@ -26,7 +26,23 @@ func StartCampaignDispatcher() {
 				Preload("SendingProfile").
 				Where("status = ? AND launch_date <= ? AND (send_email_by IS NULL OR send_email_by >= ?)", "pending", now, now).Find(&campaigns)
 
+			// Selama blackout organisasi berlangsung campaign organisasi itu yang jatuh tempo tetap pending (ditunda)
+			inBlackout := map[uint]bool{}
 			for _, camp := range campaigns {
+				paused, checked := inBlackout[camp.OrganizationID]
+				if !checked {
+					blackouts, err := services.LoadBlackoutCalendar(config.DB, camp.OrganizationID, now)
+					if err != nil {
+						log.Printf("Failed to load blackout calendar for organization %d: %v", camp.OrganizationID, err)
+					}
+					// Kalender yang gagal dimuat menunda campaign ke tick berikutnya
+					paused = err != nil || blackouts.Active(now) != nil
+					inBlackout[camp.OrganizationID] = paused
+				}
+				if paused {
+					continue
+				}
+
 				// tandai in_progress agar tidak di-pick lagi
 				config.DB.Model(&camp).Update("status", "in progress")
 
@@ -39,3 +55,62 @@ func StartCampaignDispatcher() {
 		}
 	}()
 }
+
+// StartRecipientDispatcher mengirim email recipient berstatus scheduled (delivery local_time/business_hours)
+// saat slot pengiriman di zona waktu mereka tiba.
+func StartRecipientDispatcher() {
+	log.Println("Starting Recipient Dispatcher...")
+	ticker := time.NewTicker(1 * time.Minute)
+	go func() {
+		for range ticker.C {
+			now := time.Now()
+			// Recipient yang jatuh tempo selama blackout organisasinya dijadwalkan ulang oleh
+			// SendEmailToRecipient sampai blackout berakhir
+			due, err := services.ClaimDueRecipients(config.DB, now)
+			if err != nil {
+				log.Printf("Failed to claim scheduled recipients: %v", err)
+			}
+
+			campaigns := map[uint]*models.Campaign{}
+			for _, rec := range due {
+				camp, loaded := campaigns[rec.CampaignID]
+				if !loaded {
+					var c models.Campaign
+					err := config.DB.
+						Preload("EmailTemplate").
+						Preload("LandingPage").
+						Preload("SendingProfile").
+						First(&c, rec.CampaignID).Error
+					if err != nil {
+						log.Printf("Failed to load campaign %d for scheduled recipient %d: %v", rec.CampaignID, rec.ID, err)
+						config.DB.Model(&rec).Updates(models.Recipient{Status: "failed", Error: "Failed to load campaign: " + err.Error()})
+						continue
+					}
+					camp = &c
+					campaigns[rec.CampaignID] = camp
+				}
+				go services.SendEmailToRecipient(rec, *camp)
+			}
+		}
+	}()
+}
+
+// StartDirectorySync menjalankan sinkronisasi LDAP/AD untuk group yang jadwalnya sudah jatuh tempo.
+func StartDirectorySync() {
+	log.Println("Starting Directory Sync Watcher...")
+	ticker := time.NewTicker(1 * time.Minute)
+	go func() {
+		for range ticker.C {
+			due, err := services.DueDirectorySyncs(config.DB, time.Now())
+			if err != nil {
+				log.Printf("Failed to load directory syncs: %v", err)
+				continue
+			}
+			for _, cfg := range due {
+				if _, err := services.SyncGroupDirectory(config.DB, cfg.GroupID, "scheduled", false); err != nil && err != services.ErrDirectorySyncRunning {
+					log.Printf("Directory sync for group %d failed: %v", cfg.GroupID, err)
+				}
+			}
+		}
+	}()
+}
@@ -22,6 +22,15 @@ func LogActivity(db *gorm.DB, c *gin.Context, action, moduleName, recordID strin
 		}
 	}
 
+	// Request API key: pelaku aksi adalah service account, bukan user
+	serviceAccountID := ActingServiceAccountID(c)
+	var apiKeyID *uint
+	if key, exists := c.Get("apiKey"); exists {
+		if k, ok := key.(*models.ApiKey); ok {
+			apiKeyID = &k.ID
+		}
+	}
+
 	if userID == 0 && action == "Login" {
 		userIDInt, _ := strconv.Atoi(recordID)
 		userID = uint(userIDInt)
@@ -45,17 +54,19 @@ func LogActivity(db *gorm.DB, c *gin.Context, action, moduleName, recordID strin
 	}
 
 	logEntry := models.ActivityLog{
-		UserID:     userID,
-		Action:     action,
-		ModuleName: moduleName,
-		RecordID:   recordID,
-		OldValue:   string(oldValueJSON),
-		NewValue:   string(newValueJSON),
-		Status:     status,  // Set status aksi
-		Message:    message, // Set pesan kesalahan
-		IPAddress:  ipAddress,
-		UserAgent:  userAgent,
-		Timestamp:  time.Now(),
+		UserID:           userID,
+		ServiceAccountID: serviceAccountID,
+		ApiKeyID:         apiKeyID,
+		Action:           action,
+		ModuleName:       moduleName,
+		RecordID:         recordID,
+		OldValue:         string(oldValueJSON),
+		NewValue:         string(newValueJSON),
+		Status:           status,  // Set status aksi
+		Message:          message, // Set pesan kesalahan
+		IPAddress:        ipAddress,
+		UserAgent:        userAgent,
+		Timestamp:        time.Now(),
 	}
 
 	// Simpan log ke database
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrApiKeyInvalid = errors.New("invalid, expired or revoked API key")
+	ErrApiKeyScope   = errors.New("scope cannot be granted to an API key")
+)
+
+// Resource yang tidak boleh menjadi scope API key: service account tidak bisa membuat key baru
+// untuk dirinya sendiri dan selalu terikat ke satu organisasi.
+var apiKeyForbiddenResources = map[string]bool{"service-account": true, "organization": true}
+
+// HashApiKey mengembalikan hash SHA-256 (hex) yang disimpan di ApiKey.TokenHash.
+func HashApiKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateApiKey membuat key acak berawalan models.ApiKeyPrefix. Hanya hash dan prefix-nya yang disimpan.
+func GenerateApiKey() (plain, hash, prefix string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", "", err
+	}
+	plain = models.ApiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return plain, HashApiKey(plain), plain[:12], nil
+}
+
+// NormalizeApiKeyScopes memvalidasi scope yang diminta: harus izin yang dikenal, bukan resource
+// terlarang, dan dimiliki role pembuat key (tidak boleh memberi izin melebihi miliknya).
+// Mengembalikan scope unik terurut, dipisah spasi.
+func NormalizeApiKeyScopes(db *gorm.DB, creatorRoleID uint, scopes []string) (string, error) {
+	held, err := RolePermissionKeys(db, creatorRoleID)
+	if err != nil {
+		return "", err
+	}
+	holds := map[string]bool{}
+	for _, key := range held {
+		holds[key] = true
+	}
+
+	unique := map[string]bool{}
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		resource, _, _ := strings.Cut(scope, ":")
+		switch {
+		case !models.IsKnownPermission(scope):
+			return "", fmt.Errorf("%w: unknown permission %q", ErrApiKeyScope, scope)
+		case apiKeyForbiddenResources[resource]:
+			return "", fmt.Errorf("%w: %q", ErrApiKeyScope, scope)
+		case !holds[scope]:
+			return "", fmt.Errorf("%w: you do not hold %q", ErrApiKeyScope, scope)
+		}
+		unique[scope] = true
+	}
+
+	result := make([]string, 0, len(unique))
+	for scope := range unique {
+		result = append(result, scope)
+	}
+	sort.Strings(result)
+	return strings.Join(result, " "), nil
+}
+
+// AuthenticateApiKey mencari key aktif (belum dicabut/kedaluwarsa, service account aktif) dan
+// mencatat waktu serta IP pemakaian terakhir.
+func AuthenticateApiKey(db *gorm.DB, plain, clientIP string) (*models.ApiKey, *models.ServiceAccount, error) {
+	now := time.Now()
+	var key models.ApiKey
+	err := db.Where("token_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", HashApiKey(plain), now).
+		First(&key).Error
+	if err != nil {
+		return nil, nil, ErrApiKeyInvalid
+	}
+	var account models.ServiceAccount
+	if err := db.Where("is_active = 1").First(&account, key.ServiceAccountID).Error; err != nil {
+		return nil, nil, ErrApiKeyInvalid
+	}
+
+	db.Model(&key).UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": clientIP})
+	key.LastUsedAt = &now
+	key.LastUsedIP = clientIP
+	return &key, &account, nil
+}
+
+// ServiceAccountPrincipal adalah user pengganti di context untuk request API key: ID 0 (bukan user
+// manusia), tanpa role, dalam organisasi service account. Izin diperiksa dari scope key.
+func ServiceAccountPrincipal(account *models.ServiceAccount) *models.User {
+	return &models.User{
+		Name:           account.Name,
+		OrganizationID: account.OrganizationID,
+		IsActive:       1,
+	}
+}
+
+// ActingServiceAccountID mengembalikan ID service account pelaku request API key, atau nil untuk request user.
+// Record yang dibuat lewat API key menyimpannya karena CreatedBy dari ServiceAccountPrincipal selalu 0.
+func ActingServiceAccountID(c *gin.Context) *uint {
+	if account, exists := c.Get("serviceAccount"); exists {
+		if sa, ok := account.(*models.ServiceAccount); ok {
+			return &sa.ID
+		}
+	}
+	return nil
+}
+
+// CreatorName mengembalikan nama user pembuat record, atau nama service account jika record dibuat lewat API key.
+func CreatorName(db *gorm.DB, userID int, serviceAccountID *uint) string {
+	if userID != 0 {
+		var user models.User
+		if err := db.Select("name").First(&user, userID).Error; err == nil {
+			return user.Name
+		}
+	}
+	if serviceAccountID != nil {
+		var account models.ServiceAccount
+		if err := db.Select("name").First(&account, *serviceAccountID).Error; err == nil {
+			return account.Name
+		}
+	}
+	return ""
+}
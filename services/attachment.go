@@ -0,0 +1,300 @@
+package services
+
+import (
+	"archive/zip"
+	"be-awarenix/models"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// LandingPageURL membangun URL landing page frontend untuk recipient.
+func LandingPageURL(rid string, campaignID, landingPageID uint) string {
+	frontendDomain := "localhost:5173"
+	return fmt.Sprintf(
+		"http://%s/lander?rid=%s&campaign=%d&page=%d",
+		frontendDomain, rid, campaignID, landingPageID,
+	)
+}
+
+// AttachmentTrackingURL membangun URL tracking attachment. Jika redirect true,
+// endpoint mengarahkan ke landing page setelah mencatat event (dipakai link di PDF).
+func AttachmentTrackingURL(rid string, attachmentID uint, redirect bool) string {
+	backendBase := "localhost:3000"
+	q := url.Values{}
+	q.Set("rid", rid)
+	q.Set("att", fmt.Sprint(attachmentID))
+	if redirect {
+		q.Set("go", "1")
+	}
+	return fmt.Sprintf("http://%s/track/attachment?%s", backendBase, q.Encode())
+}
+
+// BuildAttachment membangkitkan file attachment untuk satu recipient.
+// Title dan Content dirender sebagai template dengan data yang sama seperti body email.
+func BuildAttachment(att models.EmailAttachment, rid string, data map[string]interface{}) (string, []byte, error) {
+	title, err := renderAttachmentText(att.Title, data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid attachment title template: %w", err)
+	}
+	// Content HTML dirender dengan html/template agar data recipient di-escape sesuai konteks;
+	// judulnya di-escape sendiri oleh buildHTMLAttachment.
+	renderContent := renderAttachmentText
+	if att.Type == models.AttachmentTypeHTML {
+		renderContent = renderAttachmentHTML
+	}
+	content, err := renderContent(att.Content, data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid attachment content template: %w", err)
+	}
+
+	fileName := att.FileName
+	if !strings.HasSuffix(strings.ToLower(fileName), "."+att.Type) {
+		fileName += "." + att.Type
+	}
+
+	beaconURL := AttachmentTrackingURL(rid, att.ID, false)
+	linkURL := AttachmentTrackingURL(rid, att.ID, true)
+
+	switch att.Type {
+	case models.AttachmentTypeHTML:
+		return fileName, buildHTMLAttachment(title, content, beaconURL), nil
+	case models.AttachmentTypePDF:
+		linkText := att.LinkText
+		if linkText == "" {
+			linkText = "Open the full document"
+		}
+		return fileName, buildPDFAttachment(title, content, linkText, linkURL), nil
+	case models.AttachmentTypeDOCX:
+		file, err := buildDOCXAttachment(title, content, beaconURL)
+		return fileName, file, err
+	default:
+		return "", nil, fmt.Errorf("unsupported attachment type %q", att.Type)
+	}
+}
+
+func renderAttachmentText(text string, data map[string]interface{}) (string, error) {
+	tpl, err := template.New("attachment").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func renderAttachmentHTML(text string, data map[string]interface{}) (string, error) {
+	tpl, err := htmltemplate.New("attachment").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// buildHTMLAttachment menyisipkan beacon gambar ke dokumen HTML.
+func buildHTMLAttachment(title, content, beaconURL string) []byte {
+	beacon := fmt.Sprintf(`<img src="%s" width="1" height="1" alt="" style="display:none"/>`, beaconURL)
+
+	lower := strings.ToLower(content)
+	if idx := strings.LastIndex(lower, "</body>"); idx >= 0 {
+		return []byte(content[:idx] + beacon + content[idx:])
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>")
+	xml.EscapeText(&buf, []byte(title))
+	buf.WriteString("</title></head><body>")
+	buf.WriteString(content)
+	buf.WriteString(beacon)
+	buf.WriteString("</body></html>")
+	return buf.Bytes()
+}
+
+// buildPDFAttachment membuat PDF satu halaman berisi judul, paragraf teks
+// dan link annotation menuju linkURL.
+func buildPDFAttachment(title, content, linkText, linkURL string) []byte {
+	const (
+		left     = 50
+		top      = 790
+		bottom   = 120
+		leading  = 16
+		maxChars = 90
+	)
+
+	var stream bytes.Buffer
+	fmt.Fprintf(&stream, "BT /F2 16 Tf %d %d Td (%s) Tj ET\n", left, top, pdfEscape(title))
+
+	y := top - 30
+	for _, line := range wrapText(content, maxChars) {
+		if y < bottom {
+			break
+		}
+		if line != "" {
+			fmt.Fprintf(&stream, "BT /F1 11 Tf %d %d Td (%s) Tj ET\n", left, y, pdfEscape(line))
+		}
+		y -= leading
+	}
+
+	// Link berwarna biru dan bergaris bawah
+	y -= leading
+	linkWidth := int(float64(len(linkText)) * 6.7)
+	fmt.Fprintf(&stream, "0 0 1 rg BT /F2 12 Tf %d %d Td (%s) Tj ET\n", left, y, pdfEscape(linkText))
+	fmt.Fprintf(&stream, "0 0 1 RG 0.8 w %d %d m %d %d l S\n", left, y-2, left+linkWidth, y-2)
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R /Annots [7 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", stream.Len(), stream.String()),
+		fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%d %d %d %d] /Border [0 0 0] /A << /S /URI /URI (%s) >> >>",
+			left, y-4, left+linkWidth, y+12, pdfEscape(linkURL)),
+		fmt.Sprintf("<< /Title (%s) >>", pdfEscape(title)),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, len(objects), xrefOffset)
+	return buf.Bytes()
+}
+
+// pdfEscape meng-escape string literal PDF; karakter di luar ASCII diganti "?".
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// wrapText memecah teks per paragraf lalu membungkus baris pada batas kata.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := words[0]
+		for _, w := range words[1:] {
+			if len(line)+1+len(w) > width {
+				lines = append(lines, line)
+				line = w
+				continue
+			}
+			line += " " + w
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// buildDOCXAttachment membuat dokumen Word dengan dua beacon eksternal:
+// remote template (attachedTemplate) dan gambar tertaut yang dimuat saat dokumen dibuka.
+func buildDOCXAttachment(title, content, beaconURL string) ([]byte, error) {
+	esc := func(s string) string {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, `<w:p><w:r><w:rPr><w:b/><w:sz w:val="32"/></w:rPr><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, esc(title))
+	for _, para := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(&body, `<w:p><w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, esc(para))
+	}
+	body.WriteString(`<w:p><w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">` +
+		`<wp:extent cx="9525" cy="9525"/><wp:docPr id="1" name="Picture 1"/>` +
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+		`<pic:pic><pic:nvPicPr><pic:cNvPr id="1" name="Picture 1"/><pic:cNvPicPr/></pic:nvPicPr>` +
+		`<pic:blipFill><a:blip r:link="rIdBeacon"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>` +
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="9525" cy="9525"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>` +
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>`)
+
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+			`<Override PartName="/word/settings.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"/>` +
+			`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+			`</Relationships>`},
+		{"docProps/core.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+			`<dc:title>` + esc(title) + `</dc:title></cp:coreProperties>`},
+		{"word/_rels/document.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rIdSettings" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings" Target="settings.xml"/>` +
+			`<Relationship Id="rIdBeacon" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="` + esc(beaconURL) + `" TargetMode="External"/>` +
+			`</Relationships>`},
+		{"word/settings.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<w:attachedTemplate r:id="rIdTemplate"/></w:settings>`},
+		{"word/_rels/settings.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rIdTemplate" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/attachedTemplate" Target="` + esc(beaconURL) + `" TargetMode="External"/>` +
+			`</Relationships>`},
+		{"word/document.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+			` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"` +
+			` xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"` +
+			` xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"` +
+			` xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+			`<w:body>` + body.String() + `</w:body></w:document>`},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(f.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -2,11 +2,18 @@ package services
 
 import (
 	"be-awarenix/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -16,8 +23,23 @@ func ComparePassword(hash, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// ComparePasswordDummy menjalankan bcrypt terhadap hash dummy untuk email yang tidak terdaftar,
+// sehingga waktu respons login tidak membedakan akun yang ada dan yang tidak.
+func ComparePasswordDummy(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
+
 // GenerateJWT membuat JWT signed dengan HS256
-func GenerateJWT(userID uint, email string, status string) (string, int64, error) {
+// sessionUID disimpan pada claim "jti" agar access token terikat ke UserSession dan bisa dicabut.
+func GenerateJWT(userID uint, email string, status string, sessionUID string) (string, int64, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		return "", 0, fmt.Errorf("JWT_SECRET not set")
@@ -36,6 +58,7 @@ func GenerateJWT(userID uint, email string, status string) (string, int64, error
 		"email": email,
 		"iat":   time.Now().Unix(),
 		"exp":   expTime,
+		"jti":   sessionUID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -47,72 +70,205 @@ func GenerateJWT(userID uint, email string, status string) (string, int64, error
 	return signedToken, expTime, nil
 }
 
-func GenerateRefreshToken(userID uint) (string, int64, error) {
-	secret := os.Getenv("REFRESH_SECRET") // Gunakan secret terpisah untuk refresh token
-	if secret == "" {
-		return "", 0, fmt.Errorf("REFRESH_SECRET not set")
-	}
+// Masa berlaku refresh token. Session KeepMeLoggedIn berlaku lebih lama; batas absolut session
+// tidak diperpanjang oleh refresh.
+const (
+	refreshTokenTTL         = 7 * 24 * time.Hour
+	refreshTokenTTLKeep     = 30 * 24 * time.Hour
+	sessionMaxLifetime      = 30 * 24 * time.Hour
+	RefreshTokenCookieName  = "refresh_token"
+	RefreshTokenCookiePath  = "/api/v1/auth"
+	refreshTokenPlainPrefix = "rt_"
+)
 
-	exp := 7 * 24 * time.Hour // Refresh token berlaku 7 hari (contoh, bisa disesuaikan)
-	expTime := time.Now().Add(exp).Unix()
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionInvalid      = errors.New("session has been revoked or expired")
+)
 
-	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": expTime,
-		"iat": time.Now().Unix(),
+func hashRefreshToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRefreshToken membuat refresh token acak beserta hash yang disimpan di database.
+func GenerateRefreshToken() (plain, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	plain = refreshTokenPlainPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return plain, hashRefreshToken(plain), nil
+}
+
+func refreshTokenExpiry(session *models.UserSession, now time.Time) time.Time {
+	ttl := refreshTokenTTL
+	if session.KeepLoggedIn {
+		ttl = refreshTokenTTLKeep
 	}
+	exp := now.Add(ttl)
+	if exp.After(session.ExpiresAt) {
+		exp = session.ExpiresAt
+	}
+	return exp
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(secret))
+// StartSession mencatat login baru dan menerbitkan refresh token pertamanya.
+func StartSession(db *gorm.DB, userID uint, keepLoggedIn bool, ip, userAgent string) (*models.UserSession, string, time.Time, error) {
+	now := time.Now()
+	session := models.UserSession{
+		UID:          uuid.NewString(),
+		UserID:       userID,
+		IPAddress:    ip,
+		UserAgent:    truncateRunes(userAgent, 255),
+		KeepLoggedIn: keepLoggedIn,
+		CreatedAt:    now,
+		LastUsedAt:   now,
+		ExpiresAt:    now.Add(sessionMaxLifetime),
+	}
+	plain, hash, err := GenerateRefreshToken()
 	if err != nil {
-		return "", 0, err
+		return nil, "", now, err
 	}
+	exp := refreshTokenExpiry(&session, now)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.RefreshToken{UserID: userID, SessionID: session.ID, TokenHash: hash, ExpiresAt: exp}).Error
+	})
+	if err != nil {
+		return nil, "", now, err
+	}
+	return &session, plain, exp, nil
+}
 
-	return signedToken, expTime, nil
+// RevokeSession mencabut session beserta semua refresh token-nya.
+func RevokeSession(db *gorm.DB, sessionID uint, reason string) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserSession{}).Where("id = ? AND revoked_at IS NULL", sessionID).
+			Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).Where("session_id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", now).Error
+	})
 }
 
-// ValidateRefreshToken memvalidasi refresh token
-func ValidateRefreshToken(refreshToken string) (uint, error) {
-	secret := os.Getenv("REFRESH_SECRET")
-	if secret == "" {
-		return 0, fmt.Errorf("REFRESH_SECRET not set")
+// RotateRefreshToken menukar refresh token dengan token baru di session yang sama. Token yang sudah
+// pernah ditukar lalu dikirim ulang dianggap dicuri: seluruh session (keluarga token) dicabut dan
+// ErrRefreshTokenReused dikembalikan.
+func RotateRefreshToken(db *gorm.DB, plain, ip, userAgent string) (*models.UserSession, string, time.Time, error) {
+	now := time.Now()
+	var current models.RefreshToken
+	if err := db.Where("token_hash = ?", hashRefreshToken(plain)).First(&current).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", now, ErrRefreshTokenInvalid
+		}
+		return nil, "", now, err
+	}
+	var session models.UserSession
+	if err := db.First(&session, current.SessionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", now, ErrRefreshTokenInvalid
+		}
+		return nil, "", now, err
+	}
+	if session.RevokedAt != nil || current.RevokedAt != nil {
+		return &session, "", now, ErrRefreshTokenInvalid
+	}
+	if current.UsedAt != nil {
+		if err := RevokeSession(db, session.ID, models.SessionRevokedReuse); err != nil {
+			return &session, "", now, err
+		}
+		return &session, "", now, ErrRefreshTokenReused
+	}
+	if !now.Before(current.ExpiresAt) || !now.Before(session.ExpiresAt) {
+		return &session, "", now, ErrRefreshTokenInvalid
 	}
 
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	next, hash, err := GenerateRefreshToken()
+	if err != nil {
+		return &session, "", now, err
+	}
+	exp := refreshTokenExpiry(&session, now)
+	reused := false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Dua request bersamaan dengan token yang sama: hanya satu yang berhasil menandai used_at
+		res := tx.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", current.ID).Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			reused = true
+			return nil
+		}
+		replacement := models.RefreshToken{UserID: session.UserID, SessionID: session.ID, TokenHash: hash, ExpiresAt: exp}
+		if err := tx.Create(&replacement).Error; err != nil {
+			return err
 		}
-		return []byte(secret), nil
+		if err := tx.Model(&current).Update("replaced_by_id", replacement.ID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&session).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"ip_address":   ip,
+			"user_agent":   truncateRunes(userAgent, 255),
+		}).Error
 	})
-
 	if err != nil {
-		return 0, err
+		return &session, "", now, err
 	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userIDFloat, ok := claims["sub"].(float64)
-		if !ok {
-			return 0, fmt.Errorf("Invalid user ID in refresh token")
+	if reused {
+		if err := RevokeSession(db, session.ID, models.SessionRevokedReuse); err != nil {
+			return &session, "", now, err
 		}
-		return uint(userIDFloat), nil
+		return &session, "", now, ErrRefreshTokenReused
 	}
-	return 0, fmt.Errorf("Invalid refresh token")
+	return &session, next, exp, nil
 }
 
-// SaveRefreshTokenToDB menyimpan refresh token ke database
-func SaveRefreshTokenToDB(db *gorm.DB, userID uint, refreshToken string, expiresAt int64) error {
-	// Hapus token lama untuk user ini
-	db.Where("user_id = ?", userID).Delete(&models.RefreshToken{})
+// sessionTouchInterval membatasi seberapa sering LastUsedAt diperbarui oleh request biasa.
+const sessionTouchInterval = time.Minute
 
-	newToken := models.RefreshToken{
-		UserID:    userID,
-		Token:     refreshToken,
-		ExpiresAt: time.Unix(expiresAt, 0),
+// ActiveSession mengembalikan session milik userID dengan UID dari claim jti jika belum dicabut dan belum kedaluwarsa.
+func ActiveSession(db *gorm.DB, uid string, userID uint) (*models.UserSession, error) {
+	if uid == "" {
+		return nil, ErrSessionInvalid
+	}
+	var session models.UserSession
+	if err := db.Where("uid = ? AND user_id = ?", uid, userID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrSessionInvalid
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		return nil, ErrSessionInvalid
 	}
-	return db.Create(&newToken).Error
+	if now.Sub(session.LastUsedAt) > sessionTouchInterval {
+		db.Model(&session).Update("last_used_at", now)
+	}
+	return &session, nil
 }
 
-// DeleteRefreshTokenFromDB menghapus refresh token dari database
-func DeleteRefreshTokenFromDB(db *gorm.DB, userID uint, refreshToken string) error {
-	return db.Where("user_id = ? AND token = ?", userID, refreshToken).Delete(&models.RefreshToken{}).Error
+// RevokeUserSessions mencabut semua session aktif user kecuali exceptID (0 = tanpa pengecualian).
+func RevokeUserSessions(db *gorm.DB, userID uint, reason string, exceptID uint) (int64, error) {
+	var ids []uint
+	query := db.Model(&models.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != 0 {
+		query = query.Where("id <> ?", exceptID)
+	}
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := RevokeSession(db, id, reason); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(ids)), nil
 }
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// blackoutMaxChain membatasi penelusuran blackout yang saling menyambung saat mencari waktu bebas blackout.
+const blackoutMaxChain = 1000
+
+// blackoutStep mengembalikan langkah pengulangan (tahun, bulan, hari) untuk satu Interval.
+func blackoutStep(w models.BlackoutWindow) (years, months, days int) {
+	interval := w.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	switch w.Frequency {
+	case models.BlackoutDaily:
+		return 0, 0, interval
+	case models.BlackoutWeekly:
+		return 0, 0, 7 * interval
+	case models.BlackoutMonthly:
+		return 0, interval, 0
+	case models.BlackoutYearly:
+		return interval, 0, 0
+	}
+	return 0, 0, 0
+}
+
+// ValidateBlackoutWindow memeriksa rentang dan aturan pengulangan. Satu kemunculan harus lebih pendek
+// dari interval pengulangannya agar kemunculan tidak saling tumpang tindih.
+func ValidateBlackoutWindow(w *models.BlackoutWindow) error {
+	if !w.EndAt.After(w.StartAt) {
+		return errors.New("endAt must be after startAt")
+	}
+	if w.Frequency == "" {
+		w.Interval, w.RepeatUntil = 1, nil
+		return nil
+	}
+	if w.Interval < 1 {
+		w.Interval = 1
+	}
+	years, months, days := blackoutStep(*w)
+	if years == 0 && months == 0 && days == 0 {
+		return fmt.Errorf("invalid frequency %q, use daily, weekly, monthly or yearly", w.Frequency)
+	}
+	// Bulan terpendek (28 hari) menentukan jarak minimum antar kemunculan bulanan
+	minPeriod := time.Duration(days) * 24 * time.Hour
+	if months > 0 {
+		minPeriod = time.Duration(28*months) * 24 * time.Hour
+	}
+	if years > 0 {
+		minPeriod = time.Duration(365*years) * 24 * time.Hour
+	}
+	if w.EndAt.Sub(w.StartAt) >= minPeriod {
+		return errors.New("a recurring blackout must be shorter than its repeat interval")
+	}
+	if w.RepeatUntil != nil && w.RepeatUntil.Before(w.StartAt) {
+		return errors.New("repeatUntil must not be before startAt")
+	}
+	return nil
+}
+
+// blackoutOccurrence mengembalikan kemunculan ke-k (k = 0 untuk rentang asli).
+func blackoutOccurrence(w models.BlackoutWindow, k int) models.BlackoutOccurrence {
+	years, months, days := blackoutStep(w)
+	start := w.StartAt.AddDate(years*k, months*k, days*k)
+	return models.BlackoutOccurrence{
+		WindowID: w.ID,
+		Name:     w.Name,
+		Reason:   w.Reason,
+		StartAt:  start,
+		EndAt:    start.Add(w.EndAt.Sub(w.StartAt)),
+	}
+}
+
+// blackoutIndex mengembalikan k terbesar dengan awal kemunculan ke-k ≤ t, atau -1 jika t sebelum StartAt.
+func blackoutIndex(w models.BlackoutWindow, t time.Time) int {
+	if t.Before(w.StartAt) {
+		return -1
+	}
+	if w.Frequency == "" {
+		return 0
+	}
+	years, months, days := blackoutStep(w)
+	var k int
+	switch {
+	case years > 0:
+		k = (t.Year() - w.StartAt.Year()) / years
+	case months > 0:
+		k = ((t.Year()-w.StartAt.Year())*12 + int(t.Month()) - int(w.StartAt.Month())) / months
+	default:
+		k = int(t.Sub(w.StartAt).Hours()/24) / days
+	}
+	// Perkiraan bisa meleset satu langkah karena DST dan panjang bulan yang berbeda
+	for k > 0 && blackoutOccurrence(w, k).StartAt.After(t) {
+		k--
+	}
+	for !blackoutOccurrence(w, k+1).StartAt.After(t) {
+		k++
+	}
+	return k
+}
+
+// blackoutWithinRepeat melaporkan apakah kemunculan ke-k masih dalam batas pengulangan.
+func blackoutWithinRepeat(w models.BlackoutWindow, k int) bool {
+	if k < 0 || (w.Frequency == "" && k > 0) {
+		return false
+	}
+	return w.RepeatUntil == nil || !blackoutOccurrence(w, k).StartAt.After(*w.RepeatUntil)
+}
+
+// BlackoutActiveAt mengembalikan kemunculan window yang sedang berlangsung pada t, atau nil.
+func BlackoutActiveAt(w models.BlackoutWindow, t time.Time) *models.BlackoutOccurrence {
+	k := blackoutIndex(w, t)
+	if !blackoutWithinRepeat(w, k) {
+		return nil
+	}
+	occ := blackoutOccurrence(w, k)
+	if !t.Before(occ.EndAt) {
+		return nil
+	}
+	return &occ
+}
+
+// NextBlackoutOccurrence mengembalikan kemunculan yang sedang berlangsung pada t atau yang berikutnya, atau nil.
+func NextBlackoutOccurrence(w models.BlackoutWindow, t time.Time) *models.BlackoutOccurrence {
+	if occ := BlackoutActiveAt(w, t); occ != nil {
+		return occ
+	}
+	k := blackoutIndex(w, t) + 1
+	if !blackoutWithinRepeat(w, k) {
+		return nil
+	}
+	occ := blackoutOccurrence(w, k)
+	return &occ
+}
+
+// BlackoutCalendar berisi semua blackout window organisasi.
+type BlackoutCalendar struct {
+	windows []models.BlackoutWindow
+}
+
+// LoadBlackoutCalendar memuat blackout window organisasi yang masih bisa berlaku pada atau setelah now.
+func LoadBlackoutCalendar(db *gorm.DB, organizationID uint, now time.Time) (*BlackoutCalendar, error) {
+	var windows []models.BlackoutWindow
+	err := db.Scopes(InOrganization(organizationID)).
+		Where("end_at > ? OR (frequency <> '' AND (repeat_until IS NULL OR repeat_until >= ?))", now, now).
+		Find(&windows).Error
+	if err != nil {
+		return nil, err
+	}
+	return &BlackoutCalendar{windows: windows}, nil
+}
+
+// Active mengembalikan blackout yang berlangsung pada t; jika beberapa tumpang tindih, yang berakhir paling akhir.
+func (cal *BlackoutCalendar) Active(t time.Time) *models.BlackoutOccurrence {
+	if cal == nil {
+		return nil
+	}
+	var active *models.BlackoutOccurrence
+	for _, w := range cal.windows {
+		if occ := BlackoutActiveAt(w, t); occ != nil && (active == nil || occ.EndAt.After(active.EndAt)) {
+			active = occ
+		}
+	}
+	return active
+}
+
+// ClearAfter mengembalikan waktu paling awal pada atau setelah t yang tidak berada dalam blackout.
+// Blackout yang saling menyambung diikuti sampai benar-benar bebas.
+func (cal *BlackoutCalendar) ClearAfter(t time.Time) time.Time {
+	for i := 0; i < blackoutMaxChain; i++ {
+		occ := cal.Active(t)
+		if occ == nil {
+			return t
+		}
+		t = occ.EndAt
+	}
+	return t
+}
+
+// ScheduleDelivery menggabungkan delivery window campaign dengan blackout organisasi: slot yang jatuh
+// dalam blackout digeser ke slot pertama setelah blackout berakhir.
+func ScheduleDelivery(w models.DeliveryWindow, loc *time.Location, country string, holidays *HolidayCalendar, blackouts *BlackoutCalendar, now time.Time) (time.Time, error) {
+	slot, err := NextDeliveryTime(w, loc, country, holidays, now)
+	for i := 0; err == nil && i < blackoutMaxChain; i++ {
+		clear := blackouts.ClearAfter(slot)
+		if clear.Equal(slot) {
+			return slot, nil
+		}
+		slot, err = NextDeliveryTime(w, loc, country, holidays, clear)
+	}
+	if err != nil {
+		return now, err
+	}
+	return now, errors.New("no delivery slot outside blackout windows")
+}
+
+// CampaignWaitingStatus menjelaskan mengapa campaign pending atau in progress belum mengirim email,
+// atau nil jika campaign tidak sedang menunggu.
+func CampaignWaitingStatus(db *gorm.DB, camp models.Campaign, now time.Time) (*models.CampaignWaiting, error) {
+	if camp.Status != "pending" && camp.Status != "in progress" {
+		return nil, nil
+	}
+	blackouts, err := LoadBlackoutCalendar(db, camp.OrganizationID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if camp.Status == "pending" {
+		launch := camp.LaunchDate
+		if launch.Before(now) {
+			launch = now
+		}
+		if occ := blackouts.Active(launch); occ != nil {
+			until := blackouts.ClearAfter(launch)
+			return &models.CampaignWaiting{
+				Reason:   fmt.Sprintf("Launch deferred by blackout window %q", occ.Name),
+				Until:    &until,
+				Blackout: occ,
+			}, nil
+		}
+		if camp.LaunchDate.After(now) {
+			return &models.CampaignWaiting{Reason: "Waiting for launch date", Until: &camp.LaunchDate}, nil
+		}
+		return &models.CampaignWaiting{Reason: "Waiting for the campaign dispatcher to start sending"}, nil
+	}
+
+	var scheduled struct {
+		Count int64
+		Next  *time.Time
+	}
+	err = db.Model(&models.Recipient{}).
+		Select("COUNT(*) AS count, MIN(scheduled_at) AS next").
+		Where("campaign_id = ? AND status = ?", camp.ID, models.RecipientScheduled).
+		Scan(&scheduled).Error
+	if err != nil {
+		return nil, err
+	}
+	if occ := blackouts.Active(now); occ != nil {
+		until := blackouts.ClearAfter(now)
+		return &models.CampaignWaiting{
+			Reason:              fmt.Sprintf("Sending paused by blackout window %q", occ.Name),
+			Until:               &until,
+			Blackout:            occ,
+			ScheduledRecipients: scheduled.Count,
+		}, nil
+	}
+	if scheduled.Count > 0 {
+		return &models.CampaignWaiting{
+			Reason:              fmt.Sprintf("%d recipient(s) waiting for their delivery slot", scheduled.Count),
+			Until:               scheduled.Next,
+			ScheduledRecipients: scheduled.Count,
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestBlackoutIndex(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	date := func(y int, m time.Month, d, h, min int, loc *time.Location) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, loc)
+	}
+	window := func(start time.Time, length time.Duration, frequency string, interval int) models.BlackoutWindow {
+		return models.BlackoutWindow{StartAt: start, EndAt: start.Add(length), Frequency: frequency, Interval: interval}
+	}
+
+	// 31 Januari + 1 bulan dinormalisasi AddDate menjadi 3 Maret (2025 bukan tahun kabisat)
+	monthEnd := window(date(2025, 1, 31, 9, 0, time.UTC), 2*time.Hour, models.BlackoutMonthly, 1)
+	leapDay := window(date(2024, 2, 29, 0, 0, time.UTC), 24*time.Hour, models.BlackoutYearly, 1)
+	// Minggu pertama Maret 2025 melewati awal DST (9 Maret) di New York
+	weeklyDST := window(date(2025, 3, 3, 9, 0, newYork), time.Hour, models.BlackoutWeekly, 1)
+	// Akhir DST (2 November 2025) membuat satu hari 25 jam
+	dailyFallBack := window(date(2025, 11, 1, 9, 0, newYork), time.Hour, models.BlackoutDaily, 1)
+
+	tests := []struct {
+		name string
+		w    models.BlackoutWindow
+		t    time.Time
+		want int
+	}{
+		{"before start", monthEnd, date(2025, 1, 30, 0, 0, time.UTC), -1},
+		{"one-off after start", window(date(2025, 1, 1, 0, 0, time.UTC), time.Hour, "", 1), date(2026, 1, 1, 0, 0, time.UTC), 0},
+		{"exactly at start", monthEnd, monthEnd.StartAt, 0},
+		{"daily interval", window(date(2025, 1, 1, 9, 0, time.UTC), time.Hour, models.BlackoutDaily, 2), date(2025, 1, 6, 10, 0, time.UTC), 2},
+		{"monthly end of february", monthEnd, date(2025, 2, 28, 12, 0, time.UTC), 0},
+		{"monthly normalized into march", monthEnd, date(2025, 3, 3, 9, 0, time.UTC), 1},
+		{"monthly end of march", monthEnd, date(2025, 3, 31, 10, 0, time.UTC), 2},
+		{"monthly thirty day month", monthEnd, date(2025, 4, 30, 12, 0, time.UTC), 2},
+		{"monthly interval", window(date(2025, 1, 15, 9, 0, time.UTC), time.Hour, models.BlackoutMonthly, 3), date(2025, 7, 14, 0, 0, time.UTC), 1},
+		{"yearly from leap day", leapDay, date(2025, 2, 28, 12, 0, time.UTC), 0},
+		{"yearly leap day normalized", leapDay, date(2025, 3, 1, 0, 0, time.UTC), 1},
+		{"weekly before spring forward", weeklyDST, date(2025, 3, 10, 8, 59, newYork), 0},
+		{"weekly after spring forward", weeklyDST, date(2025, 3, 10, 9, 0, newYork), 1},
+		{"daily before fall back", dailyFallBack, date(2025, 11, 3, 8, 30, newYork), 1},
+		{"daily after fall back", dailyFallBack, date(2025, 11, 3, 9, 0, newYork), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blackoutIndex(tt.w, tt.t); got != tt.want {
+				t.Errorf("blackoutIndex = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlackoutCalendarClearAfter(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	at := func(d, h, min int) time.Time { return time.Date(2025, time.March, d, h, min, 0, 0, newYork) }
+	until := at(20, 0, 0)
+
+	cal := &BlackoutCalendar{windows: []models.BlackoutWindow{
+		{Name: "morning", StartAt: at(3, 9, 0), EndAt: at(3, 12, 0)},
+		{Name: "overlap", StartAt: at(3, 11, 0), EndAt: at(3, 14, 0)},
+		{Name: "adjacent", StartAt: at(3, 14, 0), EndAt: at(3, 15, 0)},
+		// Malam harian 22:00-06:00 (8 jam), panjang kemunculan dihitung sebagai durasi tetap
+		{Name: "nightly", StartAt: at(5, 22, 0), EndAt: at(6, 6, 0), Frequency: models.BlackoutDaily, Interval: 1, RepeatUntil: &until},
+		{Name: "after nightly", StartAt: at(12, 6, 0), EndAt: at(12, 8, 0)},
+	}}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"outside any blackout", at(3, 8, 0), at(3, 8, 0)},
+		{"follows overlapping and adjacent windows", at(3, 10, 0), at(3, 15, 0)},
+		{"end is exclusive", at(3, 15, 0), at(3, 15, 0)},
+		{"recurring occurrence", at(7, 23, 0), at(8, 6, 0)},
+		// Kemunculan 8 Maret 22:00 EST berakhir 8 jam kemudian, yaitu 07:00 EDT setelah DST dimulai
+		{"occurrence across spring forward", at(9, 1, 0), at(9, 7, 0)},
+		{"recurring followed by one-off", at(11, 23, 0), at(12, 8, 0)},
+		{"last occurrence within repeatUntil", at(20, 1, 0), at(20, 6, 0)},
+		{"after repeatUntil", at(21, 1, 0), at(21, 1, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.ClearAfter(tt.t); !got.Equal(tt.want) {
+				t.Errorf("ClearAfter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	var empty *BlackoutCalendar
+	if got := empty.ClearAfter(at(3, 10, 0)); !got.Equal(at(3, 10, 0)) {
+		t.Errorf("nil calendar ClearAfter = %s", got)
+	}
+}
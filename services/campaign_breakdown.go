@@ -0,0 +1,70 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CampaignBreakdownDimension mengembalikan ekspresi SQL untuk dimensi breakdown: position, company,
+// country, atau attr.<key>. Nilai diambil dari profil yang disalin ke recipient saat campaign dikirim,
+// dengan data member saat ini sebagai fallback untuk recipient lama. Attribute harus milik organizationID.
+func CampaignBreakdownDimension(db *gorm.DB, organizationID uint, dimension string) (string, error) {
+	dimension = strings.ToLower(strings.TrimSpace(dimension))
+	var expr string
+	switch dimension {
+	case "position", "company", "country":
+		expr = fmt.Sprintf("COALESCE(%s, members.%s)", MemberAttributeSQL("recipients.attributes", dimension), dimension)
+	default:
+		key, isAttr := strings.CutPrefix(dimension, "attr.")
+		if !isAttr {
+			return "", fmt.Errorf("invalid dimension %q, use position, company, country or attr.<key>", dimension)
+		}
+		attrs, err := LoadMemberAttributes(db, organizationID)
+		if err != nil {
+			return "", err
+		}
+		def, ok := attrs[key]
+		if !ok {
+			return "", fmt.Errorf("unknown member attribute %q", key)
+		}
+		expr = fmt.Sprintf("COALESCE(%s, %s)", MemberAttributeSQL("recipients.attributes", def.Key), MemberAttributeSQL("members.attributes", def.Key))
+	}
+	return "COALESCE(NULLIF(" + expr + ", ''), '(not set)')", nil
+}
+
+// CampaignBreakdown menghitung jumlah recipient unik per tipe event untuk setiap nilai dimensi.
+func CampaignBreakdown(db *gorm.DB, camp models.Campaign, dimension string) ([]models.CampaignBreakdownRow, error) {
+	expr, err := CampaignBreakdownDimension(db, camp.OrganizationID, dimension)
+	if err != nil {
+		return nil, err
+	}
+
+	countType := func(eventType models.EventType, alias string) string {
+		return fmt.Sprintf("COUNT(DISTINCT CASE WHEN events.type = '%s' THEN recipients.id END) AS %s", eventType, alias)
+	}
+	selects := []string{
+		expr + " AS value",
+		"COUNT(DISTINCT recipients.id) AS recipients",
+		"COUNT(DISTINCT CASE WHEN recipients.status = 'sent' THEN recipients.id END) AS sent",
+		countType(models.Opened, "opened"),
+		countType(models.Clicked, "clicked"),
+		countType(models.Submitted, "submitted"),
+		countType(models.Reported, "reported"),
+		countType(models.AttachmentOpened, "attachment_opened"),
+		countType(models.Scanned, "scanned"),
+	}
+
+	rows := []models.CampaignBreakdownRow{}
+	err = db.Table("recipients").
+		Select(strings.Join(selects, ", ")).
+		Joins("LEFT JOIN members ON members.id = recipients.user_id AND members.email = recipients.email").
+		Joins("LEFT JOIN events ON events.recipient_id = recipients.id").
+		Where("recipients.campaign_id = ?", camp.ID).
+		Group("value").
+		Order("recipients DESC, value").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// deliverySearchDays membatasi pencarian slot pengiriman agar konfigurasi yang tidak pernah terpenuhi
+// (mis. semua hari kerja jatuh pada hari libur) tidak berputar tanpa akhir.
+const deliverySearchDays = 370
+
+const (
+	defaultBusinessStart = "09:00"
+	defaultBusinessEnd   = "17:00"
+	defaultBusinessDays  = "1,2,3,4,5"
+)
+
+// parseClock mengubah "HH:MM" menjadi menit sejak tengah malam.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseDeliveryDays mengubah "1,2,3" (1 = Senin ... 7 = Minggu) menjadi himpunan time.Weekday.
+func parseDeliveryDays(value string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 || n > 7 {
+			return nil, fmt.Errorf("invalid day %q, use 1 (Monday) to 7 (Sunday)", part)
+		}
+		days[time.Weekday(n%7)] = true
+	}
+	if len(days) == 0 {
+		return nil, errors.New("at least one delivery day is required")
+	}
+	return days, nil
+}
+
+// NormalizeDeliveryWindow memvalidasi opsi pengiriman campaign dan mengisi nilai default.
+// Input nil berarti semua email dikirim saat launch.
+func NormalizeDeliveryWindow(input *models.DeliveryWindow) (models.DeliveryWindow, error) {
+	if input == nil || input.Mode == "" || input.Mode == models.DeliveryImmediate {
+		return models.DeliveryWindow{Mode: models.DeliveryImmediate}, nil
+	}
+	w := models.DeliveryWindow{Mode: input.Mode, SkipHolidays: input.SkipHolidays}
+
+	switch input.Mode {
+	case models.DeliveryLocalTime:
+		if strings.TrimSpace(input.LocalTime) == "" {
+			return w, errors.New("localTime is required for local_time delivery")
+		}
+		minutes, err := parseClock(input.LocalTime)
+		if err != nil {
+			return w, err
+		}
+		w.LocalTime = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	case models.DeliveryBusinessHours:
+		w.Start, w.End = defaultBusinessStart, defaultBusinessEnd
+		if strings.TrimSpace(input.Start) != "" {
+			w.Start = input.Start
+		}
+		if strings.TrimSpace(input.End) != "" {
+			w.End = input.End
+		}
+		start, err := parseClock(w.Start)
+		if err != nil {
+			return w, err
+		}
+		end, err := parseClock(w.End)
+		if err != nil {
+			return w, err
+		}
+		if start >= end {
+			return w, errors.New("business hours start must be before end")
+		}
+		w.Start = fmt.Sprintf("%02d:%02d", start/60, start%60)
+		w.End = fmt.Sprintf("%02d:%02d", end/60, end%60)
+	default:
+		return w, fmt.Errorf("invalid delivery mode %q, use immediate, local_time or business_hours", input.Mode)
+	}
+
+	w.Days = defaultBusinessDays
+	if strings.TrimSpace(input.Days) != "" {
+		days, err := parseDeliveryDays(input.Days)
+		if err != nil {
+			return w, err
+		}
+		numbers := make([]string, 0, len(days))
+		for d := range days {
+			n := int(d)
+			if n == 0 {
+				n = 7
+			}
+			numbers = append(numbers, strconv.Itoa(n))
+		}
+		sort.Strings(numbers)
+		w.Days = strings.Join(numbers, ",")
+	}
+	return w, nil
+}
+
+// HolidayCalendar berisi hari libur per tanggal (YYYY-MM-DD).
+type HolidayCalendar struct {
+	byDate map[string][]models.Holiday
+}
+
+// LoadHolidayCalendar memuat hari libur mulai dari kemarin; tanggal sebelumnya tidak lagi relevan
+// untuk mencari slot pengiriman.
+func LoadHolidayCalendar(db *gorm.DB, now time.Time) (*HolidayCalendar, error) {
+	var holidays []models.Holiday
+	from := now.AddDate(0, 0, -1).Format("2006-01-02")
+	if err := db.Where("date >= ?", from).Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	cal := &HolidayCalendar{byDate: map[string][]models.Holiday{}}
+	for _, h := range holidays {
+		cal.byDate[h.Date] = append(cal.byDate[h.Date], h)
+	}
+	return cal, nil
+}
+
+// Holiday mengembalikan hari libur pada tanggal lokal day yang berlaku untuk negara recipient, atau nil.
+func (cal *HolidayCalendar) Holiday(day time.Time, country string) *models.Holiday {
+	if cal == nil {
+		return nil
+	}
+	country = strings.TrimSpace(country)
+	holidays := cal.byDate[day.Format("2006-01-02")]
+	for i := range holidays {
+		if holidays[i].Country == "" || strings.EqualFold(holidays[i].Country, country) {
+			return &holidays[i]
+		}
+	}
+	return nil
+}
+
+// NextDeliveryTime menghitung slot pengiriman paling awal pada atau setelah now untuk recipient di
+// zona waktu loc. Hari di luar Days dan (jika SkipHolidays) hari libur dilewati.
+func NextDeliveryTime(w models.DeliveryWindow, loc *time.Location, country string, cal *HolidayCalendar, now time.Time) (time.Time, error) {
+	if w.Mode == "" || w.Mode == models.DeliveryImmediate {
+		return now, nil
+	}
+	days, err := parseDeliveryDays(w.Days)
+	if err != nil {
+		return now, err
+	}
+
+	var from, until int
+	switch w.Mode {
+	case models.DeliveryLocalTime:
+		if from, err = parseClock(w.LocalTime); err != nil {
+			return now, err
+		}
+		until = from + 1
+	case models.DeliveryBusinessHours:
+		if from, err = parseClock(w.Start); err != nil {
+			return now, err
+		}
+		if until, err = parseClock(w.End); err != nil {
+			return now, err
+		}
+	default:
+		return now, fmt.Errorf("invalid delivery mode %q", w.Mode)
+	}
+
+	local := now.In(loc)
+	for offset := 0; offset <= deliverySearchDays; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		at := func(minutes int) time.Time {
+			return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, loc)
+		}
+		if !days[day.Weekday()] {
+			continue
+		}
+		if w.SkipHolidays && cal.Holiday(day, country) != nil {
+			continue
+		}
+		start, end := at(from), at(until)
+		if !now.Before(end) {
+			continue
+		}
+		if now.After(start) {
+			return now, nil
+		}
+		return start, nil
+	}
+	return now, fmt.Errorf("no delivery slot within %d days", deliverySearchDays)
+}
+
+// ClaimDueRecipients mengambil recipient berstatus scheduled yang slotnya sudah tiba pada campaign
+// yang masih berjalan, lalu mengubahnya ke pending. Update bersyarat status memastikan satu recipient
+// hanya diambil sekali meskipun dispatcher berjalan bersamaan.
+func ClaimDueRecipients(db *gorm.DB, now time.Time) ([]models.Recipient, error) {
+	var due []models.Recipient
+	err := db.Joins("JOIN campaigns ON campaigns.id = recipients.campaign_id").
+		Where("recipients.status = ? AND recipients.scheduled_at <= ? AND campaigns.status = ?", models.RecipientScheduled, now, "in progress").
+		Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.Recipient, 0, len(due))
+	for _, rec := range due {
+		res := db.Model(&models.Recipient{}).
+			Where("id = ? AND status = ?", rec.ID, models.RecipientScheduled).
+			Update("status", "pending")
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		if res.RowsAffected == 1 {
+			rec.Status = "pending"
+			claimed = append(claimed, rec)
+		}
+	}
+	return claimed, nil
+}
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrDirectorySyncRunning dikembalikan jika sync untuk group yang sama masih berjalan.
+var ErrDirectorySyncRunning = errors.New("directory sync for this group is already running")
+
+// DefaultDirectoryAttributeMap memetakan field Member ke atribut LDAP.
+// Beberapa atribut dapat dipisah "|" sebagai fallback berurutan.
+var DefaultDirectoryAttributeMap = map[string]string{
+	"name":     "displayName|cn",
+	"email":    "mail|userPrincipalName",
+	"position": "title",
+	"company":  "company|department|o",
+	"country":  "co|c",
+}
+
+// DirectoryEntry adalah satu entry hasil pencarian directory.
+type DirectoryEntry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Get mengembalikan nilai pertama atribut (nama atribut tidak case-sensitive).
+func (e DirectoryEntry) Get(attr string) string {
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attr) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// DirectoryClient adalah operasi directory yang dibutuhkan sync.
+type DirectoryClient interface {
+	Search(baseDN, filter string, attributes []string) ([]DirectoryEntry, error)
+	Close() error
+}
+
+// DirectoryDialer membuka koneksi ke directory. Dapat diganti dengan FakeDirectory untuk pengujian.
+var DirectoryDialer = dialLDAP
+
+type ldapDirectoryClient struct {
+	conn *ldap.Conn
+}
+
+func dialLDAP(profile models.DirectoryProfile) (DirectoryClient, error) {
+	u, err := url.Parse(profile.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory URL: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: profile.InsecureSkipVerify,
+	}
+
+	conn, err := ldap.DialURL(profile.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to directory: %w", err)
+	}
+	conn.SetTimeout(30 * time.Second)
+
+	if profile.StartTLS && u.Scheme != "ldaps" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("StartTLS failed: %w", err)
+		}
+	}
+
+	if profile.BindDN != "" {
+		password, err := DecryptSecret(profile.BindPassword)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := conn.Bind(profile.BindDN, password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("bind failed: %w", err)
+		}
+	}
+	return &ldapDirectoryClient{conn: conn}, nil
+}
+
+func (c *ldapDirectoryClient) Search(baseDN, filter string, attributes []string) ([]DirectoryEntry, error) {
+	req := ldap.NewSearchRequest(
+		baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, 0, false, filter, attributes, nil,
+	)
+	res, err := c.conn.SearchWithPaging(req, 500)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]DirectoryEntry, 0, len(res.Entries))
+	for _, e := range res.Entries {
+		attrs := make(map[string][]string, len(e.Attributes))
+		for _, a := range e.Attributes {
+			attrs[a.Name] = a.Values
+		}
+		entries = append(entries, DirectoryEntry{DN: e.DN, Attributes: attrs})
+	}
+	return entries, nil
+}
+
+func (c *ldapDirectoryClient) Close() error {
+	return c.conn.Close()
+}
+
+// TestDirectoryProfile membuka koneksi, bind, lalu mencari satu entry di BaseDN.
+func TestDirectoryProfile(profile models.DirectoryProfile) error {
+	client, err := DirectoryDialer(profile)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	_, err = client.Search(profile.BaseDN, "(objectClass=*)", []string{"1.1"})
+	return err
+}
+
+// DirectoryAttributeMap menggabungkan mapping group dengan default.
+func DirectoryAttributeMap(raw datatypes.JSON) map[string]string {
+	result := make(map[string]string, len(DefaultDirectoryAttributeMap))
+	for k, v := range DefaultDirectoryAttributeMap {
+		result[k] = v
+	}
+	if len(raw) > 0 {
+		var custom map[string]string
+		if err := json.Unmarshal(raw, &custom); err == nil {
+			for k, v := range custom {
+				if _, ok := result[k]; ok && strings.TrimSpace(v) != "" {
+					result[k] = v
+				}
+			}
+		}
+	}
+	return result
+}
+
+var (
+	directorySyncMu      sync.Mutex
+	directorySyncRunning = map[uint]bool{}
+)
+
+// SyncGroupDirectory menyinkronkan anggota group dengan directory.
+// Sync pertama (atau full=true) membaca semua entry dan menghapus anggota yang tidak ada di directory.
+// Sync berikutnya hanya membaca entry yang berubah sejak HighWaterMark, ditambah daftar DN untuk mendeteksi penghapusan.
+func SyncGroupDirectory(db *gorm.DB, groupID uint, trigger string, full bool) (*models.DirectorySyncLog, error) {
+	directorySyncMu.Lock()
+	if directorySyncRunning[groupID] {
+		directorySyncMu.Unlock()
+		return nil, ErrDirectorySyncRunning
+	}
+	directorySyncRunning[groupID] = true
+	directorySyncMu.Unlock()
+	defer func() {
+		directorySyncMu.Lock()
+		delete(directorySyncRunning, groupID)
+		directorySyncMu.Unlock()
+	}()
+
+	var cfg models.GroupDirectorySync
+	if err := db.Preload("DirectoryProfile").Where("group_id = ?", groupID).First(&cfg).Error; err != nil {
+		return nil, err
+	}
+
+	syncLog := &models.DirectorySyncLog{
+		GroupID:            groupID,
+		DirectoryProfileID: cfg.DirectoryProfileID,
+		Trigger:            trigger,
+		Incremental:        !full && cfg.HighWaterMark != "",
+		StartedAt:          time.Now(),
+	}
+
+	changes, hwm, err := runDirectorySync(db, &cfg, syncLog)
+	syncLog.FinishedAt = time.Now()
+	if len(changes) > 1000 {
+		changes = changes[:1000] // batasi ukuran log
+	}
+	if changesJSON, jerr := json.Marshal(changes); jerr == nil {
+		syncLog.Changes = datatypes.JSON(changesJSON)
+	}
+
+	updates := map[string]interface{}{"last_attempt_at": syncLog.FinishedAt}
+	if err != nil {
+		syncLog.Status = "failed"
+		syncLog.Error = err.Error()
+		updates["failure_count"] = gorm.Expr("failure_count + 1")
+	} else {
+		syncLog.Status = "success"
+		updates["last_synced_at"] = syncLog.FinishedAt
+		updates["failure_count"] = 0
+		if hwm != "" {
+			updates["high_water_mark"] = hwm
+		}
+	}
+	db.Model(&cfg).Updates(updates)
+
+	if cerr := db.Create(syncLog).Error; cerr != nil {
+		log.Printf("Failed to save directory sync log for group %d: %v", groupID, cerr)
+	}
+	return syncLog, err
+}
+
+func runDirectorySync(db *gorm.DB, cfg *models.GroupDirectorySync, syncLog *models.DirectorySyncLog) ([]models.DirectorySyncChange, string, error) {
+	profile := cfg.DirectoryProfile
+	baseDN := cfg.BaseDN
+	if baseDN == "" {
+		baseDN = profile.BaseDN
+	}
+	filter := strings.TrimSpace(cfg.Filter)
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+	if _, err := ldap.CompileFilter(filter); err != nil {
+		return nil, "", fmt.Errorf("invalid filter: %w", err)
+	}
+	changeAttr := profile.ChangeAttribute
+	if changeAttr == "" {
+		changeAttr = "modifyTimestamp"
+	}
+
+	attrMap := DirectoryAttributeMap(cfg.AttributeMap)
+	attrSet := map[string]bool{changeAttr: true}
+	for _, spec := range attrMap {
+		for _, a := range strings.Split(spec, "|") {
+			attrSet[strings.TrimSpace(a)] = true
+		}
+	}
+	attributes := make([]string, 0, len(attrSet))
+	for a := range attrSet {
+		attributes = append(attributes, a)
+	}
+	sort.Strings(attributes)
+
+	// 1. Ambil data dari directory
+	highWaterMark := ""
+	if syncLog.Incremental {
+		highWaterMark = cfg.HighWaterMark
+	}
+	entries, presentDNs, err := searchDirectory(profile, baseDN, filter, changeAttr, highWaterMark, attributes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 2. Bandingkan dengan anggota group
+	var existing []models.Member
+	if err := db.Where("group_id = ?", cfg.GroupID).Find(&existing).Error; err != nil {
+		return nil, "", err
+	}
+	byDN := map[string]*models.Member{}
+	byEmail := map[string]*models.Member{}
+	for i := range existing {
+		m := &existing[i]
+		if m.DirectoryDN != "" {
+			byDN[strings.ToLower(m.DirectoryDN)] = m
+		}
+		byEmail[strings.ToLower(m.Email)] = m
+	}
+
+	var changes []models.DirectorySyncChange
+	var creates []models.Member
+	type memberUpdate struct {
+		id     uint
+		values map[string]interface{}
+	}
+	var updates []memberUpdate
+	matched := map[uint]bool{}
+	seenEmail := map[string]bool{}
+	hwm := cfg.HighWaterMark
+	now := time.Now()
+
+	for _, e := range entries {
+		if v := e.Get(changeAttr); v > hwm {
+			hwm = v
+		}
+
+		values := map[string]string{}
+		for field, spec := range attrMap {
+			for _, a := range strings.Split(spec, "|") {
+				if v := strings.TrimSpace(e.Get(strings.TrimSpace(a))); v != "" {
+					values[field] = v
+					break
+				}
+			}
+		}
+
+		email := values["email"]
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email || len(email) > 50 {
+			syncLog.Skipped++
+			changes = append(changes, models.DirectorySyncChange{Action: "skipped", DN: e.DN, Email: email, Reason: "missing or invalid email"})
+			continue
+		}
+		key := strings.ToLower(email)
+		if seenEmail[key] {
+			syncLog.Skipped++
+			changes = append(changes, models.DirectorySyncChange{Action: "skipped", DN: e.DN, Email: email, Reason: "duplicate email"})
+			continue
+		}
+		seenEmail[key] = true
+
+		if values["name"] == "" {
+			values["name"], _, _ = strings.Cut(email, "@")
+		}
+		member := models.Member{
+			GroupID:     cfg.GroupID,
+			Name:        truncateRunes(values["name"], 30),
+			Email:       email,
+			Position:    truncateRunes(values["position"], 30),
+			Company:     truncateRunes(values["company"], 50),
+			Country:     truncateRunes(values["country"], 50),
+			DirectoryDN: truncateRunes(e.DN, 255),
+		}
+
+		current := byDN[strings.ToLower(e.DN)]
+		if current == nil {
+			current = byEmail[key]
+		}
+		if current == nil {
+			member.CreatedAt, member.UpdatedAt = now, now
+			creates = append(creates, member)
+			syncLog.Added++
+			changes = append(changes, models.DirectorySyncChange{Action: "added", DN: e.DN, Email: email})
+			continue
+		}
+
+		matched[current.ID] = true
+		if current.Name != member.Name || current.Email != member.Email || current.Position != member.Position ||
+			current.Company != member.Company || current.Country != member.Country || current.DirectoryDN != member.DirectoryDN {
+			updates = append(updates, memberUpdate{id: current.ID, values: map[string]interface{}{
+				"name":         member.Name,
+				"email":        member.Email,
+				"position":     member.Position,
+				"company":      member.Company,
+				"country":      member.Country,
+				"directory_dn": member.DirectoryDN,
+				"updated_at":   now,
+			}})
+			syncLog.Updated++
+			changes = append(changes, models.DirectorySyncChange{Action: "updated", DN: e.DN, Email: email})
+		}
+	}
+
+	// 3. Anggota yang tidak lagi ada di directory dihapus.
+	// Pada full sync, anggota manual (tanpa DN) yang tidak cocok juga dihapus agar group mencerminkan directory.
+	var removeIDs []uint
+	for _, m := range existing {
+		if matched[m.ID] {
+			continue
+		}
+		if m.DirectoryDN != "" {
+			if presentDNs[strings.ToLower(m.DirectoryDN)] {
+				continue
+			}
+		} else if syncLog.Incremental {
+			continue
+		}
+		removeIDs = append(removeIDs, m.ID)
+		syncLog.Removed++
+		changes = append(changes, models.DirectorySyncChange{Action: "removed", DN: m.DirectoryDN, Email: m.Email})
+	}
+
+	// 4. Simpan perubahan
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, u := range updates {
+			if err := tx.Model(&models.Member{}).Where("id = ?", u.id).Updates(u.values).Error; err != nil {
+				return err
+			}
+		}
+		if len(creates) > 0 {
+			if err := tx.CreateInBatches(&creates, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(removeIDs) > 0 {
+			if err := tx.Where("id IN ?", removeIDs).Delete(&models.Member{}).Error; err != nil {
+				return err
+			}
+		}
+		if syncLog.Added+syncLog.Updated+syncLog.Removed > 0 {
+			return tx.Model(&models.Group{}).Where("id = ?", cfg.GroupID).Update("updated_at", now).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return changes, "", err
+	}
+	return changes, hwm, nil
+}
+
+// searchDirectory mengambil entry yang perlu diproses dan DN (lowercase) semua entry yang masih cocok dengan filter.
+// Jika highWaterMark terisi, hanya entry dengan changeAttr >= highWaterMark yang dibaca lengkap.
+func searchDirectory(profile models.DirectoryProfile, baseDN, filter, changeAttr, highWaterMark string, attributes []string) ([]DirectoryEntry, map[string]bool, error) {
+	client, err := DirectoryDialer(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.Close()
+
+	var entries []DirectoryEntry
+	presentDNs := map[string]bool{}
+	if highWaterMark != "" {
+		changedFilter := fmt.Sprintf("(&%s(%s>=%s))", filter, changeAttr, ldap.EscapeFilter(highWaterMark))
+		if entries, err = client.Search(baseDN, changedFilter, attributes); err != nil {
+			return nil, nil, fmt.Errorf("search failed: %w", err)
+		}
+		all, err := client.Search(baseDN, filter, []string{"1.1"})
+		if err != nil {
+			return nil, nil, fmt.Errorf("search failed: %w", err)
+		}
+		for _, e := range all {
+			presentDNs[strings.ToLower(e.DN)] = true
+		}
+	} else {
+		if entries, err = client.Search(baseDN, filter, attributes); err != nil {
+			return nil, nil, fmt.Errorf("search failed: %w", err)
+		}
+		for _, e := range entries {
+			presentDNs[strings.ToLower(e.DN)] = true
+		}
+	}
+	return entries, presentDNs, nil
+}
+
+// maxDirectorySyncBackoff membatasi jeda percobaan ulang group yang terus gagal.
+const maxDirectorySyncBackoff = 24 * time.Hour
+
+// directorySyncDue melaporkan apakah jadwal sync group sudah jatuh tempo. Setelah gagal, percobaan
+// berikutnya ditunda dari LastAttemptAt selama interval x 2^(FailureCount-1), maksimal 24 jam.
+func directorySyncDue(cfg models.GroupDirectorySync, now time.Time) bool {
+	interval := time.Duration(cfg.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 60 * time.Minute
+	}
+	if cfg.FailureCount > 0 && cfg.LastAttemptAt != nil {
+		backoff := interval
+		for i := 1; i < cfg.FailureCount && backoff < maxDirectorySyncBackoff; i++ {
+			backoff *= 2
+		}
+		if backoff > maxDirectorySyncBackoff && interval < maxDirectorySyncBackoff {
+			backoff = maxDirectorySyncBackoff
+		}
+		return !cfg.LastAttemptAt.Add(backoff).After(now)
+	}
+	return cfg.LastSyncedAt == nil || !cfg.LastSyncedAt.Add(interval).After(now)
+}
+
+// DueDirectorySyncs mengembalikan group yang jadwal sync-nya sudah jatuh tempo.
+func DueDirectorySyncs(db *gorm.DB, now time.Time) ([]models.GroupDirectorySync, error) {
+	var configs []models.GroupDirectorySync
+	if err := db.Where("enabled = ?", true).Find(&configs).Error; err != nil {
+		return nil, err
+	}
+	due := make([]models.GroupDirectorySync, 0, len(configs))
+	for _, cfg := range configs {
+		if directorySyncDue(cfg, now) {
+			due = append(due, cfg)
+		}
+	}
+	return due, nil
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// FakeDirectory adalah directory in-memory untuk pengujian sync tanpa server LDAP.
+// Pasang dengan: services.DirectoryDialer = fake.Dialer
+type FakeDirectory struct {
+	mu      sync.Mutex
+	entries map[string]DirectoryEntry
+}
+
+func NewFakeDirectory(entries ...DirectoryEntry) *FakeDirectory {
+	fd := &FakeDirectory{entries: map[string]DirectoryEntry{}}
+	for _, e := range entries {
+		fd.Put(e)
+	}
+	return fd
+}
+
+// Put menambah atau mengganti entry berdasarkan DN.
+func (fd *FakeDirectory) Put(e DirectoryEntry) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	fd.entries[strings.ToLower(e.DN)] = e
+}
+
+// Delete menghapus entry berdasarkan DN.
+func (fd *FakeDirectory) Delete(dn string) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	delete(fd.entries, strings.ToLower(dn))
+}
+
+// Dialer dapat dipasang sebagai DirectoryDialer.
+func (fd *FakeDirectory) Dialer(models.DirectoryProfile) (DirectoryClient, error) {
+	return fd, nil
+}
+
+func (fd *FakeDirectory) Close() error { return nil }
+
+// Search mengevaluasi filter LDAP terhadap entry di bawah baseDN (scope subtree).
+func (fd *FakeDirectory) Search(baseDN, filter string, attributes []string) ([]DirectoryEntry, error) {
+	packet, err := ldap.CompileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.ToLower(baseDN)
+
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+
+	var result []DirectoryEntry
+	for dn, e := range fd.entries {
+		if base != "" && dn != base && !strings.HasSuffix(dn, ","+base) {
+			continue
+		}
+		if !fakeFilterMatch(packet, e) {
+			continue
+		}
+		result = append(result, fakeSelectAttributes(e, attributes))
+	}
+	return result, nil
+}
+
+func fakeSelectAttributes(e DirectoryEntry, attributes []string) DirectoryEntry {
+	out := DirectoryEntry{DN: e.DN, Attributes: map[string][]string{}}
+	all := len(attributes) == 0
+	for _, a := range attributes {
+		if a == "*" {
+			all = true
+		}
+	}
+	for name, values := range e.Attributes {
+		keep := all
+		for _, a := range attributes {
+			if strings.EqualFold(a, name) {
+				keep = true
+			}
+		}
+		if keep {
+			out.Attributes[name] = values
+		}
+	}
+	return out
+}
+
+func fakePacketString(p *ber.Packet) string {
+	if s, ok := p.Value.(string); ok {
+		return s
+	}
+	if p.Data != nil {
+		return p.Data.String()
+	}
+	return ""
+}
+
+func fakeValues(e DirectoryEntry, attr string) []string {
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attr) {
+			return values
+		}
+	}
+	return nil
+}
+
+func fakeFilterMatch(p *ber.Packet, e DirectoryEntry) bool {
+	switch p.Tag {
+	case ldap.FilterAnd:
+		for _, child := range p.Children {
+			if !fakeFilterMatch(child, e) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range p.Children {
+			if fakeFilterMatch(child, e) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(p.Children) == 1 && !fakeFilterMatch(p.Children[0], e)
+	case ldap.FilterPresent:
+		attr := fakePacketString(p)
+		return strings.EqualFold(attr, "objectClass") || len(fakeValues(e, attr)) > 0
+	case ldap.FilterEqualityMatch, ldap.FilterApproxMatch, ldap.FilterGreaterOrEqual, ldap.FilterLessOrEqual:
+		if len(p.Children) != 2 {
+			return false
+		}
+		want := strings.ToLower(fakePacketString(p.Children[1]))
+		for _, v := range fakeValues(e, fakePacketString(p.Children[0])) {
+			v = strings.ToLower(v)
+			switch p.Tag {
+			case ldap.FilterGreaterOrEqual:
+				if v >= want {
+					return true
+				}
+			case ldap.FilterLessOrEqual:
+				if v <= want {
+					return true
+				}
+			default:
+				if v == want {
+					return true
+				}
+			}
+		}
+		return false
+	case ldap.FilterSubstrings:
+		if len(p.Children) != 2 {
+			return false
+		}
+		for _, v := range fakeValues(e, fakePacketString(p.Children[0])) {
+			if fakeSubstringMatch(strings.ToLower(v), p.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	}
+	return false // extensible match tidak didukung
+}
+
+func fakeSubstringMatch(v string, parts []*ber.Packet) bool {
+	for _, part := range parts {
+		s := strings.ToLower(fakePacketString(part))
+		switch part.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(v, s) {
+				return false
+			}
+			v = v[len(s):]
+		case ldap.FilterSubstringsAny:
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(v, s) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSearchDirectory(t *testing.T) {
+	fake := NewFakeDirectory(
+		DirectoryEntry{DN: "uid=alice,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+			"objectClass": {"person"}, "mail": {"alice@example.com"}, "modifyTimestamp": {"20240101000000Z"},
+		}},
+		DirectoryEntry{DN: "uid=bob,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+			"objectClass": {"person"}, "mail": {"bob@example.com"}, "modifyTimestamp": {"20240301000000Z"},
+		}},
+		DirectoryEntry{DN: "uid=svc,ou=system,dc=example,dc=com", Attributes: map[string][]string{
+			"objectClass": {"person"}, "mail": {"svc@example.com"}, "modifyTimestamp": {"20240301000000Z"},
+		}},
+	)
+	original := DirectoryDialer
+	DirectoryDialer = fake.Dialer
+	defer func() { DirectoryDialer = original }()
+
+	tests := []struct {
+		name          string
+		highWaterMark string
+		wantEntries   []string
+		wantPresent   []string
+	}{
+		{
+			name:        "full sync reads every entry under base DN",
+			wantEntries: []string{"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"},
+			wantPresent: []string{"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"},
+		},
+		{
+			name:          "incremental sync reads changed entries but lists all present DNs",
+			highWaterMark: "20240201000000Z",
+			wantEntries:   []string{"uid=bob,ou=people,dc=example,dc=com"},
+			wantPresent:   []string{"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, present, err := searchDirectory(models.DirectoryProfile{}, "ou=people,dc=example,dc=com",
+				"(objectClass=person)", "modifyTimestamp", tt.highWaterMark, []string{"mail", "modifyTimestamp"})
+			if err != nil {
+				t.Fatalf("searchDirectory: %v", err)
+			}
+			var gotEntries []string
+			for _, e := range entries {
+				gotEntries = append(gotEntries, e.DN)
+				if e.Get("mail") == "" {
+					t.Errorf("entry %s missing requested attribute mail", e.DN)
+				}
+			}
+			var gotPresent []string
+			for dn := range present {
+				gotPresent = append(gotPresent, dn)
+			}
+			sort.Strings(gotEntries)
+			sort.Strings(gotPresent)
+			if !equalStrings(gotEntries, tt.wantEntries) {
+				t.Errorf("entries = %v, want %v", gotEntries, tt.wantEntries)
+			}
+			if !equalStrings(gotPresent, tt.wantPresent) {
+				t.Errorf("present DNs = %v, want %v", gotPresent, tt.wantPresent)
+			}
+		})
+	}
+}
+
+func TestDirectorySyncDue(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+
+	tests := []struct {
+		name string
+		cfg  models.GroupDirectorySync
+		want bool
+	}{
+		{"never synced", models.GroupDirectorySync{IntervalMinutes: 60}, true},
+		{"synced within interval", models.GroupDirectorySync{IntervalMinutes: 60, LastSyncedAt: ago(30 * time.Minute)}, false},
+		{"interval elapsed", models.GroupDirectorySync{IntervalMinutes: 60, LastSyncedAt: ago(time.Hour)}, true},
+		{"default interval", models.GroupDirectorySync{LastSyncedAt: ago(59 * time.Minute)}, false},
+		{"first failure waits one interval", models.GroupDirectorySync{IntervalMinutes: 60, FailureCount: 1, LastAttemptAt: ago(30 * time.Minute)}, false},
+		{"first failure retried after interval", models.GroupDirectorySync{IntervalMinutes: 60, FailureCount: 1, LastAttemptAt: ago(time.Hour)}, true},
+		{"third failure backs off 4x", models.GroupDirectorySync{IntervalMinutes: 60, FailureCount: 3, LastAttemptAt: ago(3 * time.Hour)}, false},
+		{"third failure retried after 4x", models.GroupDirectorySync{IntervalMinutes: 60, FailureCount: 3, LastAttemptAt: ago(4 * time.Hour)}, true},
+		{"backoff capped at 24h", models.GroupDirectorySync{IntervalMinutes: 60, FailureCount: 30, LastAttemptAt: ago(24 * time.Hour)}, true},
+		{"failure ignores old success", models.GroupDirectorySync{IntervalMinutes: 60, FailureCount: 2, LastSyncedAt: ago(72 * time.Hour), LastAttemptAt: ago(time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := directorySyncDue(tt.cfg, now); got != tt.want {
+				t.Errorf("directorySyncDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	domainVerificationPrefix    = "awarenix-verification="
+	domainVerificationSubdomain = "_awarenix"
+	domainLookupTimeout         = 10 * time.Second
+)
+
+// TXTResolver adalah sumber TXT record untuk verifikasi domain.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainTXTResolver dapat diganti (mis. dengan StaticTXTResolver) untuk pengujian lokal.
+// Jika nil, dipakai resolver sistem atau server DNS dari env DNS_RESOLVER (host:port).
+var DomainTXTResolver TXTResolver
+
+// StaticTXTResolver menjawab lookup dari map nama -> TXT record, tanpa jaringan.
+type StaticTXTResolver map[string][]string
+
+func (r StaticTXTResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if records, ok := r[strings.ToLower(strings.TrimSuffix(name, "."))]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func domainResolver() TXTResolver {
+	if DomainTXTResolver != nil {
+		return DomainTXTResolver
+	}
+	server := strings.TrimSpace(os.Getenv("DNS_RESOLVER"))
+	if server == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// NormalizeTargetDomain memvalidasi nama domain target.
+func NormalizeTargetDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if !domainNamePattern.MatchString(domain) {
+		return "", errors.New("domain must be a valid domain name such as example.com")
+	}
+	return domain, nil
+}
+
+// NewDomainVerificationToken membuat token acak untuk TXT record.
+func NewDomainVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// DomainVerificationRecord mengembalikan nama dan nilai TXT record yang harus dibuat.
+func DomainVerificationRecord(d models.TargetDomain) (string, string) {
+	return domainVerificationSubdomain + "." + d.Domain, domainVerificationPrefix + d.VerificationToken
+}
+
+// TargetDomainResponse menambahkan instruksi DNS ke data domain.
+func TargetDomainResponse(d models.TargetDomain) models.TargetDomainResponse {
+	name, value := DomainVerificationRecord(d)
+	return models.TargetDomainResponse{TargetDomain: d, RecordName: name, RecordType: "TXT", RecordValue: value}
+}
+
+// VerifyTargetDomain mencari TXT record verifikasi di _awarenix.<domain> dan di domain itu sendiri,
+// lalu menyimpan hasilnya. Domain yang sebelumnya terverifikasi kehilangan status jika record hilang.
+func VerifyTargetDomain(db *gorm.DB, d *models.TargetDomain) error {
+	ctx, cancel := context.WithTimeout(context.Background(), domainLookupTimeout)
+	defer cancel()
+
+	recordName, expected := DomainVerificationRecord(*d)
+	resolver := domainResolver()
+	var lookupErr error
+	found := false
+	for _, name := range []string{recordName, d.Domain} {
+		records, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+				lookupErr = err
+			}
+			continue
+		}
+		for _, r := range records {
+			if strings.TrimSpace(strings.Trim(r, `"`)) == expected {
+				found = true
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	now := time.Now()
+	d.LastCheckedAt = &now
+	if found {
+		d.Status = models.DomainVerified
+		d.LastError = ""
+		if d.VerifiedAt == nil {
+			d.VerifiedAt = &now
+		}
+	} else {
+		d.Status = models.DomainFailed
+		d.VerifiedAt = nil
+		d.LastError = fmt.Sprintf("TXT record %q not found on %s", expected, recordName)
+		if lookupErr != nil {
+			d.LastError = truncateRunes("DNS lookup failed: "+lookupErr.Error(), 255)
+		}
+	}
+	return db.Model(d).Select("status", "verified_at", "last_checked_at", "last_error").Updates(d).Error
+}
+
+// DomainAllowList berisi domain target yang sudah terverifikasi.
+type DomainAllowList map[string]bool
+
+// LoadDomainAllowList memuat domain organisasi yang terverifikasi.
+func LoadDomainAllowList(db *gorm.DB, organizationID uint) (DomainAllowList, error) {
+	var domains []string
+	err := db.Model(&models.TargetDomain{}).Scopes(InOrganization(organizationID)).
+		Where("status = ?", models.DomainVerified).Pluck("domain", &domains).Error
+	if err != nil {
+		return nil, err
+	}
+	allow := DomainAllowList{}
+	for _, d := range domains {
+		allow[d] = true
+	}
+	return allow, nil
+}
+
+// EmailDomain mengembalikan bagian domain dari alamat email (huruf kecil).
+func EmailDomain(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		return email[at+1:]
+	}
+	return ""
+}
+
+// Allows melaporkan apakah domain email (atau domain induknya) sudah terverifikasi.
+func (a DomainAllowList) Allows(email string) bool {
+	domain := EmailDomain(email)
+	for domain != "" {
+		if a[domain] {
+			return true
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// failingTXTResolver mensimulasikan server DNS yang tidak bisa dihubungi.
+type failingTXTResolver struct{}
+
+func (failingTXTResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return nil, errors.New("i/o timeout")
+}
+
+// dryRunDB membuat koneksi gorm yang hanya membangun SQL tanpa menghubungi database.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dialector := mysql.New(mysql.Config{DSN: "test:test@tcp(127.0.0.1:0)/test", SkipInitializeWithVersion: true})
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	return db
+}
+
+func TestVerifyTargetDomain(t *testing.T) {
+	const token = "0123456789abcdef"
+	expected := domainVerificationPrefix + token
+
+	tests := []struct {
+		name       string
+		resolver   TXTResolver
+		wantStatus string
+		wantError  string
+	}{
+		{
+			name:       "record on _awarenix subdomain",
+			resolver:   StaticTXTResolver{"_awarenix.example.com": {"v=spf1 -all", expected}},
+			wantStatus: models.DomainVerified,
+		},
+		{
+			name:       "quoted record on the domain itself",
+			resolver:   StaticTXTResolver{"example.com": {`"` + expected + `"`}},
+			wantStatus: models.DomainVerified,
+		},
+		{
+			name:       "record missing",
+			resolver:   StaticTXTResolver{"_awarenix.example.com": {domainVerificationPrefix + "other"}},
+			wantStatus: models.DomainFailed,
+			wantError:  "not found on _awarenix.example.com",
+		},
+		{
+			name:       "lookup error",
+			resolver:   failingTXTResolver{},
+			wantStatus: models.DomainFailed,
+			wantError:  "DNS lookup failed: i/o timeout",
+		},
+	}
+
+	original := DomainTXTResolver
+	defer func() { DomainTXTResolver = original }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			DomainTXTResolver = tt.resolver
+			d := &models.TargetDomain{ID: 1, Domain: "example.com", VerificationToken: token}
+			if err := VerifyTargetDomain(dryRunDB(t), d); err != nil {
+				t.Fatalf("VerifyTargetDomain: %v", err)
+			}
+			if d.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", d.Status, tt.wantStatus)
+			}
+			if d.LastCheckedAt == nil {
+				t.Error("LastCheckedAt not set")
+			}
+			if tt.wantStatus == models.DomainVerified && (d.VerifiedAt == nil || d.LastError != "") {
+				t.Errorf("verified domain has VerifiedAt=%v LastError=%q", d.VerifiedAt, d.LastError)
+			}
+			if tt.wantStatus == models.DomainFailed && d.VerifiedAt != nil {
+				t.Error("failed domain kept VerifiedAt")
+			}
+			if !strings.Contains(d.LastError, tt.wantError) {
+				t.Errorf("LastError = %q, want it to contain %q", d.LastError, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestDomainAllowListAllows(t *testing.T) {
+	allow := DomainAllowList{"example.com": true, "corp.example.org": true}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"alice@example.com", true},
+		{"Alice@EXAMPLE.com", true},
+		{"bob@mail.example.com", true},
+		{"carol@a.b.corp.example.org", true},
+		{"dave@example.org", false},
+		{"erin@notexample.com", false},
+		{"frank@example.com.evil.net", false},
+		{"no-at-sign", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := allow.Allows(tt.email); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
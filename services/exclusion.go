@@ -0,0 +1,124 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var domainNamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// NormalizeExclusionValue memvalidasi dan menormalkan nilai entri exclusion sesuai tipenya.
+func NormalizeExclusionValue(exclusionType, value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch exclusionType {
+	case models.ExclusionEmail:
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			return "", errors.New("value must be a valid email address")
+		}
+	case models.ExclusionDomain:
+		value = strings.TrimPrefix(strings.TrimPrefix(value, "*."), "@")
+		if !domainNamePattern.MatchString(value) {
+			return "", errors.New("value must be a domain such as example.com")
+		}
+	case models.ExclusionPattern:
+		if !strings.ContainsAny(value, "*?") {
+			return "", errors.New("pattern must contain * or ? wildcards, use type email for exact addresses")
+		}
+		if strings.Trim(value, "*?.@") == "" {
+			return "", errors.New("pattern is too broad")
+		}
+	default:
+		return "", fmt.Errorf("invalid exclusion type %q", exclusionType)
+	}
+	return value, nil
+}
+
+func exclusionWildcard(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.NewReplacer(`\*`, `.*`, `\?`, `.`).Replace(expr)
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+// ExclusionMatcher mencocokkan alamat email dengan entri exclusion yang aktif.
+type ExclusionMatcher struct {
+	emails   map[string]*models.Exclusion
+	domains  map[string]*models.Exclusion
+	patterns []exclusionPattern
+}
+
+type exclusionPattern struct {
+	re        *regexp.Regexp
+	exclusion *models.Exclusion
+}
+
+// LoadExclusionMatcher memuat entri exclusion organisasi yang belum kedaluwarsa pada waktu now.
+func LoadExclusionMatcher(db *gorm.DB, organizationID uint, now time.Time) (*ExclusionMatcher, error) {
+	var entries []models.Exclusion
+	err := db.Scopes(InOrganization(organizationID)).Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("id").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	m := &ExclusionMatcher{emails: map[string]*models.Exclusion{}, domains: map[string]*models.Exclusion{}}
+	for i := range entries {
+		e := &entries[i]
+		switch e.Type {
+		case models.ExclusionEmail:
+			m.emails[e.Value] = e
+		case models.ExclusionDomain:
+			m.domains[e.Value] = e
+		case models.ExclusionPattern:
+			m.patterns = append(m.patterns, exclusionPattern{re: exclusionWildcard(e.Value), exclusion: e})
+		}
+	}
+	return m, nil
+}
+
+// Match mengembalikan entri exclusion yang cocok dengan email, atau nil.
+// Urutan prioritas: email persis, domain (termasuk subdomain), lalu pattern.
+func (m *ExclusionMatcher) Match(email string) *models.Exclusion {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if e, ok := m.emails[email]; ok {
+		return e
+	}
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		domain := email[at+1:]
+		for domain != "" {
+			if e, ok := m.domains[domain]; ok {
+				return e
+			}
+			_, parent, found := strings.Cut(domain, ".")
+			if !found {
+				break
+			}
+			domain = parent
+		}
+	}
+	for _, p := range m.patterns {
+		if p.re.MatchString(email) {
+			return p.exclusion
+		}
+	}
+	return nil
+}
+
+// CheckExclusion memeriksa satu alamat email terhadap exclusion list organisasi saat ini.
+func CheckExclusion(db *gorm.DB, organizationID uint, email string) (*models.Exclusion, error) {
+	matcher, err := LoadExclusionMatcher(db, organizationID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return matcher.Match(email), nil
+}
+
+// ExclusionReason adalah pesan yang disimpan pada recipient yang dikecualikan.
+func ExclusionReason(e *models.Exclusion) string {
+	return fmt.Sprintf("Excluded by %s exclusion %q: %s", e.Type, e.Value, e.Reason)
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	groupRuleMaxDepth      = 5
+	groupRuleMaxConditions = 50
+)
+
+// groupRuleColumns adalah field Member yang dapat dipakai di rule.
+var groupRuleColumns = map[string]string{
+	"name":     "members.name",
+	"email":    "members.email",
+	"position": "members.position",
+	"company":  "members.company",
+	"country":  "members.country",
+	"timezone": "members.timezone",
+}
+
+var groupRuleEventTypes = map[string]bool{
+	"any":                           true,
+	string(models.Opened):           true,
+	string(models.Clicked):          true,
+	string(models.Submitted):        true,
+	string(models.Reported):         true,
+	string(models.AttachmentOpened): true,
+	string(models.Scanned):          true,
+}
+
+// ValidateGroupRule memeriksa struktur rule sebelum disimpan; attribute yang dipakai harus milik organizationID.
+func ValidateGroupRule(db *gorm.DB, rule *models.GroupRule, organizationID uint) error {
+	if rule == nil {
+		return errors.New("rule is required for dynamic groups")
+	}
+	if len(rule.Conditions) == 0 {
+		return errors.New("rule must have at least one condition")
+	}
+	attrs, err := LoadMemberAttributes(db, organizationID)
+	if err != nil {
+		return err
+	}
+	count := 0
+	_, _, err = groupRuleGroupSQL(rule.Match, rule.Conditions, attrs, organizationID, 1, &count)
+	return err
+}
+
+func groupRuleGroupSQL(match string, conditions []models.GroupRuleCondition, attrs map[string]models.MemberAttribute, organizationID uint, depth int, count *int) (string, []interface{}, error) {
+	if depth > groupRuleMaxDepth {
+		return "", nil, fmt.Errorf("rule is nested deeper than %d levels", groupRuleMaxDepth)
+	}
+	joiner := " AND "
+	switch strings.ToLower(match) {
+	case "", "all":
+	case "any":
+		joiner = " OR "
+	default:
+		return "", nil, fmt.Errorf("invalid match %q, use all or any", match)
+	}
+	if len(conditions) == 0 {
+		return "", nil, errors.New("condition group must not be empty")
+	}
+
+	parts := make([]string, 0, len(conditions))
+	var args []interface{}
+	for _, cond := range conditions {
+		*count++
+		if *count > groupRuleMaxConditions {
+			return "", nil, fmt.Errorf("rule has more than %d conditions", groupRuleMaxConditions)
+		}
+		var (
+			sql     string
+			condArg []interface{}
+			err     error
+		)
+		if len(cond.Conditions) > 0 {
+			sql, condArg, err = groupRuleGroupSQL(cond.Match, cond.Conditions, attrs, organizationID, depth+1, count)
+		} else {
+			sql, condArg, err = groupRuleConditionSQL(cond, attrs, organizationID)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, sql)
+		args = append(args, condArg...)
+	}
+	return "(" + strings.Join(parts, joiner) + ")", args, nil
+}
+
+func groupRuleLike(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(v)
+}
+
+// groupRuleConditionSQL membangun kondisi SQL satu condition. Kondisi event hanya melihat event dari
+// campaign milik organizationID dan dicocokkan lewat person_id, bukan email mentah.
+func groupRuleConditionSQL(cond models.GroupRuleCondition, attrs map[string]models.MemberAttribute, organizationID uint) (string, []interface{}, error) {
+	field := strings.ToLower(cond.Field)
+	op := strings.ToLower(cond.Operator)
+
+	if field == "event" {
+		eventType := strings.ToLower(cond.Value)
+		if !groupRuleEventTypes[eventType] {
+			return "", nil, fmt.Errorf("invalid event type %q", cond.Value)
+		}
+		if cond.Days < 0 {
+			return "", nil, errors.New("days must not be negative")
+		}
+		sub := "SELECT recipients.person_id FROM recipients JOIN events ON events.recipient_id = recipients.id" +
+			" WHERE recipients.person_id IS NOT NULL AND recipients.campaign_id IN (SELECT id FROM campaigns WHERE organization_id = ?)"
+		args := []interface{}{organizationID}
+		if eventType != "any" {
+			sub += " AND events.type = ?"
+			args = append(args, eventType)
+		}
+		if cond.Days > 0 {
+			sub += " AND events.timestamp >= ?"
+			args = append(args, time.Now().AddDate(0, 0, -cond.Days))
+		}
+		switch op {
+		case "happened":
+			return "members.person_id IN (" + sub + ")", args, nil
+		case "not_happened":
+			return "(members.person_id IS NULL OR members.person_id NOT IN (" + sub + "))", args, nil
+		}
+		return "", nil, fmt.Errorf("invalid operator %q for event, use happened or not_happened", cond.Operator)
+	}
+
+	// Field bawaan dibandingkan apa adanya; custom attribute ("attr.<key>") mengikuti tipe definisinya
+	// dan member yang tidak mengisi attribute dianggap cocok untuk operator negatif.
+	valueType := models.MemberAttributeString
+	column, ok := groupRuleColumns[field]
+	rawColumn := column
+	negate := func(sql string) string { return sql }
+	value := func(v string) (interface{}, error) { return v, nil }
+	if !ok {
+		key, isAttr := strings.CutPrefix(field, "attr.")
+		def, found := attrs[key]
+		if !isAttr || !found {
+			return "", nil, fmt.Errorf("invalid field %q", cond.Field)
+		}
+		valueType = def.Type
+		column = MemberAttributeSQL("members.attributes", def.Key)
+		rawColumn = column
+		negate = func(sql string) string { return "(" + rawColumn + " IS NULL OR " + sql + ")" }
+		if valueType != models.MemberAttributeString {
+			value = func(v string) (interface{}, error) {
+				n, err := NormalizeMemberAttributeValue(def, v)
+				if err == nil && n == nil {
+					err = fmt.Errorf("%s requires a value", cond.Field)
+				}
+				if b, isBool := n.(bool); isBool {
+					return strconv.FormatBool(b), err
+				}
+				return n, err
+			}
+		}
+		if valueType == models.MemberAttributeNumber {
+			column = "CAST(" + column + " AS DECIMAL(30,10))"
+		}
+	}
+
+	switch op {
+	case "equals", "not_equals", "gt", "gte", "lt", "lte":
+		ordered := op != "equals" && op != "not_equals"
+		if ordered && valueType != models.MemberAttributeNumber && valueType != models.MemberAttributeDate {
+			return "", nil, fmt.Errorf("operator %s requires a number or date field", op)
+		}
+		v, err := value(cond.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		sqlOp := map[string]string{"equals": " = ?", "not_equals": " <> ?", "gt": " > ?", "gte": " >= ?", "lt": " < ?", "lte": " <= ?"}[op]
+		if op == "not_equals" {
+			return negate(column + sqlOp), []interface{}{v}, nil
+		}
+		return column + sqlOp, []interface{}{v}, nil
+	case "in", "not_in":
+		if len(cond.Values) == 0 {
+			return "", nil, fmt.Errorf("operator %s requires values", op)
+		}
+		values := make([]interface{}, 0, len(cond.Values))
+		for _, raw := range cond.Values {
+			v, err := value(raw)
+			if err != nil {
+				return "", nil, err
+			}
+			values = append(values, v)
+		}
+		if op == "in" {
+			return column + " IN ?", []interface{}{values}, nil
+		}
+		return negate(column + " NOT IN ?"), []interface{}{values}, nil
+	case "is_empty":
+		return "(" + rawColumn + " IS NULL OR " + rawColumn + " = '')", nil, nil
+	case "is_not_empty":
+		return "(" + rawColumn + " IS NOT NULL AND " + rawColumn + " <> '')", nil, nil
+	}
+
+	if valueType != models.MemberAttributeString {
+		return "", nil, fmt.Errorf("invalid operator %q for field %s", cond.Operator, field)
+	}
+	switch op {
+	case "contains":
+		return column + " LIKE ?", []interface{}{"%" + groupRuleLike(cond.Value) + "%"}, nil
+	case "not_contains":
+		return negate(column + " NOT LIKE ?"), []interface{}{"%" + groupRuleLike(cond.Value) + "%"}, nil
+	case "starts_with":
+		return column + " LIKE ?", []interface{}{groupRuleLike(cond.Value) + "%"}, nil
+	case "ends_with":
+		return column + " LIKE ?", []interface{}{"%" + groupRuleLike(cond.Value)}, nil
+	}
+	return "", nil, fmt.Errorf("invalid operator %q for field %s", cond.Operator, field)
+}
+
+// ResolveGroupRule mengembalikan Member yang memenuhi rule, unik per email.
+// Kandidat dibatasi ke group static milik organizationID.
+func ResolveGroupRule(db *gorm.DB, rule *models.GroupRule, organizationID uint) ([]models.Member, error) {
+	attrs, err := LoadMemberAttributes(db, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	count := 0
+	where, args, err := groupRuleGroupSQL(rule.Match, rule.Conditions, attrs, organizationID, 1, &count)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := db.Model(&models.Group{}).Select("id").Where("type <> ?", models.GroupTypeDynamic).
+		Scopes(InOrganization(organizationID))
+	if len(rule.SourceGroupIDs) > 0 {
+		sources = sources.Where("id IN ?", rule.SourceGroupIDs)
+	}
+
+	var candidates []models.Member
+	err = db.Model(&models.Member{}).
+		Where("members.group_id IN (?)", sources).
+		Where(where, args...).
+		Order("members.id").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	members := make([]models.Member, 0, len(candidates))
+	for _, m := range candidates {
+		key := strings.ToLower(m.Email)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// ResolveGroupMembers mengembalikan anggota group: tabel members untuk group static, rule untuk group dynamic.
+func ResolveGroupMembers(db *gorm.DB, group *models.Group) ([]models.Member, error) {
+	if group.Type != models.GroupTypeDynamic {
+		var members []models.Member
+		err := db.Where("group_id = ?", group.ID).Order("id").Find(&members).Error
+		return members, err
+	}
+	var rule models.GroupRule
+	if err := json.Unmarshal(group.Rule, &rule); err != nil {
+		return nil, fmt.Errorf("invalid rule for group %d: %w", group.ID, err)
+	}
+	return ResolveGroupRule(db, &rule, group.OrganizationID)
+}
+
+// SnapshotCampaignGroup menghitung anggota group saat campaign diluncurkan dan menyimpannya sebagai snapshot.
+// Jika snapshot sudah ada (mis. scheduler dijalankan ulang), anggota dari snapshot yang dipakai.
+func SnapshotCampaignGroup(db *gorm.DB, camp models.Campaign) ([]models.Member, error) {
+	var existing models.CampaignGroupSnapshot
+	if err := db.Where("campaign_id = ?", camp.ID).First(&existing).Error; err == nil {
+		var snap []models.SnapshotMember
+		if err := json.Unmarshal(existing.Members, &snap); err != nil {
+			return nil, err
+		}
+		members := make([]models.Member, 0, len(snap))
+		for _, s := range snap {
+			members = append(members, models.Member{ID: s.MemberID, GroupID: s.GroupID, PersonID: s.PersonID, Name: s.Name, Email: s.Email, Position: s.Position, Company: s.Company, Country: s.Country, Timezone: s.Timezone, Attributes: s.Attributes})
+		}
+		return members, nil
+	}
+
+	var group models.Group
+	if err := db.First(&group, camp.GroupID).Error; err != nil {
+		return nil, err
+	}
+	members, err := ResolveGroupMembers(db, &group)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := make([]models.SnapshotMember, 0, len(members))
+	for _, m := range members {
+		snap = append(snap, models.SnapshotMember{MemberID: m.ID, GroupID: m.GroupID, PersonID: m.PersonID, Name: m.Name, Email: m.Email, Position: m.Position, Company: m.Company, Country: m.Country, Timezone: m.Timezone, Attributes: m.Attributes})
+	}
+	snapJSON, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	groupType := group.Type
+	if groupType == "" {
+		groupType = models.GroupTypeStatic
+	}
+	snapshot := models.CampaignGroupSnapshot{
+		CampaignID:  camp.ID,
+		GroupID:     group.ID,
+		GroupName:   group.Name,
+		GroupType:   groupType,
+		Rule:        group.Rule,
+		MemberCount: len(members),
+		Members:     datatypes.JSON(snapJSON),
+		ResolvedAt:  time.Now(),
+	}
+	if err := db.Create(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICalEvent adalah VEVENT dari file iCalendar (RFC 5545) yang relevan untuk blackout window.
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	RRule       string
+}
+
+// icalLine adalah satu content line: NAME;PARAM=VALUE:value
+type icalLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseICalLine(raw string) (icalLine, error) {
+	// Titik dua di dalam parameter ber-quote (mis. TZID="GMT+07:00") bukan pemisah value
+	split := -1
+	quoted := false
+	for i, r := range raw {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			split = i
+			break
+		}
+	}
+	if split < 0 {
+		return icalLine{}, fmt.Errorf("invalid line %q", raw)
+	}
+	parts := strings.Split(raw[:split], ";")
+	line := icalLine{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: raw[split+1:]}
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			line.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return line, nil
+}
+
+// icalUnescape menerjemahkan escape TEXT (\n, \, \; \\).
+func icalUnescape(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return r.Replace(s)
+}
+
+// parseICalTime membaca DATE atau DATE-TIME. Waktu tanpa Z dan tanpa TZID dianggap waktu lokal aplikasi.
+func parseICalTime(line icalLine) (t time.Time, allDay bool, err error) {
+	loc := DefaultLocation()
+	if tzid := line.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	value := strings.TrimSpace(line.value)
+	switch {
+	case line.params["VALUE"] == "DATE" || len(value) == 8:
+		t, err = time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+// parseICalDuration membaca DURATION sederhana seperti P1D, PT4H atau P1W.
+func parseICalDuration(value string) (time.Duration, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "+")
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var total time.Duration
+	inTime := false
+	num := ""
+	for _, r := range value[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+		case r == 'T':
+			inTime = true
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			num = ""
+			switch {
+			case r == 'W':
+				total += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D':
+				total += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				total += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				total += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				total += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+		}
+	}
+	return total, nil
+}
+
+// ParseICalendar membaca semua VEVENT dari file iCalendar. Event tanpa DTSTART dilewati.
+func ParseICalendar(r io.Reader) ([]ICalEvent, error) {
+	// Unfold: baris yang diawali spasi/tab adalah lanjutan baris sebelumnya
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, errors.New("not an iCalendar file")
+	}
+
+	var events []ICalEvent
+	var current *ICalEvent
+	var duration time.Duration
+	hasEnd, allDay := false, false
+	depth := 0 // VALARM dan komponen lain di dalam VEVENT diabaikan
+	for _, raw := range lines {
+		line, err := parseICalLine(raw)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case line.name == "BEGIN" && strings.EqualFold(line.value, "VEVENT"):
+			current, duration, hasEnd, allDay, depth = &ICalEvent{}, 0, false, false, 0
+			continue
+		case current == nil:
+			continue
+		case line.name == "BEGIN":
+			depth++
+			continue
+		case line.name == "END" && depth > 0:
+			depth--
+			continue
+		case depth > 0:
+			continue
+		case line.name == "END" && strings.EqualFold(line.value, "VEVENT"):
+			if !current.Start.IsZero() {
+				switch {
+				case hasEnd:
+				case duration > 0:
+					current.End = current.Start.Add(duration)
+				case allDay:
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		}
+
+		switch line.name {
+		case "UID":
+			current.UID = line.value
+		case "SUMMARY":
+			current.Summary = icalUnescape(line.value)
+		case "DESCRIPTION":
+			current.Description = icalUnescape(line.value)
+		case "RRULE":
+			current.RRule = line.value
+		case "DTSTART":
+			if current.Start, allDay, err = parseICalTime(line); err != nil {
+				return nil, fmt.Errorf("invalid DTSTART %q", line.value)
+			}
+		case "DTEND":
+			if current.End, _, err = parseICalTime(line); err != nil {
+				return nil, fmt.Errorf("invalid DTEND %q", line.value)
+			}
+			hasEnd = true
+		case "DURATION":
+			if duration, err = parseICalDuration(line.value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return events, nil
+}
+
+// BlackoutFromICalEvent mengubah VEVENT menjadi BlackoutWindow. RRULE yang didukung hanya
+// FREQ, INTERVAL, UNTIL dan COUNT; aturan lain (BYDAY, dll.) ditolak agar blackout tidak diam-diam salah.
+func BlackoutFromICalEvent(ev ICalEvent) (models.BlackoutWindow, error) {
+	name := strings.TrimSpace(ev.Summary)
+	if name == "" {
+		name = "Imported blackout"
+	}
+	w := models.BlackoutWindow{
+		Name:        truncateRunes(name, 100),
+		Reason:      truncateRunes(strings.TrimSpace(ev.Description), 255),
+		StartAt:     ev.Start,
+		EndAt:       ev.End,
+		Interval:    1,
+		Source:      models.BlackoutSourceICal,
+		ExternalUID: truncateRunes(strings.TrimSpace(ev.UID), 255),
+	}
+
+	count := 0
+	if ev.RRule != "" {
+		for _, part := range strings.Split(ev.RRule, ";") {
+			key, value, _ := strings.Cut(part, "=")
+			switch strings.ToUpper(key) {
+			case "FREQ":
+				w.Frequency = strings.ToLower(value)
+			case "INTERVAL":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return w, fmt.Errorf("invalid RRULE INTERVAL %q", value)
+				}
+				w.Interval = n
+			case "UNTIL":
+				until, _, err := parseICalTime(icalLine{value: value, params: map[string]string{}})
+				if err != nil {
+					return w, fmt.Errorf("invalid RRULE UNTIL %q", value)
+				}
+				w.RepeatUntil = &until
+			case "COUNT":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return w, fmt.Errorf("invalid RRULE COUNT %q", value)
+				}
+				count = n
+			case "WKST":
+			default:
+				return w, fmt.Errorf("unsupported RRULE part %s", key)
+			}
+		}
+	}
+	if err := ValidateBlackoutWindow(&w); err != nil {
+		return w, err
+	}
+	if count > 0 && w.Frequency != "" {
+		last := blackoutOccurrence(w, count-1).StartAt
+		w.RepeatUntil = &last
+	}
+	return w, nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadICalFixture(t *testing.T) map[string]ICalEvent {
+	t.Helper()
+	f, err := os.Open("testdata/blackouts.ics")
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer f.Close()
+	events, err := ParseICalendar(f)
+	if err != nil {
+		t.Fatalf("ParseICalendar: %v", err)
+	}
+	byUID := map[string]ICalEvent{}
+	for _, ev := range events {
+		byUID[ev.UID] = ev
+	}
+	if len(byUID) != len(events) {
+		t.Fatalf("duplicate UIDs in %d events", len(events))
+	}
+	return byUID
+}
+
+func TestParseICalendar(t *testing.T) {
+	jakarta := mustLoadLocation(t, "Asia/Jakarta")
+	events := loadICalFixture(t)
+
+	tests := []struct {
+		uid  string
+		want ICalEvent
+	}{
+		{
+			// SUMMARY dan DESCRIPTION dilipat (tab dan spasi), DTEND memakai TZID ber-quote
+			uid: "year-end-freeze@example.com",
+			want: ICalEvent{
+				Summary:     "Year-end change freeze",
+				Description: "No phishing during closing, see\nfinance calendar",
+				Start:       time.Date(2025, 12, 22, 0, 0, 0, 0, jakarta),
+				End:         time.Date(2026, 1, 2, 0, 0, 0, 0, jakarta),
+			},
+		},
+		{
+			// DURATION menggantikan DTEND; DESCRIPTION di dalam VALARM diabaikan
+			uid: "board-meeting@example.com",
+			want: ICalEvent{
+				Summary: "Board meeting",
+				Start:   time.Date(2025, 3, 3, 14, 0, 0, 0, time.UTC),
+				End:     time.Date(2025, 3, 3, 18, 30, 0, 0, time.UTC),
+				RRule:   "FREQ=WEEKLY;COUNT=3",
+			},
+		},
+		{
+			// DATE tanpa DTEND berlangsung satu hari penuh
+			uid: "holiday@example.com",
+			want: ICalEvent{
+				Summary: "Company holiday",
+				Start:   time.Date(2025, 8, 18, 0, 0, 0, 0, DefaultLocation()),
+				End:     time.Date(2025, 8, 19, 0, 0, 0, 0, DefaultLocation()),
+			},
+		},
+		{
+			// DTEND bertipe DATE bersifat eksklusif
+			uid: "offsite@example.com",
+			want: ICalEvent{
+				Summary: "Offsite",
+				Start:   time.Date(2025, 9, 1, 0, 0, 0, 0, DefaultLocation()),
+				End:     time.Date(2025, 9, 4, 0, 0, 0, 0, DefaultLocation()),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			got, ok := events[tt.uid]
+			if !ok {
+				t.Fatalf("event %s not parsed", tt.uid)
+			}
+			if got.Summary != tt.want.Summary || got.Description != tt.want.Description || got.RRule != tt.want.RRule {
+				t.Errorf("got %q / %q / %q, want %q / %q / %q",
+					got.Summary, got.Description, got.RRule, tt.want.Summary, tt.want.Description, tt.want.RRule)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("got %s - %s, want %s - %s", got.Start, got.End, tt.want.Start, tt.want.End)
+			}
+		})
+	}
+
+	if _, ok := events["no-start@example.com"]; ok {
+		t.Error("event without DTSTART was not skipped")
+	}
+	if len(events) != 5 {
+		t.Errorf("parsed %d events, want 5", len(events))
+	}
+}
+
+func TestParseICalendarErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not an iCalendar file", "BEGIN:VEVENT\nEND:VEVENT\n"},
+		{"empty file", ""},
+		{"invalid DTSTART", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:2025-03-03\nEND:VEVENT\nEND:VCALENDAR\n"},
+		{"invalid DURATION", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20250303T090000Z\nDURATION:1D\nEND:VEVENT\nEND:VCALENDAR\n"},
+		{"line without value", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY\nEND:VEVENT\nEND:VCALENDAR\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseICalendar(strings.NewReader(tt.input)); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
+
+func TestParseICalDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "P1W", want: 7 * 24 * time.Hour},
+		{value: "P2D", want: 48 * time.Hour},
+		{value: "PT4H30M", want: 4*time.Hour + 30*time.Minute},
+		{value: "P1DT2H", want: 26 * time.Hour},
+		{value: "+PT15S", want: 15 * time.Second},
+		{value: "1D", wantErr: true},
+		{value: "P1H", wantErr: true}, // jam harus setelah T
+		{value: "P1X", wantErr: true},
+		{value: "PTH", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseICalDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("duration = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseICalLineQuotedParam(t *testing.T) {
+	line, err := parseICalLine(`DTSTART;TZID="GMT+07:00";VALUE=DATE-TIME:20250303T090000`)
+	if err != nil {
+		t.Fatalf("parseICalLine: %v", err)
+	}
+	if line.name != "DTSTART" || line.params["TZID"] != "GMT+07:00" || line.params["VALUE"] != "DATE-TIME" || line.value != "20250303T090000" {
+		t.Errorf("parsed %+v", line)
+	}
+}
+
+func TestBlackoutFromICalEvent(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	jakarta := mustLoadLocation(t, "Asia/Jakarta")
+	fixture := loadICalFixture(t)
+	event := func(start time.Time, length time.Duration, rrule string) ICalEvent {
+		return ICalEvent{UID: "inline", Summary: "Inline", Start: start, End: start.Add(length), RRule: rrule}
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name          string
+		ev            ICalEvent
+		wantFrequency string
+		wantInterval  int
+		wantUntil     *time.Time
+		wantErr       string
+	}{
+		{name: "one-off from fixture", ev: fixture["year-end-freeze@example.com"], wantInterval: 1},
+		{
+			name: "weekly COUNT from fixture", ev: fixture["board-meeting@example.com"],
+			wantFrequency: models.BlackoutWeekly, wantInterval: 1, wantUntil: ptr(time.Date(2025, 3, 17, 14, 0, 0, 0, time.UTC)),
+		},
+		{name: "BYDAY from fixture", ev: fixture["standup@example.com"], wantErr: "unsupported RRULE part BYDAY"},
+		{
+			// Kemunculan kedua sudah EDT: UNTIL tetap 09:00 waktu New York, bukan 08:00
+			name: "COUNT across DST", ev: event(time.Date(2025, 3, 2, 9, 0, 0, 0, newYork), time.Hour, "FREQ=WEEKLY;COUNT=2"),
+			wantFrequency: models.BlackoutWeekly, wantInterval: 1, wantUntil: ptr(time.Date(2025, 3, 9, 9, 0, 0, 0, newYork)),
+		},
+		{
+			name: "monthly COUNT from month end", ev: event(time.Date(2025, 1, 31, 9, 0, 0, 0, jakarta), time.Hour, "FREQ=MONTHLY;COUNT=2"),
+			wantFrequency: models.BlackoutMonthly, wantInterval: 1, wantUntil: ptr(time.Date(2025, 3, 3, 9, 0, 0, 0, jakarta)),
+		},
+		{
+			name: "COUNT with INTERVAL", ev: event(time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC), 8*time.Hour, "FREQ=DAILY;INTERVAL=2;COUNT=3;WKST=MO"),
+			wantFrequency: models.BlackoutDaily, wantInterval: 2, wantUntil: ptr(time.Date(2025, 1, 5, 22, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "UNTIL in UTC", ev: event(time.Date(2025, 1, 6, 9, 0, 0, 0, jakarta), time.Hour, "FREQ=YEARLY;UNTIL=20280106T020000Z"),
+			wantFrequency: models.BlackoutYearly, wantInterval: 1, wantUntil: ptr(time.Date(2028, 1, 6, 2, 0, 0, 0, time.UTC)),
+		},
+		{name: "invalid INTERVAL", ev: event(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour, "FREQ=DAILY;INTERVAL=0"), wantErr: `invalid RRULE INTERVAL "0"`},
+		{name: "invalid COUNT", ev: event(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour, "FREQ=DAILY;COUNT=x"), wantErr: `invalid RRULE COUNT "x"`},
+		{name: "invalid UNTIL", ev: event(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour, "FREQ=DAILY;UNTIL=soon"), wantErr: `invalid RRULE UNTIL "soon"`},
+		{name: "unknown FREQ", ev: event(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour, "FREQ=HOURLY"), wantErr: `invalid frequency "hourly"`},
+		{name: "occurrence longer than interval", ev: event(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 48*time.Hour, "FREQ=DAILY"), wantErr: "a recurring blackout must be shorter than its repeat interval"},
+		{name: "end before start", ev: event(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), -time.Hour, ""), wantErr: "endAt must be after startAt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := BlackoutFromICalEvent(tt.ev)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BlackoutFromICalEvent: %v", err)
+			}
+			if w.Frequency != tt.wantFrequency || w.Interval != tt.wantInterval {
+				t.Errorf("frequency/interval = %q/%d, want %q/%d", w.Frequency, w.Interval, tt.wantFrequency, tt.wantInterval)
+			}
+			switch {
+			case tt.wantUntil == nil && w.RepeatUntil != nil:
+				t.Errorf("repeatUntil = %s, want nil", w.RepeatUntil)
+			case tt.wantUntil != nil && (w.RepeatUntil == nil || !w.RepeatUntil.Equal(*tt.wantUntil)):
+				t.Errorf("repeatUntil = %v, want %s", w.RepeatUntil, tt.wantUntil)
+			}
+			if w.Source != models.BlackoutSourceICal || w.ExternalUID != tt.ev.UID {
+				t.Errorf("source/uid = %q/%q", w.Source, w.ExternalUID)
+			}
+		})
+	}
+
+	freeze, _ := BlackoutFromICalEvent(fixture["year-end-freeze@example.com"])
+	if freeze.Name != "Year-end change freeze" || freeze.Reason != "No phishing during closing, see\nfinance calendar" {
+		t.Errorf("name/reason = %q/%q", freeze.Name, freeze.Reason)
+	}
+	unnamed, _ := BlackoutFromICalEvent(ICalEvent{Summary: "  ", Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)})
+	if unnamed.Name != "Imported blackout" {
+		t.Errorf("unnamed event name = %q", unnamed.Name)
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kebijakan brute-force login. Akun mendapat jeda progresif lalu lockout; IP hanya lockout dengan
+// ambang lebih tinggi karena banyak user bisa berbagi satu IP (NAT kantor).
+const (
+	loginFailureWindow   = 15 * time.Minute // kegagalan lebih lama dari ini dilupakan
+	loginDelayAfter      = 3                // jeda mulai berlaku setelah kegagalan ke-3
+	loginMaxDelay        = 30 * time.Second
+	loginAccountLockAt   = 5
+	loginIPLockAt        = 30
+	loginLockoutDuration = 15 * time.Minute // berlipat dua setiap lockout berikutnya
+	loginMaxLockout      = 24 * time.Hour
+)
+
+// loginThrottleKey menormalkan key agar email beda huruf besar/kecil dihitung sebagai akun yang sama.
+func loginThrottleKey(kind, key string) string {
+	key = strings.TrimSpace(key)
+	if kind == models.LoginThrottleAccount {
+		key = strings.ToLower(key)
+	}
+	return truncateRunes(key, 255)
+}
+
+// loginDelay mengembalikan jeda wajib setelah sejumlah kegagalan berturut-turut (1s, 2s, 4s, ... maks 30s).
+func loginDelay(kind string, failures int) time.Duration {
+	if kind != models.LoginThrottleAccount || failures < loginDelayAfter {
+		return 0
+	}
+	delay := time.Second << uint(failures-loginDelayAfter)
+	if delay > loginMaxDelay || delay <= 0 {
+		delay = loginMaxDelay
+	}
+	return delay
+}
+
+// LoginRetryAt mengembalikan waktu paling awal percobaan berikutnya diterima, atau nil jika boleh sekarang.
+func LoginRetryAt(t models.LoginThrottle, now time.Time) *time.Time {
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		until := *t.LockedUntil
+		return &until
+	}
+	if t.LastFailureAt == nil || now.Sub(*t.LastFailureAt) > loginFailureWindow {
+		return nil
+	}
+	next := t.LastFailureAt.Add(loginDelay(t.Kind, t.Failures))
+	if now.Before(next) {
+		return &next
+	}
+	return nil
+}
+
+// CheckLoginAllowed mengembalikan lama tunggu sebelum percobaan login untuk email/IP ini diterima (0 = boleh).
+func CheckLoginAllowed(db *gorm.DB, email, ip string, now time.Time) (time.Duration, error) {
+	var throttles []models.LoginThrottle
+	err := db.Where("(kind = ? AND `key` = ?) OR (kind = ? AND `key` = ?)",
+		models.LoginThrottleAccount, loginThrottleKey(models.LoginThrottleAccount, email),
+		models.LoginThrottleIP, loginThrottleKey(models.LoginThrottleIP, ip)).
+		Find(&throttles).Error
+	if err != nil {
+		return 0, err
+	}
+	var wait time.Duration
+	for _, t := range throttles {
+		if retry := LoginRetryAt(t, now); retry != nil && retry.Sub(now) > wait {
+			wait = retry.Sub(now)
+		}
+	}
+	return wait, nil
+}
+
+// recordFailure menambah kegagalan untuk satu key dan mengunci jika ambang tercapai.
+// Mengembalikan waktu akhir lockout jika percobaan ini memicu lockout.
+func recordFailure(db *gorm.DB, kind, key string, lockAt int, now time.Time) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := db.Transaction(func(tx *gorm.DB) error {
+		throttle := models.LoginThrottle{Kind: kind, Key: loginThrottleKey(kind, key)}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&throttle).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("kind = ? AND `key` = ?", throttle.Kind, throttle.Key).First(&throttle).Error; err != nil {
+			return err
+		}
+
+		if throttle.LastFailureAt == nil || now.Sub(*throttle.LastFailureAt) > loginFailureWindow {
+			throttle.Failures = 0
+		}
+		throttle.Failures++
+		throttle.LastFailureAt = &now
+		throttle.UpdatedAt = now
+		if throttle.Failures >= lockAt {
+			duration := loginLockoutDuration << uint(throttle.LockCount)
+			if duration > loginMaxLockout || duration <= 0 {
+				duration = loginMaxLockout
+			}
+			until := now.Add(duration)
+			throttle.LockedUntil = &until
+			throttle.LockCount++
+			throttle.Failures = 0
+			lockedUntil = &until
+		}
+		return tx.Save(&throttle).Error
+	})
+	return lockedUntil, err
+}
+
+// RecordLoginFailure mencatat login gagal untuk akun (email) dan IP. Nilai kembalian tidak nil
+// jika percobaan ini mengunci akun atau IP tersebut.
+func RecordLoginFailure(db *gorm.DB, email, ip string, now time.Time) (accountLockedUntil, ipLockedUntil *time.Time, err error) {
+	if email != "" {
+		if accountLockedUntil, err = recordFailure(db, models.LoginThrottleAccount, email, loginAccountLockAt, now); err != nil {
+			return nil, nil, err
+		}
+	}
+	if ip != "" {
+		if ipLockedUntil, err = recordFailure(db, models.LoginThrottleIP, ip, loginIPLockAt, now); err != nil {
+			return nil, nil, err
+		}
+	}
+	return accountLockedUntil, ipLockedUntil, nil
+}
+
+// ResetLoginFailures menghapus hitungan kegagalan akun setelah login berhasil. Riwayat LockCount tetap
+// disimpan; hitungan IP tidak direset agar satu akun valid tidak bisa membuka kunci IP penyerang.
+func ResetLoginFailures(db *gorm.DB, email string) error {
+	return db.Model(&models.LoginThrottle{}).
+		Where("kind = ? AND `key` = ?", models.LoginThrottleAccount, loginThrottleKey(models.LoginThrottleAccount, email)).
+		Updates(map[string]interface{}{"failures": 0, "last_failure_at": nil, "locked_until": nil, "updated_at": time.Now()}).Error
+}
+
+// UnlockLoginThrottle membuka lockout (dipakai admin) dan mereset hitungan kegagalan serta LockCount.
+func UnlockLoginThrottle(db *gorm.DB, id uint) error {
+	return db.Model(&models.LoginThrottle{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"failures": 0, "lock_count": 0, "last_failure_at": nil, "locked_until": nil, "updated_at": time.Now()}).Error
+}
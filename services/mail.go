@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/smtp"
@@ -152,11 +153,46 @@ func SendEmailToRecipient(rec models.Recipient, camp models.Campaign) {
 	backendBase := "localhost:3000"
 	frontendDomain := "localhost:5173"
 
+	// Exclusion bisa ditambahkan setelah recipient dibuat; periksa ulang tepat sebelum mengirim
+	excluded, err := CheckExclusion(config.DB, camp.OrganizationID, rec.Email)
+	if err != nil {
+		config.DB.Model(&rec).Updates(models.Recipient{Status: "failed", Error: "Failed to check exclusion list: " + err.Error()})
+		return
+	}
+	if excluded != nil {
+		config.DB.Model(&rec).Updates(models.Recipient{Status: models.RecipientExcluded, ExclusionID: &excluded.ID, Error: ExclusionReason(excluded)})
+		return
+	}
+
+	// Verifikasi domain bisa dicabut atau gagal saat re-check setelah recipient dijadwalkan
+	allowedDomains, err := LoadDomainAllowList(config.DB, camp.OrganizationID)
+	if err != nil {
+		config.DB.Model(&rec).Updates(models.Recipient{Status: "failed", Error: "Failed to load verified domains: " + err.Error()})
+		return
+	}
+	if !allowedDomains.Allows(rec.Email) {
+		config.DB.Model(&rec).Updates(models.Recipient{Status: models.RecipientDomainUnverified, Error: "Domain " + EmailDomain(rec.Email) + " is not a verified target domain"})
+		return
+	}
+
+	// Blackout bisa dibuat saat campaign berjalan; recipient dijadwalkan ulang setelah blackout berakhir
+	blackouts, err := LoadBlackoutCalendar(config.DB, camp.OrganizationID, time.Now())
+	if err != nil {
+		config.DB.Model(&rec).Updates(models.Recipient{Status: "failed", Error: "Failed to load blackout calendar: " + err.Error()})
+		return
+	}
+	if now := time.Now(); blackouts.Active(now) != nil {
+		resumeAt := blackouts.ClearAfter(now)
+		config.DB.Model(&rec).Updates(models.Recipient{Status: models.RecipientScheduled, ScheduledAt: &resumeAt})
+		return
+	}
+
 	// --- AMBIL NAMA RECIPIENT DARI GROUP MEMBER ---
+	// rec.UserID adalah ID Member; untuk group dynamic member berasal dari group lain
 	var recipientName string
 	var gm models.Member
-	err := config.DB.
-		Where("group_id = ? AND email = ?", camp.GroupID, rec.Email).
+	err = config.DB.
+		Where("id = ? AND email = ?", rec.UserID, rec.Email).
 		First(&gm).Error
 	if err != nil {
 		// fallback ke email jika nama tidak ditemukan
@@ -165,18 +201,51 @@ func SendEmailToRecipient(rec models.Recipient, camp models.Campaign) {
 		recipientName = gm.Name
 	}
 
+	// Custom attribute member tersedia sebagai {{.Attributes.<key>}}; key yang tidak diisi bernilai kosong
+	attrDefs, err := LoadMemberAttributes(config.DB, camp.OrganizationID)
+	if err != nil {
+		log.Printf("Failed to load member attributes: %v", err)
+	}
+
 	// Data untuk template (body dan subject)
 	templateData := map[string]interface{}{
-		"Name":  recipientName,
-		"Email": rec.Email,
-		"LandingURL": fmt.Sprintf(
-			"http://%s/lander?rid=%s&campaign=%d&page=%d",
-			frontendDomain, rec.UID, camp.ID, camp.LandingPageID,
-		),
+		"Name":       recipientName,
+		"Email":      rec.Email,
+		"LandingURL": LandingPageURL(rec.UID, camp.ID, camp.LandingPageID),
+		"Attributes": MemberAttributeStrings(gm.Attributes, attrDefs),
 	}
 
 	// 1. Render email body
-	tplBody, _ := template.New("emailBody").Parse(camp.EmailTemplate.Body)
+	// {{qrcode}} menyisipkan QR code inline (CID), {{qrcodeAttachment}} melampirkannya sebagai file
+	var qrPNG []byte
+	qrInline, qrAttached := false, false
+	buildQR := func() bool {
+		if qrPNG == nil {
+			png, err := QRCodePNG(QRScanURL(rec.UID), 256)
+			if err != nil {
+				log.Printf("Failed to build QR code for %s: %v", rec.Email, err)
+				return false
+			}
+			qrPNG = png
+		}
+		return true
+	}
+	bodyFuncs := template.FuncMap{
+		"qrcode": func() string {
+			if !buildQR() {
+				return ""
+			}
+			qrInline = true
+			return fmt.Sprintf(`<img src="cid:%s" alt="QR code" width="200" height="200"/>`, QRCodeFileName)
+		},
+		"qrcodeAttachment": func() string {
+			if buildQR() {
+				qrAttached = true
+			}
+			return ""
+		},
+	}
+	tplBody, _ := template.New("emailBody").Funcs(bodyFuncs).Parse(camp.EmailTemplate.Body)
 	var bufBody bytes.Buffer
 	tplBody.Execute(&bufBody, templateData)
 	body := bufBody.String()
@@ -194,41 +263,7 @@ func SendEmailToRecipient(rec models.Recipient, camp models.Campaign) {
 	)
 	body += pixel
 
-	// Ambil bahasa dari template email
-	emailLanguage := camp.EmailTemplate.Language
-	var reportButtonText string
-	var reportIntroText string
-	switch emailLanguage {
-	case "Indonesia":
-		reportButtonText = "Laporkan Email Ini"
-		reportIntroText = "Jika Anda yakin email ini adalah phishing, silakan"
-	case "English":
-		reportButtonText = "Report This Email"
-		reportIntroText = "If you believe this email is phishing, please"
-	default:
-		reportButtonText = "Report This Email"
-		reportIntroText = "If you believe this email is phishing, please"
-	}
-
-	// 4. Tambahkan tombol “Laporkan Email Ini” dengan styling mirip Gmail
-	// Menggunakan gaya yang lebih subtle dan teks yang disesuaikan bahasa
-	reportLink := fmt.Sprintf(`
-      <div style="text-align:center; margin-top:20px; font-family:Arial, sans-serif; font-size:12px; color:#999;">
-        %s <a href="http://%s/track/report?rid=%s&campaign=%d"
-           style="
-             color:#1a73e8; /* Warna biru mirip Gmail */
-             text-decoration:none;
-           ">
-          %s
-        </a>.
-      </div>`,
-		reportIntroText, // Menggunakan variabel teks pengantar
-		backendBase, rec.UID, camp.ID,
-		reportButtonText,
-	)
-	body += reportLink
-
-	// 5. Rewrite click links (termasuk placeholder {{.Name}} & {{.Email}})
+	// 4. Rewrite click links (termasuk placeholder {{.Name}} & {{.Email}})
 	body = RewriteLinks( // Asumsi RewriteLinks sudah didefinisikan
 		body,
 		rec.UID,
@@ -239,18 +274,56 @@ func SendEmailToRecipient(rec models.Recipient, camp models.Campaign) {
 		rec.Email,
 	)
 
-	// 6. SMTP send
+	// 5. SMTP send
+	// Message-ID dipakai add-in report untuk mencocokkan laporan dengan recipient
+	messageID := BuildMessageID(rec.UID, camp.SendingProfile.SmtpFrom)
+	config.DB.Model(&rec).Update("message_id", messageID)
+
 	m := gomail.NewMessage()
 	m.SetHeader("From", camp.SendingProfile.SmtpFrom)
 	m.SetHeader("To", rec.Email)
 	m.SetHeader("Subject", subject) // Gunakan subject yang sudah di-render
+	m.SetHeader("Message-ID", messageID)
 	m.SetBody("text/html", body)
 
+	// 6. QR code quishing
+	writeQR := gomail.SetCopyFunc(func(w io.Writer) error {
+		_, err := w.Write(qrPNG)
+		return err
+	})
+	if qrInline {
+		m.Embed(QRCodeFileName, writeQR)
+	}
+	if qrAttached {
+		m.Attach(QRCodeFileName, writeQR)
+	}
+
+	// 7. Attachment lure dibangkitkan per recipient dengan tracking token
+	var attachments []models.EmailAttachment
+	config.DB.Where("email_template_id = ?", camp.EmailTemplateID).Find(&attachments)
+	for _, att := range attachments {
+		fileName, file, err := BuildAttachment(att, rec.UID, templateData)
+		if err != nil {
+			log.Printf("Failed to build attachment %d for %s: %v", att.ID, rec.Email, err)
+			continue
+		}
+		m.Attach(fileName, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(file)
+			return err
+		}))
+	}
+
+	password, err := DecryptSecret(camp.SendingProfile.Password)
+	if err != nil {
+		config.DB.Model(&rec).Updates(models.Recipient{Status: "failed", Error: err.Error()})
+		return
+	}
+
 	d := gomail.NewDialer(
 		camp.SendingProfile.Host,
 		camp.SendingProfile.Port,
 		camp.SendingProfile.Username,
-		camp.SendingProfile.Password,
+		password,
 	)
 
 	if err := d.DialAndSend(m); err != nil {
@@ -284,7 +357,7 @@ func MonitorCampaignStatus(campaignID uint) {
 
 		config.DB.
 			Model(&models.Recipient{}).
-			Where("campaign_id = ? AND status IN ?", campaignID, []string{"sent", "failed"}).
+			Where("campaign_id = ? AND status IN ?", campaignID, []string{"sent", "failed", models.RecipientExcluded, models.RecipientDomainUnverified}).
 			Count(&done)
 
 		// 3. Penanganan deadline SendEmailBy
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const memberAttributeMaxLen = 255
+
+var memberAttributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// memberAttributeDateLayouts adalah format tanggal yang diterima; XLSX memakai mm-dd-yy untuk sel tanggal.
+var memberAttributeDateLayouts = []string{"2006-01-02", time.RFC3339, "2006/01/02", "02/01/2006", "01-02-06"}
+
+// ValidateMemberAttributeKey memastikan key aman dipakai sebagai path JSON dan tidak bentrok dengan field bawaan.
+func ValidateMemberAttributeKey(key string) error {
+	if !memberAttributeKeyPattern.MatchString(key) {
+		return errors.New("key must start with a letter and contain only lowercase letters, digits and underscores")
+	}
+	for _, f := range MemberFields {
+		if f.Name == key {
+			return fmt.Errorf("key %q is reserved for a built-in member field", key)
+		}
+	}
+	return nil
+}
+
+// LoadMemberAttributes mengembalikan definisi custom attribute organisasi, dikunci oleh Key.
+func LoadMemberAttributes(db *gorm.DB, organizationID uint) (map[string]models.MemberAttribute, error) {
+	var defs []models.MemberAttribute
+	if err := db.Scopes(InOrganization(organizationID)).Find(&defs).Error; err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]models.MemberAttribute, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+	}
+	return byKey, nil
+}
+
+// NormalizeMemberAttributeValue mengubah nilai input menjadi nilai bertipe sesuai definisi.
+// Nilai kosong menghasilkan nil, artinya attribute dihapus dari member.
+func NormalizeMemberAttributeValue(def models.MemberAttribute, raw interface{}) (interface{}, error) {
+	var text string
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		text = strings.TrimSpace(v)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		text = v.String()
+	case bool:
+		text = strconv.FormatBool(v)
+	default:
+		return nil, fmt.Errorf("%s must be a %s", def.Key, def.Type)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	switch def.Type {
+	case models.MemberAttributeNumber:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("%s must be a number", def.Key)
+		}
+		return n, nil
+	case models.MemberAttributeDate:
+		for _, layout := range memberAttributeDateLayouts {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t.Format("2006-01-02"), nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be a date in YYYY-MM-DD format", def.Key)
+	case models.MemberAttributeBoolean:
+		switch strings.ToLower(text) {
+		case "true", "yes", "y", "1", "ya":
+			return true, nil
+		case "false", "no", "n", "0", "tidak":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s must be true or false", def.Key)
+	default:
+		if utf8.RuneCountInString(text) > memberAttributeMaxLen {
+			return nil, fmt.Errorf("%s must be at most %d characters", def.Key, memberAttributeMaxLen)
+		}
+		return text, nil
+	}
+}
+
+// NormalizeMemberAttributes memvalidasi input attribute terhadap definisi. Key yang tidak dikenal ditolak;
+// nilai kosong tetap ada di hasil sebagai nil agar bisa dipakai untuk menghapus attribute.
+func NormalizeMemberAttributes(defs map[string]models.MemberAttribute, input map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(input))
+	for key, raw := range input {
+		def, ok := defs[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown member attribute %q", key)
+		}
+		v, err := NormalizeMemberAttributeValue(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = v
+	}
+	return values, nil
+}
+
+// MergeMemberAttributes menerapkan nilai baru di atas attribute yang tersimpan; nilai nil menghapus key.
+func MergeMemberAttributes(existing datatypes.JSON, values map[string]interface{}) (datatypes.JSON, error) {
+	merged := map[string]interface{}{}
+	if len(existing) > 0 {
+		if err := json.Unmarshal(existing, &merged); err != nil {
+			merged = map[string]interface{}{}
+		}
+	}
+	for key, v := range values {
+		if v == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = v
+		}
+	}
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(merged)
+	return datatypes.JSON(b), err
+}
+
+// BuildMemberAttributes memvalidasi input attribute dan mengembalikannya sebagai JSON untuk member baru.
+func BuildMemberAttributes(defs map[string]models.MemberAttribute, input map[string]interface{}) (datatypes.JSON, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+	values, err := NormalizeMemberAttributes(defs, input)
+	if err != nil {
+		return nil, err
+	}
+	return MergeMemberAttributes(nil, values)
+}
+
+// MemberAttributeStrings mengembalikan nilai attribute sebagai teks untuk template dan export.
+// Semua key yang terdefinisi selalu ada (kosong jika member tidak mengisinya).
+func MemberAttributeStrings(raw datatypes.JSON, defs map[string]models.MemberAttribute) map[string]string {
+	out := make(map[string]string, len(defs))
+	for key := range defs {
+		out[key] = ""
+	}
+	var values map[string]interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &values) != nil {
+		return out
+	}
+	for key, v := range values {
+		switch t := v.(type) {
+		case float64:
+			out[key] = strconv.FormatFloat(t, 'f', -1, 64)
+		case bool:
+			out[key] = strconv.FormatBool(t)
+		case string:
+			out[key] = t
+		}
+	}
+	return out
+}
+
+// SortedMemberAttributeKeys mengembalikan key definisi secara berurutan (untuk kolom export).
+func SortedMemberAttributeKeys(defs map[string]models.MemberAttribute) []string {
+	keys := make([]string, 0, len(defs))
+	for key := range defs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MemberAttributeSQL mengembalikan ekspresi SQL nilai attribute pada kolom JSON tertentu.
+// Key harus sudah lolos ValidateMemberAttributeKey.
+func MemberAttributeSQL(column, key string) string {
+	return fmt.Sprintf(`JSON_UNQUOTE(JSON_EXTRACT(%s, '$."%s"'))`, column, key)
+}
+
+// RecipientAttributes menyalin profil member (field bawaan dan custom attribute) ke recipient saat
+// campaign dikirim, sehingga breakdown laporan tidak berubah jika data member diubah kemudian.
+func RecipientAttributes(m models.Member) datatypes.JSON {
+	profile := map[string]interface{}{}
+	if len(m.Attributes) > 0 {
+		_ = json.Unmarshal(m.Attributes, &profile)
+	}
+	profile["position"] = m.Position
+	profile["company"] = m.Company
+	profile["country"] = m.Country
+	b, err := json.Marshal(profile)
+	if err != nil {
+		return nil
+	}
+	return datatypes.JSON(b)
+}
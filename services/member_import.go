@@ -0,0 +1,319 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MemberFields adalah field Member yang dapat diimpor beserta batas panjang kolomnya.
+var MemberFields = []struct {
+	Name     string
+	Required bool
+	MaxLen   int
+}{
+	{"name", true, 30},
+	{"email", true, 50},
+	{"position", true, 30},
+	{"company", false, 50},
+	{"country", false, 50},
+	{"timezone", false, 64},
+}
+
+// memberHeaderAliases dipakai untuk menebak mapping jika client tidak mengirim mapping.
+var memberHeaderAliases = map[string][]string{
+	"name":     {"name", "full name", "fullname", "nama", "nama lengkap", "employee name"},
+	"email":    {"email", "e-mail", "email address", "mail", "alamat email"},
+	"position": {"position", "job title", "title", "jabatan", "posisi"},
+	"company":  {"company", "organization", "organisation", "perusahaan", "department", "departemen"},
+	"country":  {"country", "negara", "location", "lokasi"},
+	"timezone": {"timezone", "time zone", "tz", "zona waktu"},
+}
+
+// ReadMemberFile membaca file CSV atau XLSX menjadi baris-baris string (baris pertama adalah header).
+func ReadMemberFile(filename string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM dari Excel
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		// Export Excel berlocale Indonesia memakai ';' sebagai pemisah
+		firstLine, _, _ := strings.Cut(string(data), "\n")
+		if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+			reader.Comma = ';'
+		}
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV file: %w", err)
+		}
+		return rows, nil
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX file: %w", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("XLSX file has no sheets")
+		}
+		rows, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX file: %w", err)
+		}
+		return rows, nil
+	default:
+		return nil, errors.New("unsupported file type, use .csv or .xlsx")
+	}
+}
+
+// ResolveMemberMapping mencocokkan mapping field -> nama kolom header menjadi field -> index kolom.
+// Field yang tidak ada di mapping ditebak dari nama header. Custom attribute memakai field "attr.<key>"
+// dan ditebak dari kolom yang bernama sama dengan key atau label attribute.
+func ResolveMemberMapping(header []string, mapping map[string]string, attrs map[string]models.MemberAttribute) (map[string]int, map[string]string, error) {
+	index := map[string]int{}
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, exists := index[key]; !exists {
+			index[key] = i
+		}
+	}
+
+	columns := map[string]int{}
+	resolved := map[string]string{}
+	claimed := map[int]bool{}
+	for _, key := range SortedMemberAttributeKeys(attrs) {
+		field := "attr." + key
+		if col, ok := mapping[field]; ok && strings.TrimSpace(col) != "" {
+			i, found := index[strings.ToLower(strings.TrimSpace(col))]
+			if !found {
+				return nil, nil, fmt.Errorf("mapped column %q for field %s not found in header", col, field)
+			}
+			columns[field], resolved[field], claimed[i] = i, header[i], true
+			continue
+		}
+		for _, name := range []string{key, strings.ToLower(strings.TrimSpace(attrs[key].Label))} {
+			if i, found := index[name]; found && !claimed[i] {
+				columns[field], resolved[field], claimed[i] = i, header[i], true
+				break
+			}
+		}
+	}
+	for field := range mapping {
+		if _, ok := columns[field]; strings.HasPrefix(field, "attr.") && !ok {
+			return nil, nil, fmt.Errorf("unknown member attribute %q in mapping", strings.TrimPrefix(field, "attr."))
+		}
+	}
+
+	for _, f := range MemberFields {
+		if col, ok := mapping[f.Name]; ok && strings.TrimSpace(col) != "" {
+			i, found := index[strings.ToLower(strings.TrimSpace(col))]
+			if !found {
+				return nil, nil, fmt.Errorf("mapped column %q for field %s not found in header", col, f.Name)
+			}
+			columns[f.Name] = i
+			resolved[f.Name] = header[i]
+			continue
+		}
+		for _, alias := range memberHeaderAliases[f.Name] {
+			if i, found := index[alias]; found && !claimed[i] {
+				columns[f.Name] = i
+				resolved[f.Name] = header[i]
+				break
+			}
+		}
+	}
+
+	for _, f := range MemberFields {
+		if _, ok := columns[f.Name]; f.Required && !ok {
+			return nil, nil, fmt.Errorf("no column mapped for required field %s", f.Name)
+		}
+	}
+	return columns, resolved, nil
+}
+
+// ValidateMemberRows mengubah baris file menjadi anggota valid dan daftar error per baris.
+// Nomor baris mengikuti file (header = baris 1).
+func ValidateMemberRows(rows [][]string, columns map[string]int, attrs map[string]models.MemberAttribute) ([]models.MemberImportRow, []models.MemberImportRowError) {
+	var valid []models.MemberImportRow
+	var rowErrors []models.MemberImportRowError
+	seen := map[string]int{}
+
+	for i, row := range rows {
+		rowNum := i + 2
+		values := map[string]string{}
+		empty := true
+		for field, col := range columns {
+			if col < len(row) {
+				values[field] = strings.TrimSpace(row[col])
+				if values[field] != "" {
+					empty = false
+				}
+			}
+		}
+		if empty {
+			continue // baris kosong di akhir file
+		}
+
+		var errs []models.MemberImportRowError
+		for _, f := range MemberFields {
+			v := values[f.Name]
+			if f.Required && v == "" {
+				errs = append(errs, models.MemberImportRowError{Row: rowNum, Field: f.Name, Message: f.Name + " is required"})
+				continue
+			}
+			if utf8.RuneCountInString(v) > f.MaxLen {
+				errs = append(errs, models.MemberImportRowError{Row: rowNum, Field: f.Name, Value: v, Message: fmt.Sprintf("%s must be at most %d characters", f.Name, f.MaxLen)})
+			}
+		}
+
+		timezone, err := NormalizeTimezone(values["timezone"])
+		if err != nil {
+			errs = append(errs, models.MemberImportRowError{Row: rowNum, Field: "timezone", Value: values["timezone"], Message: err.Error()})
+		}
+
+		// Sel attribute yang kosong menghapus nilai attribute member (nil)
+		var attributes map[string]interface{}
+		for field := range columns {
+			key, isAttr := strings.CutPrefix(field, "attr.")
+			if !isAttr {
+				continue
+			}
+			v, err := NormalizeMemberAttributeValue(attrs[key], values[field])
+			if err != nil {
+				errs = append(errs, models.MemberImportRowError{Row: rowNum, Field: field, Value: values[field], Message: err.Error()})
+				continue
+			}
+			if attributes == nil {
+				attributes = map[string]interface{}{}
+			}
+			attributes[key] = v
+		}
+
+		email := values["email"]
+		if email != "" {
+			if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+				errs = append(errs, models.MemberImportRowError{Row: rowNum, Field: "email", Value: email, Message: "invalid email address"})
+			} else if first, dup := seen[strings.ToLower(email)]; dup {
+				errs = append(errs, models.MemberImportRowError{Row: rowNum, Field: "email", Value: email, Message: fmt.Sprintf("duplicate email, already used in row %d", first)})
+			} else {
+				seen[strings.ToLower(email)] = rowNum
+			}
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+		valid = append(valid, models.MemberImportRow{
+			Row: rowNum,
+			Member: models.NewMember{
+				Name:       values["name"],
+				Email:      email,
+				Position:   values["position"],
+				Company:    values["company"],
+				Country:    values["country"],
+				Timezone:   timezone,
+				Attributes: attributes,
+			},
+		})
+	}
+	return valid, rowErrors
+}
+
+// memberExport menyusun header dan baris export; kolom custom attribute memakai key sebagai header
+// agar file bisa diimpor ulang.
+type memberExport struct {
+	attrs map[string]models.MemberAttribute
+	keys  []string
+}
+
+func newMemberExport(attrs map[string]models.MemberAttribute) memberExport {
+	return memberExport{attrs: attrs, keys: SortedMemberAttributeKeys(attrs)}
+}
+
+func (e memberExport) header() []string {
+	return append([]string{"Name", "Email", "Position", "Company", "Country", "Timezone"}, e.keys...)
+}
+
+func (e memberExport) row(m models.Member) []string {
+	row := []string{m.Name, m.Email, m.Position, m.Company, m.Country, m.Timezone}
+	values := MemberAttributeStrings(m.Attributes, e.attrs)
+	for _, key := range e.keys {
+		row = append(row, values[key])
+	}
+	return row
+}
+
+// WriteMembersCSV menulis anggota group dalam format CSV dengan header yang bisa diimpor ulang.
+func WriteMembersCSV(w io.Writer, members []models.Member, attrs map[string]models.MemberAttribute) error {
+	export := newMemberExport(attrs)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(export.header()); err != nil {
+		return err
+	}
+	for _, m := range members {
+		row := export.row(m)
+		for i, v := range row {
+			row[i] = csvSafe(v)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMembersXLSX menulis anggota group sebagai workbook XLSX satu sheet.
+func WriteMembersXLSX(w io.Writer, members []models.Member, attrs map[string]models.MemberAttribute) error {
+	export := newMemberExport(attrs)
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+
+	write := func(rowNum int, values []string) error {
+		cell, err := excelize.CoordinatesToCellName(1, rowNum)
+		if err != nil {
+			return err
+		}
+		row := make([]interface{}, len(values))
+		for i, v := range values {
+			row[i] = v
+		}
+		return f.SetSheetRow(sheet, cell, &row)
+	}
+
+	if err := write(1, export.header()); err != nil {
+		return err
+	}
+	for i, m := range members {
+		if err := write(i+2, export.row(m)); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}
+
+// csvSafe mencegah formula injection saat file CSV dibuka di spreadsheet.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
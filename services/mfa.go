@@ -0,0 +1,359 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Parameter TOTP (RFC 6238) yang didukung semua aplikasi authenticator umum.
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	totpSkew   = 1 // toleransi satu time step sebelum/sesudah untuk jam perangkat yang meleset
+
+	mfaRecoveryCodeCount = 10
+	MfaChallengeTTL      = 5 * time.Minute
+	mfaChallengeAttempts = 5 // per challenge; kegagalan juga dihitung di throttle akun/IP (lihat RecordLoginFailure)
+)
+
+var (
+	ErrMfaChallengeInvalid = errors.New("MFA challenge is invalid or expired")
+	ErrMfaCodeInvalid      = errors.New("invalid verification code")
+	ErrMfaNotEnrolled      = errors.New("MFA enrollment has not been started")
+	ErrMfaAlreadyEnabled   = errors.New("MFA is already enabled")
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// mfaIssuer adalah nama yang tampil di aplikasi authenticator.
+func mfaIssuer() string {
+	if issuer := strings.TrimSpace(os.Getenv("MFA_ISSUER")); issuer != "" {
+		return issuer
+	}
+	return "Awarenix"
+}
+
+// GenerateTOTPSecret membuat secret acak 160-bit dalam base32 tanpa padding.
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// totpAt menghitung kode TOTP untuk time step tertentu (HMAC-SHA1, dynamic truncation).
+func totpAt(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// TOTPCode mengembalikan kode TOTP yang berlaku pada t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	return totpAt(secret, t.Unix()/totpPeriod)
+}
+
+// matchTOTP mencari time step dalam rentang toleransi yang cocok dengan code dan lebih baru dari lastStep.
+func matchTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := totpAt(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPProvisioningURI membangun URI otpauth:// yang dibaca aplikasi authenticator dari QR code.
+func TOTPProvisioningURI(secret, account string) string {
+	issuer := mfaIssuer()
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// MfaRequiredForRole melaporkan apakah kebijakan organisasi mewajibkan MFA untuk role tersebut.
+func MfaRequiredForRole(db *gorm.DB, roleID int) (bool, error) {
+	var count int64
+	err := db.Model(&models.MfaRolePolicy{}).Where("role_id = ?", roleID).Count(&count).Error
+	return count > 0, err
+}
+
+// MfaEnabled melaporkan apakah user sudah mengaktifkan MFA.
+func MfaEnabled(db *gorm.DB, userID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.UserMfa{}).Where("user_id = ? AND enabled = ?", userID, true).Count(&count).Error
+	return count > 0, err
+}
+
+// BeginMfaEnrollment membuat (atau mengganti) secret yang belum aktif untuk user.
+func BeginMfaEnrollment(db *gorm.DB, userID uint, account string) (*models.MfaEnrollment, error) {
+	var mfa models.UserMfa
+	err := db.Where("user_id = ?", userID).First(&mfa).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := EncryptSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	mfa.UserID, mfa.Secret, mfa.LastStep, mfa.UpdatedAt = userID, encrypted, 0, now
+	if mfa.ID == 0 {
+		mfa.CreatedAt = now
+	}
+	if err := db.Save(&mfa).Error; err != nil {
+		return nil, err
+	}
+
+	uri := TOTPProvisioningURI(secret, account)
+	png, err := QRCodePNG(uri, 256)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MfaEnrollment{
+		Secret:          secret,
+		ProvisioningURI: uri,
+		QRCode:          "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// checkTOTP memverifikasi code terhadap secret user dan mencatat time step-nya agar tidak bisa dipakai ulang.
+func checkTOTP(db *gorm.DB, mfa *models.UserMfa, code string) error {
+	secret, err := DecryptSecret(mfa.Secret)
+	if err != nil {
+		return err
+	}
+	step, ok := matchTOTP(secret, code, time.Now(), mfa.LastStep)
+	if !ok {
+		return ErrMfaCodeInvalid
+	}
+	// Update bersyarat: dua request bersamaan dengan kode yang sama hanya satu yang lolos
+	res := db.Model(&models.UserMfa{}).Where("id = ? AND last_step < ?", mfa.ID, step).
+		Updates(map[string]interface{}{"last_step": step, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMfaCodeInvalid
+	}
+	mfa.LastStep = step
+	return nil
+}
+
+// ActivateMfa mengaktifkan MFA setelah kode pertama dari authenticator valid, lalu menerbitkan recovery code.
+func ActivateMfa(db *gorm.DB, userID uint, code string) ([]string, error) {
+	var mfa models.UserMfa
+	if err := db.Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMfaNotEnrolled
+		}
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	if err := checkTOTP(db, &mfa, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&mfa).Updates(map[string]interface{}{"enabled": true, "enabled_at": now}).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// normalizeRecoveryCode mengabaikan huruf besar/kecil, spasi dan tanda hubung.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
+
+// replaceRecoveryCodes menghapus recovery code lama dan membuat set baru. Plaintext hanya dikembalikan sekali.
+func replaceRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	if err := db.Where("user_id = ?", userID).Delete(&models.MfaRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	rows := make([]models.MfaRecoveryCode, 0, mfaRecoveryCodeCount)
+	now := time.Now()
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(buf)) // 8 karakter
+		code := raw[:4] + "-" + raw[4:]
+		codes = append(codes, code)
+		rows = append(rows, models.MfaRecoveryCode{UserID: userID, CodeHash: hashRefreshToken(normalizeRecoveryCode(code)), CreatedAt: now})
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes mengganti semua recovery code user yang MFA-nya aktif.
+func RegenerateRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// VerifyMfa memeriksa kode TOTP atau recovery code (sekali pakai) untuk user yang MFA-nya aktif.
+func VerifyMfa(db *gorm.DB, userID uint, code, recoveryCode string) error {
+	var mfa models.UserMfa
+	if err := db.Where("user_id = ? AND enabled = ?", userID, true).First(&mfa).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrMfaNotEnrolled
+		}
+		return err
+	}
+	if strings.TrimSpace(code) != "" {
+		return checkTOTP(db, &mfa, code)
+	}
+	if strings.TrimSpace(recoveryCode) == "" {
+		return ErrMfaCodeInvalid
+	}
+	res := db.Model(&models.MfaRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRefreshToken(normalizeRecoveryCode(recoveryCode))).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMfaCodeInvalid
+	}
+	return nil
+}
+
+// RemainingRecoveryCodes menghitung recovery code yang belum dipakai.
+func RemainingRecoveryCodes(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.MfaRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// DisableMfa menghapus secret dan recovery code user.
+func DisableMfa(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserMfa{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.MfaRecoveryCode{}).Error
+	})
+}
+
+// CreateMfaChallenge menerbitkan token langkah kedua login yang berlaku MfaChallengeTTL.
+func CreateMfaChallenge(db *gorm.DB, userID uint, loginStatus string, refreshCookie, enrollment bool, ip string) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	plain := "mfa_" + base64.RawURLEncoding.EncodeToString(raw)
+	now := time.Now()
+	challenge := models.MfaChallenge{
+		UserID:        userID,
+		TokenHash:     hashRefreshToken(plain),
+		LoginStatus:   loginStatus,
+		RefreshCookie: refreshCookie,
+		Enrollment:    enrollment,
+		IPAddress:     ip,
+		ExpiresAt:     now.Add(MfaChallengeTTL),
+		CreatedAt:     now,
+	}
+	if err := db.Create(&challenge).Error; err != nil {
+		return "", time.Time{}, err
+	}
+	return plain, challenge.ExpiresAt, nil
+}
+
+// LoadMfaChallenge mengambil challenge yang masih berlaku dari token plaintext.
+func LoadMfaChallenge(db *gorm.DB, plain string) (*models.MfaChallenge, error) {
+	var challenge models.MfaChallenge
+	if err := db.Where("token_hash = ?", hashRefreshToken(strings.TrimSpace(plain))).First(&challenge).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMfaChallengeInvalid
+		}
+		return nil, err
+	}
+	if challenge.UsedAt != nil || !time.Now().Before(challenge.ExpiresAt) || challenge.Attempts >= mfaChallengeAttempts {
+		return nil, ErrMfaChallengeInvalid
+	}
+	return &challenge, nil
+}
+
+// FailMfaChallenge mencatat satu percobaan gagal; challenge tidak berlaku lagi setelah batas percobaan.
+func FailMfaChallenge(db *gorm.DB, challenge *models.MfaChallenge) error {
+	return db.Model(challenge).Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// ConsumeMfaChallenge menandai challenge terpakai. Hanya satu request yang bisa menukarnya dengan JWT.
+func ConsumeMfaChallenge(db *gorm.DB, challenge *models.MfaChallenge) error {
+	res := db.Model(&models.MfaChallenge{}).Where("id = ? AND used_at IS NULL", challenge.ID).Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMfaChallengeInvalid
+	}
+	return nil
+}
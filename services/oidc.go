@@ -0,0 +1,700 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"gorm.io/gorm"
+)
+
+// OIDCHTTPClient dipakai untuk discovery, JWKS dan token endpoint. Dapat diganti saat pengujian.
+var OIDCHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+const (
+	OidcStateTTL       = 10 * time.Minute // batas waktu user menyelesaikan login di IdP
+	OidcLoginCodeTTL   = 2 * time.Minute  // batas waktu frontend menukar kode serah-terima
+	oidcDiscoveryTTL   = time.Hour
+	oidcJWKSMinRefresh = 30 * time.Second // refetch JWKS untuk kid baru paling cepat setiap 30 detik
+	oidcDefaultScopes  = "openid email profile"
+)
+
+var (
+	ErrOidcStateInvalid     = errors.New("login request is invalid or has expired")
+	ErrOidcLoginCodeInvalid = errors.New("login code is invalid or has expired")
+	ErrOidcNoRole           = errors.New("your identity provider account is not mapped to any role")
+	ErrOidcNotProvisioned   = errors.New("no account exists for this identity and automatic provisioning is disabled")
+	ErrOidcUserInactive     = errors.New("account is not active")
+	ErrOidcEmailUnverified  = errors.New("an account with this email already exists but the identity provider did not verify the email")
+	ErrOidcEmailMissing     = errors.New("identity provider did not return an email address")
+	ErrOidcAccountNotLinked = errors.New("an account with this email exists but cannot be signed in with this identity provider")
+)
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type oidcJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type oidcProviderCache struct {
+	discovery     *oidcDiscovery
+	discoveredAt  time.Time
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+var (
+	oidcCacheMu sync.Mutex
+	oidcCache   = map[string]*oidcProviderCache{}
+)
+
+// OidcClaims adalah claim ID token yang dipakai untuk login dan provisioning.
+type OidcClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Raw           jwt.MapClaims
+}
+
+func oidcGetJSON(rawURL string, out interface{}) error {
+	resp, err := OIDCHTTPClient.Get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", rawURL, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+func oidcCacheEntry(issuer string) *oidcProviderCache {
+	entry, ok := oidcCache[issuer]
+	if !ok {
+		entry = &oidcProviderCache{}
+		oidcCache[issuer] = entry
+	}
+	return entry
+}
+
+// OidcDiscover mengambil (dan meng-cache) dokumen .well-known/openid-configuration milik issuer.
+func OidcDiscover(issuer string) (*oidcDiscovery, error) {
+	issuer = strings.TrimRight(strings.TrimSpace(issuer), "/")
+	oidcCacheMu.Lock()
+	entry := oidcCacheEntry(issuer)
+	if entry.discovery != nil && time.Since(entry.discoveredAt) < oidcDiscoveryTTL {
+		d := entry.discovery
+		oidcCacheMu.Unlock()
+		return d, nil
+	}
+	oidcCacheMu.Unlock()
+
+	var d oidcDiscovery
+	if err := oidcGetJSON(issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimRight(d.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer mismatch: expected %s, got %s", issuer, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksURI == "" {
+		return nil, errors.New("OIDC discovery document is missing required endpoints")
+	}
+
+	oidcCacheMu.Lock()
+	entry = oidcCacheEntry(issuer)
+	entry.discovery, entry.discoveredAt = &d, time.Now()
+	oidcCacheMu.Unlock()
+	return &d, nil
+}
+
+func oidcDecodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// parseOidcJWK mengubah JWK RSA atau EC menjadi public key.
+func parseOidcJWK(k oidcJWK) (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := oidcDecodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := oidcDecodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err := oidcDecodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := oidcDecodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// oidcSigningKey mencari public key berdasarkan kid; JWKS diambil ulang jika kid belum dikenal (rotasi key IdP).
+func oidcSigningKey(issuer, jwksURI, kid string) (interface{}, error) {
+	issuer = strings.TrimRight(issuer, "/")
+	oidcCacheMu.Lock()
+	entry := oidcCacheEntry(issuer)
+	key, ok := entry.keys[kid]
+	stale := time.Since(entry.keysFetchedAt) >= oidcJWKSMinRefresh
+	oidcCacheMu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := oidcGetJSON(jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	keys := map[string]interface{}{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := parseOidcJWK(k); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	oidcCacheMu.Lock()
+	entry = oidcCacheEntry(issuer)
+	entry.keys, entry.keysFetchedAt = keys, time.Now()
+	oidcCacheMu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	// Token tanpa kid dari IdP dengan satu key
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// OidcRedirectURL adalah redirect_uri yang didaftarkan di IdP untuk semua provider.
+func OidcRedirectURL() string {
+	return strings.TrimRight(os.Getenv("APP_URL"), "/") + "/api/v1/auth/oidc/callback"
+}
+
+func oidcRandom(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge menghitung code_challenge S256 dari code_verifier (RFC 7636).
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// BeginOidcLogin menyimpan state/nonce/PKCE verifier dan mengembalikan URL authorization IdP.
+func BeginOidcLogin(db *gorm.DB, provider models.OidcProvider, loginHint string) (string, error) {
+	d, err := OidcDiscover(provider.Issuer)
+	if err != nil {
+		return "", err
+	}
+	state, err := oidcRandom(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidcRandom(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidcRandom(48)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := db.Create(&models.OidcLoginState{
+		ProviderID:   provider.ID,
+		StateHash:    hashRefreshToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(OidcStateTTL),
+		CreatedAt:    now,
+	}).Error; err != nil {
+		return "", err
+	}
+
+	scopes := strings.TrimSpace(provider.Scopes)
+	if scopes == "" {
+		scopes = oidcDefaultScopes
+	}
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", provider.ClientID)
+	params.Set("redirect_uri", OidcRedirectURL())
+	params.Set("scope", scopes)
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", pkceChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+	if loginHint != "" {
+		params.Set("login_hint", loginHint)
+	}
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// claimString membaca claim string; kosong jika tidak ada atau bukan string.
+func claimString(claims jwt.MapClaims, name string) string {
+	v, _ := claims[name].(string)
+	return v
+}
+
+// claimValues membaca claim pada path bertitik sebagai daftar string (string tunggal atau array).
+func claimValues(claims map[string]interface{}, path string) []string {
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			if mc, ok := current.(jwt.MapClaims); ok {
+				m = mc
+			} else {
+				return nil
+			}
+		}
+		current = m[part]
+	}
+	switch v := current.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// verifyOidcIDToken memverifikasi tanda tangan dan claim standar ID token (iss, aud, azp, exp, nonce).
+func verifyOidcIDToken(provider models.OidcProvider, d *oidcDiscovery, rawToken, nonce string) (*OidcClaims, error) {
+	token, err := jwt.Parse(rawToken, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			// HS* (secret bersama) dan none tidak diterima untuk ID token
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return oidcSigningKey(provider.Issuer, d.JwksURI, kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid ID token: %v", err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid ID token claims")
+	}
+
+	if strings.TrimRight(claimString(claims, "iss"), "/") != strings.TrimRight(d.Issuer, "/") {
+		return nil, errors.New("ID token issuer mismatch")
+	}
+	audiences := claimValues(claims, "aud")
+	audOK := false
+	for _, aud := range audiences {
+		if aud == provider.ClientID {
+			audOK = true
+		}
+	}
+	if !audOK {
+		return nil, errors.New("ID token audience mismatch")
+	}
+	if azp := claimString(claims, "azp"); len(audiences) > 1 && azp != provider.ClientID {
+		return nil, errors.New("ID token authorized party mismatch")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("ID token has no expiry")
+	}
+	if claimString(claims, "nonce") != nonce {
+		return nil, errors.New("ID token nonce mismatch")
+	}
+	subject := claimString(claims, "sub")
+	if subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	result := &OidcClaims{
+		Subject: subject,
+		Email:   strings.TrimSpace(claimString(claims, "email")),
+		Name:    strings.TrimSpace(claimString(claims, "name")),
+		Raw:     claims,
+	}
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = v
+	case string:
+		result.EmailVerified = v == "true"
+	}
+	if result.Email == "" {
+		if preferred := claimString(claims, "preferred_username"); strings.Contains(preferred, "@") {
+			result.Email = preferred
+		}
+	}
+	return result, nil
+}
+
+// exchangeOidcCode menukar authorization code di token endpoint (client_secret_basic jika ada secret).
+func exchangeOidcCode(provider models.OidcProvider, d *oidcDiscovery, code, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", OidcRedirectURL())
+	form.Set("client_id", provider.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequest(http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if provider.ClientSecret != "" {
+		secret, err := DecryptSecret(provider.ClientSecret)
+		if err != nil {
+			return "", err
+		}
+		req.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(secret))
+	}
+
+	resp, err := OIDCHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if body.Error != "" {
+		return "", fmt.Errorf("token endpoint error: %s %s", body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || body.IDToken == "" {
+		return "", fmt.Errorf("token endpoint returned %s without an ID token", resp.Status)
+	}
+	return body.IDToken, nil
+}
+
+// CompleteOidcCallback memvalidasi state (sekali pakai), menukar code dan memverifikasi ID token.
+func CompleteOidcCallback(db *gorm.DB, state, code string) (*models.OidcLoginState, *models.OidcProvider, *OidcClaims, error) {
+	var loginState models.OidcLoginState
+	if err := db.Where("state_hash = ?", hashRefreshToken(state)).First(&loginState).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, nil, ErrOidcStateInvalid
+		}
+		return nil, nil, nil, err
+	}
+	now := time.Now()
+	if !now.Before(loginState.ExpiresAt) {
+		return nil, nil, nil, ErrOidcStateInvalid
+	}
+	res := db.Model(&models.OidcLoginState{}).Where("id = ? AND callback_at IS NULL", loginState.ID).Update("callback_at", now)
+	if res.Error != nil {
+		return nil, nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, nil, ErrOidcStateInvalid
+	}
+
+	var provider models.OidcProvider
+	if err := db.Preload("RoleMappings").Where("id = ? AND enabled = ?", loginState.ProviderID, true).First(&provider).Error; err != nil {
+		return nil, nil, nil, ErrOidcStateInvalid
+	}
+	d, err := OidcDiscover(provider.Issuer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	idToken, err := exchangeOidcCode(provider, d, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	claims, err := verifyOidcIDToken(provider, d, idToken, loginState.Nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &loginState, &provider, claims, nil
+}
+
+// MapOidcRole memilih role dari claim berdasarkan mapping (Priority kecil dulu), lalu DefaultRoleID.
+func MapOidcRole(provider models.OidcProvider, claims map[string]interface{}) uint {
+	if provider.RoleClaim != "" {
+		values := map[string]bool{}
+		for _, v := range claimValues(claims, provider.RoleClaim) {
+			values[strings.ToLower(v)] = true
+		}
+		var best *models.OidcRoleMapping
+		for i, m := range provider.RoleMappings {
+			if !values[strings.ToLower(m.ClaimValue)] {
+				continue
+			}
+			if best == nil || m.Priority < best.Priority || (m.Priority == best.Priority && m.ID < best.ID) {
+				best = &provider.RoleMappings[i]
+			}
+		}
+		if best != nil {
+			return best.RoleID
+		}
+	}
+	return provider.DefaultRoleID
+}
+
+// oidcProvisionOrganization menentukan organisasi user hasil AutoProvision: organisasi provider, atau
+// organisasi default jika provider tidak menentukannya.
+func oidcProvisionOrganization(db *gorm.DB, provider models.OidcProvider) (uint, error) {
+	if provider.OrganizationID != 0 {
+		return provider.OrganizationID, nil
+	}
+	return DefaultOrganizationID(db)
+}
+
+// OidcProvidersForOrganization membatasi query provider ke provider yang melayani organisasi: provider
+// milik organisasi itu, ditambah provider tanpa organisasi (0) jika organisasinya adalah organisasi default.
+func OidcProvidersForOrganization(db *gorm.DB, organizationID uint) (*gorm.DB, error) {
+	defaultID, err := DefaultOrganizationID(db)
+	if err != nil {
+		return nil, err
+	}
+	if organizationID == defaultID {
+		return db.Where("organization_id IN ?", []uint{0, organizationID}), nil
+	}
+	return db.Where("organization_id = ?", organizationID), nil
+}
+
+// ResolveOidcUser mencari user dari identity (atau email terverifikasi), membuatnya jika AutoProvision,
+// dan menyelaraskan role dari claim jika SyncRole. Provider hanya bisa masuk sebagai user organisasinya
+// sendiri; user lintas organisasi (organization:cross-tenant) tidak pernah ditautkan lewat email.
+func ResolveOidcUser(db *gorm.DB, provider models.OidcProvider, claims *OidcClaims) (*models.User, error) {
+	roleID := MapOidcRole(provider, claims.Raw)
+	if roleID == 0 {
+		return nil, ErrOidcNoRole
+	}
+	organizationID, err := oidcProvisionOrganization(db, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err = db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var identity models.UserIdentity
+		err := tx.Where("provider_id = ? AND subject = ?", provider.ID, claims.Subject).First(&identity).Error
+		switch {
+		case err == nil:
+			if err := tx.First(&user, identity.UserID).Error; err != nil {
+				return err
+			}
+			if user.OrganizationID != organizationID {
+				return ErrOidcAccountNotLinked
+			}
+		case err != gorm.ErrRecordNotFound:
+			return err
+		default:
+			if claims.Email == "" {
+				return ErrOidcEmailMissing
+			}
+			err := tx.Where("email = ?", claims.Email).First(&user).Error
+			switch {
+			case err == nil:
+				// Akun lokal hanya ditautkan jika IdP menjamin email tersebut milik user
+				if !claims.EmailVerified {
+					return ErrOidcEmailUnverified
+				}
+				// Email yang sama di organisasi lain bukan akun milik IdP ini
+				if user.OrganizationID != organizationID {
+					return ErrOidcAccountNotLinked
+				}
+				crossTenant, err := RoleHasPermission(tx, uint(user.Role), PermissionCrossTenant)
+				if err != nil {
+					return err
+				}
+				if crossTenant {
+					return ErrOidcAccountNotLinked
+				}
+			case err != gorm.ErrRecordNotFound:
+				return err
+			case !provider.AutoProvision:
+				return ErrOidcNotProvisioned
+			default:
+				name := claims.Name
+				if name == "" {
+					name, _, _ = strings.Cut(claims.Email, "@")
+				}
+				user = models.User{
+					OrganizationID: organizationID,
+					Name:           truncateRunes(name, 50),
+					Email:          claims.Email,
+					Position:       "-",
+					Role:           int(roleID),
+					IsActive:       1,
+					CreatedAt:      now,
+				}
+				if err := tx.Create(&user).Error; err != nil {
+					return err
+				}
+			}
+			identity = models.UserIdentity{UserID: user.ID, ProviderID: provider.ID, Subject: claims.Subject, CreatedAt: now}
+			if err := tx.Create(&identity).Error; err != nil {
+				return err
+			}
+		}
+
+		if user.IsActive == 0 {
+			return ErrOidcUserInactive
+		}
+		if provider.SyncRole && user.Role != int(roleID) {
+			user.Role = int(roleID)
+			if err := tx.Model(&user).Updates(map[string]interface{}{"role": roleID, "updated_at": now}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&identity).Updates(map[string]interface{}{"email": claims.Email, "last_login_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// IssueOidcLoginCode membuat kode serah-terima sekali pakai untuk frontend setelah callback berhasil.
+func IssueOidcLoginCode(db *gorm.DB, state *models.OidcLoginState, userID uint) (string, error) {
+	code, err := oidcRandom(32)
+	if err != nil {
+		return "", err
+	}
+	hash := hashRefreshToken(code)
+	exp := time.Now().Add(OidcLoginCodeTTL)
+	err = db.Model(state).Updates(map[string]interface{}{"user_id": userID, "login_code_hash": hash, "login_code_exp": exp}).Error
+	return code, err
+}
+
+// ConsumeOidcLoginCode menukar kode serah-terima dengan user ID; kode hanya bisa dipakai sekali.
+func ConsumeOidcLoginCode(db *gorm.DB, code string) (uint, error) {
+	var state models.OidcLoginState
+	if err := db.Where("login_code_hash = ?", hashRefreshToken(strings.TrimSpace(code))).First(&state).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, ErrOidcLoginCodeInvalid
+		}
+		return 0, err
+	}
+	if state.LoginCodeExp == nil || !time.Now().Before(*state.LoginCodeExp) || state.UserID == 0 {
+		return 0, ErrOidcLoginCodeInvalid
+	}
+	res := db.Model(&models.OidcLoginState{}).Where("id = ? AND used_at IS NULL", state.ID).Update("used_at", time.Now())
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrOidcLoginCodeInvalid
+	}
+	return state.UserID, nil
+}
+
+// LoadAuthSettings mengambil pengaturan autentikasi organisasi atau default jika belum diatur.
+func LoadAuthSettings(db *gorm.DB, organizationID uint) (models.AuthSettings, error) {
+	var settings models.AuthSettings
+	err := db.Where("organization_id = ?", organizationID).First(&settings).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.AuthSettings{OrganizationID: organizationID}, nil
+	}
+	return settings, err
+}
+
+// EnabledOidcProviderCount menghitung provider SSO aktif yang melayani organisasi.
+func EnabledOidcProviderCount(db *gorm.DB, organizationID uint) (int64, error) {
+	query, err := OidcProvidersForOrganization(db.Model(&models.OidcProvider{}), organizationID)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = query.Where("enabled = ?", true).Count(&count).Error
+	return count, err
+}
+
+// LocalLoginAllowed melaporkan apakah login email/password diizinkan untuk organisasi. Login lokal tetap
+// diizinkan jika organisasi tidak punya provider SSO aktif, agar tidak terkunci di luar aplikasi.
+func LocalLoginAllowed(db *gorm.DB, organizationID uint) (bool, error) {
+	settings, err := LoadAuthSettings(db, organizationID)
+	if err != nil || !settings.LocalLoginDisabled {
+		return true, err
+	}
+	providers, err := EnabledOidcProviderCount(db, organizationID)
+	if err != nil {
+		return true, err
+	}
+	return providers == 0, nil
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// MockOIDCUser adalah akun pada MockOIDCProvider.
+type MockOIDCUser struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string // dikirim sebagai claim "groups"
+}
+
+type mockOIDCCode struct {
+	user          MockOIDCUser
+	clientID      string
+	redirectURI   string
+	nonce         string
+	challenge     string
+	challengeType string
+	expiresAt     time.Time
+}
+
+// MockOIDCProvider adalah identity provider OpenID Connect minimal untuk pengujian login SSO tanpa IdP nyata.
+// Hanya dikompilasi untuk test karena authorize menyetujui login tanpa password.
+// Endpoint authorize langsung menyetujui user yang dipilih lewat login_hint (atau DefaultEmail).
+//
+//	mock, _ := services.NewMockOIDCProvider("awarenix", "secret")
+//	srv := httptest.NewServer(mock)
+//	mock.SetIssuer(srv.URL)
+type MockOIDCProvider struct {
+	ClientID     string
+	ClientSecret string // kosong = public client, hanya PKCE
+	DefaultEmail string
+
+	mu     sync.Mutex
+	issuer string
+	key    *rsa.PrivateKey
+	kid    string
+	users  map[string]MockOIDCUser
+	codes  map[string]mockOIDCCode
+}
+
+func NewMockOIDCProvider(clientID, clientSecret string) (*MockOIDCProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &MockOIDCProvider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		kid:          "mock-" + mockRandomID(4),
+		users:        map[string]MockOIDCUser{},
+		codes:        map[string]mockOIDCCode{},
+	}, nil
+}
+
+// SetIssuer mengatur URL dasar provider (mis. URL httptest.Server atau APP_URL + path mount).
+func (m *MockOIDCProvider) SetIssuer(issuer string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.issuer = strings.TrimRight(issuer, "/")
+}
+
+// Issuer mengembalikan URL issuer yang diumumkan provider.
+func (m *MockOIDCProvider) Issuer() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.issuer
+}
+
+// PutUser menambah atau mengganti akun berdasarkan email.
+func (m *MockOIDCProvider) PutUser(u MockOIDCUser) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u.Subject == "" {
+		u.Subject = "mock|" + strings.ToLower(u.Email)
+	}
+	m.users[strings.ToLower(u.Email)] = u
+}
+
+// RotateKey mengganti key penandatanganan (untuk menguji refetch JWKS).
+func (m *MockOIDCProvider) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.key, m.kid = key, "mock-"+mockRandomID(4)
+	return nil
+}
+
+func (m *MockOIDCProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/.well-known/openid-configuration"):
+		m.serveDiscovery(w)
+	case strings.HasSuffix(path, "/authorize"):
+		m.serveAuthorize(w, r)
+	case strings.HasSuffix(path, "/token") && r.Method == http.MethodPost:
+		m.serveToken(w, r)
+	case strings.HasSuffix(path, "/jwks"):
+		m.serveJWKS(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func mockRandomID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func mockWriteJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (m *MockOIDCProvider) serveDiscovery(w http.ResponseWriter) {
+	issuer := m.Issuer()
+	mockWriteJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256", "plain"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+	})
+}
+
+func (m *MockOIDCProvider) serveJWKS(w http.ResponseWriter) {
+	m.mu.Lock()
+	pub, kid := m.key.PublicKey, m.kid
+	m.mu.Unlock()
+	mockWriteJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *MockOIDCProvider) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	target, err := url.Parse(redirectURI)
+	if err != nil || redirectURI == "" || q.Get("client_id") != m.ClientID {
+		http.Error(w, "invalid client_id or redirect_uri", http.StatusBadRequest)
+		return
+	}
+	redirect := func(params url.Values) {
+		params.Set("state", q.Get("state"))
+		target.RawQuery = params.Encode()
+		http.Redirect(w, r, target.String(), http.StatusFound)
+	}
+	if q.Get("response_type") != "code" || !strings.Contains(" "+q.Get("scope")+" ", " openid ") {
+		redirect(url.Values{"error": {"invalid_request"}})
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(q.Get("login_hint")))
+	if email == "" {
+		email = strings.ToLower(m.DefaultEmail)
+	}
+	m.mu.Lock()
+	user, ok := m.users[email]
+	m.mu.Unlock()
+	if !ok {
+		redirect(url.Values{"error": {"access_denied"}, "error_description": {"unknown user"}})
+		return
+	}
+
+	code := mockRandomID(16)
+	m.mu.Lock()
+	m.codes[code] = mockOIDCCode{
+		user:          user,
+		clientID:      m.ClientID,
+		redirectURI:   redirectURI,
+		nonce:         q.Get("nonce"),
+		challenge:     q.Get("code_challenge"),
+		challengeType: q.Get("code_challenge_method"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	m.mu.Unlock()
+	redirect(url.Values{"code": {code}})
+}
+
+func (m *MockOIDCProvider) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		mockWriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID, clientSecret, basic := r.BasicAuth()
+	if basic {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != m.ClientID || (m.ClientSecret != "" && clientSecret != m.ClientSecret) {
+		mockWriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		mockWriteJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	m.mu.Lock()
+	grant, ok := m.codes[code]
+	delete(m.codes, code)
+	m.mu.Unlock()
+	if !ok || time.Now().After(grant.expiresAt) || grant.clientID != clientID || grant.redirectURI != r.PostForm.Get("redirect_uri") {
+		mockWriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	verifier := r.PostForm.Get("code_verifier")
+	if grant.challenge != "" {
+		expected := verifier
+		if grant.challengeType == "S256" {
+			sum := sha256.Sum256([]byte(verifier))
+			expected = base64.RawURLEncoding.EncodeToString(sum[:])
+		}
+		if expected != grant.challenge {
+			mockWriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+			return
+		}
+	}
+
+	idToken, err := m.SignIDToken(grant.user, grant.nonce, nil)
+	if err != nil {
+		mockWriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	mockWriteJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": mockRandomID(16),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// SignIDToken menandatangani ID token untuk user; extra menimpa claim standar (mis. untuk menguji aud/iss salah).
+func (m *MockOIDCProvider) SignIDToken(user MockOIDCUser, nonce string, extra map[string]interface{}) (string, error) {
+	now := time.Now()
+	m.mu.Lock()
+	key, kid, issuer := m.key, m.kid, m.issuer
+	m.mu.Unlock()
+
+	claims := jwt.MapClaims{
+		"iss":            issuer,
+		"sub":            user.Subject,
+		"aud":            m.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+		"groups":         user.Groups,
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeSQL adalah driver database/sql minimal: query yang mengandung salah satu key rows mengembalikan
+// baris tersebut, query lain kosong, dan setiap statement non-SELECT dicatat dengan LastInsertId berurutan.
+type fakeSQL struct {
+	rows map[string]fakeRows
+
+	mu     sync.Mutex
+	execs  []string
+	lastID int64
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (f *fakeSQL) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeSQL) Driver() driver.Driver                        { return nil }
+
+// open membuat koneksi gorm MySQL di atas fakeSQL.
+func (f *fakeSQL) open(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(f), SkipInitializeWithVersion: true}),
+		&gorm.Config{DisableAutomaticPing: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open fake db: %v", err)
+	}
+	return db
+}
+
+func (f *fakeSQL) executed(prefix string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, q := range f.execs {
+		if strings.HasPrefix(q, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeConn struct{ f *fakeSQL }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.f, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	f     *fakeSQL
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.f.mu.Lock()
+	defer s.f.mu.Unlock()
+	s.f.execs = append(s.f.execs, s.query)
+	s.f.lastID++
+	return fakeResult(s.f.lastID), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	for key, rows := range s.f.rows {
+		if strings.Contains(s.query, key) {
+			return &fakeCursor{rows: rows}, nil
+		}
+	}
+	return &fakeCursor{}, nil
+}
+
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeCursor struct {
+	rows fakeRows
+	next int
+}
+
+func (c *fakeCursor) Columns() []string { return c.rows.columns }
+func (c *fakeCursor) Close() error      { return nil }
+
+func (c *fakeCursor) Next(dest []driver.Value) error {
+	if c.next >= len(c.rows.values) {
+		return io.EOF
+	}
+	copy(dest, c.rows.values[c.next])
+	c.next++
+	return nil
+}
+
+// mockOIDCLogin menjalankan langkah authorize di mock seperti yang dilakukan browser setelah BeginOidcLogin,
+// lalu mengembalikan authorization code dari redirect.
+func mockOIDCLogin(t *testing.T, d *oidcDiscovery, clientID, email, nonce, verifier string) string {
+	t.Helper()
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", OidcRedirectURL())
+	params.Set("scope", oidcDefaultScopes)
+	params.Set("state", "state")
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", pkceChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+	params.Set("login_hint", email)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(d.AuthorizationEndpoint + "?" + params.Encode())
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %s, Location %q", resp.Status, resp.Header.Get("Location"))
+	}
+	code := location.Query().Get("code")
+	if code == "" {
+		t.Fatalf("authorize redirect has no code: %s", location)
+	}
+	return code
+}
+
+// newMockOIDC menjalankan MockOIDCProvider di httptest dan mengembalikan provider yang menunjuk ke sana.
+func newMockOIDC(t *testing.T) (*MockOIDCProvider, models.OidcProvider) {
+	t.Helper()
+	t.Setenv("APP_URL", "https://awarenix.test")
+	mock, err := NewMockOIDCProvider("awarenix", "")
+	if err != nil {
+		t.Fatalf("NewMockOIDCProvider: %v", err)
+	}
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+	mock.SetIssuer(srv.URL)
+	mock.PutUser(MockOIDCUser{Email: "alice@example.com", EmailVerified: true, Name: "Alice", Groups: []string{"staff", "Phish-Admins"}})
+
+	provider := models.OidcProvider{
+		ID:        1,
+		Issuer:    srv.URL,
+		ClientID:  "awarenix",
+		RoleClaim: "groups",
+		RoleMappings: []models.OidcRoleMapping{
+			{ID: 1, ClaimValue: "staff", RoleID: 3, Priority: 10},
+			{ID: 2, ClaimValue: "phish-admins", RoleID: 2, Priority: 1},
+		},
+	}
+	return mock, provider
+}
+
+func TestOidcCodeExchange(t *testing.T) {
+	_, provider := newMockOIDC(t)
+	d, err := OidcDiscover(provider.Issuer)
+	if err != nil {
+		t.Fatalf("OidcDiscover: %v", err)
+	}
+
+	t.Run("code with matching PKCE verifier", func(t *testing.T) {
+		code := mockOIDCLogin(t, d, provider.ClientID, "alice@example.com", "nonce-1", "verifier-1")
+		idToken, err := exchangeOidcCode(provider, d, code, "verifier-1")
+		if err != nil {
+			t.Fatalf("exchangeOidcCode: %v", err)
+		}
+		claims, err := verifyOidcIDToken(provider, d, idToken, "nonce-1")
+		if err != nil {
+			t.Fatalf("verifyOidcIDToken: %v", err)
+		}
+		if claims.Subject != "mock|alice@example.com" || claims.Email != "alice@example.com" || !claims.EmailVerified || claims.Name != "Alice" {
+			t.Errorf("claims = %+v", claims)
+		}
+		if got := MapOidcRole(provider, claims.Raw); got != 2 {
+			t.Errorf("MapOidcRole = %d, want 2", got)
+		}
+	})
+
+	t.Run("wrong PKCE verifier", func(t *testing.T) {
+		code := mockOIDCLogin(t, d, provider.ClientID, "alice@example.com", "nonce-2", "verifier-2")
+		if _, err := exchangeOidcCode(provider, d, code, "other-verifier"); err == nil || !strings.Contains(err.Error(), "PKCE") {
+			t.Errorf("exchangeOidcCode error = %v, want PKCE failure", err)
+		}
+	})
+
+	t.Run("code used twice", func(t *testing.T) {
+		code := mockOIDCLogin(t, d, provider.ClientID, "alice@example.com", "nonce-3", "verifier-3")
+		if _, err := exchangeOidcCode(provider, d, code, "verifier-3"); err != nil {
+			t.Fatalf("first exchange: %v", err)
+		}
+		if _, err := exchangeOidcCode(provider, d, code, "verifier-3"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+			t.Errorf("second exchange error = %v, want invalid_grant", err)
+		}
+	})
+}
+
+func TestVerifyOidcIDToken(t *testing.T) {
+	mock, provider := newMockOIDC(t)
+	d, err := OidcDiscover(provider.Issuer)
+	if err != nil {
+		t.Fatalf("OidcDiscover: %v", err)
+	}
+	user := MockOIDCUser{Subject: "mock|bob", Email: "bob@example.com", EmailVerified: true}
+
+	tests := []struct {
+		name    string
+		nonce   string
+		extra   map[string]interface{}
+		wantErr string
+	}{
+		{name: "valid", nonce: "n"},
+		{name: "wrong audience", nonce: "n", extra: map[string]interface{}{"aud": "other-client"}, wantErr: "audience"},
+		{name: "wrong issuer", nonce: "n", extra: map[string]interface{}{"iss": "https://evil.example"}, wantErr: "issuer"},
+		{name: "nonce mismatch", nonce: "other", wantErr: "nonce"},
+		{name: "expired", nonce: "n", extra: map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()}, wantErr: "expired"},
+		{name: "multiple audiences without azp", nonce: "n", extra: map[string]interface{}{"aud": []string{"awarenix", "other"}}, wantErr: "authorized party"},
+		{name: "missing subject", nonce: "n", extra: map[string]interface{}{"sub": ""}, wantErr: "subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := mock.SignIDToken(user, tt.nonce, tt.extra)
+			if err != nil {
+				t.Fatalf("SignIDToken: %v", err)
+			}
+			claims, err := verifyOidcIDToken(provider, d, token, "n")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("verifyOidcIDToken: %v", err)
+				}
+				if claims.Subject != user.Subject || claims.Email != user.Email {
+					t.Errorf("claims = %+v", claims)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyOidcIDToken error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("shared-secret signature rejected", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iss": provider.Issuer, "aud": provider.ClientID, "sub": "mock|bob", "nonce": "n", "exp": time.Now().Add(time.Minute).Unix(),
+		}).SignedString([]byte(provider.ClientID))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		if _, err := verifyOidcIDToken(provider, d, token, "n"); err == nil || !strings.Contains(err.Error(), "signing method") {
+			t.Errorf("verifyOidcIDToken error = %v, want signing method rejection", err)
+		}
+	})
+}
+
+func TestMapOidcRole(t *testing.T) {
+	_, provider := newMockOIDC(t)
+	groups := func(values ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"groups": values}
+	}
+
+	tests := []struct {
+		name          string
+		claims        map[string]interface{}
+		defaultRoleID uint
+		want          uint
+	}{
+		{"single mapping", groups("staff"), 0, 3},
+		{"lower priority wins, case-insensitive", groups("staff", "PHISH-ADMINS"), 0, 2},
+		{"string claim", map[string]interface{}{"groups": "staff"}, 0, 3},
+		{"no match falls back to default", groups("finance"), 4, 4},
+		{"no match without default", groups("finance"), 0, 0},
+		{"missing claim", map[string]interface{}{}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := provider
+			p.DefaultRoleID = tt.defaultRoleID
+			if got := MapOidcRole(p, tt.claims); got != tt.want {
+				t.Errorf("MapOidcRole = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveOidcUser(t *testing.T) {
+	_, provider := newMockOIDC(t)
+	provider.OrganizationID = 3
+	provider.AutoProvision = true
+	claims := &OidcClaims{
+		Subject:       "mock|alice@example.com",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Name:          "Alice",
+		Raw:           jwt.MapClaims{"groups": []interface{}{"staff"}},
+	}
+	existingUser := func(organizationID, role int64) fakeRows {
+		return fakeRows{
+			columns: []string{"id", "organization_id", "name", "email", "role", "is_active"},
+			values:  [][]driver.Value{{int64(42), organizationID, "Alice Local", "alice@example.com", role, int64(1)}},
+		}
+	}
+	crossTenant := fakeRows{columns: []string{"count(*)"}, values: [][]driver.Value{{int64(1)}}}
+
+	tests := []struct {
+		name          string
+		rows          map[string]fakeRows
+		autoProvision bool
+		unverified    bool
+		wantErr       error
+		wantUserID    uint
+		wantCreate    bool
+	}{
+		{name: "provisions new user in provider organization", autoProvision: true, wantUserID: 1, wantCreate: true},
+		{name: "auto provision disabled", wantErr: ErrOidcNotProvisioned},
+		{name: "links user in provider organization", rows: map[string]fakeRows{"FROM `users`": existingUser(3, 3)}, wantUserID: 42},
+		{name: "unverified email", rows: map[string]fakeRows{"FROM `users`": existingUser(3, 3)}, unverified: true, wantErr: ErrOidcEmailUnverified},
+		{name: "user in another organization", rows: map[string]fakeRows{"FROM `users`": existingUser(5, 3)}, wantErr: ErrOidcAccountNotLinked},
+		{
+			name:    "cross-tenant user",
+			rows:    map[string]fakeRows{"FROM `users`": existingUser(3, 1), "FROM `role_permissions`": crossTenant},
+			wantErr: ErrOidcAccountNotLinked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSQL{rows: tt.rows}
+			p := provider
+			p.AutoProvision = tt.autoProvision
+			c := *claims
+			c.EmailVerified = !tt.unverified
+
+			user, err := ResolveOidcUser(fake.open(t), p, &c)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolveOidcUser error = %v, want %v", err, tt.wantErr)
+				}
+				if fake.executed("INSERT") {
+					t.Error("rejected login still wrote to the database")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveOidcUser: %v", err)
+			}
+			if user.ID != tt.wantUserID || user.OrganizationID != 3 || user.Role != 3 {
+				t.Errorf("user = id %d org %d role %d, want id %d org 3 role 3", user.ID, user.OrganizationID, user.Role, tt.wantUserID)
+			}
+			if got := fake.executed("INSERT INTO `users`"); got != tt.wantCreate {
+				t.Errorf("user created = %v, want %v", got, tt.wantCreate)
+			}
+			if !fake.executed("INSERT INTO `user_identities`") {
+				t.Error("identity was not linked")
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Masa berlaku link email
+const (
+	InviteTokenTTL        = 72 * time.Hour
+	PasswordResetTokenTTL = time.Hour
+
+	passwordMaxBytes = 72 // batas input bcrypt
+)
+
+var ErrUserTokenInvalid = errors.New("link is invalid or has expired")
+
+// PasswordPolicyError berisi semua aturan password yang dilanggar.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the policy: " + strings.Join(e.Violations, "; ")
+}
+
+// defaultPasswordPolicy dipakai sampai admin menyimpan kebijakan sendiri.
+func defaultPasswordPolicy() models.PasswordPolicy {
+	return models.PasswordPolicy{MinLength: 8, CheckBreached: true}
+}
+
+// LoadPasswordPolicy mengambil kebijakan password organisasi atau default jika belum diatur.
+func LoadPasswordPolicy(db *gorm.DB) (models.PasswordPolicy, error) {
+	var policy models.PasswordPolicy
+	err := db.Order("id").First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		return defaultPasswordPolicy(), nil
+	}
+	return policy, err
+}
+
+// ValidatePassword memeriksa password terhadap kebijakan organisasi, termasuk daftar password bocor.
+func ValidatePassword(db *gorm.DB, password, email string) error {
+	policy, err := LoadPasswordPolicy(db)
+	if err != nil {
+		return err
+	}
+
+	var violations []string
+	if len([]rune(password)) < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+	if len(password) > passwordMaxBytes {
+		violations = append(violations, fmt.Sprintf("must be at most %d bytes", passwordMaxBytes))
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if policy.RequireUppercase && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if policy.RequireLowercase && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+	if email != "" && strings.EqualFold(strings.TrimSpace(password), strings.TrimSpace(email)) {
+		violations = append(violations, "must not be the same as the email address")
+	}
+	if policy.CheckBreached && len(violations) == 0 {
+		breached, err := PasswordBreached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, "appears in a list of breached passwords, choose another one")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateAndHashPassword memvalidasi password terhadap kebijakan lalu mengembalikan hash bcrypt-nya.
+func ValidateAndHashPassword(db *gorm.DB, password, email string) (string, error) {
+	if err := ValidatePassword(db, password, email); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// PasswordBreached mencari SHA-1 password di file PASSWORD_BREACHED_HASHES_FILE. File berisi satu hash
+// hex per baris, terurut, boleh dengan sufiks ":count" (format unduhan Have I Been Pwned), sehingga
+// pencarian biner langsung di file tanpa memuat seluruh daftar ke memori. Tanpa file, pemeriksaan dilewati.
+func PasswordBreached(password string) (bool, error) {
+	path := strings.TrimSpace(os.Getenv("PASSWORD_BREACHED_HASHES_FILE"))
+	if path == "" {
+		return false, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	sum := sha1.Sum([]byte(password))
+	target := []byte(strings.ToUpper(hex.EncodeToString(sum[:])))
+	return searchSortedHashFile(f, info.Size(), target)
+}
+
+// hashLineAt membaca baris lengkap pertama yang dimulai pada atau setelah offset dan mengembalikan hash-nya.
+func hashLineAt(r io.ReaderAt, size, offset int64) ([]byte, error) {
+	reader := bufio.NewReader(io.NewSectionReader(r, offset, size-offset))
+	if offset > 0 {
+		// Offset bisa jatuh di tengah baris; lewati sisa baris tersebut
+		if _, err := reader.ReadBytes('\n'); err != nil {
+			return nil, nil
+		}
+	}
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil, nil
+	}
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		line = line[:i]
+	}
+	return bytes.ToUpper(line), nil
+}
+
+// searchSortedHashFile melakukan pencarian biner berbasis offset byte pada file hash yang terurut.
+func searchSortedHashFile(r io.ReaderAt, size int64, target []byte) (bool, error) {
+	lo, hi := int64(0), size
+	for hi-lo > 256 {
+		mid := lo + (hi-lo)/2
+		hash, err := hashLineAt(r, size, mid)
+		if err != nil {
+			return false, err
+		}
+		if hash == nil || bytes.Compare(hash, target) > 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	// Sisa rentang kecil: baca baris per baris dari lo sampai melewati target
+	reader := bufio.NewReader(io.NewSectionReader(r, lo, size-lo))
+	if lo > 0 {
+		if _, err := reader.ReadBytes('\n'); err != nil {
+			return false, nil
+		}
+	}
+	for {
+		line, err := reader.ReadBytes('\n')
+		hash := bytes.TrimSpace(line)
+		if i := bytes.IndexByte(hash, ':'); i >= 0 {
+			hash = hash[:i]
+		}
+		if len(hash) > 0 {
+			switch cmp := bytes.Compare(bytes.ToUpper(hash), target); {
+			case cmp == 0:
+				return true, nil
+			case cmp > 0:
+				return false, nil
+			}
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// signUserToken menandatangani bagian acak token bersama tujuannya dengan JWT_SECRET, sehingga token
+// tidak bisa dipakai untuk tujuan lain meskipun baris database-nya diubah.
+func signUserToken(purpose, random string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte(purpose + ":" + random))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IssueUserToken membuat token link email satu kali dan membatalkan token lama user dengan tujuan yang sama.
+func IssueUserToken(db *gorm.DB, userID uint, purpose string, ttl time.Duration, createdBy int) (string, time.Time, error) {
+	if os.Getenv("JWT_SECRET") == "" {
+		return "", time.Time{}, fmt.Errorf("JWT_SECRET not set")
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	random := base64.RawURLEncoding.EncodeToString(raw)
+	plain := random + "." + signUserToken(purpose, random)
+
+	now := time.Now()
+	token := models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashRefreshToken(plain),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		CreatedBy: createdBy,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&token).Error
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return plain, token.ExpiresAt, nil
+}
+
+// LookupUserToken memverifikasi tanda tangan dan mengambil token yang belum dipakai dan belum kedaluwarsa.
+func LookupUserToken(db *gorm.DB, plain string) (*models.UserToken, error) {
+	plain = strings.TrimSpace(plain)
+	random, signature, ok := strings.Cut(plain, ".")
+	if !ok || random == "" {
+		return nil, ErrUserTokenInvalid
+	}
+	var token models.UserToken
+	if err := db.Where("token_hash = ?", hashRefreshToken(plain)).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+	if !hmac.Equal([]byte(signature), []byte(signUserToken(token.Purpose, random))) {
+		return nil, ErrUserTokenInvalid
+	}
+	if token.UsedAt != nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, ErrUserTokenInvalid
+	}
+	return &token, nil
+}
+
+// ConsumeUserToken menandai token terpakai; hanya satu request yang berhasil untuk token yang sama.
+func ConsumeUserToken(db *gorm.DB, token *models.UserToken) error {
+	res := db.Model(&models.UserToken{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserTokenInvalid
+	}
+	return nil
+}
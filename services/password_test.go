@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// breachedHashes membuat daftar hash terurut yang cukup panjang agar searchSortedHashFile benar-benar
+// melakukan pencarian biner sebelum membaca sisa rentang baris per baris.
+func breachedHashes(passwords ...string) []string {
+	hashes := make([]string, 0, 200+len(passwords))
+	for i := 0; i < 200; i++ {
+		hashes = append(hashes, sha1Hex(fmt.Sprintf("filler-%d", i)))
+	}
+	for _, p := range passwords {
+		hashes = append(hashes, sha1Hex(p))
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// writeHashFile menulis hash dalam format unduhan Have I Been Pwned (HASH:count, CRLF).
+func writeHashFile(t *testing.T, hashes []string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	for i, h := range hashes {
+		fmt.Fprintf(&buf, "%s:%d\r\n", h, i+1)
+	}
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write hash file: %v", err)
+	}
+	return path
+}
+
+func TestSearchSortedHashFile(t *testing.T) {
+	hashes := breachedHashes("password123")
+	var buf bytes.Buffer
+	for i, h := range hashes {
+		switch {
+		case i%3 == 0:
+			fmt.Fprintf(&buf, "%s:%d\n", h, i+1)
+		case i%3 == 1:
+			// Baris huruf kecil tetap cocok dengan target huruf besar
+			fmt.Fprintf(&buf, "%s\n", strings.ToLower(h))
+		default:
+			fmt.Fprintf(&buf, "%s:%d\r\n", h, i+1)
+		}
+	}
+	data := buf.Bytes()
+	// Baris terakhir tanpa newline penutup
+	data = bytes.TrimRight(data, "\r\n")
+
+	tests := []struct {
+		name   string
+		target string
+		want   bool
+	}{
+		{"first line", hashes[0], true},
+		{"last line without newline", hashes[len(hashes)-1], true},
+		{"line with count suffix", hashes[99], true},
+		{"lowercase line", hashes[100], true},
+		{"crlf line", hashes[101], true},
+		{"known password", sha1Hex("password123"), true},
+		{"before first line", strings.Repeat("0", 40), false},
+		{"after last line", strings.Repeat("F", 40), false},
+		{"between lines", sha1Hex("not-in-the-list"), false},
+		{"prefix of a line", hashes[50][:20], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := searchSortedHashFile(bytes.NewReader(data), int64(len(data)), []byte(tt.target))
+			if err != nil {
+				t.Fatalf("searchSortedHashFile: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("found = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Setiap baris harus ditemukan, apa pun offset tengah baris yang dipilih pencarian biner
+	for i, h := range hashes {
+		found, err := searchSortedHashFile(bytes.NewReader(data), int64(len(data)), []byte(h))
+		if err != nil || !found {
+			t.Errorf("line %d (%s): found = %v, err = %v", i, h, found, err)
+		}
+	}
+
+	empty, err := searchSortedHashFile(bytes.NewReader(nil), 0, []byte(hashes[0]))
+	if err != nil || empty {
+		t.Errorf("empty file: found = %v, err = %v", empty, err)
+	}
+}
+
+func TestHashLineAt(t *testing.T) {
+	data := []byte("AAAA:1\nbbbb:2\nCCCC\n")
+	tests := []struct {
+		offset int64
+		want   string
+	}{
+		{0, "AAAA"},
+		{2, "BBBB"},  // tengah baris pertama: lewati ke baris berikutnya
+		{6, "BBBB"},  // tepat di newline baris pertama
+		{7, "CCCC"},  // awal baris kedua dianggap sisa baris sebelumnya
+		{12, "CCCC"}, // tengah baris kedua
+		{15, ""},     // tengah baris terakhir: tidak ada baris lengkap lagi
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.offset), func(t *testing.T) {
+			got, err := hashLineAt(bytes.NewReader(data), int64(len(data)), tt.offset)
+			if err != nil {
+				t.Fatalf("hashLineAt: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("hashLineAt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	t.Setenv("PASSWORD_BREACHED_HASHES_FILE", writeHashFile(t, breachedHashes("Summer24!", "password123")))
+
+	policy := func(minLength int, upper, lower, digit, symbol, breached bool) map[string]fakeRows {
+		return map[string]fakeRows{"FROM `password_policies`": {
+			columns: []string{"id", "min_length", "require_uppercase", "require_lowercase", "require_digit", "require_symbol", "check_breached"},
+			values:  [][]driver.Value{{int64(1), int64(minLength), upper, lower, digit, symbol, breached}},
+		}}
+	}
+	strict := policy(10, true, true, true, true, true)
+
+	tests := []struct {
+		name     string
+		rows     map[string]fakeRows // nil: belum ada kebijakan tersimpan, pakai default
+		password string
+		email    string
+		want     []string
+	}{
+		{name: "default policy accepts", password: "correct horse", email: "alice@example.com"},
+		{name: "default policy min length", password: "short", want: []string{"must be at least 8 characters"}},
+		{name: "min length counts runes", password: "ééééééé", want: []string{"must be at least 8 characters"}},
+		{name: "default policy breached", password: "password123", want: []string{"appears in a list of breached passwords, choose another one"}},
+		{name: "same as email", password: " Alice@Example.com ", email: "alice@example.com", want: []string{"must not be the same as the email address"}},
+		{name: "longer than bcrypt input", password: strings.Repeat("a", 73), want: []string{"must be at most 72 bytes"}},
+		{name: "strict policy accepts", rows: strict, password: "Tr0ub4dor&3x"},
+		{
+			name: "strict policy reports every rule", rows: strict, password: "abc",
+			want: []string{"must be at least 10 characters", "must contain an uppercase letter", "must contain a digit", "must contain a symbol"},
+		},
+		{name: "lowercase required", rows: strict, password: "TR0UB4DOR&3X", want: []string{"must contain a lowercase letter"}},
+		{name: "space counts as symbol", rows: strict, password: "Tr0ub4dor 3x"},
+		{name: "breached checked last", rows: strict, password: "Summer24!", want: []string{"must be at least 10 characters"}},
+		{name: "breached under strict policy", rows: policy(8, true, true, true, true, true), password: "Summer24!", want: []string{"appears in a list of breached passwords, choose another one"}},
+		{name: "breached check disabled", rows: policy(8, false, false, false, false, false), password: "password123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSQL{rows: tt.rows}
+			err := ValidatePassword(fake.open(t), tt.password, tt.email)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidatePassword: %v", err)
+				}
+				return
+			}
+			var policyErr *PasswordPolicyError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("err = %v, want PasswordPolicyError", err)
+			}
+			if !equalStrings(policyErr.Violations, tt.want) {
+				t.Errorf("violations = %q, want %q", policyErr.Violations, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordBreachedWithoutFile(t *testing.T) {
+	t.Setenv("PASSWORD_BREACHED_HASHES_FILE", "")
+	breached, err := PasswordBreached("password123")
+	if err != nil || breached {
+		t.Errorf("breached = %v, err = %v; want check skipped", breached, err)
+	}
+
+	t.Setenv("PASSWORD_BREACHED_HASHES_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+	if _, err := PasswordBreached("password123"); err == nil {
+		t.Error("missing file: want error")
+	}
+}
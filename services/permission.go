@@ -0,0 +1,81 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrUnknownPermission = errors.New("unknown permission")
+
+// RoleHasPermission melaporkan apakah role memiliki izin dengan key tersebut.
+func RoleHasPermission(db *gorm.DB, roleID uint, key string) (bool, error) {
+	var count int64
+	err := db.Model(&models.RolePermission{}).
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id = ? AND permissions.`key` = ?", roleID, key).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RolePermissionKeys mengembalikan key semua izin milik role, terurut.
+func RolePermissionKeys(db *gorm.DB, roleID uint) ([]string, error) {
+	keys := []string{}
+	err := db.Table("permissions").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role_id = ?", roleID).
+		Order("permissions.`key`").
+		Pluck("permissions.`key`", &keys).Error
+	return keys, err
+}
+
+// SetRolePermissions mengganti seluruh izin role dengan keys. Mengembalikan izin yang ditambahkan dan dicabut.
+func SetRolePermissions(db *gorm.DB, roleID uint, keys []string) ([]string, []string, error) {
+	wanted := map[string]bool{}
+	for _, key := range keys {
+		if !models.IsKnownPermission(key) {
+			return nil, nil, fmt.Errorf("%w %q", ErrUnknownPermission, key)
+		}
+		wanted[key] = true
+	}
+
+	var added, removed []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var permissions []models.Permission
+		if err := tx.Find(&permissions).Error; err != nil {
+			return err
+		}
+		current, err := RolePermissionKeys(tx, roleID)
+		if err != nil {
+			return err
+		}
+		has := map[string]bool{}
+		for _, key := range current {
+			has[key] = true
+		}
+
+		now := time.Now()
+		for _, p := range permissions {
+			switch {
+			case wanted[p.Key] && !has[p.Key]:
+				if err := tx.Create(&models.RolePermission{RoleID: roleID, PermissionID: p.ID, CreatedAt: now, UpdatedAt: now}).Error; err != nil {
+					return err
+				}
+				added = append(added, p.Key)
+			case !wanted[p.Key] && has[p.Key]:
+				if err := tx.Where("role_id = ? AND permission_id = ?", roleID, p.ID).Delete(&models.RolePermission{}).Error; err != nil {
+					return err
+				}
+				removed = append(removed, p.Key)
+			}
+		}
+		return nil
+	})
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed, err
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	riskWindowDays       = 365 // campaign lebih lama dari ini tidak dihitung
+	riskHalfLifeDays     = 180 // bobot campaign berkurang setengah setiap 180 hari
+	riskTrainingDays     = 180
+	riskTrainingDiscount = 5
+	riskTrainingMax      = 20
+)
+
+// riskEventPoints adalah bobot interaksi terburuk dalam satu campaign.
+var riskEventPoints = map[string]float64{
+	string(models.Submitted):        100,
+	string(models.Clicked):          60,
+	string(models.Scanned):          60,
+	string(models.AttachmentOpened): 60,
+	string(models.Opened):           10,
+}
+
+// PersonCampaignHistory mengembalikan setiap campaign tenant yang menargetkan person beserta interaksinya, terbaru dulu.
+func PersonCampaignHistory(db *gorm.DB, personID uint, tenant Tenant) ([]models.PersonCampaignHistory, error) {
+	var rows []struct {
+		ID           uint
+		UID          string
+		CampaignID   uint
+		CampaignName string
+		Status       string
+		CreatedAt    time.Time
+	}
+	err := db.Table("recipients").
+		Select("recipients.id, recipients.uid, recipients.campaign_id, campaigns.name AS campaign_name, recipients.status, recipients.created_at").
+		Joins("LEFT JOIN campaigns ON campaigns.id = recipients.campaign_id").
+		Where("recipients.person_id = ?", personID).
+		Where("recipients.campaign_id IN (?)", db.Model(&models.Campaign{}).Select("id").Scopes(tenant.Scope)).
+		Order("recipients.created_at DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.PersonCampaignHistory, 0, len(rows))
+	if len(rows) == 0 {
+		return history, nil
+	}
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+	var events []models.Event
+	if err := db.Where("recipient_id IN ?", ids).Order("timestamp").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	byRecipient := map[uint][]models.PersonInteraction{}
+	for _, e := range events {
+		byRecipient[e.RecipientID] = append(byRecipient[e.RecipientID], models.PersonInteraction{Type: string(e.Type), Timestamp: e.Timestamp})
+	}
+
+	for _, r := range rows {
+		interactions := byRecipient[r.ID]
+		if interactions == nil {
+			interactions = []models.PersonInteraction{}
+		}
+		history = append(history, models.PersonCampaignHistory{
+			CampaignID:   r.CampaignID,
+			CampaignName: r.CampaignName,
+			RecipientUID: r.UID,
+			Status:       r.Status,
+			TargetedAt:   r.CreatedAt,
+			Interactions: interactions,
+		})
+	}
+	return history, nil
+}
+
+// CalculateRiskScore menghitung skor risiko 0-100 dari riwayat campaign dan training.
+//
+// Setiap campaign dalam 365 hari terakhir diberi nilai interaksi terburuknya
+// (submit 100, klik/scan/lampiran 60, open 10), dikurangi 30 jika email dilaporkan.
+// Nilai dirata-rata dengan bobot yang meluruh setiap 180 hari, lalu dikurangi 5
+// per training yang selesai dalam 180 hari terakhir (maksimal 20).
+func CalculateRiskScore(history []models.PersonCampaignHistory, trainings []models.TrainingCompletion, now time.Time) models.PersonRiskScore {
+	risk := models.PersonRiskScore{ScoredAt: now}
+
+	var weighted, weights float64
+	for _, h := range history {
+		ageDays := now.Sub(h.TargetedAt).Hours() / 24
+		if ageDays > riskWindowDays {
+			continue
+		}
+		if ageDays < 0 {
+			ageDays = 0
+		}
+		risk.Campaigns++
+
+		var points float64
+		reported := false
+		for _, i := range h.Interactions {
+			if i.Type == string(models.Reported) {
+				reported = true
+				continue
+			}
+			points = math.Max(points, riskEventPoints[i.Type])
+		}
+		if points >= 60 {
+			risk.Compromise++
+		}
+		if reported {
+			risk.Reported++
+			points = math.Max(points-30, 0)
+		}
+
+		w := math.Pow(0.5, ageDays/riskHalfLifeDays)
+		weighted += w * points
+		weights += w
+	}
+
+	for _, t := range trainings {
+		if now.Sub(t.CompletedAt).Hours()/24 <= riskTrainingDays {
+			risk.Trainings++
+		}
+	}
+
+	var score float64
+	if weights > 0 {
+		score = weighted / weights
+	}
+	score -= math.Min(float64(risk.Trainings*riskTrainingDiscount), riskTrainingMax)
+	risk.Score = int(math.Round(math.Max(0, math.Min(100, score))))
+
+	switch {
+	case risk.Score >= 60:
+		risk.Level = "high"
+	case risk.Score >= 30:
+		risk.Level = "medium"
+	default:
+		risk.Level = "low"
+	}
+	return risk
+}
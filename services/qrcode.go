@@ -0,0 +1,23 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCodeFileName adalah nama file PNG QR code di email, juga dipakai sebagai Content-ID inline.
+const QRCodeFileName = "qrcode.png"
+
+// QRScanURL membangun URL yang di-encode dalam QR code. Path terpisah dari /track/click
+// sehingga scan tercatat sebagai event scanned, bukan clicked.
+func QRScanURL(rid string) string {
+	backendBase := "localhost:3000"
+	return fmt.Sprintf("http://%s/track/qr/%s", backendBase, url.PathEscape(rid))
+}
+
+// QRCodePNG merender content sebagai PNG QR code (error correction medium) berukuran size x size piksel.
+func QRCodePNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"be-awarenix/config"
+	"be-awarenix/models"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrReportNotSimulation dikembalikan ketika pesan yang dilaporkan tidak cocok dengan recipient simulasi manapun.
+var ErrReportNotSimulation = errors.New("reported message is not an Awarenix simulation")
+
+// ParseReportedHeaders mem-parsing raw header pesan yang dikirim oleh add-in.
+// Header boleh kosong; hasilnya berupa header kosong.
+func ParseReportedHeaders(raw string) mail.Header {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return mail.Header{}
+	}
+	// net/mail membutuhkan baris kosong sebagai pemisah header dan body
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	msg, err := mail.ReadMessage(strings.NewReader(raw + "\n\n"))
+	if err != nil {
+		return mail.Header{}
+	}
+	return msg.Header
+}
+
+// NormalizeMessageID membersihkan Message-ID ke bentuk "<local@domain>".
+func NormalizeMessageID(messageID string) string {
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return ""
+	}
+	if !strings.HasPrefix(messageID, "<") {
+		messageID = "<" + messageID
+	}
+	if !strings.HasSuffix(messageID, ">") {
+		messageID = messageID + ">"
+	}
+	return messageID
+}
+
+// BuildMessageID membuat Message-ID untuk email simulasi berdasarkan rid recipient
+// dan domain dari alamat pengirim sending profile.
+func BuildMessageID(rid, smtpFrom string) string {
+	domain := "awarenix.local"
+	if addr, err := mail.ParseAddress(smtpFrom); err == nil {
+		smtpFrom = addr.Address
+	}
+	if at := strings.LastIndex(smtpFrom, "@"); at >= 0 && at < len(smtpFrom)-1 {
+		domain = strings.TrimSpace(smtpFrom[at+1:])
+	}
+	return fmt.Sprintf("<%s@%s>", rid, domain)
+}
+
+// FindRecipientByMessageID mencari recipient simulasi berdasarkan Message-ID.
+// Untuk email lama yang belum menyimpan message_id, local-part Message-ID dicocokkan dengan rid.
+func FindRecipientByMessageID(db *gorm.DB, messageID string) (*models.Recipient, error) {
+	messageID = NormalizeMessageID(messageID)
+	if messageID == "" {
+		return nil, ErrReportNotSimulation
+	}
+
+	var rec models.Recipient
+	err := db.Where("message_id = ?", messageID).First(&rec).Error
+	if err == nil {
+		return &rec, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	localPart := strings.TrimPrefix(messageID, "<")
+	if at := strings.Index(localPart, "@"); at > 0 {
+		localPart = localPart[:at]
+		err = db.Where("uid = ?", localPart).First(&rec).Error
+		if err == nil {
+			return &rec, nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+	}
+
+	return nil, ErrReportNotSimulation
+}
+
+// ForwardReportToSOC meneruskan laporan pesan non-simulasi ke SOC webhook (SOC_WEBHOOK_URL).
+// Jika SOC_WEBHOOK_SECRET diisi, payload ditandatangani HMAC-SHA256 pada header X-Awarenix-Signature.
+// Mengembalikan false tanpa error jika webhook belum dikonfigurasi.
+func ForwardReportToSOC(payload models.SOCReportPayload) (bool, error) {
+	webhookURL := os.Getenv("SOC_WEBHOOK_URL")
+	if webhookURL == "" {
+		return false, nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal SOC payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build SOC webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret := os.Getenv("SOC_WEBHOOK_SECRET"); secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Awarenix-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to call SOC webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, fmt.Errorf("SOC webhook responded with status %d", resp.StatusCode)
+	}
+	return true, nil
+}
+
+// ReportedMessageID mengambil Message-ID dari request report, memprioritaskan field message_id
+// lalu header Message-ID pada raw headers.
+func ReportedMessageID(input models.PhishReportRequest, headers mail.Header) string {
+	if input.MessageID != "" {
+		return NormalizeMessageID(input.MessageID)
+	}
+	return NormalizeMessageID(headers.Get("Message-Id"))
+}
+
+// ReportedCampaignName mengembalikan nama campaign untuk recipient yang dilaporkan, kosong jika tidak ditemukan.
+func ReportedCampaignName(rec *models.Recipient) string {
+	var camp models.Campaign
+	if err := config.DB.Select("id", "name").First(&camp, rec.CampaignID).Error; err != nil {
+		return ""
+	}
+	return camp.Name
+}
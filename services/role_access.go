@@ -0,0 +1,151 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBuiltInRole       = errors.New("built-in roles cannot be renamed or deleted")
+	ErrRoleAccessLocked  = errors.New("access of the " + models.SuperAdminRoleName + " role cannot be changed")
+	ErrRoleAccessInvalid = errors.New("invalid role access")
+)
+
+// RoleAccessEditable melaporkan apakah akses menu, submenu dan izin role boleh diubah.
+func RoleAccessEditable(role models.Role) error {
+	if role.BuiltIn && role.Name == models.SuperAdminRoleName {
+		return ErrRoleAccessLocked
+	}
+	return nil
+}
+
+// RoleAccessIDs mengembalikan ID menu dan submenu yang bisa diakses role.
+func RoleAccessIDs(db *gorm.DB, roleID uint) ([]uint, []uint, error) {
+	menuIDs, submenuIDs := []uint{}, []uint{}
+	if err := db.Model(&models.RoleMenuAccess{}).Where("role_id = ?", roleID).Order("menu_id").Pluck("menu_id", &menuIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := db.Model(&models.RoleSubmenuAccess{}).Where("role_id = ?", roleID).Order("submenu_id").Pluck("submenu_id", &submenuIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	return menuIDs, submenuIDs, nil
+}
+
+// uintDiff mengembalikan elemen wanted yang belum ada di current dan elemen current yang tidak ada di wanted.
+func uintDiff(current, wanted []uint) ([]uint, []uint) {
+	has, want := map[uint]bool{}, map[uint]bool{}
+	for _, id := range current {
+		has[id] = true
+	}
+	added, removed := []uint{}, []uint{}
+	for _, id := range wanted {
+		if !has[id] && !want[id] {
+			added = append(added, id)
+		}
+		want[id] = true
+	}
+	for _, id := range current {
+		if !want[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return added, removed
+}
+
+// countExisting memastikan semua ID ada di tabel model.
+func countExisting(tx *gorm.DB, model interface{}, ids []uint, label string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(model).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(ids) {
+		return fmt.Errorf("%w: one or more %s do not exist", ErrRoleAccessInvalid, label)
+	}
+	return nil
+}
+
+// RoleAccessChange merangkum perubahan akses role untuk activity log.
+type RoleAccessChange struct {
+	AddedMenus         []uint   `json:"addedMenus"`
+	RemovedMenus       []uint   `json:"removedMenus"`
+	AddedSubmenus      []uint   `json:"addedSubmenus"`
+	RemovedSubmenus    []uint   `json:"removedSubmenus"`
+	AddedPermissions   []string `json:"addedPermissions,omitempty"`
+	RemovedPermissions []string `json:"removedPermissions,omitempty"`
+}
+
+// SetRoleAccess mengganti seluruh akses menu dan submenu role, dan izin API jika permissions tidak nil.
+func SetRoleAccess(db *gorm.DB, roleID uint, menuIDs, submenuIDs []uint, permissions *[]string) (*RoleAccessChange, error) {
+	change := &RoleAccessChange{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := countExisting(tx, &models.Menu{}, uniqueUints(menuIDs), "menus"); err != nil {
+			return err
+		}
+		if err := countExisting(tx, &models.Submenu{}, uniqueUints(submenuIDs), "submenus"); err != nil {
+			return err
+		}
+		currentMenus, currentSubmenus, err := RoleAccessIDs(tx, roleID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		change.AddedMenus, change.RemovedMenus = uintDiff(currentMenus, menuIDs)
+		for _, id := range change.AddedMenus {
+			if err := tx.Create(&models.RoleMenuAccess{RoleID: roleID, MenuID: id, CreatedAt: now, UpdatedAt: now}).Error; err != nil {
+				return err
+			}
+		}
+		if len(change.RemovedMenus) > 0 {
+			if err := tx.Where("role_id = ? AND menu_id IN ?", roleID, change.RemovedMenus).Delete(&models.RoleMenuAccess{}).Error; err != nil {
+				return err
+			}
+		}
+
+		change.AddedSubmenus, change.RemovedSubmenus = uintDiff(currentSubmenus, submenuIDs)
+		for _, id := range change.AddedSubmenus {
+			if err := tx.Create(&models.RoleSubmenuAccess{RoleID: roleID, SubmenuID: id, CreatedAt: now, UpdatedAt: now}).Error; err != nil {
+				return err
+			}
+		}
+		if len(change.RemovedSubmenus) > 0 {
+			if err := tx.Where("role_id = ? AND submenu_id IN ?", roleID, change.RemovedSubmenus).Delete(&models.RoleSubmenuAccess{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if permissions != nil {
+			change.AddedPermissions, change.RemovedPermissions, err = SetRolePermissions(tx, roleID, *permissions)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func uniqueUints(ids []uint) []uint {
+	seen := map[uint]bool{}
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ScimBaseURL dipakai untuk meta.location resource SCIM.
+var ScimBaseURL = "http://localhost:3000/scim/v2"
+
+// ScimMemberValues mengambil field Member dari resource SCIM User.
+func ScimMemberValues(res *models.ScimUserResource) (models.NewMember, error) {
+	var member models.NewMember
+
+	// Email: primary, lalu email pertama, lalu userName jika berbentuk email
+	for _, e := range res.Emails {
+		if e.Primary {
+			member.Email = e.Value
+			break
+		}
+	}
+	if member.Email == "" && len(res.Emails) > 0 {
+		member.Email = res.Emails[0].Value
+	}
+	if member.Email == "" && strings.Contains(res.UserName, "@") {
+		member.Email = res.UserName
+	}
+	member.Email = strings.TrimSpace(member.Email)
+	if addr, err := mail.ParseAddress(member.Email); err != nil || addr.Address != member.Email {
+		return member, scimBadRequest("invalidValue", "user must have a valid email address")
+	}
+	if len(member.Email) > 50 {
+		return member, scimBadRequest("invalidValue", "email must be at most 50 characters")
+	}
+
+	switch {
+	case res.DisplayName != "":
+		member.Name = res.DisplayName
+	case res.Name != nil && res.Name.Formatted != "":
+		member.Name = res.Name.Formatted
+	case res.Name != nil && (res.Name.GivenName != "" || res.Name.FamilyName != ""):
+		member.Name = strings.TrimSpace(res.Name.GivenName + " " + res.Name.FamilyName)
+	default:
+		member.Name, _, _ = strings.Cut(member.Email, "@")
+	}
+	member.Name = scimTruncate(member.Name, 30)
+	member.Position = scimTruncate(res.Title, 30)
+
+	if res.Enterprise != nil {
+		member.Company = res.Enterprise.Organization
+		if member.Company == "" {
+			member.Company = res.Enterprise.Department
+		}
+	}
+	member.Company = scimTruncate(member.Company, 50)
+
+	for i, a := range res.Addresses {
+		if a.Primary || i == 0 {
+			member.Country = a.Country
+		}
+		if a.Primary {
+			break
+		}
+	}
+	member.Country = scimTruncate(member.Country, 50)
+	// timezone IdP yang tidak dikenali diabaikan; zona waktu lalu diturunkan dari negara
+	member.Timezone, _ = NormalizeTimezone(res.Timezone)
+	return member, nil
+}
+
+func scimTruncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+// SyncScimUserMembers menyesuaikan baris Member milik user SCIM dengan group yang diikutinya.
+// User nonaktif tidak memiliki Member sehingga tidak lagi menjadi target campaign.
+func SyncScimUserMembers(tx *gorm.DB, user *models.ScimUser, createdBy int) error {
+	var groups []models.Group
+	if err := tx.Model(user).Association("Groups").Find(&groups); err != nil {
+		return err
+	}
+	desired := map[uint]bool{}
+	if user.Active {
+		for _, g := range groups {
+			desired[g.ID] = true
+		}
+	}
+
+	var res models.ScimUserResource
+	if err := json.Unmarshal([]byte(user.Resource), &res); err != nil {
+		return err
+	}
+	values, err := ScimMemberValues(&res)
+	if err != nil {
+		return err
+	}
+
+	var existing []models.Member
+	if err := tx.Where("scim_user_id = ?", user.ID).Find(&existing).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, m := range existing {
+		if !desired[m.GroupID] {
+			if err := tx.Delete(&m).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		delete(desired, m.GroupID)
+		err := tx.Model(&m).Updates(map[string]interface{}{
+			"name":       values.Name,
+			"email":      values.Email,
+			"position":   values.Position,
+			"company":    values.Company,
+			"country":    values.Country,
+			"timezone":   values.Timezone,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for groupID := range desired {
+		// Anggota manual dengan email yang sama diambil alih agar tidak dobel
+		var manual models.Member
+		err := tx.Where("group_id = ? AND email = ? AND scim_user_id IS NULL", groupID, values.Email).First(&manual).Error
+		if err == nil {
+			err = tx.Model(&manual).Updates(map[string]interface{}{
+				"scim_user_id": user.ID,
+				"name":         values.Name,
+				"position":     values.Position,
+				"company":      values.Company,
+				"country":      values.Country,
+				"timezone":     values.Timezone,
+				"updated_at":   now,
+			}).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		userID := user.ID
+		member := models.Member{
+			GroupID:    groupID,
+			Name:       values.Name,
+			Email:      values.Email,
+			Position:   values.Position,
+			Company:    values.Company,
+			Country:    values.Country,
+			Timezone:   values.Timezone,
+			ScimUserID: &userID,
+			CreatedAt:  now,
+			CreatedBy:  createdBy,
+			UpdatedAt:  now,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scimVersion(v int) string {
+	return fmt.Sprintf("W/\"%d\"", v)
+}
+
+// ScimUserToResource membangun representasi SCIM User dari data tersimpan.
+func ScimUserToResource(user *models.ScimUser) models.ScimUserResource {
+	var res models.ScimUserResource
+	json.Unmarshal([]byte(user.Resource), &res)
+
+	active := models.ScimBool(user.Active)
+	res.Schemas = []string{models.ScimSchemaUser}
+	if res.Enterprise != nil {
+		res.Schemas = append(res.Schemas, models.ScimSchemaEnterpriseUser)
+	}
+	res.ID = strconv.FormatUint(uint64(user.ID), 10)
+	res.ExternalID = user.ExternalID
+	res.UserName = user.UserName
+	res.Active = &active
+	res.Groups = nil
+	for _, g := range user.Groups {
+		res.Groups = append(res.Groups, models.ScimMultiValue{
+			Value:   strconv.FormatUint(uint64(g.ID), 10),
+			Display: g.Name,
+			Ref:     fmt.Sprintf("%s/Groups/%d", ScimBaseURL, g.ID),
+		})
+	}
+	res.Meta = &models.ScimMeta{
+		ResourceType: "User",
+		Created:      user.CreatedAt,
+		LastModified: user.UpdatedAt,
+		Location:     fmt.Sprintf("%s/Users/%d", ScimBaseURL, user.ID),
+		Version:      scimVersion(user.Version),
+	}
+	return res
+}
+
+// ScimGroupToResource membangun representasi SCIM Group. members berisi user SCIM yang tergabung.
+func ScimGroupToResource(group *models.Group, members []models.ScimUser, includeMembers bool) models.ScimGroupResource {
+	res := models.ScimGroupResource{
+		Schemas:     []string{models.ScimSchemaGroup},
+		ID:          strconv.FormatUint(uint64(group.ID), 10),
+		ExternalID:  group.ExternalID,
+		DisplayName: group.Name,
+		Meta: &models.ScimMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     fmt.Sprintf("%s/Groups/%d", ScimBaseURL, group.ID),
+		},
+	}
+	if includeMembers {
+		res.Members = []models.ScimMultiValue{}
+		for _, u := range members {
+			res.Members = append(res.Members, models.ScimMultiValue{
+				Value:   strconv.FormatUint(uint64(u.ID), 10),
+				Display: u.UserName,
+				Ref:     fmt.Sprintf("%s/Users/%d", ScimBaseURL, u.ID),
+			})
+		}
+	}
+	return res
+}
+
+// ScimResourceMap mengubah resource menjadi map JSON generik untuk filter dan PATCH.
+func ScimResourceMap(resource interface{}) map[string]interface{} {
+	data, _ := json.Marshal(resource)
+	m := map[string]interface{}{}
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// ScimPage menerapkan filter, startIndex dan count (RFC 7644 3.4.2.4) ke daftar resource.
+// Dipakai bila filter tidak bisa diterjemahkan seluruhnya ke SQL (lihat ScimFilterSQL).
+func ScimPage(resources []interface{}, filter ScimFilter, startIndex, count int) models.ScimListResponse {
+	var matched []interface{}
+	for _, r := range resources {
+		if filter == nil || filter.Match(ScimResourceMap(r)) {
+			matched = append(matched, r)
+		}
+	}
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	page := []interface{}{}
+	from := startIndex - 1
+	if from < len(matched) && count > 0 {
+		to := from + count
+		if to > len(matched) {
+			to = len(matched)
+		}
+		page = matched[from:to]
+	}
+	return ScimListPage(page, int64(len(matched)), startIndex)
+}
+
+// ScimListPage membungkus satu halaman resource yang sudah dipaging di database.
+func ScimListPage(resources []interface{}, total int64, startIndex int) models.ScimListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	return models.ScimListResponse{
+		Schemas:      []string{models.ScimSchemaListResponse},
+		TotalResults: int(total),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+// ScimMemberIDs mengubah daftar members SCIM Group menjadi id ScimUser milik organizationID yang valid.
+func ScimMemberIDs(db *gorm.DB, organizationID uint, members []models.ScimMultiValue) ([]uint, error) {
+	ids := make([]uint, 0, len(members))
+	seen := map[uint]bool{}
+	for _, m := range members {
+		id, err := strconv.ParseUint(m.Value, 10, 64)
+		if err != nil {
+			return nil, scimBadRequest("invalidValue", "invalid member value %q", m.Value)
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	var found int64
+	if err := db.Model(&models.ScimUser{}).Scopes(InOrganization(organizationID)).Where("id IN ?", ids).Count(&found).Error; err != nil {
+		return nil, err
+	}
+	if int(found) != len(ids) {
+		return nil, &ScimAPIError{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "one or more members do not exist"}
+	}
+	return ids, nil
+}
@@ -0,0 +1,720 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScimAPIError adalah error yang dikirim ke client dalam format error SCIM (RFC 7644 3.12).
+type ScimAPIError struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *ScimAPIError) Error() string { return e.Detail }
+
+func scimBadRequest(scimType, format string, args ...interface{}) *ScimAPIError {
+	return &ScimAPIError{Status: http.StatusBadRequest, ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+// ScimFilter adalah filter SCIM yang sudah diparse (RFC 7644 3.4.2.2).
+type ScimFilter interface {
+	Match(resource map[string]interface{}) bool
+}
+
+type scimAndFilter struct{ left, right ScimFilter }
+type scimOrFilter struct{ left, right ScimFilter }
+type scimNotFilter struct{ inner ScimFilter }
+
+type scimCompareFilter struct {
+	path  []string
+	op    string
+	value interface{}
+}
+
+// scimValuePathFilter: emails[type eq "work" and value co "@example.com"]
+type scimValuePathFilter struct {
+	path  []string
+	inner ScimFilter
+}
+
+func (f scimAndFilter) Match(r map[string]interface{}) bool {
+	return f.left.Match(r) && f.right.Match(r)
+}
+
+func (f scimOrFilter) Match(r map[string]interface{}) bool {
+	return f.left.Match(r) || f.right.Match(r)
+}
+
+func (f scimNotFilter) Match(r map[string]interface{}) bool {
+	return !f.inner.Match(r)
+}
+
+func (f scimValuePathFilter) Match(r map[string]interface{}) bool {
+	for _, v := range scimResolve(r, f.path) {
+		if m, ok := v.(map[string]interface{}); ok && f.inner.Match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f scimCompareFilter) Match(r map[string]interface{}) bool {
+	values := scimResolve(r, f.path)
+	// Atribut complex multi-valued tanpa sub-atribut dibandingkan dengan sub-atribut "value"
+	for i, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			_, values[i], _ = scimGetKey(m, "value")
+		}
+	}
+
+	if f.op == "pr" {
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if f.op == "ne" {
+		return !scimCompareFilter{path: f.path, op: "eq", value: f.value}.Match(r)
+	}
+
+	caseExact := len(f.path) == 1 && (strings.EqualFold(f.path[0], "id") || strings.EqualFold(f.path[0], "externalId"))
+	for _, v := range values {
+		if scimCompare(v, f.op, f.value, caseExact) {
+			return true
+		}
+	}
+	return false
+}
+
+func scimCompare(actual interface{}, op string, expected interface{}, caseExact bool) bool {
+	switch exp := expected.(type) {
+	case nil:
+		return op == "eq" && actual == nil
+	case bool:
+		act, ok := actual.(bool)
+		return ok && op == "eq" && act == exp
+	case float64:
+		var act float64
+		switch a := actual.(type) {
+		case float64:
+			act = a
+		case string:
+			n, err := strconv.ParseFloat(a, 64)
+			if err != nil {
+				return false
+			}
+			act = n
+		default:
+			return false
+		}
+		switch op {
+		case "eq":
+			return act == exp
+		case "gt":
+			return act > exp
+		case "ge":
+			return act >= exp
+		case "lt":
+			return act < exp
+		case "le":
+			return act <= exp
+		}
+		return false
+	case string:
+		var act string
+		switch a := actual.(type) {
+		case string:
+			act = a
+		case float64:
+			act = strconv.FormatFloat(a, 'f', -1, 64)
+		case bool:
+			act = strconv.FormatBool(a)
+		default:
+			return false
+		}
+		if !caseExact {
+			act, exp = strings.ToLower(act), strings.ToLower(exp)
+		}
+		switch op {
+		case "eq":
+			return act == exp
+		case "co":
+			return strings.Contains(act, exp)
+		case "sw":
+			return strings.HasPrefix(act, exp)
+		case "ew":
+			return strings.HasSuffix(act, exp)
+		case "gt":
+			return act > exp
+		case "ge":
+			return act >= exp
+		case "lt":
+			return act < exp
+		case "le":
+			return act <= exp
+		}
+	}
+	return false
+}
+
+// scimSplitPath mengubah "name.givenName" atau "urn:...:enterprise:2.0:User:department" menjadi segmen path.
+func scimSplitPath(attrPath string) []string {
+	if strings.HasPrefix(strings.ToLower(attrPath), "urn:") {
+		i := strings.LastIndex(attrPath, ":")
+		schema, rest := attrPath[:i], attrPath[i+1:]
+		parts := strings.Split(rest, ".")
+		if strings.EqualFold(schema, models.ScimSchemaUser) || strings.EqualFold(schema, models.ScimSchemaGroup) {
+			return parts
+		}
+		return append([]string{schema}, parts...)
+	}
+	return strings.Split(attrPath, ".")
+}
+
+// scimGetKey mencari key map tanpa membedakan huruf besar/kecil (nama atribut SCIM case-insensitive).
+func scimGetKey(m map[string]interface{}, name string) (string, interface{}, bool) {
+	if v, ok := m[name]; ok {
+		return name, v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return k, v, true
+		}
+	}
+	return name, nil, false
+}
+
+// scimResolve mengembalikan semua nilai pada path; array diratakan.
+func scimResolve(v interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		if arr, ok := v.([]interface{}); ok {
+			return append([]interface{}{}, arr...)
+		}
+		return []interface{}{v}
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		_, next, ok := scimGetKey(t, path[0])
+		if !ok {
+			return nil
+		}
+		return scimResolve(next, path[1:])
+	case []interface{}:
+		var out []interface{}
+		for _, item := range t {
+			out = append(out, scimResolve(item, path)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// ScimFilterSQL memindahkan perbandingan "eq" pada atribut yang punya kolom (columns: nama atribut
+// SCIM -> kolom) ke klausa WHERE. Hanya operand "and" tingkat atas yang diterjemahkan; sisanya
+// dikembalikan sebagai rest untuk dievaluasi di Go. rest nil berarti seluruh filter sudah ada di SQL,
+// sehingga total dan paging dapat dihitung di database.
+func ScimFilterSQL(filter ScimFilter, columns map[string]string) (scope func(*gorm.DB) *gorm.DB, rest ScimFilter) {
+	var conditions []clause.Expression
+	for _, part := range scimConjuncts(filter) {
+		if column, value, ok := scimColumnEq(part, columns); ok {
+			conditions = append(conditions, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: value})
+			continue
+		}
+		if rest == nil {
+			rest = part
+		} else {
+			rest = scimAndFilter{rest, part}
+		}
+	}
+	scope = func(db *gorm.DB) *gorm.DB {
+		if len(conditions) == 0 {
+			return db
+		}
+		return db.Where(clause.And(conditions...))
+	}
+	return scope, rest
+}
+
+func scimConjuncts(f ScimFilter) []ScimFilter {
+	switch t := f.(type) {
+	case nil:
+		return nil
+	case scimAndFilter:
+		return append(scimConjuncts(t.left), scimConjuncts(t.right)...)
+	}
+	return []ScimFilter{f}
+}
+
+func scimColumnEq(f ScimFilter, columns map[string]string) (string, string, bool) {
+	cmp, ok := f.(scimCompareFilter)
+	if !ok || cmp.op != "eq" || len(cmp.path) != 1 {
+		return "", "", false
+	}
+	value, ok := cmp.value.(string)
+	if !ok {
+		return "", "", false
+	}
+	for attr, column := range columns {
+		if strings.EqualFold(attr, cmp.path[0]) {
+			return column, value, true
+		}
+	}
+	return "", "", false
+}
+
+// ---- parser ----
+
+type scimFilterParser struct {
+	tokens []string
+	pos    int
+}
+
+func scimTokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		ch := s[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '(' || ch == ')' || ch == '[' || ch == ']':
+			tokens = append(tokens, string(ch))
+			i++
+		case ch == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, scimBadRequest("invalidFilter", "unterminated string in filter")
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\r\n()[]", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// ParseScimFilter memparse ekspresi filter SCIM.
+func ParseScimFilter(s string) (ScimFilter, error) {
+	tokens, err := scimTokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &scimFilterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, scimBadRequest("invalidFilter", "unexpected %q in filter", p.tokens[p.pos])
+	}
+	return f, nil
+}
+
+func (p *scimFilterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *scimFilterParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *scimFilterParser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return scimBadRequest("invalidFilter", "expected %q in filter, got %q", tok, got)
+	}
+	return nil
+}
+
+func (p *scimFilterParser) parseOr() (ScimFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = scimOrFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *scimFilterParser) parseAnd() (ScimFilter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = scimAndFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *scimFilterParser) parseUnary() (ScimFilter, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return nil, scimBadRequest("invalidFilter", "unexpected end of filter")
+	case strings.EqualFold(tok, "not"):
+		p.next()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return scimNotFilter{inner}, nil
+	case tok == "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseAttrExpr()
+}
+
+func (p *scimFilterParser) parseAttrExpr() (ScimFilter, error) {
+	attr := p.next()
+	if attr == ")" || attr == "]" || attr == "[" || strings.HasPrefix(attr, "\"") {
+		return nil, scimBadRequest("invalidFilter", "expected attribute name in filter, got %q", attr)
+	}
+	path := scimSplitPath(attr)
+
+	if p.peek() == "[" {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return scimValuePathFilter{path: path, inner: inner}, nil
+	}
+
+	op := strings.ToLower(p.next())
+	switch op {
+	case "pr":
+		return scimCompareFilter{path: path, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, scimBadRequest("invalidFilter", "unsupported operator %q in filter", op)
+	}
+
+	raw := p.next()
+	if raw == "" {
+		return nil, scimBadRequest("invalidFilter", "missing value for %s in filter", attr)
+	}
+	var value interface{}
+	switch strings.ToLower(raw) {
+	case "true":
+		value = true
+	case "false":
+		value = false
+	case "null":
+		value = nil
+	default:
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, scimBadRequest("invalidFilter", "invalid value %s in filter", raw)
+		}
+	}
+	return scimCompareFilter{path: path, op: op, value: value}, nil
+}
+
+// ---- PATCH (RFC 7644 3.5.2) ----
+
+type scimPatchPath struct {
+	attr   []string
+	filter ScimFilter
+	sub    string
+}
+
+func parseScimPatchPath(path string) (*scimPatchPath, error) {
+	pp := &scimPatchPath{}
+	attr := path
+	if i := strings.Index(path, "["); i >= 0 {
+		j := strings.LastIndex(path, "]")
+		if j < i {
+			return nil, scimBadRequest("invalidPath", "invalid path %q", path)
+		}
+		filter, err := ParseScimFilter(path[i+1 : j])
+		if err != nil {
+			return nil, scimBadRequest("invalidPath", "invalid path %q: %v", path, err)
+		}
+		pp.filter = filter
+		attr = path[:i]
+		if rest := path[j+1:]; rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				return nil, scimBadRequest("invalidPath", "invalid path %q", path)
+			}
+			pp.sub = rest[1:]
+		}
+	}
+	pp.attr = scimSplitPath(attr)
+	return pp, nil
+}
+
+// ApplyScimPatch menerapkan operasi PATCH ke representasi JSON resource.
+func ApplyScimPatch(resource map[string]interface{}, ops []models.ScimPatchOperation) error {
+	for _, op := range ops {
+		var value interface{}
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return scimBadRequest("invalidValue", "invalid value for %s operation", op.Op)
+			}
+		}
+		kind := strings.ToLower(op.Op)
+		if kind != "add" && kind != "replace" && kind != "remove" {
+			return scimBadRequest("invalidSyntax", "unsupported patch operation %q", op.Op)
+		}
+
+		if op.Path == "" {
+			if kind == "remove" {
+				return scimBadRequest("noTarget", "remove operation requires a path")
+			}
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return scimBadRequest("invalidValue", "%s operation without path requires an object value", op.Op)
+			}
+			for k, v := range obj {
+				// Sebagian IdP mengirim {"name.givenName": "..."} tanpa path
+				pp, err := parseScimPatchPath(k)
+				if err != nil {
+					return err
+				}
+				if err := scimPatchSet(resource, pp, v, kind); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		pp, err := parseScimPatchPath(op.Path)
+		if err != nil {
+			return err
+		}
+		if kind == "remove" {
+			if err := scimPatchRemove(resource, pp, value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := scimPatchSet(resource, pp, value, kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scimParent mengembalikan map induk untuk path (dibuat jika belum ada) dan nama key terakhir.
+func scimParent(resource map[string]interface{}, path []string, create bool) (map[string]interface{}, string) {
+	current := resource
+	for _, seg := range path[:len(path)-1] {
+		key, next, ok := scimGetKey(current, seg)
+		m, isMap := next.(map[string]interface{})
+		if !ok || !isMap {
+			if !create {
+				return nil, ""
+			}
+			m = map[string]interface{}{}
+			current[key] = m
+		}
+		current = m
+	}
+	key, _, _ := scimGetKey(current, path[len(path)-1])
+	return current, key
+}
+
+func scimPatchSet(resource map[string]interface{}, pp *scimPatchPath, value interface{}, kind string) error {
+	parent, key := scimParent(resource, pp.attr, true)
+
+	if pp.filter == nil {
+		existing, exists := parent[key]
+		switch {
+		case kind == "add" && exists:
+			if arr, ok := existing.([]interface{}); ok {
+				if add, ok := value.([]interface{}); ok {
+					parent[key] = scimAppendUnique(arr, add...)
+				} else {
+					parent[key] = scimAppendUnique(arr, value)
+				}
+				return nil
+			}
+			if em, ok := existing.(map[string]interface{}); ok {
+				if vm, ok := value.(map[string]interface{}); ok {
+					for k, v := range vm {
+						em[k] = v
+					}
+					return nil
+				}
+			}
+			parent[key] = value
+		default:
+			parent[key] = value
+		}
+		return nil
+	}
+
+	arr, _ := parent[key].([]interface{})
+	matched := false
+	for _, item := range arr {
+		m, ok := item.(map[string]interface{})
+		if !ok || !pp.filter.Match(m) {
+			continue
+		}
+		matched = true
+		if pp.sub != "" {
+			subKey, _, _ := scimGetKey(m, pp.sub)
+			m[subKey] = value
+		} else if vm, ok := value.(map[string]interface{}); ok {
+			for k, v := range vm {
+				m[k] = v
+			}
+		}
+	}
+	if matched {
+		return nil
+	}
+
+	// Tidak ada elemen yang cocok: buat elemen baru dari filter eq (mis. emails[type eq "work"].value)
+	item := map[string]interface{}{}
+	if !scimFilterDefaults(pp.filter, item) {
+		return scimBadRequest("noTarget", "no value matches the path filter")
+	}
+	if pp.sub != "" {
+		item[pp.sub] = value
+	} else if vm, ok := value.(map[string]interface{}); ok {
+		for k, v := range vm {
+			item[k] = v
+		}
+	}
+	parent[key] = append(arr, item)
+	return nil
+}
+
+func scimFilterDefaults(f ScimFilter, item map[string]interface{}) bool {
+	switch t := f.(type) {
+	case scimCompareFilter:
+		if t.op != "eq" || len(t.path) != 1 {
+			return false
+		}
+		item[t.path[0]] = t.value
+		return true
+	case scimAndFilter:
+		return scimFilterDefaults(t.left, item) && scimFilterDefaults(t.right, item)
+	}
+	return false
+}
+
+func scimPatchRemove(resource map[string]interface{}, pp *scimPatchPath, value interface{}) error {
+	parent, key := scimParent(resource, pp.attr, false)
+	if parent == nil {
+		return nil
+	}
+
+	if pp.filter == nil {
+		// {"op":"remove","path":"members","value":[{"value":"id"}]}
+		if values, ok := value.([]interface{}); ok {
+			arr, _ := parent[key].([]interface{})
+			kept := arr[:0]
+			for _, item := range arr {
+				if !scimContainsValue(values, item) {
+					kept = append(kept, item)
+				}
+			}
+			parent[key] = kept
+			return nil
+		}
+		delete(parent, key)
+		return nil
+	}
+
+	arr, _ := parent[key].([]interface{})
+	kept := make([]interface{}, 0, len(arr))
+	for _, item := range arr {
+		m, ok := item.(map[string]interface{})
+		if !ok || !pp.filter.Match(m) {
+			kept = append(kept, item)
+			continue
+		}
+		if pp.sub != "" {
+			subKey, _, _ := scimGetKey(m, pp.sub)
+			delete(m, subKey)
+			kept = append(kept, m)
+		}
+	}
+	parent[key] = kept
+	return nil
+}
+
+func scimItemValue(item interface{}) interface{} {
+	if m, ok := item.(map[string]interface{}); ok {
+		_, v, _ := scimGetKey(m, "value")
+		return v
+	}
+	return item
+}
+
+func scimContainsValue(list []interface{}, item interface{}) bool {
+	v := scimItemValue(item)
+	for _, candidate := range list {
+		if fmt.Sprint(scimItemValue(candidate)) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// scimAppendUnique menambah elemen multi-valued tanpa menggandakan "value" yang sama (mis. members).
+func scimAppendUnique(arr []interface{}, items ...interface{}) []interface{} {
+	for _, item := range items {
+		if scimItemValue(item) != nil && scimContainsValue(arr, item) {
+			continue
+		}
+		arr = append(arr, item)
+	}
+	return arr
+}
@@ -0,0 +1,39 @@
+package services
+
+import "testing"
+
+func TestScimFilterSQL(t *testing.T) {
+	columns := map[string]string{"userName": "user_name", "externalId": "external_id"}
+	tests := []struct {
+		filter   string
+		wantRest bool
+	}{
+		{`userName eq "alice@example.com"`, false},
+		{`USERNAME eq "alice@example.com"`, false},
+		{`userName eq "alice@example.com" and externalId eq "42"`, false},
+		{`userName sw "alice"`, true},
+		{`userName eq "alice@example.com" or externalId eq "42"`, true},
+		{`userName eq "alice@example.com" and emails[type eq "work"]`, true},
+		{`displayName eq "Alice"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseScimFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseScimFilter: %v", err)
+			}
+			scope, rest := ScimFilterSQL(filter, columns)
+			if scope == nil {
+				t.Fatal("scope is nil")
+			}
+			if (rest != nil) != tt.wantRest {
+				t.Errorf("rest = %#v, want rest present: %v", rest, tt.wantRest)
+			}
+		})
+	}
+
+	_, rest := ScimFilterSQL(nil, columns)
+	if rest != nil {
+		t.Errorf("nil filter left rest %#v", rest)
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Format secret terenkripsi: enc:v1:<key id>:<DEK terbungkus>:<ciphertext>
+// DEK (data encryption key) acak per secret, dibungkus dengan master key (KEK) dari environment.
+const secretPrefix = "enc:v1:"
+
+// SecretColumn mendaftarkan kolom tabel yang menyimpan secret terenkripsi, dipakai saat rotasi key.
+type SecretColumn struct {
+	Table      string
+	PrimaryKey string
+	Column     string
+}
+
+// SecretColumns berisi semua kolom secret. Tambahkan kolom baru (DKIM key, API key, dst.) di sini.
+var SecretColumns = []SecretColumn{
+	{Table: "sending_profiles", PrimaryKey: "id", Column: "password"},
+	{Table: "directory_profiles", PrimaryKey: "id", Column: "bind_password"},
+	{Table: "user_mfas", PrimaryKey: "id", Column: "secret"},
+	{Table: "oidc_providers", PrimaryKey: "id", Column: "client_secret"},
+}
+
+type secretKeyring struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// loadSecretKeyring membaca master key dari SECRETS_MASTER_KEYS ("kid:base64key,kid2:base64key")
+// dan key aktif dari SECRETS_ACTIVE_KEY_ID. Jika SECRETS_MASTER_KEYS kosong, AES_GCM_KEY dipakai dengan id "default".
+func loadSecretKeyring() (*secretKeyring, error) {
+	ring := &secretKeyring{keys: map[string][]byte{}}
+
+	raw := strings.TrimSpace(os.Getenv("SECRETS_MASTER_KEYS"))
+	if raw == "" {
+		if legacy := strings.TrimSpace(os.Getenv("AES_GCM_KEY")); legacy != "" {
+			raw = "default:" + legacy
+		}
+	}
+	if raw == "" {
+		return nil, fmt.Errorf("SECRETS_MASTER_KEYS not set")
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, encoded, ok := strings.Cut(entry, ":")
+		if !ok || kid == "" {
+			return nil, fmt.Errorf("invalid SECRETS_MASTER_KEYS entry %q, expected <key id>:<base64 key>", entry)
+		}
+		if strings.Contains(kid, ":") {
+			return nil, fmt.Errorf("invalid key id %q", kid)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q is not valid base64: %w", kid, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("master key %q must be 32 bytes, got %d", kid, len(key))
+		}
+		if ring.activeID == "" {
+			ring.activeID = kid
+		}
+		ring.keys[kid] = key
+	}
+
+	if active := strings.TrimSpace(os.Getenv("SECRETS_ACTIVE_KEY_ID")); active != "" {
+		ring.activeID = active
+	}
+	if _, ok := ring.keys[ring.activeID]; !ok {
+		return nil, fmt.Errorf("active secret key %q not found in SECRETS_MASTER_KEYS", ring.activeID)
+	}
+	return ring, nil
+}
+
+// IsEncryptedSecret melaporkan apakah nilai tersimpan sudah dalam format envelope.
+func IsEncryptedSecret(stored string) bool {
+	return strings.HasPrefix(stored, secretPrefix)
+}
+
+// SecretKeyID mengembalikan id master key yang dipakai untuk nilai terenkripsi, kosong untuk plaintext.
+func SecretKeyID(stored string) string {
+	if !IsEncryptedSecret(stored) {
+		return ""
+	}
+	kid, _, _ := strings.Cut(strings.TrimPrefix(stored, secretPrefix), ":")
+	return kid
+}
+
+// EncryptSecret mengenkripsi secret dengan master key aktif. String kosong dikembalikan apa adanya.
+func EncryptSecret(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	ring, err := loadSecretKeyring()
+	if err != nil {
+		return "", err
+	}
+
+	dek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	ciphertext, err := sealAESGCM(dek, []byte(plain), nil)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := sealAESGCM(ring.keys[ring.activeID], dek, []byte(ring.activeID))
+	if err != nil {
+		return "", err
+	}
+
+	return secretPrefix + ring.activeID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptSecret mendekripsi nilai tersimpan. Nilai lama yang masih plaintext dikembalikan apa adanya.
+func DecryptSecret(stored string) (string, error) {
+	if !IsEncryptedSecret(stored) {
+		return stored, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(stored, secretPrefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted secret")
+	}
+	kid := parts[0]
+
+	ring, err := loadSecretKeyring()
+	if err != nil {
+		return "", err
+	}
+	kek, ok := ring.keys[kid]
+	if !ok {
+		return "", fmt.Errorf("secret key %q not found in SECRETS_MASTER_KEYS", kid)
+	}
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.New("malformed encrypted secret")
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.New("malformed encrypted secret")
+	}
+
+	dek, err := openAESGCM(kek, wrapped, []byte(kid))
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	plain, err := openAESGCM(dek, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+// RotateSecrets mengenkripsi ulang semua kolom di SecretColumns dengan master key aktif,
+// termasuk nilai lama yang masih plaintext. Mengembalikan jumlah baris yang diperbarui.
+func RotateSecrets(db *gorm.DB) (int, error) {
+	ring, err := loadSecretKeyring()
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, col := range SecretColumns {
+		var rows []struct {
+			ID    uint64
+			Value string
+		}
+		err := db.Table(col.Table).
+			Select(fmt.Sprintf("%s AS id, %s AS value", col.PrimaryKey, col.Column)).
+			Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", col.Column, col.Column)).
+			Scan(&rows).Error
+		if err != nil {
+			return updated, fmt.Errorf("failed to read %s.%s: %w", col.Table, col.Column, err)
+		}
+
+		for _, row := range rows {
+			if SecretKeyID(row.Value) == ring.activeID {
+				continue
+			}
+			plain, err := DecryptSecret(row.Value)
+			if err != nil {
+				return updated, fmt.Errorf("failed to decrypt %s.%s id %d: %w", col.Table, col.Column, row.ID, err)
+			}
+			encrypted, err := EncryptSecret(plain)
+			if err != nil {
+				return updated, fmt.Errorf("failed to encrypt %s.%s id %d: %w", col.Table, col.Column, row.ID, err)
+			}
+			err = db.Table(col.Table).
+				Where(fmt.Sprintf("%s = ?", col.PrimaryKey), row.ID).
+				Update(col.Column, encrypted).Error
+			if err != nil {
+				return updated, fmt.Errorf("failed to update %s.%s id %d: %w", col.Table, col.Column, row.ID, err)
+			}
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func sealAESGCM(key, plain, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plain, aad), nil
+}
+
+func openAESGCM(key, sealed, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	smtpDiagDialTimeout = 10 * time.Second
+	smtpDiagTimeout     = 60 * time.Second
+)
+
+var errSMTPDiagPreviousFailed = errors.New("skipped because a previous step failed")
+
+// smtpDiagnosis menjalankan tahapan diagnosa satu per satu; setelah satu tahap gagal,
+// tahap berikutnya dicatat sebagai skipped.
+type smtpDiagnosis struct {
+	report *models.SMTPDiagnosticReport
+	failed bool
+}
+
+func (d *smtpDiagnosis) step(name string, fn func(detail map[string]interface{}) (string, error)) {
+	step := models.SMTPDiagnosticStep{Name: name, Detail: map[string]interface{}{}}
+	if d.failed {
+		step.Status = "skipped"
+		step.Error = errSMTPDiagPreviousFailed.Error()
+		step.Detail = nil
+		d.report.Steps = append(d.report.Steps, step)
+		return
+	}
+
+	start := time.Now()
+	status, err := fn(step.Detail)
+	step.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		step.Error = err.Error()
+		if status == "" {
+			status = "failed"
+		}
+	}
+	if status == "" {
+		status = "success"
+	}
+	if status == "failed" {
+		d.failed = true
+	}
+	step.Status = status
+	if len(step.Detail) == 0 {
+		step.Detail = nil
+	}
+	d.report.Steps = append(d.report.Steps, step)
+}
+
+// SMTPAddress mengembalikan host dan port SMTP dari sending profile dengan aturan yang sama seperti SendTestEmail.
+func SMTPAddress(profile *models.SendingProfiles) (string, string) {
+	host := profile.Host
+	if profile.Port != 0 {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		return host, strconv.Itoa(profile.Port)
+	}
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		return h, p
+	}
+	return host, "587"
+}
+
+// DiagnoseSendingProfile menguji konektivitas SMTP sebuah sending profile tahap demi tahap
+// (TCP, banner, EHLO, STARTTLS, AUTH, MAIL FROM, RCPT TO) tanpa mengirim DATA.
+// Jika rcpt kosong, alamat SmtpFrom digunakan sebagai RCPT TO.
+func DiagnoseSendingProfile(profile *models.SendingProfiles, rcpt string) models.SMTPDiagnosticReport {
+	host, port := SMTPAddress(profile)
+	report := models.SMTPDiagnosticReport{
+		SendingProfileID: profile.ID,
+		Host:             host,
+		Port:             port,
+		StartedAt:        time.Now(),
+	}
+	d := &smtpDiagnosis{report: &report}
+
+	fromAddr := profile.SmtpFrom
+	if addr, err := mail.ParseAddress(profile.SmtpFrom); err == nil {
+		fromAddr = addr.Address
+	}
+	if rcpt == "" {
+		rcpt = fromAddr
+	}
+
+	var (
+		conn       net.Conn
+		tp         *textproto.Conn
+		extensions map[string]string
+		tlsActive  bool
+	)
+	implicitTLS := port == "465"
+
+	// 1. Koneksi TCP (dan TLS langsung untuk port 465)
+	d.step("tcp_connect", func(detail map[string]interface{}) (string, error) {
+		addr := net.JoinHostPort(host, port)
+		detail["address"] = addr
+		c, err := net.DialTimeout("tcp", addr, smtpDiagDialTimeout)
+		if err != nil {
+			return "failed", err
+		}
+		c.SetDeadline(time.Now().Add(smtpDiagTimeout))
+		detail["remote_addr"] = c.RemoteAddr().String()
+		conn = c
+		return "success", nil
+	})
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	if implicitTLS {
+		d.step("tls_handshake", func(detail map[string]interface{}) (string, error) {
+			detail["mode"] = "implicit"
+			tlsConn, status, err := smtpDiagHandshake(conn, host, detail)
+			if tlsConn != nil {
+				conn = tlsConn
+				tlsActive = true
+			}
+			return status, err
+		})
+	}
+
+	// 2. Banner 220 dari server
+	d.step("banner", func(detail map[string]interface{}) (string, error) {
+		tp = textproto.NewConn(conn)
+		code, msg, err := tp.ReadResponse(220)
+		detail["code"] = code
+		detail["message"] = msg
+		return "", err
+	})
+
+	// 3. EHLO dan daftar extension
+	d.step("ehlo", func(detail map[string]interface{}) (string, error) {
+		ext, err := smtpDiagEhlo(tp, detail)
+		extensions = ext
+		return "", err
+	})
+
+	// 4. STARTTLS (jika tidak memakai TLS langsung)
+	if !implicitTLS {
+		d.step("starttls", func(detail map[string]interface{}) (string, error) {
+			detail["mode"] = "starttls"
+			if _, ok := extensions["STARTTLS"]; !ok {
+				return "warning", errors.New("server does not advertise STARTTLS; traffic would be sent in plaintext")
+			}
+			code, msg, err := smtpDiagCmd(tp, 220, "STARTTLS")
+			detail["code"] = code
+			detail["message"] = msg
+			if err != nil {
+				return "failed", err
+			}
+			tlsConn, status, err := smtpDiagHandshake(conn, host, detail)
+			if tlsConn == nil {
+				return status, err
+			}
+			conn = tlsConn
+			tlsActive = true
+			tp = textproto.NewConn(conn)
+
+			// Extension (terutama AUTH) sering baru diumumkan setelah TLS aktif
+			ehloDetail := map[string]interface{}{}
+			ext, ehloErr := smtpDiagEhlo(tp, ehloDetail)
+			detail["ehlo_after_tls"] = ehloDetail
+			if ehloErr != nil {
+				return "failed", ehloErr
+			}
+			extensions = ext
+			return status, err
+		})
+	}
+
+	// 5. AUTH
+	d.step("auth", func(detail map[string]interface{}) (string, error) {
+		username := profile.Username
+		if username == "" {
+			username = fromAddr
+		}
+		if profile.Password == "" {
+			detail["reason"] = "no credentials configured"
+			return "skipped", nil
+		}
+		password, err := DecryptSecret(profile.Password)
+		if err != nil {
+			return "failed", err
+		}
+
+		mechanisms := strings.Fields(strings.ToUpper(extensions["AUTH"]))
+		detail["mechanisms"] = mechanisms
+		if len(mechanisms) == 0 {
+			return "failed", errors.New("server does not advertise any AUTH mechanism")
+		}
+		if !tlsActive && host != "localhost" && host != "127.0.0.1" {
+			return "failed", errors.New("refusing to send credentials over an unencrypted connection")
+		}
+		detail["username"] = username
+
+		var (
+			code int
+			msg  string
+		)
+		switch {
+		case smtpDiagHas(mechanisms, "PLAIN"):
+			detail["mechanism"] = "PLAIN"
+			token := base64.StdEncoding.EncodeToString([]byte("\x00" + username + "\x00" + password))
+			code, msg, err = smtpDiagCmd(tp, 235, "AUTH PLAIN %s", token)
+		case smtpDiagHas(mechanisms, "LOGIN"):
+			detail["mechanism"] = "LOGIN"
+			if code, msg, err = smtpDiagCmd(tp, 334, "AUTH LOGIN"); err == nil {
+				if code, msg, err = smtpDiagCmd(tp, 334, "%s", base64.StdEncoding.EncodeToString([]byte(username))); err == nil {
+					code, msg, err = smtpDiagCmd(tp, 235, "%s", base64.StdEncoding.EncodeToString([]byte(password)))
+				}
+			}
+		default:
+			return "failed", fmt.Errorf("no supported AUTH mechanism (PLAIN, LOGIN) in %v", mechanisms)
+		}
+		detail["code"] = code
+		detail["message"] = msg
+		return "", err
+	})
+
+	// 6. MAIL FROM
+	d.step("mail_from", func(detail map[string]interface{}) (string, error) {
+		detail["address"] = fromAddr
+		code, msg, err := smtpDiagCmd(tp, 250, "MAIL FROM:<%s>", fromAddr)
+		detail["code"] = code
+		detail["message"] = msg
+		return "", err
+	})
+
+	// 7. RCPT TO (250 atau 251 diterima)
+	d.step("rcpt_to", func(detail map[string]interface{}) (string, error) {
+		detail["address"] = rcpt
+		code, msg, err := smtpDiagCmd(tp, 25, "RCPT TO:<%s>", rcpt)
+		detail["code"] = code
+		detail["message"] = msg
+		return "", err
+	})
+
+	// 8. Tutup sesi tanpa mengirim DATA
+	if tp != nil {
+		smtpDiagCmd(tp, 250, "RSET")
+		smtpDiagCmd(tp, 221, "QUIT")
+	}
+
+	report.Success = !d.failed
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// smtpDiagCmd mengirim satu perintah SMTP dan membaca balasannya.
+func smtpDiagCmd(tp *textproto.Conn, expectCode int, format string, args ...interface{}) (int, string, error) {
+	id, err := tp.Cmd(format, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	tp.StartResponse(id)
+	defer tp.EndResponse(id)
+	return tp.ReadResponse(expectCode)
+}
+
+// smtpDiagEhlo mengirim EHLO (fallback HELO) dan mengembalikan extension yang diumumkan server.
+func smtpDiagEhlo(tp *textproto.Conn, detail map[string]interface{}) (map[string]string, error) {
+	extensions := map[string]string{}
+	code, msg, err := smtpDiagCmd(tp, 250, "EHLO %s", "localhost")
+	if err != nil {
+		detail["ehlo_error"] = err.Error()
+		code, msg, err = smtpDiagCmd(tp, 250, "HELO %s", "localhost")
+		detail["command"] = "HELO"
+	} else {
+		detail["command"] = "EHLO"
+	}
+	detail["code"] = code
+	if err != nil {
+		detail["message"] = msg
+		return extensions, err
+	}
+
+	lines := strings.Split(msg, "\n")
+	detail["greeting"] = lines[0]
+	for _, line := range lines[1:] {
+		name, params, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if name != "" {
+			extensions[strings.ToUpper(name)] = params
+		}
+	}
+	detail["extensions"] = extensions
+	return extensions, nil
+}
+
+// smtpDiagHandshake melakukan handshake TLS dan mencatat detail sertifikat.
+// Verifikasi sertifikat dilakukan manual agar rantai sertifikat tetap tercatat meskipun tidak valid.
+func smtpDiagHandshake(conn net.Conn, host string, detail map[string]interface{}) (net.Conn, string, error) {
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, // diverifikasi manual di bawah
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, "failed", fmt.Errorf("TLS handshake failed: %w", err)
+	}
+
+	state := tlsConn.ConnectionState()
+	detail["tls_version"] = tls.VersionName(state.Version)
+	detail["cipher_suite"] = tls.CipherSuiteName(state.CipherSuite)
+
+	chain := make([]map[string]interface{}, 0, len(state.PeerCertificates))
+	for _, cert := range state.PeerCertificates {
+		chain = append(chain, map[string]interface{}{
+			"subject":         cert.Subject.String(),
+			"issuer":          cert.Issuer.String(),
+			"serial":          cert.SerialNumber.String(),
+			"dns_names":       cert.DNSNames,
+			"not_before":      cert.NotBefore,
+			"not_after":       cert.NotAfter,
+			"expires_in_days": int(time.Until(cert.NotAfter).Hours() / 24),
+		})
+	}
+	detail["certificates"] = chain
+
+	if len(state.PeerCertificates) == 0 {
+		detail["verified"] = false
+		return tlsConn, "failed", errors.New("server presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		detail["verified"] = false
+		return tlsConn, "failed", fmt.Errorf("certificate verification failed: %w", err)
+	}
+	detail["verified"] = true
+	return tlsConn, "success", nil
+}
+
+func smtpDiagHas(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
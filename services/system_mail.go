@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gophish/gomail"
+)
+
+// systemMailSender mengembalikan alamat pengirim email sistem dari SMTP_FROM atau SMTP_USER.
+func systemMailSender() string {
+	if from := strings.TrimSpace(os.Getenv("SMTP_FROM")); from != "" {
+		return from
+	}
+	return strings.TrimSpace(os.Getenv("SMTP_USER"))
+}
+
+// SendSystemEmail mengirim email transaksional aplikasi (bukan email campaign) lewat SMTP_* di environment.
+func SendSystemEmail(to, subject, body string) error {
+	host := strings.TrimSpace(os.Getenv("SMTP_HOST"))
+	from := systemMailSender()
+	if host == "" || from == "" {
+		return fmt.Errorf("system SMTP is not configured (SMTP_HOST, SMTP_FROM)")
+	}
+	port := 587
+	if p := strings.TrimSpace(os.Getenv("SMTP_PORT")); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP_PORT %q", p)
+		}
+		port = n
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", from)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", body)
+
+	d := gomail.NewDialer(host, port, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"))
+	return d.DialAndSend(m)
+}
+
+// appName adalah nama aplikasi yang tampil di email sistem.
+func appName() string {
+	if name := strings.TrimSpace(os.Getenv("APP_NAME")); name != "" {
+		return name
+	}
+	return "Awarenix"
+}
+
+// FrontendLink membangun URL halaman frontend dengan token sebagai query parameter.
+func FrontendLink(path, token string) string {
+	base := strings.TrimRight(strings.TrimSpace(os.Getenv("FRONTEND_URL")), "/")
+	return base + path + "?token=" + url.QueryEscape(token)
+}
+
+// systemMailBody membungkus paragraf dan tombol link dalam HTML sederhana.
+func systemMailBody(greeting, intro, buttonLabel, link, footer string) string {
+	return fmt.Sprintf(`<p>%s</p><p>%s</p><p><a href="%s">%s</a></p><p>%s</p>`,
+		html.EscapeString(greeting), html.EscapeString(intro), html.EscapeString(link), html.EscapeString(buttonLabel), html.EscapeString(footer))
+}
+
+// SendInviteEmail mengirim undangan berisi link untuk mengatur password pertama.
+func SendInviteEmail(name, email, token string) error {
+	link := FrontendLink("/set-password", token)
+	body := systemMailBody(
+		"Hi "+name+",",
+		"You have been invited to "+appName()+". Set your password to activate your account.",
+		"Set your password", link,
+		fmt.Sprintf("This link expires in %d hours and can only be used once.", int(InviteTokenTTL.Hours())),
+	)
+	return SendSystemEmail(email, "You're invited to "+appName(), body)
+}
+
+// SendPasswordResetEmail mengirim link reset password.
+func SendPasswordResetEmail(name, email, token string) error {
+	link := FrontendLink("/reset-password", token)
+	body := systemMailBody(
+		"Hi "+name+",",
+		"We received a request to reset your "+appName()+" password. If you did not request this, you can ignore this email.",
+		"Reset your password", link,
+		fmt.Sprintf("This link expires in %d minutes and can only be used once.", int(PasswordResetTokenTTL.Minutes())),
+	)
+	return SendSystemEmail(email, "Reset your "+appName()+" password", body)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"be-awarenix/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationHeader memilih organisasi yang dilihat super admin (tampilan lintas tenant).
+const OrganizationHeader = "X-Organization-ID"
+
+// PermissionCrossTenant mengizinkan user melihat dan mengelola data semua organisasi.
+const PermissionCrossTenant = "organization:cross-tenant"
+
+var (
+	ErrOrganizationInactive  = errors.New("organization is inactive")
+	ErrOrganizationForbidden = errors.New("no access to this organization")
+	ErrOrganizationNotFound  = errors.New("organization not found")
+)
+
+// DefaultOrganizationID mengembalikan ID organisasi bawaan (models.DefaultOrganizationSlug).
+func DefaultOrganizationID(db *gorm.DB) (uint, error) {
+	var org models.Organization
+	if err := db.Select("id").Where("slug = ?", models.DefaultOrganizationSlug).First(&org).Error; err != nil {
+		return 0, err
+	}
+	return org.ID, nil
+}
+
+// Tenant adalah cakupan organisasi satu request, dibuat oleh middlewares.TenantContext.
+type Tenant struct {
+	// OrganizationID adalah organisasi pemilik data yang dibuat pada request ini.
+	OrganizationID uint
+	// All berarti tanpa filter organisasi: super admin tanpa header X-Organization-ID.
+	All bool
+}
+
+// ResolveTenant menentukan cakupan organisasi user. Pemegang izin organization:cross-tenant melihat
+// semua organisasi, atau satu organisasi jika header X-Organization-ID dikirim.
+func ResolveTenant(db *gorm.DB, user *models.User, header string) (Tenant, error) {
+	crossTenant, err := RoleHasPermission(db, uint(user.Role), PermissionCrossTenant)
+	if err != nil {
+		return Tenant{}, err
+	}
+
+	header = strings.TrimSpace(header)
+	if header == "" {
+		var org models.Organization
+		if err := db.First(&org, user.OrganizationID).Error; err != nil {
+			return Tenant{}, ErrOrganizationNotFound
+		}
+		if org.IsActive == 0 && !crossTenant {
+			return Tenant{}, ErrOrganizationInactive
+		}
+		return Tenant{OrganizationID: org.ID, All: crossTenant}, nil
+	}
+
+	id, err := strconv.ParseUint(header, 10, 32)
+	if err != nil {
+		return Tenant{}, ErrOrganizationNotFound
+	}
+	if uint(id) != user.OrganizationID && !crossTenant {
+		return Tenant{}, ErrOrganizationForbidden
+	}
+	var org models.Organization
+	if err := db.First(&org, id).Error; err != nil {
+		return Tenant{}, ErrOrganizationNotFound
+	}
+	if org.IsActive == 0 && !crossTenant {
+		return Tenant{}, ErrOrganizationInactive
+	}
+	return Tenant{OrganizationID: org.ID}, nil
+}
+
+// GetTenant mengambil Tenant dari context; menulis response error jika tidak ada (sejajar GetRoleScope).
+func GetTenant(c *gin.Context) (Tenant, bool) {
+	v, exists := c.Get("tenant")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "User not authenticated"})
+		return Tenant{}, false
+	}
+	tenant, ok := v.(Tenant)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to parse tenant data"})
+		return Tenant{}, false
+	}
+	return tenant, true
+}
+
+var organizationColumn = clause.Column{Table: clause.CurrentTable, Name: "organization_id"}
+
+// InOrganization adalah GORM scope yang membatasi query ke satu organisasi. Kolom memakai tabel
+// utama statement, sehingga aman dipakai bersama JOIN.
+func InOrganization(organizationID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: organizationColumn, Value: organizationID})
+	}
+}
+
+// InOrganizationOrSystem seperti InOrganization, ditambah template sistem (is_system_template = 1).
+func InOrganizationOrSystem(organizationID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Or(
+			clause.Eq{Column: organizationColumn, Value: organizationID},
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "is_system_template"}, Value: 1},
+		))
+	}
+}
+
+// Scope adalah GORM scope untuk data milik organisasi (users, groups, sending profiles, campaigns, ...).
+func (t Tenant) Scope(db *gorm.DB) *gorm.DB {
+	if t.All {
+		return db
+	}
+	return InOrganization(t.OrganizationID)(db)
+}
+
+// ScopeWithSystem adalah Scope untuk email template dan landing page, yang juga menyertakan template sistem.
+func (t Tenant) ScopeWithSystem(db *gorm.DB) *gorm.DB {
+	if t.All {
+		return db
+	}
+	return InOrganizationOrSystem(t.OrganizationID)(db)
+}
+
+// Owns melaporkan apakah data dengan organizationID boleh diakses tenant ini.
+func (t Tenant) Owns(organizationID uint) bool {
+	return t.All || t.OrganizationID == organizationID
+}
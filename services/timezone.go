@@ -1,16 +1,107 @@
 package services
 
 import (
+	"be-awarenix/models"
+	"fmt"
+	"strings"
 	"time"
 )
 
-var JakartaLocation *time.Location
+// DefaultLocation adalah zona waktu aplikasi (APP_TIMEZONE, diset ke time.Local di main.go).
+// Dipakai untuk member yang tidak punya timezone dan negaranya tidak dikenali.
+func DefaultLocation() *time.Location {
+	return time.Local
+}
+
+// countryTimezones memetakan kode ISO 3166-1 alpha-2 dan nama negara umum ke zona waktu ibu kota.
+// Negara dengan beberapa zona waktu (mis. US, Indonesia) memakai zona yang paling banyak dipakai;
+// isi Member.Timezone secara eksplisit untuk anggota di zona lain.
+var countryTimezones = map[string]string{
+	"ID": "Asia/Jakarta", "INDONESIA": "Asia/Jakarta",
+	"SG": "Asia/Singapore", "SINGAPORE": "Asia/Singapore",
+	"MY": "Asia/Kuala_Lumpur", "MALAYSIA": "Asia/Kuala_Lumpur",
+	"TH": "Asia/Bangkok", "THAILAND": "Asia/Bangkok",
+	"VN": "Asia/Ho_Chi_Minh", "VIETNAM": "Asia/Ho_Chi_Minh", "VIET NAM": "Asia/Ho_Chi_Minh",
+	"PH": "Asia/Manila", "PHILIPPINES": "Asia/Manila", "FILIPINA": "Asia/Manila",
+	"BN": "Asia/Brunei", "BRUNEI": "Asia/Brunei",
+	"TL": "Asia/Dili", "TIMOR-LESTE": "Asia/Dili",
+	"MM": "Asia/Yangon", "MYANMAR": "Asia/Yangon",
+	"KH": "Asia/Phnom_Penh", "CAMBODIA": "Asia/Phnom_Penh", "KAMBOJA": "Asia/Phnom_Penh",
+	"CN": "Asia/Shanghai", "CHINA": "Asia/Shanghai", "TIONGKOK": "Asia/Shanghai",
+	"HK": "Asia/Hong_Kong", "HONG KONG": "Asia/Hong_Kong",
+	"TW": "Asia/Taipei", "TAIWAN": "Asia/Taipei",
+	"JP": "Asia/Tokyo", "JAPAN": "Asia/Tokyo", "JEPANG": "Asia/Tokyo",
+	"KR": "Asia/Seoul", "SOUTH KOREA": "Asia/Seoul", "KOREA SELATAN": "Asia/Seoul",
+	"IN": "Asia/Kolkata", "INDIA": "Asia/Kolkata",
+	"PK": "Asia/Karachi", "PAKISTAN": "Asia/Karachi",
+	"BD": "Asia/Dhaka", "BANGLADESH": "Asia/Dhaka",
+	"AE": "Asia/Dubai", "UNITED ARAB EMIRATES": "Asia/Dubai", "UAE": "Asia/Dubai", "UNI EMIRAT ARAB": "Asia/Dubai",
+	"SA": "Asia/Riyadh", "SAUDI ARABIA": "Asia/Riyadh", "ARAB SAUDI": "Asia/Riyadh",
+	"QA": "Asia/Qatar", "QATAR": "Asia/Qatar",
+	"AU": "Australia/Sydney", "AUSTRALIA": "Australia/Sydney",
+	"NZ": "Pacific/Auckland", "NEW ZEALAND": "Pacific/Auckland", "SELANDIA BARU": "Pacific/Auckland",
+	"GB": "Europe/London", "UK": "Europe/London", "UNITED KINGDOM": "Europe/London", "INGGRIS": "Europe/London",
+	"IE": "Europe/Dublin", "IRELAND": "Europe/Dublin",
+	"NL": "Europe/Amsterdam", "NETHERLANDS": "Europe/Amsterdam", "BELANDA": "Europe/Amsterdam",
+	"DE": "Europe/Berlin", "GERMANY": "Europe/Berlin", "JERMAN": "Europe/Berlin",
+	"FR": "Europe/Paris", "FRANCE": "Europe/Paris", "PERANCIS": "Europe/Paris",
+	"ES": "Europe/Madrid", "SPAIN": "Europe/Madrid", "SPANYOL": "Europe/Madrid",
+	"IT": "Europe/Rome", "ITALY": "Europe/Rome", "ITALIA": "Europe/Rome",
+	"CH": "Europe/Zurich", "SWITZERLAND": "Europe/Zurich", "SWISS": "Europe/Zurich",
+	"SE": "Europe/Stockholm", "SWEDEN": "Europe/Stockholm", "SWEDIA": "Europe/Stockholm",
+	"PL": "Europe/Warsaw", "POLAND": "Europe/Warsaw", "POLANDIA": "Europe/Warsaw",
+	"TR": "Europe/Istanbul", "TURKEY": "Europe/Istanbul", "TURKI": "Europe/Istanbul",
+	"RU": "Europe/Moscow", "RUSSIA": "Europe/Moscow", "RUSIA": "Europe/Moscow",
+	"ZA": "Africa/Johannesburg", "SOUTH AFRICA": "Africa/Johannesburg", "AFRIKA SELATAN": "Africa/Johannesburg",
+	"EG": "Africa/Cairo", "EGYPT": "Africa/Cairo", "MESIR": "Africa/Cairo",
+	"NG": "Africa/Lagos", "NIGERIA": "Africa/Lagos",
+	"KE": "Africa/Nairobi", "KENYA": "Africa/Nairobi",
+	"US": "America/New_York", "USA": "America/New_York", "UNITED STATES": "America/New_York", "AMERIKA SERIKAT": "America/New_York",
+	"CA": "America/Toronto", "CANADA": "America/Toronto", "KANADA": "America/Toronto",
+	"MX": "America/Mexico_City", "MEXICO": "America/Mexico_City", "MEKSIKO": "America/Mexico_City",
+	"BR": "America/Sao_Paulo", "BRAZIL": "America/Sao_Paulo", "BRASIL": "America/Sao_Paulo",
+	"AR": "America/Argentina/Buenos_Aires", "ARGENTINA": "America/Argentina/Buenos_Aires",
+}
+
+// NormalizeTimezone memvalidasi nama zona waktu IANA (mis. "Asia/Makassar"). String kosong valid
+// dan berarti timezone diturunkan dari negara member.
+func NormalizeTimezone(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	if strings.EqualFold(name, "local") {
+		return "", fmt.Errorf("invalid timezone %q, use an IANA name such as Asia/Jakarta", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid timezone %q, use an IANA name such as Asia/Jakarta", name)
+	}
+	return loc.String(), nil
+}
+
+// CountryTimezone mengembalikan zona waktu untuk kode atau nama negara, atau "" jika tidak dikenali.
+func CountryTimezone(country string) string {
+	return countryTimezones[strings.ToUpper(strings.TrimSpace(country))]
+}
+
+// MemberTimezone menentukan zona waktu efektif member: timezone eksplisit, lalu turunan Country,
+// lalu zona waktu aplikasi.
+func MemberTimezone(timezone, country string) string {
+	if tz, err := NormalizeTimezone(timezone); err == nil && tz != "" {
+		return tz
+	}
+	if tz := CountryTimezone(country); tz != "" {
+		return tz
+	}
+	return DefaultLocation().String()
+}
 
-func init() {
-	loc, err := time.LoadLocation("Asia/Jakarta")
+// MemberLocation adalah MemberTimezone dalam bentuk *time.Location.
+func MemberLocation(member models.Member) *time.Location {
+	loc, err := time.LoadLocation(MemberTimezone(member.Timezone, member.Country))
 	if err != nil {
-		JakartaLocation = time.FixedZone("WIB", 7*3600)
-	} else {
-		JakartaLocation = loc
+		return DefaultLocation()
 	}
+	return loc
 }
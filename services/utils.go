@@ -43,20 +43,45 @@ func LogEventByRID(c *gin.Context, rid string, eventType string, campaignLanguag
 		return
 	}
 
-	// 2. Kumpulkan metadata umum
+	// 2. Simpan event
+	RecordEvent(c, rec, models.EventType(eventType))
+
+	// 3. Response: serve pixel / redirect / text
+	switch eventType {
+	case string(models.Opened):
+		c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+		c.File("pixel.gif")
+	case string(models.Clicked):
+		target, _ := url.QueryUnescape(c.Query("url"))
+		c.Redirect(http.StatusFound, target) // Menggunakan http.StatusFound (302)
+	case string(models.Submitted):
+		c.Redirect(http.StatusFound, "http://localhost:5173/dashboard") // Menggunakan http.StatusFound (302)
+	case string(models.Reported):
+		frontendDomain := "localhost:5173"
+		// Meneruskan parameter bahasa yang diterima ke URL frontend
+		c.Redirect(http.StatusFound, fmt.Sprintf("http://%s/report-thanks?lang=%s", frontendDomain, campaignLanguage)) // Menggunakan http.StatusFound (302)
+	default:
+		c.Status(http.StatusNoContent) // Menggunakan http.StatusNoContent (204)
+	}
+}
+
+// RecordEvent menyimpan event untuk recipient beserta metadata request.
+// Event dengan tipe yang sama hanya dicatat sekali per recipient.
+func RecordEvent(c *gin.Context, rec models.Recipient, evType models.EventType) error {
+	// 1. Kumpulkan metadata umum
 	uaString := c.Request.UserAgent()
 	ua := user_agent.New(uaString)
 	browserName, browserVersion := ua.Browser()
 	osName := ua.OS()
 
-	// 3. Siapkan map untuk detail payload
+	// 2. Siapkan map untuk detail payload
 	metaMap := map[string]interface{}{
 		"query":     c.Request.URL.Query(),
 		"referrer":  c.Request.Referer(),
 		"userAgent": uaString,
 	}
 
-	// 4. Bila metode POST, tambahkan seluruh form fields
+	// 3. Bila metode POST, tambahkan seluruh form fields
 	if c.Request.Method == "POST" {
 		c.Request.ParseForm()
 		formCopy := make(map[string][]string)
@@ -66,14 +91,13 @@ func LogEventByRID(c *gin.Context, rid string, eventType string, campaignLanguag
 		metaMap["form"] = formCopy
 	}
 
-	// 5. Marshal ke JSON untuk kolom Metadata
+	// 4. Marshal ke JSON untuk kolom Metadata
 	metaJSON, _ := json.Marshal(metaMap)
 
-	// 6. Buat object Event
-	evType := models.EventType(eventType)
+	// 5. Buat object Event
 	e := models.Event{
 		RecipientID:  rec.ID,
-		RecipientRID: rid,
+		RecipientRID: rec.UID,
 		CampaignID:   rec.CampaignID,
 		Type:         evType,
 		Timestamp:    time.Now(),
@@ -84,34 +108,19 @@ func LogEventByRID(c *gin.Context, rid string, eventType string, campaignLanguag
 		Metadata:     datatypes.JSON(metaJSON),
 	}
 
-	// 7. Duplicate check: cari count dengan recipient_id, campaign_id, type yang sama
+	// 6. Duplicate check: cari count dengan recipient_id, campaign_id, type yang sama
 	var cnt int64
-	config.DB.Model(&models.Event{}).
+	if err := config.DB.Model(&models.Event{}).
 		Where("recipient_id = ? AND campaign_id = ? AND type = ?", rec.ID, rec.CampaignID, evType).
-		Count(&cnt)
-
-	// 8. Simpan hanya jika belum ada
-	if cnt == 0 {
-		config.DB.Create(&e)
+		Count(&cnt).Error; err != nil {
+		return err
 	}
 
-	// 9. Response: serve pixel / redirect / text
-	switch eventType {
-	case string(models.Opened):
-		c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
-		c.File("pixel.gif")
-	case string(models.Clicked):
-		target, _ := url.QueryUnescape(c.Query("url"))
-		c.Redirect(http.StatusFound, target) // Menggunakan http.StatusFound (302)
-	case string(models.Submitted):
-		c.Redirect(http.StatusFound, "http://localhost:5173/dashboard") // Menggunakan http.StatusFound (302)
-	case string(models.Reported):
-		frontendDomain := "localhost:5173"
-		// Meneruskan parameter bahasa yang diterima ke URL frontend
-		c.Redirect(http.StatusFound, fmt.Sprintf("http://%s/report-thanks?lang=%s", frontendDomain, campaignLanguage)) // Menggunakan http.StatusFound (302)
-	default:
-		c.Status(http.StatusNoContent) // Menggunakan http.StatusNoContent (204)
+	// 7. Simpan hanya jika belum ada
+	if cnt == 0 {
+		return config.DB.Create(&e).Error
 	}
+	return nil
 }
 
 func RewriteLinks(
@@ -188,25 +197,6 @@ func GetRoleScope(c *gin.Context) (int, int, bool) {
 	return userID, role, true
 }
 
-func GetRoleScopeDashboard(c *gin.Context) (int, int, int, bool) {
-	userScope, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "User not authenticated"})
-		return 0, 0, 0, false
-	}
-
-	user, ok := userScope.(*models.User)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "Failed to parse user data: invalid user object in context"})
-		return 0, 0, 0, false
-	}
-
-	userID := int(user.ID)
-	role := user.Role
-
-	return userID, role, user.CreatedBy, true
-}
-
 func EncodeID(id int) string {
 	hd := hashids.NewData()
 	hd.Salt = os.Getenv("SALT_SECRET")